@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/activadee/videocraft/internal/core/video/composition"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+// AdminHandler handles operator-only HTTP requests
+type AdminHandler struct {
+	services *composition.Services
+	logger   logger.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(services *composition.Services, logger logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		services: services,
+		logger:   logger,
+	}
+}
+
+// PauseQueue handles POST /admin/queue/pause - stops workers from picking up new jobs
+func (h *AdminHandler) PauseQueue(c *gin.Context) {
+	h.services.Job.Pause()
+	h.logger.Infof("Job queue paused by admin request from %s", c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"paused":  true,
+	})
+}
+
+// ResumeQueue handles POST /admin/queue/resume - resumes processing of queued jobs
+func (h *AdminHandler) ResumeQueue(c *gin.Context) {
+	h.services.Job.Resume()
+	h.logger.Infof("Job queue resumed by admin request from %s", c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"paused":  false,
+	})
+}
+
+// WorkerStatus handles GET /admin/workers - reports worker count, active jobs and queue depth
+func (h *AdminHandler) WorkerStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.Job.WorkerStats())
+}
+
+// ScaleWorkersRequest is the body of POST /admin/workers
+type ScaleWorkersRequest struct {
+	Workers int `json:"workers" binding:"required"`
+}
+
+// ScaleWorkers handles POST /admin/workers - resizes the worker pool without a restart
+func (h *AdminHandler) ScaleWorkers(c *gin.Context) {
+	var req ScaleWorkersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body: workers is required",
+		})
+		return
+	}
+
+	if err := h.services.Job.SetWorkers(req.Workers); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	h.logger.Infof("Worker pool scaled to %d by admin request from %s", req.Workers, c.ClientIP())
+
+	c.JSON(http.StatusOK, h.services.Job.WorkerStats())
+}
+
+// QueueStatus handles GET /admin/queue - reports whether the queue is paused
+func (h *AdminHandler) QueueStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"paused": h.services.Job.IsPaused(),
+	})
+}
@@ -85,35 +85,34 @@ func (h *HealthHandler) Metrics(c *gin.Context) {
 	c.JSON(http.StatusOK, metrics)
 }
 
-// Ready handles GET /ready
+// Ready handles GET /ready - aggregates dependency checks, 503 if a critical one is down
 func (h *HealthHandler) Ready(c *gin.Context) {
-	// TODO: Add actual readiness checks in Phase 2
-	// Check if all required services are available
-	ready := true
-	checks := gin.H{
-		"database": "ok",
-		"storage":  "ok",
-		"ffmpeg":   "ok",
-	}
+	report := h.services.Health.Check(c.Request.Context())
 
 	status := http.StatusOK
-	if !ready {
+	if !report.Ready {
 		status = http.StatusServiceUnavailable
 	}
 
 	c.JSON(status, gin.H{
-		"ready":     ready,
-		"checks":    checks,
-		"timestamp": time.Now().UTC(),
+		"ready":     report.Ready,
+		"checks":    report.Components,
+		"timestamp": report.Timestamp,
 	})
 }
 
-// Live handles GET /live
+// Live handles GET /live. It only reports whether a Whisper daemon is running:
+// daemons start on demand and stop when idle, so a stopped one must not fail
+// liveness, and probing them is left to /ready.
 func (h *HealthHandler) Live(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"alive":     true,
 		"timestamp": time.Now().UTC(),
-	})
+	}
+	if h.services.Transcription != nil {
+		response["whisper_running"] = h.services.Transcription.HealthCheck() == nil
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // Helper function to convert bytes to megabytes
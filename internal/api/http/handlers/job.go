@@ -69,6 +69,30 @@ func (h *JobHandler) GetJob(c *gin.Context) {
 		response["error"] = job.Error
 	}
 
+	if len(job.Warnings) > 0 {
+		response["warnings"] = job.Warnings
+	}
+
+	if job.SubtitleID != "" {
+		response["subtitle_id"] = job.SubtitleID
+	}
+
+	if len(job.SubtitleFiles) > 0 {
+		response["subtitle_files"] = job.SubtitleFiles
+	}
+
+	if job.ManifestID != "" {
+		response["manifest_id"] = job.ManifestID
+	}
+
+	if job.Health != "" {
+		response["health"] = job.Health
+	}
+
+	if len(job.ResolvedSrcs) > 0 {
+		response["resolved_srcs"] = job.ResolvedSrcs
+	}
+
 	// Add video URL if completed
 	if job.Status == "completed" && job.VideoID != "" {
 		response["video_url"] = fmt.Sprintf("/api/v1/videos/%s", job.VideoID)
@@ -77,6 +101,87 @@ func (h *JobHandler) GetJob(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// GetJobManifest handles GET /jobs/:id/manifest - Returns the render manifest of a completed job
+func (h *JobHandler) GetJobManifest(c *gin.Context) {
+	jobID := c.Param("id")
+	h.logger.Debugf("Job manifest request for ID: %s", jobID)
+
+	job, err := h.services.Job.GetJob(jobID)
+	if err != nil {
+		h.logger.Errorf("Failed to get job %s: %v", jobID, err)
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Job not found",
+			"job_id": jobID,
+		})
+		return
+	}
+
+	if job.ManifestID == "" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Manifest not available",
+			"job_id": jobID,
+			"status": job.Status,
+		})
+		return
+	}
+
+	filePath, err := h.services.Storage.GetVideo(job.ManifestID)
+	if err != nil {
+		h.logger.Errorf("Failed to get manifest %s: %v", job.ManifestID, err)
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Manifest not found",
+			"job_id": jobID,
+		})
+		return
+	}
+
+	c.Header("Content-Type", "application/json")
+	c.Header("Cache-Control", "no-cache")
+	c.File(filePath)
+}
+
+// GetJobSubtitles handles GET /jobs/:id/subtitles - the stored sidecar
+// subtitles of a job rendered with subtitle_mode "sidecar" or "json"
+func (h *JobHandler) GetJobSubtitles(c *gin.Context) {
+	jobID := c.Param("id")
+	h.logger.Debugf("Job subtitles request for ID: %s", jobID)
+
+	job, err := h.services.Job.GetJob(jobID)
+	if err != nil {
+		h.logger.Errorf("Failed to get job %s: %v", jobID, err)
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":  "Job not found",
+			"job_id": jobID,
+		})
+		return
+	}
+
+	if job.SubtitleID == "" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":  "Subtitles not available",
+			"job_id": jobID,
+			"status": job.Status,
+		})
+		return
+	}
+
+	filePath, err := h.services.Storage.GetVideo(job.SubtitleID)
+	if err != nil {
+		h.logger.Errorf("Failed to get subtitles %s: %v", job.SubtitleID, err)
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":  "Subtitles not found",
+			"job_id": jobID,
+		})
+		return
+	}
+
+	if metadata, err := h.services.Storage.GetVideoMetadata(job.SubtitleID); err == nil {
+		c.Header("Content-Type", metadata.ContentType)
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.File(filePath)
+}
+
 // DeleteJob handles DELETE /jobs/:id - REST-compliant job cancellation
 func (h *JobHandler) DeleteJob(c *gin.Context) {
 	jobID := c.Param("id")
@@ -121,6 +226,30 @@ func (h *JobHandler) DeleteJob(c *gin.Context) {
 		response["error"] = job.Error
 	}
 
+	if len(job.Warnings) > 0 {
+		response["warnings"] = job.Warnings
+	}
+
+	if job.SubtitleID != "" {
+		response["subtitle_id"] = job.SubtitleID
+	}
+
+	if len(job.SubtitleFiles) > 0 {
+		response["subtitle_files"] = job.SubtitleFiles
+	}
+
+	if job.ManifestID != "" {
+		response["manifest_id"] = job.ManifestID
+	}
+
+	if job.Health != "" {
+		response["health"] = job.Health
+	}
+
+	if len(job.ResolvedSrcs) > 0 {
+		response["resolved_srcs"] = job.ResolvedSrcs
+	}
+
 	// TODO: Implement job cancellation logic
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Job cancellation not yet implemented",
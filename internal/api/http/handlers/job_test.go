@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/core/video/composition"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+	storage "github.com/activadee/videocraft/internal/storage/filesystem"
+)
+
+// fakeJobs serves canned jobs; the handlers under test use no other
+// job service method
+type fakeJobs struct {
+	composition.JobService
+	jobs map[string]*models.Job
+}
+
+func (f *fakeJobs) GetJob(jobID string) (*models.Job, error) {
+	job, ok := f.jobs[jobID]
+	if !ok {
+		return nil, errors.JobNotFound(jobID)
+	}
+	copied := *job
+	return &copied, nil
+}
+
+// newTestJobHandler returns a job handler serving jobs from filesystem
+// storage in temp directories
+func newTestJobHandler(t *testing.T, jobs ...*models.Job) (*JobHandler, storage.Service) {
+	t.Helper()
+	cfg := &app.Config{}
+	cfg.Storage.OutputDir = t.TempDir()
+	cfg.Storage.TempDir = t.TempDir()
+	log := logger.NewWithWriter("error", io.Discard, "text")
+
+	fake := &fakeJobs{jobs: make(map[string]*models.Job)}
+	for _, job := range jobs {
+		fake.jobs[job.ID] = job
+	}
+	store := storage.NewService(cfg, log)
+	return NewJobHandler(&composition.Services{Job: fake, Storage: store}, log), store
+}
+
+// serveJobRoute serves a GET request for path through route handled by handle
+func serveJobRoute(route, path string, handle gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET(route, handle)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestGetJobManifest(t *testing.T) {
+	const manifest = `{"job_id": "done", "video_id": "launch"}`
+	h, store := newTestJobHandler(t,
+		&models.Job{ID: "done", Status: models.JobStatusCompleted, VideoID: "launch", ManifestID: "launch-manifest"},
+		&models.Job{ID: "running", Status: models.JobStatusProcessing},
+		&models.Job{ID: "lost", Status: models.JobStatusCompleted, VideoID: "gone", ManifestID: "gone-manifest"},
+	)
+	if _, err := store.StoreManifest([]byte(manifest), "launch"); err != nil {
+		t.Fatalf("StoreManifest() error = %v", err)
+	}
+
+	tests := []struct {
+		jobID    string
+		wantCode int
+		wantBody string
+	}{
+		{"done", http.StatusOK, manifest},
+		{"running", http.StatusNotFound, "Manifest not available"},
+		{"lost", http.StatusNotFound, "Manifest not found"},
+		{"missing", http.StatusNotFound, "Job not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.jobID, func(t *testing.T) {
+			rec := serveJobRoute("/jobs/:id/manifest", "/jobs/"+tt.jobID+"/manifest", h.GetJobManifest)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.wantCode == http.StatusOK {
+				if rec.Body.String() != manifest || rec.Header().Get("Content-Type") != "application/json" {
+					t.Errorf("response = %s %q, want the stored manifest as JSON", rec.Header().Get("Content-Type"), rec.Body.String())
+				}
+				return
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/core/video/composition"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+// TranscriptionHandler handles standalone transcription requests
+type TranscriptionHandler struct {
+	cfg      *app.Config
+	services *composition.Services
+	log      logger.Logger
+}
+
+// NewTranscriptionHandler creates a new transcription handler
+func NewTranscriptionHandler(cfg *app.Config, services *composition.Services, log logger.Logger) *TranscriptionHandler {
+	return &TranscriptionHandler{
+		cfg:      cfg,
+		services: services,
+		log:      log,
+	}
+}
+
+// TranscribeRequest is the body of POST /transcribe
+type TranscribeRequest struct {
+	URL      string `json:"url" binding:"required"`
+	Language string `json:"language,omitempty"`
+
+	// WordTimestamps includes word timings in the response (default true)
+	WordTimestamps *bool `json:"word_timestamps,omitempty"`
+}
+
+// Transcribe handles POST /transcribe - transcribes an audio URL with Whisper
+// and returns the transcription without creating a video job
+func (h *TranscriptionHandler) Transcribe(c *gin.Context) {
+	var req TranscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body: url is required",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.validateRequest(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid transcription request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !h.cfg.Transcription.Enabled {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Transcription is disabled",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.cfg.Transcription.Processing.Timeout)
+	defer cancel()
+
+	result, err := h.services.Transcription.TranscribeAudio(ctx, req.URL, req.Language)
+	if err != nil {
+		h.log.Errorf("Standalone transcription of %s failed: %v", req.URL, err)
+		status, vpe := h.transcriptionError(ctx, err)
+		c.JSON(status, gin.H{
+			"error":   "Transcription failed",
+			"details": errors.SanitizeForClient(vpe),
+		})
+		return
+	}
+
+	if req.WordTimestamps != nil && !*req.WordTimestamps {
+		withoutWords := *result
+		withoutWords.WordTimestamps = nil
+		result = &withoutWords
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// validateRequest checks the audio URL like job media URLs and the language
+// like element languages. Uploaded files only exist for video requests.
+func (h *TranscriptionHandler) validateRequest(req TranscribeRequest) error {
+	if h.cfg.Storage.IsUploadedFile(req.URL) {
+		return fmt.Errorf("uploaded files cannot be transcribed standalone")
+	}
+	if err := validateRemoteURL(req.URL); err != nil {
+		return fmt.Errorf("invalid audio URL '%s': %w", req.URL, err)
+	}
+	if err := h.services.FFmpeg.ValidateMediaURL(req.URL); err != nil {
+		return fmt.Errorf("invalid audio URL '%s': %w", req.URL, err)
+	}
+
+	if req.Language != "" && !models.IsTranscriptionLanguage(req.Language) {
+		return fmt.Errorf("unsupported language %q: must be a lower-case ISO 639-1 code or \"auto\"", req.Language)
+	}
+	return nil
+}
+
+// transcriptionError maps a transcription failure to an HTTP status and a
+// domain error that is safe to describe to the client
+func (h *TranscriptionHandler) transcriptionError(ctx context.Context, err error) (int, *errors.VideoProcessingError) {
+	var vpe *errors.VideoProcessingError
+	if stderrors.As(err, &vpe) && vpe.Code == errors.ErrCodeTranscriptionUnavailable {
+		return http.StatusServiceUnavailable, vpe
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return http.StatusGatewayTimeout, errors.Timeout("transcription", h.cfg.Transcription.Processing.Timeout.String())
+	}
+	return http.StatusBadGateway, errors.TranscriptionFailed(err)
+}
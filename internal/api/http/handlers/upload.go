@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/activadee/videocraft/internal/api/models"
+)
+
+// uploadSrcPrefix marks a media src that refers to a file uploaded with the request
+const uploadSrcPrefix = "upload:"
+
+// configFormField is the multipart field carrying the video config JSON
+const configFormField = "config"
+
+// validUploadNameRegex restricts upload names to plain file names
+var validUploadNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,127}$`)
+
+// isMultipartRequest reports whether the request body is multipart/form-data
+func isMultipartRequest(c *gin.Context) bool {
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// parseMultipartVideoRequest reads the config JSON and uploaded media from a
+// multipart request, saves the uploads to a fresh directory and rewrites
+// "upload:<name>" srcs to the saved files. The returned directory must be
+// removed by the caller if the request is not turned into a job.
+func (h *VideoHandler) parseMultipartVideoRequest(c *gin.Context) (models.VideoConfigArray, string, error) {
+	limits := h.cfg.Storage
+	// Allow some headroom for the config field and multipart framing
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limits.MaxUploadTotalSize+1024*1024)
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid multipart body: %w", err)
+	}
+
+	uploadDir := filepath.Join(limits.UploadDir(), uuid.New().String())
+	if err := os.MkdirAll(uploadDir, limits.DirPerm()); err != nil {
+		return nil, "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	var config models.VideoConfigArray
+	var configFound bool
+	uploads := make(map[string]string)
+	var totalSize int64
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, uploadDir, fmt.Errorf("invalid multipart body: %w", err)
+		}
+
+		if part.FileName() == "" {
+			if part.FormName() == configFormField {
+				if err := json.NewDecoder(part).Decode(&config); err != nil {
+					return nil, uploadDir, fmt.Errorf("invalid config JSON: %w", err)
+				}
+				configFound = true
+			}
+			continue
+		}
+
+		name := part.FileName()
+		if !validUploadNameRegex.MatchString(name) {
+			return nil, uploadDir, fmt.Errorf("invalid upload file name %q", name)
+		}
+		if _, exists := uploads[name]; exists {
+			return nil, uploadDir, fmt.Errorf("duplicate upload file name %q", name)
+		}
+
+		path := filepath.Join(uploadDir, name)
+		size, err := saveUpload(part, path, limits.MaxUploadFileSize, limits.FilePerm())
+		if err != nil {
+			return nil, uploadDir, fmt.Errorf("upload %q: %w", name, err)
+		}
+		totalSize += size
+		if totalSize > limits.MaxUploadTotalSize {
+			return nil, uploadDir, fmt.Errorf("uploads exceed total size limit of %d bytes", limits.MaxUploadTotalSize)
+		}
+		uploads[name] = path
+	}
+
+	if !configFound {
+		return nil, uploadDir, fmt.Errorf("missing %q form field", configFormField)
+	}
+
+	if err := rewriteUploadSrcs(config, uploads); err != nil {
+		return nil, uploadDir, err
+	}
+
+	h.log.Infof("Received %d uploaded media files (%d bytes)", len(uploads), totalSize)
+	return config, uploadDir, nil
+}
+
+// saveUpload writes a file part to path, failing once it exceeds maxSize
+func saveUpload(src io.Reader, path string, maxSize int64, perm os.FileMode) (int64, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	size, err := io.Copy(file, io.LimitReader(src, maxSize+1))
+	if err != nil {
+		return size, fmt.Errorf("failed to save file: %w", err)
+	}
+	if size > maxSize {
+		return size, fmt.Errorf("exceeds file size limit of %d bytes", maxSize)
+	}
+	return size, nil
+}
+
+// rewriteUploadSrcs replaces every "upload:<name>" src with the saved file path
+func rewriteUploadSrcs(config models.VideoConfigArray, uploads map[string]string) error {
+	return forEachSrc(config, func(src *string) error {
+		if !strings.HasPrefix(*src, uploadSrcPrefix) {
+			return nil
+		}
+		name := strings.TrimPrefix(*src, uploadSrcPrefix)
+		path, ok := uploads[name]
+		if !ok {
+			return fmt.Errorf("src %q references a file that was not uploaded", *src)
+		}
+		*src = path
+		return nil
+	})
+}
+
+// rejectUploadedSrcs keeps JSON requests from pointing at files uploaded by other requests
+func (h *VideoHandler) rejectUploadedSrcs(config models.VideoConfigArray) error {
+	return forEachSrc(config, func(src *string) error {
+		if h.cfg.Storage.IsUploadedFile(*src) {
+			return fmt.Errorf("local file srcs are only allowed for files uploaded with the request")
+		}
+		return nil
+	})
+}
+
+// forEachSrc calls fn with a pointer to every media src in the config
+func forEachSrc(config models.VideoConfigArray, fn func(src *string) error) error {
+	for i := range config {
+		project := &config[i]
+		for j := range project.Elements {
+			if err := forEachElementSrc(&project.Elements[j], fn); err != nil {
+				return err
+			}
+		}
+		for j := range project.Scenes {
+			for k := range project.Scenes[j].Elements {
+				if err := forEachElementSrc(&project.Scenes[j].Elements[k], fn); err != nil {
+					return err
+				}
+			}
+		}
+		if project.Timeline != nil {
+			for j := range project.Timeline.Tracks {
+				for k := range project.Timeline.Tracks[j].Clips {
+					if err := fn(&project.Timeline.Tracks[j].Clips[k].Src); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// forEachElementSrc calls fn with the element's src and each of its fallback srcs
+func forEachElementSrc(element *models.Element, fn func(src *string) error) error {
+	if err := fn(&element.Src); err != nil {
+		return err
+	}
+	for i := range element.FallbackSrcs {
+		if err := fn(&element.FallbackSrcs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateUploadedAudioNotTranscribed rejects uploaded narration in projects
+// with subtitles, since the transcription daemon only fetches URLs
+func (h *VideoHandler) validateUploadedAudioNotTranscribed(config models.VideoConfigArray) error {
+	for _, project := range config {
+		hasSubtitles, hasUploadedAudio := false, false
+		for _, element := range project.Elements {
+			hasSubtitles = hasSubtitles || element.Type == "subtitles"
+		}
+		for _, scene := range project.Scenes {
+			for _, element := range scene.Elements {
+				hasSubtitles = hasSubtitles || element.Type == "subtitles"
+				hasUploadedAudio = hasUploadedAudio || (element.Type == "audio" && h.cfg.Storage.IsUploadedFile(element.Src))
+			}
+		}
+
+		if hasSubtitles && hasUploadedAudio {
+			return fmt.Errorf("uploaded audio cannot be transcribed for subtitles; host it at a URL instead")
+		}
+	}
+	return nil
+}
@@ -1,44 +1,88 @@
 package handlers
 
 import (
-	"context"
+	stderrors "errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
 	"github.com/activadee/videocraft/internal/core/video/composition"
+	"github.com/activadee/videocraft/internal/pkg/errors"
 	"github.com/activadee/videocraft/internal/pkg/logger"
+	storageServices "github.com/activadee/videocraft/internal/storage/filesystem"
 )
 
 type VideoHandler struct {
+	cfg      *app.Config
 	services *composition.Services
 	log      logger.Logger
 }
 
-func NewVideoHandler(services *composition.Services, log logger.Logger) *VideoHandler {
+func NewVideoHandler(cfg *app.Config, services *composition.Services, log logger.Logger) *VideoHandler {
 	return &VideoHandler{
+		cfg:      cfg,
 		services: services,
 		log:      log,
 	}
 }
 
-// CreateVideo handles POST /videos - REST-compliant video creation
+// CreateVideo handles POST /videos - REST-compliant video creation. The body is
+// either the config JSON or multipart/form-data with the config in a "config"
+// field plus uploaded media referenced as "upload:<filename>".
 func (h *VideoHandler) CreateVideo(c *gin.Context) {
 	h.log.Info("Generate video request received")
 
 	// Parse request body
 	var config models.VideoConfigArray
-	if err := c.ShouldBindJSON(&config); err != nil {
-		h.log.Errorf("Failed to parse video config: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid JSON format",
-			"details": err.Error(),
-		})
-		return
+	jobCreated := false
+	if isMultipartRequest(c) {
+		parsed, uploadDir, err := h.parseMultipartVideoRequest(c)
+		if uploadDir != "" {
+			// Uploads belong to the job once it is created; remove them on any earlier failure
+			defer func() {
+				if jobCreated {
+					return
+				}
+				if err := os.RemoveAll(uploadDir); err != nil {
+					h.log.Warnf("Failed to remove upload directory %s: %v", uploadDir, err)
+				}
+			}()
+		}
+		if err == nil {
+			err = h.validateUploadedAudioNotTranscribed(parsed)
+		}
+		if err != nil {
+			h.log.Errorf("Failed to parse multipart video request: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid multipart request",
+				"details": err.Error(),
+			})
+			return
+		}
+		config = parsed
+	} else {
+		if err := c.ShouldBindJSON(&config); err != nil {
+			h.log.Errorf("Failed to parse video config: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid JSON format",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err := h.rejectUploadedSrcs(config); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid media URLs",
+				"details": err.Error(),
+			})
+			return
+		}
 	}
 
 	// Validate configuration
@@ -53,40 +97,134 @@ func (h *VideoHandler) CreateVideo(c *gin.Context) {
 	if err := h.validateMediaURLs(&config); err != nil {
 		h.log.Errorf("Media URL validation failed: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid media URLs",
+			"error":   "Invalid media URLs",
 			"details": err.Error(),
 		})
 		return
 	}
 
 	// Create job for async processing
-	job, err := h.services.Job.CreateJob(&config)
+	// ?priority=low|normal|high lets quick renders overtake batch work
+	job, err := h.services.Job.CreateJob(&config, models.JobPriority(c.Query("priority")))
 	if err != nil {
 		h.log.Errorf("Failed to create job: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create video generation job",
+		c.JSON(statusForJobError(err), gin.H{
+			"error":   "Failed to create video generation job",
+			"details": errors.SanitizeForClient(err),
 		})
 		return
 	}
-
-	// Start background processing
-	go func() {
-		ctx := context.Background()
-		if err := h.services.Job.ProcessJob(ctx, job); err != nil {
-			h.log.Errorf("Background job processing failed: %v", err)
-		}
-	}()
+	jobCreated = true
 
 	c.JSON(http.StatusAccepted, gin.H{
-		"success": true,
-		"job_id": job.ID,
-		"video_id": job.VideoID,
-		"status": job.Status,
-		"message": "Video generation started",
+		"success":    true,
+		"job_id":     job.ID,
+		"video_id":   job.VideoID,
+		"status":     job.Status,
+		"message":    "Video generation started",
 		"status_url": fmt.Sprintf("/api/v1/jobs/%s/status", job.ID),
 	})
 }
 
+// EstimateVideo handles POST /estimate - predicts render time and output size without rendering
+func (h *VideoHandler) EstimateVideo(c *gin.Context) {
+	var config models.VideoConfigArray
+	if err := c.ShouldBindJSON(&config); err != nil {
+		h.log.Errorf("Failed to parse video config: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(config) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "No video projects provided",
+		})
+		return
+	}
+
+	if err := h.rejectUploadedSrcs(config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid media URLs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.validateMediaURLs(&config); err != nil {
+		h.log.Errorf("Media URL validation failed: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid media URLs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	estimate, err := h.services.Job.EstimateRender(c.Request.Context(), &config)
+	if err != nil {
+		h.log.Errorf("Failed to estimate render: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to estimate render",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, estimate)
+}
+
+// DryRunVideo handles POST /videos/dry-run - returns the FFmpeg command a
+// config would be rendered with, without creating a job or running FFmpeg
+func (h *VideoHandler) DryRunVideo(c *gin.Context) {
+	var config models.VideoConfigArray
+	if err := c.ShouldBindJSON(&config); err != nil {
+		h.log.Errorf("Failed to parse video config: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid JSON format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(config) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "No video projects provided",
+		})
+		return
+	}
+
+	if err := h.rejectUploadedSrcs(config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid media URLs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.validateMediaURLs(&config); err != nil {
+		h.log.Errorf("Media URL validation failed: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid media URLs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	command, err := h.services.Job.DryRun(c.Request.Context(), &config)
+	if err != nil {
+		h.log.Errorf("Failed to build dry-run command: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to build FFmpeg command",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, command)
+}
+
 // GetVideo handles GET /videos/:id - Returns video file or status
 func (h *VideoHandler) GetVideo(c *gin.Context) {
 	videoID := c.Param("id")
@@ -100,12 +238,79 @@ func (h *VideoHandler) GetVideo(c *gin.Context) {
 		return
 	}
 
+	h.serveVideo(c, videoID)
+}
+
+// DownloadVideo handles GET /api/v1/downloads/:id - serves a video through a
+// signed, time-limited link without requiring the API key
+func (h *VideoHandler) DownloadVideo(c *gin.Context) {
+	videoID := c.Param("id")
+	if !storageServices.VerifyDownloadSignature(h.cfg.Storage.DownloadURLSecret, videoID, c.Query("expires"), c.Query("signature")) {
+		h.log.Warnf("Rejected download link for video %s: invalid or expired signature", videoID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Download link is invalid or has expired",
+		})
+		return
+	}
+
+	h.serveVideo(c, videoID)
+}
+
+// GetDownloadURL handles GET /videos/:id/download-url - returns a URL that
+// downloads the video without the API key until it expires. The optional
+// ttl query parameter is a duration such as "15m".
+func (h *VideoHandler) GetDownloadURL(c *gin.Context) {
+	videoID := c.Param("id")
+
+	ttl := h.cfg.Storage.DownloadURLTTL
+	if raw := c.Query("ttl"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid ttl",
+				"details": "ttl must be a duration such as 15m or 2h",
+			})
+			return
+		}
+		ttl = parsed
+	}
+
+	downloadURL, err := h.services.Storage.GetDownloadURL(videoID, ttl)
+	if err != nil {
+		h.log.Errorf("Failed to create download URL for video %s: %v", videoID, err)
+		status := http.StatusInternalServerError
+		var vpe *errors.VideoProcessingError
+		if stderrors.As(err, &vpe) {
+			switch vpe.Code {
+			case errors.ErrCodeInvalidInput:
+				status = http.StatusBadRequest
+			case errors.ErrCodeFileNotFound:
+				status = http.StatusNotFound
+			}
+		}
+		c.JSON(status, gin.H{
+			"error":    "Failed to create download URL",
+			"details":  errors.SanitizeForClient(err),
+			"video_id": videoID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"video_id":   videoID,
+		"url":        downloadURL,
+		"expires_at": time.Now().Add(ttl).UTC().Format(time.RFC3339),
+	})
+}
+
+// serveVideo streams a stored video as an attachment
+func (h *VideoHandler) serveVideo(c *gin.Context, videoID string) {
 	// Get video file path from storage
 	filePath, err := h.services.Storage.GetVideo(videoID)
 	if err != nil {
 		h.log.Errorf("Failed to get video %s: %v", videoID, err)
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Video not found",
+			"error":    "Video not found",
 			"video_id": videoID,
 		})
 		return
@@ -115,15 +320,40 @@ func (h *VideoHandler) GetVideo(c *gin.Context) {
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		h.log.Errorf("Video file not found on disk: %s", filePath)
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Video file not found",
+			"error":    "Video file not found",
 			"video_id": videoID,
 		})
 		return
 	}
 
 	// Set appropriate headers for video download
-	c.Header("Content-Type", "video/mp4")
-	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="video_%s.mp4"`, videoID))
+	contentType, ext := "video/mp4", filepath.Ext(filePath)
+	switch ext {
+	case ".mov":
+		contentType = "video/quicktime"
+	case ".webm":
+		contentType = "video/webm"
+	case ".mkv":
+		contentType = "video/x-matroska"
+	case ".ass", ".srt":
+		contentType = "text/plain; charset=utf-8"
+	case ".json":
+		contentType = "application/json"
+	case "":
+		ext = ".mp4"
+	}
+
+	// Prefer the content type detected at storage time and publish the checksum for verification
+	if metadata, err := h.services.Storage.GetVideoMetadata(videoID); err == nil {
+		if metadata.ContentType != "" {
+			contentType = metadata.ContentType
+		}
+		if metadata.SHA256 != "" {
+			c.Header("X-Checksum-SHA256", metadata.SHA256)
+		}
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="video_%s%s"`, videoID, ext))
 	c.Header("Cache-Control", "no-cache")
 
 	// Stream the file
@@ -131,19 +361,58 @@ func (h *VideoHandler) GetVideo(c *gin.Context) {
 	h.log.Infof("Video %s downloaded successfully", videoID)
 }
 
+// statusForJobError maps job creation errors to HTTP status codes
+func statusForJobError(err error) int {
+	var vpe *errors.VideoProcessingError
+	if stderrors.As(err, &vpe) {
+		switch vpe.Code {
+		case errors.ErrCodeInvalidInput:
+			return http.StatusBadRequest
+		case errors.ErrCodeConflict:
+			return http.StatusConflict
+		}
+	}
+	return http.StatusInternalServerError
+}
 
 // validateMediaURLs performs lightweight URL validation without downloading
 func (h *VideoHandler) validateMediaURLs(config *models.VideoConfigArray) error {
 	for _, project := range *config {
-		// Validate background video URLs
+		// Callbacks are POSTed over the network, so uploaded files never qualify
+		if project.CallbackURL != "" {
+			if h.cfg.Storage.IsUploadedFile(project.CallbackURL) {
+				return fmt.Errorf("invalid callback URL '%s': uploaded files cannot receive callbacks", project.CallbackURL)
+			}
+			if err := validateRemoteURL(project.CallbackURL); err != nil {
+				return fmt.Errorf("invalid callback URL '%s': %w", project.CallbackURL, err)
+			}
+		}
+
+		// Validate project-level URLs: background video, background music
+		// and background images are all FFmpeg inputs
 		for _, element := range project.Elements {
-			if element.Type == "video" {
-				if err := h.services.Video.ValidateVideo(element.Src); err != nil {
-					return fmt.Errorf("invalid background video URL '%s': %w", element.Src, err)
+			switch element.Type {
+			case "video":
+				for _, src := range append([]string{element.Src}, element.FallbackSrcs...) {
+					if err := h.services.Video.ValidateVideo(src); err != nil {
+						return fmt.Errorf("invalid background video URL '%s': %w", src, err)
+					}
+				}
+
+			case "audio":
+				for _, src := range append([]string{element.Src}, element.FallbackSrcs...) {
+					if err := h.validateURL(src); err != nil {
+						return fmt.Errorf("invalid background audio URL '%s': %w", src, err)
+					}
+				}
+
+			case "image":
+				if err := h.services.Image.ValidateImage(element.Src); err != nil {
+					return fmt.Errorf("invalid background image URL '%s': %w", element.Src, err)
 				}
 			}
 		}
-		
+
 		// Validate scene element URLs
 		for _, scene := range project.Scenes {
 			for _, element := range scene.Elements {
@@ -153,10 +422,18 @@ func (h *VideoHandler) validateMediaURLs(config *models.VideoConfigArray) error
 					if element.Src == "" {
 						return fmt.Errorf("audio URL cannot be empty")
 					}
-					if err := h.validateURL(element.Src); err != nil {
-						return fmt.Errorf("invalid audio URL '%s': %w", element.Src, err)
+					for _, src := range append([]string{element.Src}, element.FallbackSrcs...) {
+						if element.AudioFromVideo {
+							if err := h.services.Video.ValidateVideo(src); err != nil {
+								return fmt.Errorf("invalid audio source video URL '%s': %w", src, err)
+							}
+							continue
+						}
+						if err := h.validateURL(src); err != nil {
+							return fmt.Errorf("invalid audio URL '%s': %w", src, err)
+						}
 					}
-					
+
 				case "image":
 					if err := h.services.Image.ValidateImage(element.Src); err != nil {
 						return fmt.Errorf("invalid image URL '%s': %w", element.Src, err)
@@ -164,8 +441,22 @@ func (h *VideoHandler) validateMediaURLs(config *models.VideoConfigArray) error
 				}
 			}
 		}
+
+		// Validate timeline clip URLs
+		if project.Timeline != nil {
+			for _, track := range project.Timeline.Tracks {
+				for _, clip := range track.Clips {
+					if track.Type == models.TrackTypeText {
+						continue
+					}
+					if err := h.validateURL(clip.Src); err != nil {
+						return fmt.Errorf("invalid timeline %s URL '%s': %w", track.Type, clip.Src, err)
+					}
+				}
+			}
+		}
 	}
-	
+
 	return nil
 }
 
@@ -174,17 +465,24 @@ func (h *VideoHandler) validateURL(urlStr string) error {
 	if urlStr == "" {
 		return fmt.Errorf("URL cannot be empty")
 	}
-	
+	if h.cfg.Storage.IsUploadedFile(urlStr) {
+		return nil
+	}
+	return validateRemoteURL(urlStr)
+}
+
+// validateRemoteURL checks that a URL parses and uses HTTP or HTTPS
+func validateRemoteURL(urlStr string) error {
 	// Parse URL
 	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
 		return fmt.Errorf("invalid URL format: %w", err)
 	}
-	
+
 	// Check protocol
 	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
 		return fmt.Errorf("only HTTP and HTTPS protocols are allowed")
 	}
-	
+
 	return nil
 }
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func newTestVideoHandler(t *testing.T) *VideoHandler {
+	t.Helper()
+	cfg := &app.Config{}
+	cfg.Storage.TempDir = t.TempDir()
+	return NewVideoHandler(cfg, nil, logger.NewWithWriter("error", io.Discard, "text"))
+}
+
+func TestValidateMediaURLsCallbackURL(t *testing.T) {
+	h := newTestVideoHandler(t)
+	uploaded := filepath.Join(h.cfg.Storage.UploadDir(), "job", "hook")
+
+	tests := []struct {
+		name        string
+		callbackURL string
+		wantErr     string
+	}{
+		{name: "https", callbackURL: "https://example.com/hooks/videocraft"},
+		{name: "http", callbackURL: "http://example.com/hooks/videocraft"},
+		{name: "ftp", callbackURL: "ftp://example.com/hooks", wantErr: "only HTTP and HTTPS"},
+		{name: "file", callbackURL: "file:///etc/passwd", wantErr: "only HTTP and HTTPS"},
+		{name: "uploaded file", callbackURL: uploaded, wantErr: "uploaded files cannot receive callbacks"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := models.VideoConfigArray{{CallbackURL: tt.callbackURL}}
+			err := h.validateMediaURLs(&config)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateMediaURLs() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateMediaURLs() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateVideoRejectsNonHTTPCallbackURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := newTestVideoHandler(t)
+	router := gin.New()
+	router.POST("/videos", h.CreateVideo)
+
+	body := `[{"callback_url": "gopher://example.com/hook"}]`
+	req := httptest.NewRequest(http.MethodPost, "/videos", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d (body %s)", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "invalid callback URL") {
+		t.Errorf("body = %s, want callback URL error", rec.Body.String())
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	adminKeyHeader = "X-Admin-Key" // #nosec G101 - This is a header name, not a credential
+)
+
+// AdminAuth restricts access to operator endpoints. Admin endpoints are
+// disabled entirely when no admin key is configured.
+func AdminAuth(adminKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if adminKey == "" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Admin endpoints are disabled",
+				"code":  "ADMIN_DISABLED",
+			})
+			c.Abort()
+			return
+		}
+
+		providedKey := c.GetHeader(adminKeyHeader)
+		if providedKey == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Admin key is required",
+				"code":  "MISSING_ADMIN_KEY",
+			})
+			c.Abort()
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(providedKey), []byte(adminKey)) != 1 {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Invalid admin key",
+				"code":  "INVALID_ADMIN_KEY",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
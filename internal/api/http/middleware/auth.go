@@ -7,10 +7,14 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// signedDownloadPrefix is the route serving signed download links
+const signedDownloadPrefix = "/api/v1/downloads/"
+
 func Auth(apiKey string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Skip auth for health endpoints
-		if isHealthEndpoint(c.Request.URL.Path) {
+		// Skip auth for health endpoints and signed download links, which
+		// carry their own signature
+		if isHealthEndpoint(c.Request.URL.Path) || strings.HasPrefix(c.Request.URL.Path, signedDownloadPrefix) {
 			c.Next()
 			return
 		}
@@ -310,6 +310,8 @@ func getSecureStatusFromErrorCode(code string) int {
 		return http.StatusBadGateway
 	case domainErrors.ErrCodeStorageFailed:
 		return http.StatusInsufficientStorage
+	case domainErrors.ErrCodeConflict:
+		return http.StatusConflict
 	default:
 		return http.StatusInternalServerError
 	}
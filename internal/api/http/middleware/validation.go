@@ -62,6 +62,16 @@ func ValidationMiddlewareWithConfig(log logger.Logger, config *ValidationConfig)
 			c.Next()
 			return
 		}
+		// Admin actions are bodyless commands
+		if strings.Contains(c.Request.URL.Path, "/admin/") {
+			c.Next()
+			return
+		}
+		// Multipart video requests carry uploads; the handler validates and size-limits them
+		if isMultipartVideoUpload(c) {
+			c.Next()
+			return
+		}
 		contentType := c.GetHeader("Content-Type")
 		if !strings.Contains(contentType, "application/json") {
 			c.JSON(http.StatusBadRequest, gin.H{
@@ -109,7 +119,7 @@ func ValidationMiddlewareWithConfig(log logger.Logger, config *ValidationConfig)
 		}
 
 		// Perform validation based on endpoint
-		if strings.Contains(c.Request.URL.Path, "generate-video") {
+		if strings.Contains(c.Request.URL.Path, "generate-video") || strings.Contains(c.Request.URL.Path, "estimate") {
 			if err := validateVideoConfig(bodyData, config); err != nil {
 				log.WithError(err).Error("Video configuration validation failed")
 				c.JSON(http.StatusBadRequest, gin.H{
@@ -148,9 +158,22 @@ func ValidationMiddlewareWithConfig(log logger.Logger, config *ValidationConfig)
 	}
 }
 
-// RequestSizeLimit middleware limits request body size
+// isMultipartVideoUpload reports whether the request creates a video from uploaded media
+func isMultipartVideoUpload(c *gin.Context) bool {
+	return c.Request.Method == http.MethodPost &&
+		strings.HasSuffix(c.Request.URL.Path, "/videos") &&
+		strings.HasPrefix(c.GetHeader("Content-Type"), "multipart/form-data")
+}
+
+// RequestSizeLimit middleware limits request body size. Multipart video
+// uploads are exempt; their handler enforces the configured upload limits.
 func RequestSizeLimit(maxSize int64) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if isMultipartVideoUpload(c) {
+			c.Next()
+			return
+		}
+
 		if c.Request.ContentLength > maxSize {
 			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
 				"error": "request entity too large",
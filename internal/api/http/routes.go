@@ -25,11 +25,13 @@ func NewRouter(cfg *app.Config, services *composition.Services, log logger.Logge
 
 	// Initialize handlers
 	healthHandler := handlers.NewHealthHandler(services, log)
-	videoHandler := handlers.NewVideoHandler(services, log)
+	videoHandler := handlers.NewVideoHandler(cfg, services, log)
 	jobHandler := handlers.NewJobHandler(services, log)
+	adminHandler := handlers.NewAdminHandler(services, log)
+	transcriptionHandler := handlers.NewTranscriptionHandler(cfg, services, log)
 
 	// Setup routes
-	setupRoutes(router, cfg, log, healthHandler, videoHandler, jobHandler)
+	setupRoutes(router, cfg, log, healthHandler, videoHandler, jobHandler, adminHandler, transcriptionHandler)
 
 	return router
 }
@@ -74,6 +76,8 @@ func setupRoutes(
 	healthHandler *handlers.HealthHandler,
 	videoHandler *handlers.VideoHandler,
 	jobHandler *handlers.JobHandler,
+	adminHandler *handlers.AdminHandler,
+	transcriptionHandler *handlers.TranscriptionHandler,
 ) {
 	// Health endpoints
 	router.GET("/health", healthHandler.Health)
@@ -85,6 +89,9 @@ func setupRoutes(
 	// CSRF token endpoint (no auth required) - must be outside authenticated groups
 	router.GET("/api/v1/csrf-token", middleware.CSRFTokenEndpoint(cfg, log))
 
+	// Signed download links authenticate with their signature instead of the API key
+	router.GET("/api/v1/downloads/:id", videoHandler.DownloadVideo)
+
 	// API v1 routes with authentication
 	v1 := router.Group("/api/v1")
 	if cfg.Security.EnableAuth {
@@ -92,12 +99,29 @@ func setupRoutes(
 	}
 
 	// REST-compliant Video API
-	v1.POST("/videos", videoHandler.CreateVideo) // Create video job
-	v1.GET("/videos/:id", videoHandler.GetVideo) // Get video or status
+	v1.POST("/videos", videoHandler.CreateVideo)                    // Create video job
+	v1.GET("/videos/:id", videoHandler.GetVideo)                    // Get video or status
+	v1.GET("/videos/:id/download-url", videoHandler.GetDownloadURL) // Time-limited download link
+	v1.POST("/estimate", videoHandler.EstimateVideo)                // Estimate render cost
+	v1.POST("/videos/dry-run", videoHandler.DryRunVideo)            // Build the FFmpeg command without rendering
 
 	// REST-compliant Job API
-	v1.GET("/jobs/:id", jobHandler.GetJob)       // Get job status
-	v1.DELETE("/jobs/:id", jobHandler.DeleteJob) // Cancel job
+	v1.GET("/jobs/:id", jobHandler.GetJob)                    // Get job status
+	v1.GET("/jobs/:id/manifest", jobHandler.GetJobManifest)   // Get render manifest
+	v1.GET("/jobs/:id/subtitles", jobHandler.GetJobSubtitles) // Get sidecar subtitles
+	v1.DELETE("/jobs/:id", jobHandler.DeleteJob)              // Cancel job
+
+	// Standalone transcription without a video job
+	v1.POST("/transcribe", transcriptionHandler.Transcribe)
+
+	// Operator API - requires the admin key in addition to the API key
+	admin := v1.Group("/admin")
+	admin.Use(middleware.AdminAuth(cfg.Security.AdminAPIKey))
+	admin.GET("/queue", adminHandler.QueueStatus)         // Queue pause state
+	admin.POST("/queue/pause", adminHandler.PauseQueue)   // Stop picking up new jobs
+	admin.POST("/queue/resume", adminHandler.ResumeQueue) // Resume processing
+	admin.GET("/workers", adminHandler.WorkerStatus)      // Worker pool status
+	admin.POST("/workers", adminHandler.ScaleWorkers)     // Resize the worker pool
 
 	// Documentation endpoint
 	router.GET("/", func(c *gin.Context) {
@@ -118,19 +142,35 @@ func setupRoutes(
 					"GET /metrics":         "System metrics",
 				},
 				"video_generation": gin.H{
-					"POST /api/v1/generate-video": "Start video generation job",
+					"POST /api/v1/generate-video": "Start video generation job (JSON, or multipart with uploaded media)",
+					"POST /api/v1/estimate":       "Estimate render time and output size",
+					"POST /api/v1/videos/dry-run": "Return the FFmpeg command without rendering",
 				},
 				"video_management": gin.H{
-					"GET /api/v1/download/:video_id":  "Download generated video",
-					"GET /api/v1/status/:video_id":    "Get video status",
-					"GET /api/v1/videos":              "List all videos",
-					"DELETE /api/v1/videos/:video_id": "Delete video",
+					"GET /api/v1/download/:video_id":      "Download generated video",
+					"GET /api/v1/videos/:id/download-url": "Create a time-limited download URL",
+					"GET /api/v1/downloads/:id":           "Download through a signed URL (no API key)",
+					"GET /api/v1/status/:video_id":        "Get video status",
+					"GET /api/v1/videos":                  "List all videos",
+					"DELETE /api/v1/videos/:video_id":     "Delete video",
 				},
 				"job_management": gin.H{
-					"GET /api/v1/jobs":                 "List all jobs",
-					"GET /api/v1/jobs/:job_id":         "Get job details",
-					"GET /api/v1/jobs/:job_id/status":  "Get job status",
-					"POST /api/v1/jobs/:job_id/cancel": "Cancel job",
+					"GET /api/v1/jobs":                   "List all jobs",
+					"GET /api/v1/jobs/:job_id":           "Get job details",
+					"GET /api/v1/jobs/:job_id/status":    "Get job status",
+					"GET /api/v1/jobs/:job_id/manifest":  "Get render manifest",
+					"GET /api/v1/jobs/:job_id/subtitles": "Get sidecar subtitles",
+					"POST /api/v1/jobs/:job_id/cancel":   "Cancel job",
+				},
+				"transcription": gin.H{
+					"POST /api/v1/transcribe": "Transcribe an audio URL without creating a video job",
+				},
+				"admin": gin.H{
+					"GET /api/v1/admin/queue":         "Get job queue pause state",
+					"POST /api/v1/admin/queue/pause":  "Pause the job queue",
+					"POST /api/v1/admin/queue/resume": "Resume the job queue",
+					"GET /api/v1/admin/workers":       "Get worker pool status",
+					"POST /api/v1/admin/workers":      "Resize the worker pool",
 				},
 				"authentication": gin.H{
 					"GET /api/v1/csrf-token": "Get CSRF token for authenticated requests",
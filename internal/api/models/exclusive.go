@@ -0,0 +1,112 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// exclusiveField is a config field and whether it is set
+type exclusiveField struct {
+	name string
+	set  bool
+}
+
+// exclusion declares that field cannot be combined with any of the fields in
+// with; reason explains which one would otherwise be ignored
+type exclusion struct {
+	field  exclusiveField
+	with   []exclusiveField
+	reason string
+}
+
+// check returns an error naming the conflicting fields
+func (x exclusion) check() error {
+	if !x.field.set {
+		return nil
+	}
+	var conflicts []string
+	for _, other := range x.with {
+		if other.set {
+			conflicts = append(conflicts, other.name)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s cannot be combined with %s: %s", x.field.name, strings.Join(conflicts, ", "), x.reason)
+}
+
+// checkExclusions returns the first conflict among exclusions
+func checkExclusions(exclusions []exclusion) error {
+	for _, x := range exclusions {
+		if err := x.check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exclusions lists the project fields that override each other. Documented
+// overrides such as crf over quality or a rendition's max_bitrate_kbps over
+// the project's are precedence rules, not conflicts, and are not listed.
+func (vp VideoProject) exclusions() []exclusion {
+	return []exclusion{
+		{
+			field: exclusiveField{"timeline", vp.Timeline != nil},
+			with: []exclusiveField{
+				{"scenes", len(vp.Scenes) > 0},
+				{"elements", len(vp.Elements) > 0},
+				{"waveform", vp.Waveform != nil},
+				{"chapters", len(vp.Chapters) > 0},
+				{"background_loop", vp.BackgroundLoop != ""},
+				{"audio_crossfade", vp.AudioCrossfade},
+			},
+			reason: "a timeline renders only its own clips",
+		},
+		{
+			field: exclusiveField{"renditions", len(vp.Renditions) > 0},
+			with: []exclusiveField{
+				{"width", vp.Width != 0},
+				{"height", vp.Height != 0},
+			},
+			reason: "each rendition sets its own output size",
+		},
+	}
+}
+
+// exclusions lists the element fields that override each other
+func (e Element) exclusions() []exclusion {
+	return []exclusion{
+		{
+			field: exclusiveField{"show_when", e.ShowWhen != ""},
+			with: []exclusiveField{
+				{"min_duration", e.MinDuration != 0},
+				{"max_duration", e.MaxDuration != 0},
+			},
+			reason: "show_when ties the image to the audio-bearing scenes",
+		},
+		{
+			field: exclusiveField{"role", e.Role != ""},
+			with: []exclusiveField{
+				{"fallback_srcs", len(e.FallbackSrcs) > 0},
+			},
+			reason: "background music has no fallback sources",
+		},
+		{
+			field:  exclusiveField{"mute", e.Mute},
+			with:   []exclusiveField{{"solo", e.Solo}},
+			reason: "mute would silence the soloed element",
+		},
+	}
+}
+
+// exclusions lists the scene fields that override each other
+func (s Scene) exclusions() []exclusion {
+	return []exclusion{
+		{
+			field:  exclusiveField{"mute", s.Mute},
+			with:   []exclusiveField{{"solo", s.Solo}},
+			reason: "mute would silence the soloed scene",
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package models
+
+import "testing"
+
+func TestExclusions(t *testing.T) {
+	tests := []struct {
+		name       string
+		exclusions []exclusion
+		wantErr    string
+	}{
+		{
+			name:       "project without conflicts",
+			exclusions: VideoProject{Scenes: []Scene{{ID: "intro"}}, Width: 1280}.exclusions(),
+		},
+		{
+			name:       "timeline alone",
+			exclusions: VideoProject{Timeline: &Timeline{}}.exclusions(),
+		},
+		{
+			name: "timeline with scenes and waveform",
+			exclusions: VideoProject{
+				Timeline: &Timeline{},
+				Scenes:   []Scene{{ID: "intro"}},
+				Waveform: &WaveformOverlay{},
+			}.exclusions(),
+			wantErr: "timeline cannot be combined with scenes, waveform: a timeline renders only its own clips",
+		},
+		{
+			name:       "timeline with background loop",
+			exclusions: VideoProject{Timeline: &Timeline{}, BackgroundLoop: "https://example.com/loop.mp4"}.exclusions(),
+			wantErr:    "timeline cannot be combined with background_loop: a timeline renders only its own clips",
+		},
+		{
+			name:       "renditions with width",
+			exclusions: VideoProject{Renditions: []Rendition{{}}, Width: 1280}.exclusions(),
+			wantErr:    "renditions cannot be combined with width: each rendition sets its own output size",
+		},
+		{
+			name:       "show_when with min_duration",
+			exclusions: Element{ShowWhen: "audio", MinDuration: 2}.exclusions(),
+			wantErr:    "show_when cannot be combined with min_duration: show_when ties the image to the audio-bearing scenes",
+		},
+		{
+			name:       "role with fallback_srcs",
+			exclusions: Element{Role: RoleBackgroundMusic, FallbackSrcs: []string{"https://example.com/b.mp3"}}.exclusions(),
+			wantErr:    "role cannot be combined with fallback_srcs: background music has no fallback sources",
+		},
+		{
+			name:       "element mute with solo",
+			exclusions: Element{Mute: true, Solo: true}.exclusions(),
+			wantErr:    "mute cannot be combined with solo: mute would silence the soloed element",
+		},
+		{
+			name:       "scene mute with solo",
+			exclusions: Scene{Mute: true, Solo: true}.exclusions(),
+			wantErr:    "mute cannot be combined with solo: mute would silence the soloed scene",
+		},
+		{
+			name:       "scene solo alone",
+			exclusions: Scene{Solo: true}.exclusions(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkExclusions(tt.exclusions)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkExclusions() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("checkExclusions() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateReportsExclusions(t *testing.T) {
+	project := VideoProject{
+		Scenes: []Scene{{ID: "intro", Mute: true, Solo: true}},
+	}
+	want := "scene intro: mute cannot be combined with solo: mute would silence the soloed scene"
+	if err := project.Validate(); err == nil || err.Error() != want {
+		t.Errorf("Validate() error = %v, want %q", err, want)
+	}
+}
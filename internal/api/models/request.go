@@ -2,9 +2,20 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
 )
 
+// validOutputIDRegex mirrors the storage video ID format (alphanumeric, hyphens, underscores)
+var validOutputIDRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,127}$`)
+
+// MaxOutputSubdirDepth is the number of path segments an output_subdir may have
+const MaxOutputSubdirDepth = 3
+
 type VideoConfigArray []VideoProject
 
 type VideoProject struct {
@@ -15,12 +26,524 @@ type VideoProject struct {
 	Height     int       `json:"height,omitempty"`
 	Scenes     []Scene   `json:"scenes,omitempty"`
 	Elements   []Element `json:"elements,omitempty"`
+
+	// OutputID requests a deterministic video ID instead of a generated one
+	OutputID string `json:"output_id,omitempty"`
+
+	// OutputSubdir stores the video (and its sidecars) in a "/"-separated
+	// subdirectory of the output directory, e.g. "campaigns/spring"
+	OutputSubdir string `json:"output_subdir,omitempty"`
+
+	// Chapters are explicit chapter markers written into the output container
+	Chapters []ChapterMarker `json:"chapters,omitempty"`
+
+	// Waveform overlays an animated waveform of the narration audio
+	Waveform *WaveformOverlay `json:"waveform,omitempty"`
+
+	// Output audio encoding; empty values keep the default AAC output
+	AudioCodec        string `json:"audio_codec,omitempty"`
+	AudioSampleFormat string `json:"audio_sample_format,omitempty"`
+	AudioProfile      string `json:"audio_profile,omitempty"`
+
+	// Timeline replaces scenes and elements with explicitly placed clips
+	Timeline *Timeline `json:"timeline,omitempty"`
+
+	// SubtitleMode is "burn" (default) or "sidecar" to store the subtitle file
+	// next to the video instead of rendering it into the frames; "json" stores
+	// the subtitle events as JSON for players that render captions themselves
+	SubtitleMode string `json:"subtitle_mode,omitempty"`
+
+	// SubtitleFormats exports the subtitles as additional caption files
+	// ("srt", "vtt") stored next to the video in every subtitle mode
+	SubtitleFormats []string `json:"subtitle_formats,omitempty"`
+
+	// SubtitlesEnabled turns subtitle generation for the project's subtitle
+	// element on or off; nil follows subtitles.enabled
+	SubtitlesEnabled *bool `json:"subtitles_enabled,omitempty"`
+
+	// KeyframeInterval sets the GOP size in KeyframeIntervalUnit ("seconds" by
+	// default, or "frames"); 0 leaves keyframe placement to FFmpeg
+	KeyframeInterval     float64 `json:"keyframe_interval,omitempty"`
+	KeyframeIntervalUnit string  `json:"keyframe_interval_unit,omitempty"`
+
+	// Faststart moves the container index to the front for progressive
+	// playback at the cost of a second pass; nil follows ffmpeg.faststart
+	Faststart *bool `json:"faststart,omitempty"`
+
+	// StripMetadata keeps source metadata (EXIF, GPS, creator tags) out of
+	// the output; chapter markers are still written. nil follows
+	// ffmpeg.strip_metadata
+	StripMetadata *bool `json:"strip_metadata,omitempty"`
+
+	// NormalizeAudio evens out the loudness of the narration elements with
+	// EBU R128 loudnorm before they are concatenated; background music is
+	// left as is. nil follows ffmpeg.normalize_audio
+	NormalizeAudio *bool `json:"normalize_audio,omitempty"`
+
+	// MaxBitrateKbps caps the peak video bitrate alongside CRF (VBV) using a
+	// BufferSizeKbits rate-control buffer (default twice the max rate); 0 disables
+	MaxBitrateKbps  int `json:"max_bitrate_kbps,omitempty"`
+	BufferSizeKbits int `json:"buffer_size_kbits,omitempty"`
+
+	// Format selects the output container (mp4, mov, mkv or webm); empty
+	// picks mp4, or mov when the audio codec cannot be muxed into MP4
+	Format string `json:"format,omitempty"`
+
+	// CRF and Preset override the encoder settings derived from Quality;
+	// Preset is an x264 preset and is not available for WebM output
+	CRF    *int   `json:"crf,omitempty"`
+	Preset string `json:"preset,omitempty"`
+
+	// PreviewSeconds renders only the first seconds of the video with a fast
+	// encoder preset to check layout and styling; capped by
+	// ffmpeg.preview_max_seconds, 0 renders the full video
+	PreviewSeconds float64 `json:"preview_seconds,omitempty"`
+
+	// Renditions re-encode the project at several sizes in one job, sharing
+	// media analysis and subtitles; each is stored as its own video
+	Renditions []Rendition `json:"renditions,omitempty"`
+
+	// BackgroundLoop controls how a single background video shorter than the
+	// content repeats: "repeat" (default) restarts it, "crossfade" blends
+	// each loop boundary to hide the seam
+	BackgroundLoop string `json:"background_loop,omitempty"`
+
+	// AudioCrossfade blends consecutive narration clips over
+	// audio.crossfade_seconds instead of cutting between them; each blend
+	// starts the next clip earlier and shortens the video accordingly
+	AudioCrossfade bool `json:"audio_crossfade,omitempty"`
+
+	// Debug logs the processing of this project's job at debug level,
+	// regardless of the configured log level
+	Debug bool `json:"debug,omitempty"`
+
+	// CallbackURL receives a signed POST once the job completes, fails or
+	// is cancelled; the first project setting one is used for the job
+	CallbackURL string `json:"callback_url,omitempty"`
+}
+
+// Rendition is one output size of a multi-rendition project. Its video ID is
+// the job's base ID (output_id or a generated one) suffixed with "-<name>".
+type Rendition struct {
+	// Name defaults to "<height>p"
+	Name   string `json:"name,omitempty"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+
+	// MaxBitrateKbps overrides the project's bitrate cap for this rendition
+	MaxBitrateKbps int `json:"max_bitrate_kbps,omitempty"`
+}
+
+// maxRenditions bounds the renditions rendered by a single job
+const maxRenditions = 8
+
+// validRenditionNameRegex keeps rendition names usable as video ID suffixes
+var validRenditionNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,32}$`)
+
+// OutputName returns the rendition's name or its "<height>p" default
+func (r Rendition) OutputName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%dp", r.Height)
+}
+
+// RenditionVideoID links a rendition's video ID to the job's base ID
+func RenditionVideoID(baseID string, r Rendition) string {
+	return baseID + "-" + r.OutputName()
+}
+
+// ForRendition returns the project rendered at the rendition's size and bitrate cap
+func (vp VideoProject) ForRendition(r Rendition) VideoProject {
+	project := vp
+	project.Renditions = nil
+	project.Width = r.Width
+	project.Height = r.Height
+	if r.MaxBitrateKbps > 0 {
+		project.MaxBitrateKbps = r.MaxBitrateKbps
+		project.BufferSizeKbits = 0
+	}
+	return project
+}
+
+// validateRenditions checks rendition sizes and that their names are unique
+func (vp VideoProject) validateRenditions() error {
+	if len(vp.Renditions) > maxRenditions {
+		return fmt.Errorf("at most %d renditions are allowed", maxRenditions)
+	}
+
+	names := make(map[string]bool)
+	for i, r := range vp.Renditions {
+		// yuv420p output needs even dimensions
+		if r.Width <= 0 || r.Height <= 0 || r.Width%2 != 0 || r.Height%2 != 0 {
+			return fmt.Errorf("rendition %d: width and height must be positive even numbers", i)
+		}
+		if r.MaxBitrateKbps < 0 {
+			return fmt.Errorf("rendition %d: max_bitrate_kbps cannot be negative", i)
+		}
+		name := r.OutputName()
+		if !validRenditionNameRegex.MatchString(name) {
+			return fmt.Errorf("rendition %d: name must be 1-32 alphanumeric, hyphen or underscore characters", i)
+		}
+		if names[name] {
+			return fmt.Errorf("rendition %d: duplicate name %q", i, name)
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+// IsPreview reports whether the project is rendered as a truncated preview
+func (vp VideoProject) IsPreview() bool {
+	return vp.PreviewSeconds > 0
+}
+
+// x264Presets lists the standard x264 encoding presets
+var x264Presets = map[string]bool{
+	"ultrafast": true, "superfast": true, "veryfast": true, "faster": true, "fast": true,
+	"medium": true, "slow": true, "slower": true, "veryslow": true, "placebo": true,
+}
+
+// maxCRF is the upper bound of the x264 CRF scale
+const maxCRF = 51
+
+// Output container formats
+const (
+	ContainerMP4  = "mp4"
+	ContainerMOV  = "mov"
+	ContainerMKV  = "mkv"
+	ContainerWebM = "webm"
+)
+
+// Output video codecs
+const (
+	VideoCodecH264 = "libx264"
+	VideoCodecVP9  = "libvpx-vp9"
+)
+
+// containerAudioCodecs lists the audio codecs each output container can carry
+var containerAudioCodecs = map[string][]string{
+	ContainerMP4:  {AudioCodecAAC, AudioCodecALAC},
+	ContainerMOV:  {AudioCodecAAC, AudioCodecALAC, AudioCodecPCMS16LE, AudioCodecPCMS24LE},
+	ContainerMKV:  {AudioCodecAAC, AudioCodecALAC, AudioCodecPCMS16LE, AudioCodecPCMS24LE},
+	ContainerWebM: {AudioCodecOpus},
+}
+
+// faststartContainers lists the containers with a relocatable moov index
+var faststartContainers = map[string]bool{ContainerMP4: true, ContainerMOV: true}
+
+// Keyframe interval units
+const (
+	KeyframeUnitSeconds = "seconds"
+	KeyframeUnitFrames  = "frames"
+)
+
+// Upper bounds keeping the GOP size within what players and segmenters handle
+const (
+	maxKeyframeIntervalSeconds = 60
+	maxKeyframeIntervalFrames  = 3600
+)
+
+// Subtitle delivery modes
+const (
+	SubtitleModeBurn    = "burn"
+	SubtitleModeSidecar = "sidecar"
+	SubtitleModeJSON    = "json"
+)
+
+// Additional caption file formats
+const (
+	SubtitleFormatSRT = "srt"
+	SubtitleFormatVTT = "vtt"
+)
+
+// DetachedSubtitles reports whether subtitles are stored next to the video
+// instead of being burned into it
+func (vp VideoProject) DetachedSubtitles() bool {
+	return vp.SubtitleMode == SubtitleModeSidecar || vp.SubtitleMode == SubtitleModeJSON
+}
+
+// SubtitlesEnabledOr reports whether subtitles are generated for the
+// project: SubtitlesEnabled when set, otherwise enabledByDefault
+func (vp VideoProject) SubtitlesEnabledOr(enabledByDefault bool) bool {
+	if vp.SubtitlesEnabled != nil {
+		return *vp.SubtitlesEnabled
+	}
+	return enabledByDefault
+}
+
+// NormalizeAudioOr reports whether the project's narration is loudness
+// normalized: NormalizeAudio when set, otherwise enabledByDefault
+func (vp VideoProject) NormalizeAudioOr(enabledByDefault bool) bool {
+	if vp.NormalizeAudio != nil {
+		return *vp.NormalizeAudio
+	}
+	return enabledByDefault
+}
+
+// Background video loop modes
+const (
+	BackgroundLoopRepeat    = "repeat"
+	BackgroundLoopCrossfade = "crossfade"
+)
+
+// Output audio codecs
+const (
+	AudioCodecAAC      = "aac"
+	AudioCodecALAC     = "alac"
+	AudioCodecPCMS16LE = "pcm_s16le"
+	AudioCodecPCMS24LE = "pcm_s24le"
+	AudioCodecOpus     = "libopus"
+)
+
+// AudioCodecSampleFormats lists the sample formats each output audio codec's encoder accepts
+var AudioCodecSampleFormats = map[string][]string{
+	AudioCodecAAC:      {"fltp"},
+	AudioCodecALAC:     {"s16p", "s32p"},
+	AudioCodecPCMS16LE: {"s16"},
+	AudioCodecPCMS24LE: {"s32"}, // 24-bit samples are carried in s32
+	AudioCodecOpus:     {"s16", "flt"},
+}
+
+// aacProfiles lists the profiles of FFmpeg's native AAC encoder
+var aacProfiles = map[string]bool{
+	"aac_low": true, "aac_main": true, "aac_ltp": true, "mpeg2_aac_low": true,
+}
+
+// OutputAudioCodec returns the project's audio codec, defaulting to AAC
+// (Opus for WebM output)
+func (vp VideoProject) OutputAudioCodec() string {
+	if vp.AudioCodec != "" {
+		return vp.AudioCodec
+	}
+	if vp.Format == ContainerWebM {
+		return AudioCodecOpus
+	}
+	return AudioCodecAAC
+}
+
+// OutputVideoCodec returns the video encoder for the output container
+func (vp VideoProject) OutputVideoCodec() string {
+	if vp.OutputFormat() == ContainerWebM {
+		return VideoCodecVP9
+	}
+	return VideoCodecH264
+}
+
+// RequiresMOV reports whether the audio codec cannot be muxed into MP4
+func (vp VideoProject) RequiresMOV() bool {
+	codec := vp.OutputAudioCodec()
+	return codec == AudioCodecPCMS16LE || codec == AudioCodecPCMS24LE
+}
+
+// VBVBufferSize returns the rate-control buffer size in kbit for the bitrate
+// cap, or 0 when the cap is disabled
+func (vp VideoProject) VBVBufferSize() int {
+	if vp.MaxBitrateKbps <= 0 {
+		return 0
+	}
+	if vp.BufferSizeKbits > 0 {
+		return vp.BufferSizeKbits
+	}
+	return 2 * vp.MaxBitrateKbps
+}
+
+// validateBitrateCap checks that the VBV buffer can hold at least one second at the max rate
+func (vp VideoProject) validateBitrateCap() error {
+	if vp.MaxBitrateKbps < 0 || vp.BufferSizeKbits < 0 {
+		return errors.New("max_bitrate_kbps and buffer_size_kbits cannot be negative")
+	}
+	if vp.BufferSizeKbits > 0 && vp.MaxBitrateKbps == 0 {
+		return errors.New("buffer_size_kbits requires max_bitrate_kbps")
+	}
+	if vp.BufferSizeKbits > 0 && vp.BufferSizeKbits < vp.MaxBitrateKbps {
+		return fmt.Errorf("buffer_size_kbits (%d) must be at least max_bitrate_kbps (%d)", vp.BufferSizeKbits, vp.MaxBitrateKbps)
+	}
+	return nil
+}
+
+// OutputFormat returns the container the project is rendered into
+func (vp VideoProject) OutputFormat() string {
+	if vp.Format != "" {
+		return vp.Format
+	}
+	if vp.RequiresMOV() {
+		return ContainerMOV // PCM audio cannot be muxed into MP4
+	}
+	return ContainerMP4
+}
+
+// SupportsFaststart reports whether the output container accepts +faststart
+func (vp VideoProject) SupportsFaststart() bool {
+	return faststartContainers[vp.OutputFormat()]
+}
+
+// validateEncoderSettings checks the CRF and preset overrides
+func (vp VideoProject) validateEncoderSettings() error {
+	if vp.CRF != nil && (*vp.CRF < 0 || *vp.CRF > maxCRF) {
+		return fmt.Errorf("crf must be between 0 and %d", maxCRF)
+	}
+	if vp.Preset != "" {
+		if !x264Presets[vp.Preset] {
+			return errors.New("unsupported preset: " + vp.Preset)
+		}
+		if vp.OutputVideoCodec() != VideoCodecH264 {
+			return fmt.Errorf("preset is not supported for %s output", vp.OutputFormat())
+		}
+	}
+	return nil
+}
+
+// validateFormat checks the output container and that it can carry the audio codec
+func (vp VideoProject) validateFormat() error {
+	format := vp.OutputFormat()
+	codecs, ok := containerAudioCodecs[format]
+	if !ok {
+		return fmt.Errorf("unsupported format %q (supported: %s, %s, %s, %s)",
+			vp.Format, ContainerMP4, ContainerMOV, ContainerMKV, ContainerWebM)
+	}
+
+	codec := vp.OutputAudioCodec()
+	for _, supported := range codecs {
+		if supported == codec {
+			return nil
+		}
+	}
+	return fmt.Errorf("audio_codec %s cannot be used with %s output (supported: %s)",
+		codec, format, strings.Join(codecs, ", "))
+}
+
+// validateOutputSubdir checks that every segment of an output subdirectory is
+// a valid video ID, which rules out "..", absolute paths and empty segments
+func validateOutputSubdir(subdir string) error {
+	if subdir == "" {
+		return nil
+	}
+
+	segments := strings.Split(subdir, "/")
+	if len(segments) > MaxOutputSubdirDepth {
+		return fmt.Errorf("output_subdir must have at most %d segments", MaxOutputSubdirDepth)
+	}
+	for _, segment := range segments {
+		if !validOutputIDRegex.MatchString(segment) {
+			return errors.New("output_subdir segments must be 1-128 alphanumeric, hyphen or underscore characters")
+		}
+	}
+	return nil
+}
+
+// validateAudioOutput checks the audio codec, sample format and profile combination
+func (vp VideoProject) validateAudioOutput() error {
+	codec := vp.OutputAudioCodec()
+	sampleFormats, ok := AudioCodecSampleFormats[codec]
+	if !ok {
+		return errors.New("unsupported audio_codec: " + codec)
+	}
+
+	if vp.AudioSampleFormat != "" {
+		supported := false
+		for _, format := range sampleFormats {
+			if format == vp.AudioSampleFormat {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("audio_sample_format %q is not supported by %s (supported: %s)",
+				vp.AudioSampleFormat, codec, strings.Join(sampleFormats, ", "))
+		}
+	}
+
+	if vp.AudioProfile != "" {
+		if codec != AudioCodecAAC {
+			return errors.New("audio_profile is only supported for the aac codec")
+		}
+		if !aacProfiles[vp.AudioProfile] {
+			return errors.New("unsupported audio_profile: " + vp.AudioProfile)
+		}
+	}
+
+	return nil
+}
+
+// validateKeyframeInterval checks the GOP size option against its unit
+func (vp VideoProject) validateKeyframeInterval() error {
+	if vp.KeyframeInterval == 0 && vp.KeyframeIntervalUnit == "" {
+		return nil
+	}
+	if vp.KeyframeInterval <= 0 {
+		return errors.New("keyframe_interval must be positive")
+	}
+
+	switch vp.KeyframeIntervalUnit {
+	case "", KeyframeUnitSeconds:
+		if vp.KeyframeInterval > maxKeyframeIntervalSeconds {
+			return fmt.Errorf("keyframe_interval cannot exceed %d seconds", maxKeyframeIntervalSeconds)
+		}
+	case KeyframeUnitFrames:
+		if vp.KeyframeInterval != float64(int(vp.KeyframeInterval)) {
+			return errors.New("keyframe_interval in frames must be a whole number")
+		}
+		if vp.KeyframeInterval > maxKeyframeIntervalFrames {
+			return fmt.Errorf("keyframe_interval cannot exceed %d frames", maxKeyframeIntervalFrames)
+		}
+	default:
+		return fmt.Errorf("keyframe_interval_unit must be %s or %s", KeyframeUnitSeconds, KeyframeUnitFrames)
+	}
+
+	return nil
+}
+
+// WaveformOverlay positions an audio waveform over the video
+type WaveformOverlay struct {
+	Width    int    `json:"width,omitempty"`    // Pixels, default 640
+	Height   int    `json:"height,omitempty"`   // Pixels, default 120
+	Position string `json:"position,omitempty"` // Subtitle anchor such as "center-bottom"
+	Color    string `json:"color,omitempty"`    // Hex color such as "#FFFFFF"
+}
+
+// validWaveformPositions uses the subtitle anchor vocabulary
+var validWaveformPositions = map[string]bool{
+	"left-bottom": true, "center-bottom": true, "right-bottom": true,
+	"left-center": true, "center-center": true, "right-center": true,
+	"left-top": true, "center-top": true, "right-top": true,
+}
+
+// validHexColorRegex matches #RRGGBB colors
+var validHexColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Validate checks waveform size, anchor and color
+func (w WaveformOverlay) Validate() error {
+	if w.Width < 0 || w.Width > 4096 || w.Height < 0 || w.Height > 4096 {
+		return errors.New("waveform width and height must be between 1 and 4096")
+	}
+	if w.Position != "" && !validWaveformPositions[w.Position] {
+		return errors.New("invalid waveform position: " + w.Position)
+	}
+	if w.Color != "" && !validHexColorRegex.MatchString(w.Color) {
+		return errors.New("invalid waveform color: " + w.Color)
+	}
+	return nil
+}
+
+// ChapterMarker starts a named chapter at Time seconds
+type ChapterMarker struct {
+	Time  float64 `json:"time"`
+	Title string  `json:"title"`
 }
 
 type Scene struct {
 	ID              string    `json:"id"`
 	BackgroundColor string    `json:"background-color,omitempty"`
 	Elements        []Element `json:"elements,omitempty"`
+
+	// SubtitleSettings overrides the visual subtitle style for this scene's captions
+	SubtitleSettings SubtitleSettings `json:"subtitle-settings,omitempty"`
+
+	// Mute silences the scene's audio; Solo silences every audio element
+	// outside soloed scenes and elements. Both keep the scene's timing.
+	Mute bool `json:"mute,omitempty"`
+	Solo bool `json:"solo,omitempty"`
 }
 
 type Element struct {
@@ -38,6 +561,168 @@ type Element struct {
 
 	Settings SubtitleSettings `json:"settings,omitempty"`
 	Language string           `json:"language,omitempty"`
+
+	// ShowWhen restricts when an image overlay is visible ("audio" = only during audio-bearing scenes)
+	ShowWhen string `json:"show_when,omitempty"`
+
+	// Start/End limit a subtitle element to a time window in seconds (End 0 = until the end)
+	Start float64 `json:"start,omitempty"`
+	End   float64 `json:"end,omitempty"`
+
+	// MinDuration/MaxDuration clamp an image overlay's scene-derived window in seconds (0 = no clamp)
+	MinDuration float64 `json:"min_duration,omitempty"`
+	MaxDuration float64 `json:"max_duration,omitempty"`
+
+	// Width/Height size an image overlay in pixels. Unset keeps the native
+	// size, a single one keeps the aspect ratio; with both, ScaleMode "fit"
+	// (default) letterboxes, "fill" crops and "stretch" distorts into the box
+	Width     int    `json:"width,omitempty"`
+	Height    int    `json:"height,omitempty"`
+	ScaleMode string `json:"scale_mode,omitempty"`
+
+	// Image overlay styling: a BorderWidth-pixel border in BorderColor (#RRGGBB,
+	// default white) and corners rounded with CornerRadius pixels on the outer edge
+	BorderWidth  int    `json:"border_width,omitempty"`
+	BorderColor  string `json:"border_color,omitempty"`
+	CornerRadius int    `json:"corner_radius,omitempty"`
+
+	// AudioFromVideo sources an audio element from the audio track of the video at Src
+	AudioFromVideo bool `json:"audio_from_video,omitempty"`
+
+	// FallbackSrcs are mirrors of Src tried in order when Src fails analysis
+	FallbackSrcs []string `json:"fallback_srcs,omitempty"`
+
+	// Role "background_music" marks a project-level audio element that loops
+	// under the narration instead of being appended to it
+	Role string `json:"role,omitempty"`
+
+	// Duck lowers background music while the narration plays by compressing
+	// it with the narration as sidechain key. DuckThreshold (0-1, default
+	// 0.05) is the narration level that triggers ducking and DuckRatio
+	// (1-20, default 8) how strongly the music is reduced above it.
+	Duck          bool    `json:"duck,omitempty"`
+	DuckThreshold float64 `json:"duck_threshold,omitempty"`
+	DuckRatio     float64 `json:"duck_ratio,omitempty"`
+
+	// Mute and Solo debug an audio mix like their scene-level counterparts
+	Mute bool `json:"mute,omitempty"`
+	Solo bool `json:"solo,omitempty"`
+
+	// Frame rate analysis of video elements, filled in during media analysis
+	FrameRate         float64 `json:"-"`
+	VariableFrameRate bool    `json:"-"`
+
+	// Frame size of video elements, filled in during media analysis (0 = unknown)
+	SourceWidth  int `json:"-"`
+	SourceHeight int `json:"-"`
+
+	// Loudness of narration elements, measured by the job before rendering
+	// when two-pass normalization is configured (nil = single pass)
+	Loudness *LoudnessMeasurement `json:"-"`
+}
+
+// LoudnessMeasurement is the first pass of two-pass EBU R128 normalization,
+// as reported by FFmpeg's loudnorm filter
+type LoudnessMeasurement struct {
+	Integrated   float64 // input_i in LUFS
+	TruePeak     float64 // input_tp in dBTP
+	Range        float64 // input_lra in LU
+	Threshold    float64 // input_thresh in LUFS
+	TargetOffset float64 // target_offset in LU
+}
+
+// maxFallbackSrcs bounds how many mirrors an element may list
+const maxFallbackSrcs = 5
+
+// Bounds of image overlay sizing and styling in pixels
+const (
+	maxImageDimension   = 4096
+	maxImageBorderWidth = 100
+)
+
+// Image scale modes for overlays with both width and height
+const (
+	ScaleModeFit     = "fit"
+	ScaleModeFill    = "fill"
+	ScaleModeStretch = "stretch"
+)
+
+// Image visibility conditions
+const (
+	ShowWhenAudio = "audio"
+)
+
+// Element roles
+const (
+	RoleBackgroundMusic = "background_music"
+)
+
+// Ducking defaults and the ranges FFmpeg's sidechaincompress accepts
+const (
+	DefaultDuckThreshold = 0.05
+	DefaultDuckRatio     = 8.0
+	minDuckThreshold     = 0.000976563
+	maxDuckRatio         = 20.0
+)
+
+// DuckSettings returns the ducking threshold and ratio, defaults filled in
+func (e Element) DuckSettings() (threshold, ratio float64) {
+	threshold, ratio = e.DuckThreshold, e.DuckRatio
+	if threshold == 0 {
+		threshold = DefaultDuckThreshold
+	}
+	if ratio == 0 {
+		ratio = DefaultDuckRatio
+	}
+	return threshold, ratio
+}
+
+// AudioMuted reports whether an audio element is silenced by the mute and
+// solo flags; scene is nil for project-level elements. Once anything is
+// soloed, only soloed elements and the elements of soloed scenes are heard.
+func (vp VideoProject) AudioMuted(scene *Scene, element Element) bool {
+	if element.Mute || (scene != nil && scene.Mute) {
+		return true
+	}
+	if !vp.hasSolo() {
+		return false
+	}
+	return !element.Solo && (scene == nil || !scene.Solo)
+}
+
+// AudioCrossfades returns how many seconds each pair of consecutive narration
+// clips of the given durations overlap, or nil without audio_crossfade. A
+// blend is shortened to half of either clip, since a clip between two blends
+// must hold both; clips of unknown duration are joined with a cut (0).
+func (vp VideoProject) AudioCrossfades(durations []float64, seconds float64) []float64 {
+	if !vp.AudioCrossfade || seconds <= 0 || len(durations) < 2 {
+		return nil
+	}
+	overlaps := make([]float64, len(durations)-1)
+	for i := range overlaps {
+		overlaps[i] = math.Max(0, math.Min(seconds, math.Min(durations[i], durations[i+1])/2))
+	}
+	return overlaps
+}
+
+// hasSolo reports whether any scene or audio element is soloed
+func (vp VideoProject) hasSolo() bool {
+	for _, element := range vp.Elements {
+		if element.Solo {
+			return true
+		}
+	}
+	for _, scene := range vp.Scenes {
+		if scene.Solo {
+			return true
+		}
+		for _, element := range scene.Elements {
+			if element.Solo {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 type SubtitleSettings struct {
@@ -52,6 +737,61 @@ type SubtitleSettings struct {
 	Position     string `json:"position,omitempty"`
 	OutlineColor string `json:"outline-color,omitempty"`
 	OutlineWidth int    `json:"outline-width,omitempty"`
+
+	// Margins from the left, right and (for top/bottom positions) vertical
+	// frame edges in pixels; 0 keeps the defaults of 10, 10 and 20
+	MarginLeft     int `json:"margin-left,omitempty"`
+	MarginRight    int `json:"margin-right,omitempty"`
+	MarginVertical int `json:"margin-vertical,omitempty"`
+
+	// ScaleOutlineWithFont derives outline width and shadow offset from the font size
+	ScaleOutlineWithFont bool `json:"scale-outline-with-font,omitempty"`
+
+	// FallbackFonts is a comma-separated font stack for text outside the Latin script
+	FallbackFonts string `json:"fallback-fonts,omitempty"`
+
+	// Classic caption pacing: chunk text by reading speed (0 = show full text for the scene)
+	ReadingSpeed     float64 `json:"reading-speed,omitempty"`
+	ReadingSpeedUnit string  `json:"reading-speed-unit,omitempty"`
+
+	// Classic caption wrapping: lines of at most MaxLineLength characters,
+	// MaxLines lines per caption, extra lines split or truncated per LineOverflow
+	MaxLineLength int    `json:"max-line-length,omitempty"`
+	MaxLines      int    `json:"max-lines,omitempty"`
+	LineOverflow  string `json:"line-overflow,omitempty"`
+
+	// ScaledBorderAndShadow scales outlines and shadows with the playback
+	// resolution (default true); false keeps them pixel-exact at render size
+	ScaledBorderAndShadow *bool `json:"scaled-border-and-shadow,omitempty"`
+
+	// TextDirection is "ltr", "rtl" or "auto" (default), which lays out
+	// captions in right-to-left scripts such as Arabic and Hebrew right to left
+	TextDirection string `json:"text-direction,omitempty"`
+
+	// Font style flags; nil keeps the defaults of bold, upright, not underlined
+	Bold      *bool `json:"bold,omitempty"`
+	Italic    *bool `json:"italic,omitempty"`
+	Underline *bool `json:"underline,omitempty"`
+}
+
+// DebugLogging reports whether any project requests debug logging for its job
+func (vca VideoConfigArray) DebugLogging() bool {
+	for _, project := range vca {
+		if project.Debug {
+			return true
+		}
+	}
+	return false
+}
+
+// CallbackURL returns the URL notified when the job finishes, or ""
+func (vca VideoConfigArray) CallbackURL() string {
+	for _, project := range vca {
+		if project.CallbackURL != "" {
+			return project.CallbackURL
+		}
+	}
+	return ""
 }
 
 // Validation
@@ -70,29 +810,161 @@ func (vca VideoConfigArray) Validate() error {
 }
 
 func (vp VideoProject) Validate() error {
+	if vp.OutputID != "" && !validOutputIDRegex.MatchString(vp.OutputID) {
+		return errors.New("output_id must be 1-128 alphanumeric, hyphen or underscore characters")
+	}
+
+	if err := validateOutputSubdir(vp.OutputSubdir); err != nil {
+		return err
+	}
+
+	if err := checkExclusions(vp.exclusions()); err != nil {
+		return err
+	}
+
+	if vp.CallbackURL != "" {
+		parsed, err := url.Parse(vp.CallbackURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.New("callback_url must be an absolute http or https URL")
+		}
+	}
+
+	if err := vp.validateAudioOutput(); err != nil {
+		return err
+	}
+
+	if err := vp.validateFormat(); err != nil {
+		return err
+	}
+
+	if vp.Faststart != nil && *vp.Faststart && !vp.SupportsFaststart() {
+		return fmt.Errorf("faststart is not supported for %s output", vp.OutputFormat())
+	}
+
+	if err := vp.validateBitrateCap(); err != nil {
+		return err
+	}
+
+	if err := vp.validateEncoderSettings(); err != nil {
+		return err
+	}
+
+	if err := vp.validateKeyframeInterval(); err != nil {
+		return err
+	}
+
+	if vp.PreviewSeconds < 0 || math.IsNaN(vp.PreviewSeconds) || math.IsInf(vp.PreviewSeconds, 0) {
+		return errors.New("preview_seconds must be a non-negative number")
+	}
+
+	if err := vp.validateRenditions(); err != nil {
+		return err
+	}
+
+	switch vp.SubtitleMode {
+	case "", SubtitleModeBurn, SubtitleModeSidecar, SubtitleModeJSON:
+	default:
+		return fmt.Errorf("subtitle_mode must be %s, %s or %s", SubtitleModeBurn, SubtitleModeSidecar, SubtitleModeJSON)
+	}
+
+	seenFormats := make(map[string]bool, len(vp.SubtitleFormats))
+	for _, format := range vp.SubtitleFormats {
+		if format != SubtitleFormatSRT && format != SubtitleFormatVTT {
+			return fmt.Errorf("subtitle_formats entries must be %s or %s", SubtitleFormatSRT, SubtitleFormatVTT)
+		}
+		if seenFormats[format] {
+			return fmt.Errorf("subtitle_formats lists %s more than once", format)
+		}
+		seenFormats[format] = true
+	}
+
+	switch vp.BackgroundLoop {
+	case "", BackgroundLoopRepeat, BackgroundLoopCrossfade:
+	default:
+		return fmt.Errorf("background_loop must be %s or %s", BackgroundLoopRepeat, BackgroundLoopCrossfade)
+	}
+
+	if vp.Waveform != nil {
+		if err := vp.Waveform.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if vp.Timeline != nil {
+		if err := vp.Timeline.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Validate chapter markers; the upper bound is checked once durations are known
+	for i, chapter := range vp.Chapters {
+		if strings.TrimSpace(chapter.Title) == "" {
+			return fmt.Errorf("chapter %d: title is required", i)
+		}
+		if chapter.Time < 0 {
+			return fmt.Errorf("chapter %d: time cannot be negative", i)
+		}
+		if i > 0 && chapter.Time <= vp.Chapters[i-1].Time {
+			return fmt.Errorf("chapter %d: markers must be in ascending time order", i)
+		}
+	}
+
 	// Validate scenes
 	for i, scene := range vp.Scenes {
 		if scene.ID == "" {
 			return errors.New("scene " + string(rune(i)) + ": ID is required")
 		}
+		if err := checkExclusions(scene.exclusions()); err != nil {
+			return errors.New("scene " + scene.ID + ": " + err.Error())
+		}
 
 		for j, element := range scene.Elements {
 			if err := element.Validate(); err != nil {
 				return errors.New("scene " + scene.ID + " element " + string(rune(j)) + ": " + err.Error())
 			}
+			if element.Role != "" {
+				return errors.New("scene " + scene.ID + ": background music must be a project-level element")
+			}
 		}
 	}
 
 	// Validate global elements
+	musicTracks := 0
 	for i, element := range vp.Elements {
 		if err := element.Validate(); err != nil {
 			return errors.New("global element " + string(rune(i)) + ": " + err.Error())
 		}
+		if element.Role == RoleBackgroundMusic {
+			musicTracks++
+		}
+	}
+	if musicTracks > 1 {
+		return errors.New("only one background music element is allowed")
 	}
 
 	return nil
 }
 
+// validateDucking checks the ducking options of a background music element
+func (e Element) validateDucking() error {
+	if !e.Duck {
+		if e.DuckThreshold != 0 || e.DuckRatio != 0 {
+			return errors.New("duck_threshold and duck_ratio require duck")
+		}
+		return nil
+	}
+	if e.Role != RoleBackgroundMusic {
+		return errors.New("duck is only supported for background music")
+	}
+	if e.DuckThreshold != 0 && (e.DuckThreshold < minDuckThreshold || e.DuckThreshold > 1) {
+		return fmt.Errorf("duck_threshold must be between %g and 1", minDuckThreshold)
+	}
+	if e.DuckRatio != 0 && (e.DuckRatio < 1 || e.DuckRatio > maxDuckRatio) {
+		return fmt.Errorf("duck_ratio must be between 1 and %g", maxDuckRatio)
+	}
+	return nil
+}
+
 func (e Element) Validate() error {
 	if e.Type == "" {
 		return errors.New("element type is required")
@@ -114,6 +986,146 @@ func (e Element) Validate() error {
 		return errors.New("duration cannot be negative")
 	}
 
+	if err := checkExclusions(e.exclusions()); err != nil {
+		return err
+	}
+
+	if len(e.FallbackSrcs) > 0 {
+		if e.Type != "audio" && e.Type != "video" {
+			return errors.New("fallback_srcs are only supported for audio and video elements")
+		}
+		if len(e.FallbackSrcs) > maxFallbackSrcs {
+			return fmt.Errorf("at most %d fallback_srcs are allowed", maxFallbackSrcs)
+		}
+		for _, src := range e.FallbackSrcs {
+			if strings.TrimSpace(src) == "" {
+				return errors.New("fallback_srcs cannot contain empty URLs")
+			}
+		}
+	}
+
+	if (e.Mute || e.Solo) && e.Type != "audio" {
+		return errors.New("mute and solo are only supported for audio elements")
+	}
+
+	if e.Role != "" {
+		if e.Role != RoleBackgroundMusic {
+			return errors.New("unsupported role: " + e.Role)
+		}
+		if e.Type != "audio" {
+			return errors.New("role background_music is only supported for audio elements")
+		}
+	}
+
+	if err := e.validateDucking(); err != nil {
+		return err
+	}
+
+	if e.Start != 0 || e.End != 0 {
+		if e.Type != "subtitles" {
+			return errors.New("start/end window is only supported for subtitle elements")
+		}
+		if e.Start < 0 || e.End < 0 {
+			return errors.New("subtitle window cannot be negative")
+		}
+		if e.End != 0 && e.End <= e.Start {
+			return errors.New("subtitle window end must be after start")
+		}
+	}
+
+	if e.ShowWhen != "" {
+		if e.Type != "image" {
+			return errors.New("show_when is only supported for image elements")
+		}
+		if e.ShowWhen != ShowWhenAudio {
+			return errors.New("unsupported show_when value: " + e.ShowWhen)
+		}
+	}
+
+	if e.MinDuration != 0 || e.MaxDuration != 0 {
+		if e.Type != "image" {
+			return errors.New("min_duration/max_duration are only supported for image elements")
+		}
+		if e.MinDuration < 0 || e.MaxDuration < 0 {
+			return errors.New("min_duration/max_duration cannot be negative")
+		}
+		if e.MaxDuration != 0 && e.MaxDuration < e.MinDuration {
+			return errors.New("max_duration must not be less than min_duration")
+		}
+	}
+
+	if e.AudioFromVideo && e.Type != "audio" {
+		return errors.New("audio_from_video is only supported for audio elements")
+	}
+
+	if e.Language != "" {
+		if e.Type != "audio" && e.Type != "subtitles" {
+			return errors.New("language is only supported for audio and subtitle elements")
+		}
+		if !IsTranscriptionLanguage(e.Language) {
+			return fmt.Errorf("unsupported language %q: must be a lower-case ISO 639-1 code or \"auto\"", e.Language)
+		}
+	}
+
+	if err := e.validateImageSize(); err != nil {
+		return err
+	}
+
+	if err := e.validateImageStyle(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateImageSize checks the overlay dimensions and scale mode of image elements
+func (e Element) validateImageSize() error {
+	if e.Width == 0 && e.Height == 0 && e.ScaleMode == "" {
+		return nil
+	}
+	if e.Type != "image" {
+		return errors.New("width, height and scale_mode are only supported for image elements")
+	}
+	if e.Width < 0 || e.Width > maxImageDimension || e.Height < 0 || e.Height > maxImageDimension {
+		return fmt.Errorf("image width and height must be between 0 and %d", maxImageDimension)
+	}
+	switch e.ScaleMode {
+	case "":
+	case ScaleModeFit, ScaleModeFill, ScaleModeStretch:
+		if e.Width == 0 || e.Height == 0 {
+			return errors.New("scale_mode requires both width and height")
+		}
+	default:
+		return errors.New("unsupported scale_mode: " + e.ScaleMode)
+	}
+	return nil
+}
+
+// validateImageStyle checks the border and corner radius of image overlays
+func (e Element) validateImageStyle() error {
+	if e.BorderWidth == 0 && e.BorderColor == "" && e.CornerRadius == 0 {
+		return nil
+	}
+	if e.Type != "image" {
+		return errors.New("border_width, border_color and corner_radius are only supported for image elements")
+	}
+	if e.BorderWidth < 0 || e.BorderWidth > maxImageBorderWidth {
+		return fmt.Errorf("border_width must be between 0 and %d", maxImageBorderWidth)
+	}
+	if e.BorderColor != "" {
+		if e.BorderWidth == 0 {
+			return errors.New("border_color requires border_width")
+		}
+		if !validHexColorRegex.MatchString(e.BorderColor) {
+			return errors.New("invalid border_color: " + e.BorderColor)
+		}
+	}
+
+	// Radii beyond half the rendered overlay are clamped when rendering
+	maxRadius := maxImageDimension/2 + maxImageBorderWidth
+	if e.CornerRadius < 0 || e.CornerRadius > maxRadius {
+		return fmt.Errorf("corner_radius must be between 0 and %d", maxRadius)
+	}
 	return nil
 }
 
@@ -126,17 +1138,216 @@ type TimingSegment struct {
 	Transcript string  `json:"transcript,omitempty"`
 }
 
+// RenderCommand is the FFmpeg invocation a project would be rendered with
+type RenderCommand struct {
+	Binary     string   `json:"binary"`
+	Args       []string `json:"args"`
+	OutputPath string   `json:"output_path"`
+
+	// SubtitlesOmitted is set when the project has subtitles; they need a
+	// transcription, so their filters are left out of dry runs
+	SubtitlesOmitted bool `json:"subtitles_omitted,omitempty"`
+}
+
+// RenderEstimate is the predicted cost of rendering a project without executing FFmpeg
+type RenderEstimate struct {
+	TotalDuration          float64           `json:"total_duration"`
+	Width                  int               `json:"width"`
+	Height                 int               `json:"height"`
+	Quality                string            `json:"quality"`
+	EstimatedRenderSeconds float64           `json:"estimated_render_seconds"`
+	EstimatedSizeBytes     int64             `json:"estimated_size_bytes"`
+	Elements               []ElementDuration `json:"elements"`
+}
+
+// ElementDuration reports the analyzed duration of a single media element
+type ElementDuration struct {
+	SceneID  string  `json:"scene_id,omitempty"`
+	Type     string  `json:"type"`
+	Src      string  `json:"src"`
+	Duration float64 `json:"duration"`
+}
+
+// RenderManifest describes a completed render for downstream automation
+type RenderManifest struct {
+	JobID      string             `json:"job_id"`
+	VideoID    string             `json:"video_id"`
+	CreatedAt  time.Time          `json:"created_at"`
+	Output     ManifestOutput     `json:"output"`
+	Subtitles  ManifestSubtitles  `json:"subtitles"`
+	Scenes     []ManifestScene    `json:"scenes,omitempty"`
+	Sources    []ManifestSource   `json:"sources"`
+	Parameters ManifestParameters `json:"parameters"`
+
+	// Locale and Display carry human-readable renderings of the numeric fields
+	Locale  string          `json:"locale"`
+	Display ManifestDisplay `json:"display"`
+}
+
+// ManifestDisplay holds locale-formatted values for people reading the manifest
+type ManifestDisplay struct {
+	Duration  string   `json:"duration"`
+	FrameRate string   `json:"frame_rate,omitempty"`
+	Scenes    []string `json:"scenes,omitempty"`
+}
+
+// ManifestOutput is the probed metadata of the rendered file
+type ManifestOutput struct {
+	Width      int     `json:"width"`
+	Height     int     `json:"height"`
+	Duration   float64 `json:"duration"`
+	Format     string  `json:"format"`
+	VideoCodec string  `json:"video_codec"`
+	AudioCodec string  `json:"audio_codec"`
+	FrameRate  float64 `json:"frame_rate,omitempty"`
+	HasAudio   bool    `json:"has_audio"`
+}
+
+// ManifestSubtitles reports whether and how subtitles were delivered
+type ManifestSubtitles struct {
+	Present    bool   `json:"present"`
+	Mode       string `json:"mode,omitempty"`
+	Format     string `json:"format,omitempty"`
+	Style      string `json:"style,omitempty"`
+	EventCount int    `json:"event_count,omitempty"`
+	SubtitleID string `json:"subtitle_id,omitempty"`
+}
+
+// ManifestScene is the position of a scene in the output timeline
+type ManifestScene struct {
+	ID        string  `json:"id,omitempty"`
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+}
+
+// ManifestSource is an input used by the render; Src has credentials and
+// query strings removed
+type ManifestSource struct {
+	SceneID  string  `json:"scene_id,omitempty"`
+	Type     string  `json:"type"`
+	Src      string  `json:"src"`
+	Duration float64 `json:"duration,omitempty"`
+}
+
+// ManifestParameters are the render settings requested for the project
+type ManifestParameters struct {
+	Resolution           string  `json:"resolution,omitempty"`
+	Quality              string  `json:"quality,omitempty"`
+	Width                int     `json:"width,omitempty"`
+	Height               int     `json:"height,omitempty"`
+	AudioCodec           string  `json:"audio_codec"`
+	AudioSampleFormat    string  `json:"audio_sample_format,omitempty"`
+	AudioProfile         string  `json:"audio_profile,omitempty"`
+	KeyframeInterval     float64 `json:"keyframe_interval,omitempty"`
+	KeyframeIntervalUnit string  `json:"keyframe_interval_unit,omitempty"`
+	OutputID             string  `json:"output_id,omitempty"`
+	OutputSubdir         string  `json:"output_subdir,omitempty"`
+	Timeline             bool    `json:"timeline,omitempty"`
+}
+
 // Job model
 type Job struct {
 	ID          string           `json:"id"`
 	Status      JobStatus        `json:"status"`
 	Config      VideoConfigArray `json:"config"`
 	VideoID     string           `json:"video_id,omitempty"`
+	SubtitleID  string           `json:"subtitle_id,omitempty"`
+	ManifestID  string           `json:"manifest_id,omitempty"`
 	Error       string           `json:"error,omitempty"`
 	Progress    int              `json:"progress"`
+	Priority    JobPriority      `json:"priority"`
 	CreatedAt   time.Time        `json:"created_at"`
 	UpdatedAt   time.Time        `json:"updated_at"`
 	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+	Warnings    []string         `json:"warnings,omitempty"`
+
+	// ResolvedSrcs maps a primary src to the fallback src that replaced it
+	ResolvedSrcs map[string]string `json:"resolved_srcs,omitempty"`
+
+	// SubtitleFiles maps each exported caption format of the first output
+	// to its stored ID
+	SubtitleFiles map[string]string `json:"subtitle_files,omitempty"`
+
+	// Renditions lists the stored outputs of a multi-rendition job; VideoID
+	// is the first of them
+	Renditions []RenditionOutput `json:"renditions,omitempty"`
+
+	// RenderProgress details the running FFmpeg render (the current
+	// rendition of a multi-rendition job); Progress stays the job's percent
+	RenderProgress *RenderProgress `json:"render_progress,omitempty"`
+
+	// RetryCount is the number of automatic retries so far; NextRetryAt is
+	// set while a pending job waits for its next attempt
+	RetryCount  int        `json:"retry_count,omitempty"`
+	NextRetryAt *time.Time `json:"next_retry_at,omitempty"`
+
+	// Health flags long-running processing jobs; it never changes Status
+	Health         JobHealth  `json:"health,omitempty"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	LastProgressAt time.Time  `json:"-"`
+}
+
+// RenderProgress is a progress report of a running FFmpeg render
+type RenderProgress struct {
+	Percent     int     `json:"percent"`
+	Frame       int64   `json:"frame"`
+	CurrentTime float64 `json:"current_time"` // Seconds of output rendered
+	Speed       float64 `json:"speed"`        // Multiple of real time, 0 when unknown
+	ETASeconds  float64 `json:"eta_seconds"`  // 0 when the speed is unknown
+
+	// Heartbeat marks a repeated report sent while FFmpeg is silent
+	Heartbeat bool `json:"heartbeat,omitempty"`
+}
+
+// RenditionOutput is a stored rendition of a completed job
+type RenditionOutput struct {
+	Name       string `json:"name"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	VideoID    string `json:"video_id"`
+	SubtitleID string `json:"subtitle_id,omitempty"`
+	ManifestID string `json:"manifest_id,omitempty"`
+
+	// SubtitleFiles maps each exported caption format to its stored ID
+	SubtitleFiles map[string]string `json:"subtitle_files,omitempty"`
+}
+
+// JobHealth is an informational flag for processing jobs
+type JobHealth string
+
+const (
+	JobHealthSlow          JobHealth = "slow"           // Running long but still progressing
+	JobHealthPossiblyStuck JobHealth = "possibly_stuck" // No progress movement for the stuck threshold
+)
+
+// JobPriority orders queued jobs; higher priorities start first
+type JobPriority string
+
+const (
+	JobPriorityLow    JobPriority = "low"
+	JobPriorityNormal JobPriority = "normal"
+	JobPriorityHigh   JobPriority = "high"
+)
+
+// IsValid reports whether p is a known priority
+func (p JobPriority) IsValid() bool {
+	switch p {
+	case JobPriorityLow, JobPriorityNormal, JobPriorityHigh:
+		return true
+	}
+	return false
+}
+
+// Rank orders priorities; jobs saved without a priority rank as normal
+func (p JobPriority) Rank() int {
+	switch p {
+	case JobPriorityHigh:
+		return 2
+	case JobPriorityLow:
+		return 0
+	default:
+		return 1
+	}
 }
 
 type JobStatus string
@@ -161,6 +1372,20 @@ type VideoInfo struct {
 	Duration  float64 `json:"duration"`
 	Format    string  `json:"format"`
 	Codec     string  `json:"codec,omitempty"`
+	HasAudio  bool    `json:"has_audio"`
+
+	// FrameRate is the average frame rate; VariableFrameRate is set when it
+	// differs from the nominal (r_frame_rate) rate
+	FrameRate         float64 `json:"frame_rate,omitempty"`
+	VariableFrameRate bool    `json:"variable_frame_rate"`
+
+	// Recorded when the video was stored: SHA-256 of its bytes (hex) and
+	// its detected content type
+	SHA256      string `json:"sha256,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+
+	// Subdir is the output subdirectory the video is stored in, if any
+	Subdir string `json:"subdir,omitempty"`
 }
 
 // GetDuration returns the video duration - implements common interface for job service
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestElementValidateDucking(t *testing.T) {
+	music := Element{Type: "audio", Src: "https://example.com/music.mp3", Role: RoleBackgroundMusic}
+
+	tests := []struct {
+		name    string
+		modify  func(e *Element)
+		wantErr bool
+	}{
+		{"defaults", func(e *Element) { e.Duck = true }, false},
+		{"custom", func(e *Element) { e.Duck, e.DuckThreshold, e.DuckRatio = true, 0.1, 12 }, false},
+		{"threshold too high", func(e *Element) { e.Duck, e.DuckThreshold = true, 1.5 }, true},
+		{"threshold too low", func(e *Element) { e.Duck, e.DuckThreshold = true, 0.0001 }, true},
+		{"ratio below one", func(e *Element) { e.Duck, e.DuckRatio = true, 0.5 }, true},
+		{"ratio too high", func(e *Element) { e.Duck, e.DuckRatio = true, 25 }, true},
+		{"settings without duck", func(e *Element) { e.DuckRatio = 4 }, true},
+		{"narration", func(e *Element) { e.Role, e.Duck = "", true }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			element := music
+			tt.modify(&element)
+			if err := element.validateDucking(); (err != nil) != tt.wantErr {
+				t.Errorf("validateDucking() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestElementValidateDurationClamps(t *testing.T) {
+	image := Element{Type: "image", Src: "https://example.com/logo.png"}
+
+	tests := []struct {
+		name    string
+		modify  func(e *Element)
+		wantErr bool
+	}{
+		{"unset", func(e *Element) {}, false},
+		{"minimum only", func(e *Element) { e.MinDuration = 2 }, false},
+		{"maximum only", func(e *Element) { e.MaxDuration = 5 }, false},
+		{"equal bounds", func(e *Element) { e.MinDuration, e.MaxDuration = 3, 3 }, false},
+		{"maximum below minimum", func(e *Element) { e.MinDuration, e.MaxDuration = 3, 2.5 }, true},
+		{"negative minimum", func(e *Element) { e.MinDuration = -1 }, true},
+		{"negative maximum", func(e *Element) { e.MaxDuration = -1 }, true},
+		{"not an image", func(e *Element) { e.Type, e.MinDuration = "audio", 2 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			element := image
+			tt.modify(&element)
+			if err := element.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVideoProjectAudioMuted(t *testing.T) {
+	narration := Element{Type: "audio", Src: "a.mp3"}
+	muted := Element{Type: "audio", Src: "b.mp3", Mute: true}
+	soloed := Element{Type: "audio", Src: "c.mp3", Solo: true}
+	quiet := Scene{ID: "quiet", Mute: true, Elements: []Element{narration}}
+	plain := Scene{ID: "plain", Elements: []Element{narration, muted}}
+
+	project := VideoProject{Scenes: []Scene{quiet, plain}}
+	if project.AudioMuted(&plain, narration) {
+		t.Error("unmuted element in an unmuted scene must be heard")
+	}
+	if !project.AudioMuted(&plain, muted) {
+		t.Error("muted element must be silenced")
+	}
+	if !project.AudioMuted(&quiet, narration) {
+		t.Error("elements of a muted scene must be silenced")
+	}
+
+	soloScene := Scene{ID: "solo", Elements: []Element{soloed}}
+	project = VideoProject{Scenes: []Scene{plain, soloScene}}
+	if !project.AudioMuted(&plain, narration) {
+		t.Error("with a solo set, other elements must be silenced")
+	}
+	if project.AudioMuted(&soloScene, soloed) {
+		t.Error("soloed element must be heard")
+	}
+}
+
+func TestVideoProjectAudioCrossfades(t *testing.T) {
+	tests := []struct {
+		name      string
+		crossfade bool
+		durations []float64
+		seconds   float64
+		want      []float64
+	}{
+		{"disabled", false, []float64{4, 4}, 0.5, nil},
+		{"single clip", true, []float64{4}, 0.5, nil},
+		{"no blend length", true, []float64{4, 4}, 0, nil},
+		{"configured length", true, []float64{4, 3, 5}, 0.5, []float64{0.5, 0.5}},
+		{"shortened to half a clip", true, []float64{4, 0.6, 4}, 0.5, []float64{0.3, 0.3}},
+		{"unknown duration cuts", true, []float64{4, 0, 4}, 0.5, []float64{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := VideoProject{AudioCrossfade: tt.crossfade}
+			if got := project.AudioCrossfades(tt.durations, tt.seconds); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AudioCrossfades() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateOutputSubdir(t *testing.T) {
+	tests := []struct {
+		subdir  string
+		wantErr bool
+	}{
+		{"", false},
+		{"campaign", false},
+		{"clients/acme/2026", false},
+		{"clients/acme/2026/q4", true},
+		{"../escape", true},
+		{"/absolute", true},
+		{"trailing/", true},
+		{"a//b", true},
+		{"with space", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subdir, func(t *testing.T) {
+			if err := validateOutputSubdir(tt.subdir); (err != nil) != tt.wantErr {
+				t.Errorf("validateOutputSubdir(%q) error = %v, wantErr %v", tt.subdir, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestElementValidateLanguage(t *testing.T) {
+	tests := []struct {
+		name    string
+		element Element
+		wantErr bool
+	}{
+		{"audio", Element{Type: "audio", Src: "https://example.com/a.mp3", Language: "de"}, false},
+		{"subtitles", Element{Type: "subtitles", Language: "ja"}, false},
+		{"detected", Element{Type: "audio", Src: "https://example.com/a.mp3", Language: LanguageAuto}, false},
+		{"whisper three-letter code", Element{Type: "audio", Src: "https://example.com/a.mp3", Language: "yue"}, false},
+		{"upper case", Element{Type: "audio", Src: "https://example.com/a.mp3", Language: "DE"}, true},
+		{"region subtag", Element{Type: "audio", Src: "https://example.com/a.mp3", Language: "en-US"}, true},
+		{"unknown code", Element{Type: "audio", Src: "https://example.com/a.mp3", Language: "xx"}, true},
+		{"image", Element{Type: "image", Src: "https://example.com/a.png", Language: "de"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.element.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Timeline track types
+const (
+	TrackTypeVideo = "video"
+	TrackTypeAudio = "audio"
+	TrackTypeImage = "image"
+	TrackTypeText  = "text"
+)
+
+// Timeline is an EDL-style alternative to scenes: tracks of clips placed at
+// explicit offsets. The first clip of the first video track is the base video
+// and is looped to cover the timeline; every other clip is layered on top.
+type Timeline struct {
+	Tracks []TimelineTrack `json:"tracks"`
+}
+
+// TimelineTrack holds clips of one media type; clips on a track may not overlap
+type TimelineTrack struct {
+	Type  string         `json:"type"`
+	Clips []TimelineClip `json:"clips"`
+}
+
+// TimelineClip places media on the timeline at Offset for Duration seconds
+type TimelineClip struct {
+	Src      string  `json:"src,omitempty"`
+	Text     string  `json:"text,omitempty"` // Text tracks only
+	Offset   float64 `json:"offset"`
+	Duration float64 `json:"duration"`
+
+	X        int `json:"x,omitempty"`
+	Y        int `json:"y,omitempty"`
+	FontSize int `json:"font-size,omitempty"` // Text tracks only
+}
+
+// End returns the clip's end time on the timeline
+func (c TimelineClip) End() float64 {
+	return c.Offset + c.Duration
+}
+
+// Duration returns the end of the last clip on the timeline
+func (t Timeline) Duration() float64 {
+	var duration float64
+	for _, track := range t.Tracks {
+		for _, clip := range track.Clips {
+			if clip.End() > duration {
+				duration = clip.End()
+			}
+		}
+	}
+	return duration
+}
+
+// BaseVideo returns the clip used as the looped base video
+func (t Timeline) BaseVideo() (TimelineClip, bool) {
+	for _, track := range t.Tracks {
+		if track.Type == TrackTypeVideo && len(track.Clips) > 0 {
+			return track.Clips[0], true
+		}
+	}
+	return TimelineClip{}, false
+}
+
+// Validate checks track types, clip timing and overlaps within each track
+func (t Timeline) Validate() error {
+	if len(t.Tracks) == 0 {
+		return errors.New("timeline requires at least one track")
+	}
+
+	base, ok := t.BaseVideo()
+	if !ok {
+		return errors.New("timeline requires a video track with at least one clip")
+	}
+	if base.Offset != 0 {
+		return errors.New("the first video clip must start at offset 0")
+	}
+
+	for i, track := range t.Tracks {
+		switch track.Type {
+		case TrackTypeVideo, TrackTypeAudio, TrackTypeImage, TrackTypeText:
+		default:
+			return fmt.Errorf("track %d: unsupported type %q", i, track.Type)
+		}
+
+		for j, clip := range track.Clips {
+			if track.Type == TrackTypeText {
+				if clip.Text == "" {
+					return fmt.Errorf("track %d clip %d: text is required", i, j)
+				}
+				if clip.FontSize < 0 || clip.FontSize > 300 {
+					return fmt.Errorf("track %d clip %d: font-size must be between 1 and 300", i, j)
+				}
+			} else if clip.Src == "" {
+				return fmt.Errorf("track %d clip %d: src is required", i, j)
+			}
+
+			if clip.Offset < 0 {
+				return fmt.Errorf("track %d clip %d: offset cannot be negative", i, j)
+			}
+			if clip.Duration <= 0 {
+				return fmt.Errorf("track %d clip %d: duration must be positive", i, j)
+			}
+			if j > 0 && clip.Offset < track.Clips[j-1].End() {
+				return fmt.Errorf("track %d clip %d: overlaps or precedes the previous clip", i, j)
+			}
+		}
+	}
+
+	return nil
+}
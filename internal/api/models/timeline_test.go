@@ -0,0 +1,86 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTimelineDuration(t *testing.T) {
+	timeline := Timeline{Tracks: []TimelineTrack{
+		{Type: TrackTypeVideo, Clips: []TimelineClip{{Src: "bg.mp4", Duration: 10}}},
+		// A gap before the last clip still counts toward the length
+		{Type: TrackTypeAudio, Clips: []TimelineClip{{Src: "a.mp3", Offset: 2, Duration: 3}, {Src: "b.mp3", Offset: 12, Duration: 4}}},
+		{Type: TrackTypeText, Clips: []TimelineClip{{Text: "Hi", Offset: 1, Duration: 2}}},
+	}}
+	if got := timeline.Duration(); got != 16 {
+		t.Errorf("Duration() = %v, want 16", got)
+	}
+}
+
+func TestTimelineBaseVideo(t *testing.T) {
+	timeline := Timeline{Tracks: []TimelineTrack{
+		{Type: TrackTypeAudio, Clips: []TimelineClip{{Src: "a.mp3", Duration: 3}}},
+		{Type: TrackTypeVideo},
+		{Type: TrackTypeVideo, Clips: []TimelineClip{{Src: "bg.mp4", Duration: 10}, {Src: "cut.mp4", Offset: 10, Duration: 2}}},
+	}}
+	base, ok := timeline.BaseVideo()
+	if !ok || base.Src != "bg.mp4" {
+		t.Errorf("BaseVideo() = %+v, %v, want the first clip of the first non-empty video track", base, ok)
+	}
+}
+
+func TestTimelineValidate(t *testing.T) {
+	base := TimelineTrack{Type: TrackTypeVideo, Clips: []TimelineClip{{Src: "bg.mp4", Duration: 10}}}
+	audio := func(clips ...TimelineClip) Timeline {
+		return Timeline{Tracks: []TimelineTrack{base, {Type: TrackTypeAudio, Clips: clips}}}
+	}
+
+	tests := []struct {
+		name     string
+		timeline Timeline
+		wantErr  string
+	}{
+		{name: "gap between clips", timeline: audio(TimelineClip{Src: "a", Duration: 2}, TimelineClip{Src: "b", Offset: 5, Duration: 2})},
+		{name: "adjacent clips", timeline: audio(TimelineClip{Src: "a", Duration: 2}, TimelineClip{Src: "b", Offset: 2, Duration: 2})},
+		{
+			name:     "overlapping clips",
+			timeline: audio(TimelineClip{Src: "a", Duration: 3}, TimelineClip{Src: "b", Offset: 2, Duration: 2}),
+			wantErr:  "overlaps or precedes",
+		},
+		{
+			name:     "clips out of order",
+			timeline: audio(TimelineClip{Src: "a", Offset: 5, Duration: 1}, TimelineClip{Src: "b", Offset: 1, Duration: 1}),
+			wantErr:  "overlaps or precedes",
+		},
+		{
+			name: "overlap across tracks",
+			timeline: Timeline{Tracks: []TimelineTrack{
+				base,
+				{Type: TrackTypeAudio, Clips: []TimelineClip{{Src: "a", Duration: 5}}},
+				{Type: TrackTypeAudio, Clips: []TimelineClip{{Src: "b", Offset: 2, Duration: 5}}},
+			}},
+		},
+		{
+			name:     "base video starts late",
+			timeline: Timeline{Tracks: []TimelineTrack{{Type: TrackTypeVideo, Clips: []TimelineClip{{Src: "bg.mp4", Offset: 1, Duration: 10}}}}},
+			wantErr:  "must start at offset 0",
+		},
+		{name: "no base video", timeline: Timeline{Tracks: []TimelineTrack{{Type: TrackTypeAudio}}}, wantErr: "requires a video track"},
+		{name: "negative offset", timeline: audio(TimelineClip{Src: "a", Offset: -1, Duration: 2}), wantErr: "offset cannot be negative"},
+		{name: "zero duration", timeline: audio(TimelineClip{Src: "a", Duration: 0}), wantErr: "duration must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.timeline.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -4,21 +4,32 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"github.com/spf13/viper"
+	"golang.org/x/text/language"
 )
 
 type Config struct {
 	Server        ServerConfig        `mapstructure:"server"`
 	FFmpeg        FFmpegConfig        `mapstructure:"ffmpeg"`
+	Audio         AudioConfig         `mapstructure:"audio"`
 	Transcription TranscriptionConfig `mapstructure:"transcription"`
 	Subtitles     SubtitlesConfig     `mapstructure:"subtitles"`
 	Storage       StorageConfig       `mapstructure:"storage"`
 	Job           JobConfig           `mapstructure:"job"`
 	Log           LogConfig           `mapstructure:"log"`
 	Security      SecurityConfig      `mapstructure:"security"`
+	Estimate      EstimateConfig      `mapstructure:"estimate"`
+	Health        HealthConfig        `mapstructure:"health"`
 }
 
 type ServerConfig struct {
@@ -36,13 +47,126 @@ type FFmpegConfig struct {
 	Timeout     time.Duration `mapstructure:"timeout"`
 	Quality     int           `mapstructure:"quality"`
 	Preset      string        `mapstructure:"preset"`
+
+	// Faststart relocates the MP4/MOV index for progressive playback; it
+	// requires a second pass over the output file
+	Faststart bool `mapstructure:"faststart"`
+
+	// StripMetadata drops global, stream and chapter metadata of the inputs
+	// from rendered videos unless a project sets strip_metadata itself
+	StripMetadata bool `mapstructure:"strip_metadata"`
+
+	// Preview renders (preview_seconds) are capped to PreviewMaxSeconds and
+	// encoded with PreviewPreset instead of the project's preset
+	PreviewMaxSeconds float64 `mapstructure:"preview_max_seconds"`
+	PreviewPreset     string  `mapstructure:"preview_preset"`
+
+	// OversizedImagePolicy decides what happens to image overlays sized
+	// larger than the background video frame
+	OversizedImagePolicy string `mapstructure:"oversized_image_policy"`
+
+	// BackgroundCrossfadeSeconds is the blend at each loop boundary of a
+	// background_loop "crossfade" project
+	BackgroundCrossfadeSeconds float64 `mapstructure:"background_crossfade_seconds"`
+
+	// BackgroundLoopWarnThreshold adds a job warning when a background video
+	// must repeat more often than this to cover the content (0 = never)
+	BackgroundLoopWarnThreshold int `mapstructure:"background_loop_warn_threshold"`
+
+	// HWAccel selects a hardware encoder (none, nvenc, vaapi or qsv).
+	// HWAccelDevice is the DRM render node used by VAAPI and QSV; with
+	// HWAccelFallback set, renders use the software encoder when the
+	// device is unavailable instead of failing.
+	HWAccel         string `mapstructure:"hwaccel"`
+	HWAccelDevice   string `mapstructure:"hwaccel_device"`
+	HWAccelFallback bool   `mapstructure:"hwaccel_fallback"`
+
+	// InitialProgress is reported as soon as FFmpeg starts (0 disables), and
+	// ProgressHeartbeat re-sends the last progress whenever FFmpeg reported
+	// nothing for that long (0 disables), so long setup phases don't look hung
+	InitialProgress   int           `mapstructure:"initial_progress"`
+	ProgressHeartbeat time.Duration `mapstructure:"progress_heartbeat"`
+
+	// IntegrityCheck decodes every render once more before it is stored:
+	// "off", "warn" (decode errors become job warnings) or "fail"
+	IntegrityCheck string `mapstructure:"integrity_check"`
+
+	// NormalizeAudio runs every narration input through EBU R128 loudnorm
+	// unless a project sets normalize_audio itself. LoudnormMode "single"
+	// normalizes in one pass; "two_pass" measures each input first for a
+	// more accurate, linear normalization at the cost of an extra decode.
+	NormalizeAudio bool   `mapstructure:"normalize_audio"`
+	LoudnormMode   string `mapstructure:"loudnorm_mode"`
+}
+
+// Modes of audio loudness normalization
+const (
+	LoudnormSinglePass = "single"
+	LoudnormTwoPass    = "two_pass"
+)
+
+// Modes of the post-render integrity check
+const (
+	IntegrityCheckOff  = "off"
+	IntegrityCheckWarn = "warn"
+	IntegrityCheckFail = "fail"
+)
+
+// Policies for image overlays larger than the canvas
+const (
+	OversizedImageClip   = "clip"
+	OversizedImageFit    = "fit"
+	OversizedImageReject = "reject"
+)
+
+// AudioConfig shapes the narration track of scene-based renders
+type AudioConfig struct {
+	// TailPadding is the silence in seconds appended after the narration;
+	// the output duration includes it, so the video outlasts the last word
+	TailPadding float64 `mapstructure:"tail_padding"`
+
+	// CrossfadeSeconds is the blend between consecutive narration clips of
+	// audio_crossfade projects
+	CrossfadeSeconds float64 `mapstructure:"crossfade_seconds"`
 }
 
+// Upper bounds of the audio settings in seconds
+const (
+	maxAudioTailPadding = 60.0
+	maxAudioCrossfade   = 10.0
+)
+
+// Hardware accelerators for video encoding
+const (
+	HWAccelNone  = "none"
+	HWAccelNVENC = "nvenc"
+	HWAccelVAAPI = "vaapi"
+	HWAccelQSV   = "qsv"
+)
+
 type TranscriptionConfig struct {
 	Enabled    bool             `mapstructure:"enabled"`
 	Daemon     DaemonConfig     `mapstructure:"daemon"`
 	Python     PythonConfig     `mapstructure:"python"`
 	Processing ProcessingConfig `mapstructure:"processing"`
+
+	// MaxConcurrent caps simultaneous transcriptions independently of
+	// job.max_concurrent, since transcription is often GPU-bound (0 = unlimited)
+	MaxConcurrent int `mapstructure:"max_concurrent"`
+
+	Cache TranscriptionCacheConfig `mapstructure:"cache"`
+}
+
+// TranscriptionCacheConfig controls the on-disk cache of transcription
+// results, keyed by audio URL, Whisper model and language
+type TranscriptionCacheConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Dir     string `mapstructure:"dir"`
+
+	// Entries unused for TTL expire (0 = never); beyond MaxEntries the least
+	// recently used entries are evicted (0 = unlimited)
+	TTL        time.Duration `mapstructure:"ttl"`
+	MaxEntries int           `mapstructure:"max_entries"`
 }
 
 type DaemonConfig struct {
@@ -50,6 +174,19 @@ type DaemonConfig struct {
 	IdleTimeout        time.Duration `mapstructure:"idle_timeout"`
 	StartupTimeout     time.Duration `mapstructure:"startup_timeout"`
 	RestartMaxAttempts int           `mapstructure:"restart_max_attempts"`
+
+	// After StartupFailureThreshold consecutive failed startups (0 disables)
+	// transcription fails fast for StartupCooldown before one retry is allowed
+	StartupFailureThreshold int           `mapstructure:"startup_failure_threshold"`
+	StartupCooldown         time.Duration `mapstructure:"startup_cooldown"`
+
+	// PoolSize is the number of daemons transcribing in parallel; each loads
+	// its own copy of the model
+	PoolSize int `mapstructure:"pool_size"`
+
+	// ReplayAttempts is how often a request lost to a daemon crash is sent
+	// again to the restarted daemon (0 fails it right away)
+	ReplayAttempts int `mapstructure:"replay_attempts"`
 }
 
 type PythonConfig struct {
@@ -72,8 +209,87 @@ type SubtitlesConfig struct {
 	FontSize   int         `mapstructure:"font_size"`
 	Position   string      `mapstructure:"position"`
 	Colors     ColorConfig `mapstructure:"colors"`
+
+	// Reading speed for classic captions (0 disables chunking); unit is "words" or "chars" per second
+	ReadingSpeed     float64 `mapstructure:"reading_speed"`
+	ReadingSpeedUnit string  `mapstructure:"reading_speed_unit"`
+
+	// Scale outline width and shadow offset with font size (value = font size * ratio)
+	ScaleOutlineWithFont bool    `mapstructure:"scale_outline_with_font"`
+	OutlineRatio         float64 `mapstructure:"outline_ratio"`
+	ShadowRatio          float64 `mapstructure:"shadow_ratio"`
+
+	// MaxEvents caps subtitle events per video (0 = unlimited); MaxEventsPolicy
+	// is "fail" or "classic" (regenerate with chunked classic captions)
+	MaxEvents       int    `mapstructure:"max_events"`
+	MaxEventsPolicy string `mapstructure:"max_events_policy"`
+
+	// Emoji handling in subtitle text: "keep", "strip" or "replace" (with EmojiReplacement)
+	EmojiHandling    string `mapstructure:"emoji_handling"`
+	EmojiReplacement string `mapstructure:"emoji_replacement"`
+
+	// FallbackFonts render caption text outside the Latin script, in order of preference
+	FallbackFonts []string `mapstructure:"fallback_fonts"`
+
+	// FontsDir is searched for fonts when burning subtitles in addition to
+	// the fonts installed system-wide ("" uses the system fonts only)
+	FontsDir string `mapstructure:"fonts_dir"`
+
+	// FailOnEmpty fails the job when subtitles were requested but transcription
+	// produced no events; otherwise the video renders without them and a warning is recorded
+	FailOnEmpty bool `mapstructure:"fail_on_empty"`
+
+	// Normalization of transcription text before event generation; all rules
+	// are off by default so the raw transcription is preserved
+	CollapseSpaces        bool `mapstructure:"collapse_spaces"`
+	FixPunctuationSpacing bool `mapstructure:"fix_punctuation_spacing"`
+	SentenceCase          bool `mapstructure:"sentence_case"`
+
+	// Words transcribed with a confidence below MinWordConfidence (0 disables)
+	// are dropped from progressive captions or dimmed, per LowConfidenceMode
+	MinWordConfidence float64 `mapstructure:"min_word_confidence"`
+	LowConfidenceMode string  `mapstructure:"low_confidence_mode"`
+
+	// Classic captions wrap at MaxLineLength characters (0 disables wrapping);
+	// text beyond MaxLines lines (0 = unlimited) is handled per LineOverflow
+	MaxLineLength int    `mapstructure:"max_line_length"`
+	MaxLines      int    `mapstructure:"max_lines"`
+	LineOverflow  string `mapstructure:"line_overflow"`
 }
 
+// Treatments of low-confidence words in progressive captions
+const (
+	LowConfidenceDrop = "drop"
+	LowConfidenceDim  = "dim"
+)
+
+// Treatments of classic captions wrapping to more than the maximum lines:
+// split them into sequential events or truncate them with an ellipsis
+const (
+	LineOverflowSplit    = "split"
+	LineOverflowTruncate = "truncate"
+)
+
+// Bounds of classic caption wrapping
+const (
+	MinSubtitleLineLength = 10
+	MaxSubtitleLineLength = 200
+	MaxSubtitleLines      = 10
+)
+
+// Policies applied when a video exceeds the subtitle event cap
+const (
+	MaxEventsPolicyFail    = "fail"
+	MaxEventsPolicyClassic = "classic"
+)
+
+// Emoji handling modes for subtitle text
+const (
+	EmojiHandlingKeep    = "keep"
+	EmojiHandlingStrip   = "strip"
+	EmojiHandlingReplace = "replace"
+)
+
 type ColorConfig struct {
 	Word    string `mapstructure:"word"`
 	Outline string `mapstructure:"outline"`
@@ -85,6 +301,183 @@ type StorageConfig struct {
 	MaxFileSize     int64         `mapstructure:"max_file_size"`
 	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
 	RetentionDays   int           `mapstructure:"retention_days"`
+
+	// CleanupFailureThreshold is the number of consecutive failing cleanup runs
+	// after which cleanup is reported as unhealthy
+	CleanupFailureThreshold int `mapstructure:"cleanup_failure_threshold"`
+
+	// OutputCollisionPolicy decides what happens when a client-supplied output ID already exists
+	OutputCollisionPolicy string `mapstructure:"output_collision_policy"`
+
+	// UserAgent is sent on every media fetch: HTTP downloads, ffprobe URL
+	// analysis and the remote inputs of FFmpeg renders
+	UserAgent string `mapstructure:"user_agent"`
+
+	// HTTPProxy routes media downloads, ffprobe URL analysis and the remote
+	// inputs of FFmpeg renders through an HTTP proxy such as "http://proxy.internal:3128"; empty leaves
+	// downloads to the standard HTTP_PROXY/HTTPS_PROXY environment
+	HTTPProxy string `mapstructure:"http_proxy"`
+
+	// Limits for media uploaded with multipart video requests
+	MaxUploadFileSize  int64 `mapstructure:"max_upload_file_size"`
+	MaxUploadTotalSize int64 `mapstructure:"max_upload_total_size"`
+
+	// Locale is the BCP 47 tag used for human-readable numbers and times in
+	// render manifests (e.g. "en", "de-DE")
+	Locale string `mapstructure:"locale"`
+
+	// Backend keeps stored videos on local disk in OutputDir ("filesystem")
+	// or in an S3-compatible bucket ("s3"); renders are written to OutputDir
+	// either way
+	Backend string   `mapstructure:"backend"`
+	S3      S3Config `mapstructure:"s3"`
+
+	// Octal permissions (e.g. "0640") for files and directories created for
+	// outputs, uploads and temp media; the process umask still applies to
+	// new files, but stored videos and subtitles are set to FileMode exactly
+	FileMode string `mapstructure:"file_mode"`
+	DirMode  string `mapstructure:"dir_mode"`
+
+	// Time-limited download URLs: DownloadURLSecret signs links served by
+	// the API for local storage (generated at startup when empty, so links
+	// then end with the process); requested lifetimes default to
+	// DownloadURLTTL and may not exceed DownloadURLMaxTTL
+	DownloadURLSecret string        `mapstructure:"download_url_secret"`
+	DownloadURLTTL    time.Duration `mapstructure:"download_url_ttl"`
+	DownloadURLMaxTTL time.Duration `mapstructure:"download_url_max_ttl"`
+}
+
+// maxPresignedURLTTL is the longest lifetime S3 accepts for a presigned URL
+const maxPresignedURLTTL = 7 * 24 * time.Hour
+
+// S3Config locates the bucket of the s3 storage backend
+type S3Config struct {
+	// Endpoint is the base URL of an S3-compatible service such as MinIO;
+	// empty uses AWS S3 in Region
+	Endpoint string `mapstructure:"endpoint"`
+	Region   string `mapstructure:"region"`
+	Bucket   string `mapstructure:"bucket"`
+
+	// Prefix is prepended to every object key (e.g. "videos/")
+	Prefix string `mapstructure:"prefix"`
+
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+
+	// PathStyle addresses the bucket as <endpoint>/<bucket> instead of
+	// <bucket>.<endpoint>, as most self-hosted services require
+	PathStyle bool `mapstructure:"path_style"`
+
+	// CacheDir holds videos fetched for download and probing; its files
+	// expire with the retention of stored videos
+	CacheDir string `mapstructure:"cache_dir"`
+}
+
+// Permissions used when storage.file_mode or storage.dir_mode cannot be parsed
+const (
+	defaultFileMode os.FileMode = 0644
+	defaultDirMode  os.FileMode = 0755
+)
+
+// FilePerm returns the permissions for created files
+func (s StorageConfig) FilePerm() os.FileMode {
+	mode, err := parseFileMode(s.FileMode)
+	if err != nil {
+		return defaultFileMode
+	}
+	return mode
+}
+
+// DirPerm returns the permissions for created directories
+func (s StorageConfig) DirPerm() os.FileMode {
+	mode, err := parseFileMode(s.DirMode)
+	if err != nil {
+		return defaultDirMode
+	}
+	return mode
+}
+
+// parseFileMode parses an octal permission string such as "0644"
+func parseFileMode(value string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(strings.TrimPrefix(value, "0o"), 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("not an octal mode")
+	}
+	if mode&^uint64(os.ModePerm) != 0 {
+		return 0, fmt.Errorf("only permission bits (at most 0777) are allowed")
+	}
+	return os.FileMode(mode), nil
+}
+
+// Transports for media fetches, shared per proxy so connections are pooled
+var (
+	mediaTransportsMu sync.Mutex
+	mediaTransports   = map[string]*http.Transport{}
+)
+
+// MediaTransport returns the shared transport for outbound media fetches,
+// routed through HTTPProxy when one is configured
+func (s StorageConfig) MediaTransport() *http.Transport {
+	mediaTransportsMu.Lock()
+	defer mediaTransportsMu.Unlock()
+
+	if transport, ok := mediaTransports[s.HTTPProxy]; ok {
+		return transport
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyURL, err := url.Parse(s.HTTPProxy); err == nil && s.HTTPProxy != "" {
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	mediaTransports[s.HTTPProxy] = transport
+	return transport
+}
+
+// HTTPProxyArgs returns the ffprobe and FFmpeg options that apply
+// HTTPProxy to URL inputs
+func (s StorageConfig) HTTPProxyArgs() []string {
+	if s.HTTPProxy == "" {
+		return nil
+	}
+	return []string{"-http_proxy", s.HTTPProxy}
+}
+
+// UploadDir is where media uploaded with a video request is kept until its job finishes
+func (s StorageConfig) UploadDir() string {
+	return filepath.Join(s.TempDir, "uploads")
+}
+
+// IsUploadedFile reports whether src is a local path inside the upload directory.
+// Such paths are the only local files accepted as media sources.
+func (s StorageConfig) IsUploadedFile(src string) bool {
+	if !filepath.IsAbs(src) {
+		return false
+	}
+	uploadDir, err := filepath.Abs(s.UploadDir())
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(uploadDir, filepath.Clean(src))
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+}
+
+// Storage backends
+const (
+	StorageBackendFilesystem = "filesystem"
+	StorageBackendS3         = "s3"
+)
+
+// Output collision policies for client-supplied output IDs
+const (
+	CollisionPolicyOverwrite = "overwrite"
+	CollisionPolicyReject    = "reject"
+	CollisionPolicyVersion   = "version"
+)
+
+// HealthConfig controls the readiness probe's dependency checks
+type HealthConfig struct {
+	CheckTimeout   time.Duration `mapstructure:"check_timeout"`
+	CriticalChecks []string      `mapstructure:"critical_checks"` // ffmpeg, ffprobe, whisper, storage, disk
+	MinFreeDiskMB  int64         `mapstructure:"min_free_disk_mb"`
 }
 
 type JobConfig struct {
@@ -92,6 +485,58 @@ type JobConfig struct {
 	QueueSize           int           `mapstructure:"queue_size"`
 	MaxConcurrent       int           `mapstructure:"max_concurrent"`
 	StatusCheckInterval time.Duration `mapstructure:"status_check_interval"`
+
+	// Processing jobs running longer than SlowJobThreshold are flagged "slow";
+	// those without progress movement for StuckJobThreshold are flagged
+	// "possibly_stuck". Flags are informational only (0 disables each check).
+	SlowJobThreshold  time.Duration `mapstructure:"slow_job_threshold"`
+	StuckJobThreshold time.Duration `mapstructure:"stuck_job_threshold"`
+
+	// ProgressBufferSize is the number of updates queued per progress
+	// subscriber; a slow subscriber loses the oldest queued updates
+	ProgressBufferSize int `mapstructure:"progress_buffer_size"`
+
+	// DeadLetterDir receives a JSON record of every failed job with its
+	// config, error and FFmpeg log; empty disables dead-lettering
+	DeadLetterDir string `mapstructure:"dead_letter_dir"`
+
+	// AnalysisTimeout bounds each ffprobe analysis of a media source so a
+	// stalled URL fails on its own instead of stalling the job (0 disables)
+	AnalysisTimeout time.Duration `mapstructure:"analysis_timeout"`
+
+	// Jobs failing with a transient error are queued again up to MaxRetries
+	// times, after RetryBackoff doubled with every retry (0 disables retries)
+	MaxRetries   int           `mapstructure:"max_retries"`
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+
+	// StoreDir persists job state as JSON files so jobs survive restarts
+	// ("" keeps jobs in memory only). Jobs found processing at startup are
+	// queued again or failed according to InterruptedPolicy. Finished
+	// jobs expire after storage.retention_days, like their videos.
+	StoreDir          string `mapstructure:"store_dir"`
+	InterruptedPolicy string `mapstructure:"interrupted_policy"`
+
+	// Finished jobs with a callback_url are POSTed there, signed with
+	// HMAC-SHA256 of CallbackSecret when set. Each attempt is bounded by
+	// CallbackTimeout; failed deliveries are retried CallbackRetries times.
+	CallbackSecret  string        `mapstructure:"callback_secret"`
+	CallbackTimeout time.Duration `mapstructure:"callback_timeout"`
+	CallbackRetries int           `mapstructure:"callback_retries"`
+}
+
+// Policies for jobs interrupted by a restart while processing
+const (
+	InterruptedJobRequeue = "requeue"
+	InterruptedJobFail    = "fail"
+)
+
+// EstimateConfig holds the coefficients of the render cost model used by the estimate endpoint.
+// Coefficients are expressed for 1080p output and scaled linearly by pixel count.
+type EstimateConfig struct {
+	RenderSecondsPerSecond float64 `mapstructure:"render_seconds_per_second"`
+	HighQualityMultiplier  float64 `mapstructure:"high_quality_multiplier"`
+	BitrateKbps            int     `mapstructure:"bitrate_kbps"`
+	HighQualityBitrateKbps int     `mapstructure:"high_quality_bitrate_kbps"`
 }
 
 type LogConfig struct {
@@ -106,8 +551,21 @@ type SecurityConfig struct {
 	AllowedDomains []string `mapstructure:"allowed_domains"`
 	EnableCSRF     bool     `mapstructure:"enable_csrf"`
 	CSRFSecret     string   `mapstructure:"csrf_secret"`
+	AdminAPIKey    string   `mapstructure:"admin_api_key"` // Required for /api/v1/admin endpoints; empty disables them
+
+	// ProbeRedirects controls redirects when ffprobe analyzes a URL: "resolve"
+	// follows up to MaxProbeRedirects hops ourselves, re-validating each one;
+	// "deny" rejects any redirect
+	ProbeRedirects    string `mapstructure:"probe_redirects"`
+	MaxProbeRedirects int    `mapstructure:"max_probe_redirects"`
 }
 
+// Redirect policies for ffprobe URL analysis
+const (
+	ProbeRedirectsResolve = "resolve"
+	ProbeRedirectsDeny    = "deny"
+)
+
 func Load() (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -125,6 +583,8 @@ func Load() (*Config, error) {
 
 	// Manual bindings for complex types
 	_ = viper.BindEnv("security.allowed_domains", "VIDEOCRAFT_SECURITY_ALLOWED_DOMAINS")
+	_ = viper.BindEnv("storage.s3.access_key_id", "VIDEOCRAFT_STORAGE_S3_ACCESS_KEY_ID")
+	_ = viper.BindEnv("storage.s3.secret_access_key", "VIDEOCRAFT_STORAGE_S3_SECRET_ACCESS_KEY")
 
 	// Read config file
 	if err := viper.ReadInConfig(); err != nil {
@@ -161,9 +621,276 @@ func Load() (*Config, error) {
 		config.Security.CSRFSecret = secret
 	}
 
+	// Download links are signed with a per-process secret unless one is configured
+	if config.Storage.DownloadURLSecret == "" {
+		secret, err := generateSecureAPIKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate download URL secret: %w", err)
+		}
+		config.Storage.DownloadURLSecret = secret
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
+// validate checks configuration values that cannot be expressed through defaults alone
+func (c *Config) validate() error {
+	if c.Job.SlowJobThreshold < 0 || c.Job.StuckJobThreshold < 0 {
+		return fmt.Errorf("job.slow_job_threshold and job.stuck_job_threshold cannot be negative")
+	}
+
+	if c.Job.AnalysisTimeout < 0 {
+		return fmt.Errorf("job.analysis_timeout cannot be negative")
+	}
+	if c.Job.MaxRetries < 0 {
+		return fmt.Errorf("job.max_retries cannot be negative")
+	}
+	if c.Job.RetryBackoff < 0 {
+		return fmt.Errorf("job.retry_backoff cannot be negative")
+	}
+	switch c.Job.InterruptedPolicy {
+	case InterruptedJobRequeue, InterruptedJobFail:
+	default:
+		return fmt.Errorf("invalid job.interrupted_policy %q: must be requeue or fail", c.Job.InterruptedPolicy)
+	}
+	if c.Job.CallbackTimeout <= 0 {
+		return fmt.Errorf("job.callback_timeout must be positive")
+	}
+	if c.Job.CallbackRetries < 0 {
+		return fmt.Errorf("job.callback_retries cannot be negative")
+	}
+
+	if c.FFmpeg.PreviewMaxSeconds <= 0 {
+		return fmt.Errorf("ffmpeg.preview_max_seconds must be positive")
+	}
+	if strings.TrimSpace(c.FFmpeg.PreviewPreset) == "" {
+		return fmt.Errorf("ffmpeg.preview_preset cannot be empty")
+	}
+
+	switch c.FFmpeg.OversizedImagePolicy {
+	case OversizedImageClip, OversizedImageFit, OversizedImageReject:
+	default:
+		return fmt.Errorf("invalid ffmpeg.oversized_image_policy %q: must be clip, fit or reject", c.FFmpeg.OversizedImagePolicy)
+	}
+
+	if c.FFmpeg.BackgroundCrossfadeSeconds <= 0 {
+		return fmt.Errorf("ffmpeg.background_crossfade_seconds must be positive")
+	}
+	if c.FFmpeg.BackgroundLoopWarnThreshold < 0 {
+		return fmt.Errorf("ffmpeg.background_loop_warn_threshold cannot be negative")
+	}
+
+	switch c.FFmpeg.HWAccel {
+	case HWAccelNone, HWAccelNVENC:
+	case HWAccelVAAPI, HWAccelQSV:
+		if strings.TrimSpace(c.FFmpeg.HWAccelDevice) == "" {
+			return fmt.Errorf("ffmpeg.hwaccel_device is required for ffmpeg.hwaccel %s", c.FFmpeg.HWAccel)
+		}
+	default:
+		return fmt.Errorf("invalid ffmpeg.hwaccel %q: must be none, nvenc, vaapi or qsv", c.FFmpeg.HWAccel)
+	}
+
+	if c.FFmpeg.InitialProgress < 0 || c.FFmpeg.InitialProgress > 10 {
+		return fmt.Errorf("ffmpeg.initial_progress must be between 0 and 10")
+	}
+	if c.FFmpeg.ProgressHeartbeat < 0 {
+		return fmt.Errorf("ffmpeg.progress_heartbeat cannot be negative")
+	}
+
+	switch c.FFmpeg.IntegrityCheck {
+	case IntegrityCheckOff, IntegrityCheckWarn, IntegrityCheckFail:
+	default:
+		return fmt.Errorf("invalid ffmpeg.integrity_check %q: must be off, warn or fail", c.FFmpeg.IntegrityCheck)
+	}
+
+	if c.Audio.TailPadding < 0 || c.Audio.TailPadding > maxAudioTailPadding {
+		return fmt.Errorf("audio.tail_padding must be between 0 and %g seconds", maxAudioTailPadding)
+	}
+	if c.Audio.CrossfadeSeconds <= 0 || c.Audio.CrossfadeSeconds > maxAudioCrossfade {
+		return fmt.Errorf("audio.crossfade_seconds must be positive and at most %g seconds", maxAudioCrossfade)
+	}
+
+	switch c.FFmpeg.LoudnormMode {
+	case LoudnormSinglePass, LoudnormTwoPass:
+	default:
+		return fmt.Errorf("invalid ffmpeg.loudnorm_mode %q: must be single or two_pass", c.FFmpeg.LoudnormMode)
+	}
+
+	if c.Job.ProgressBufferSize < 1 {
+		return fmt.Errorf("job.progress_buffer_size must be at least 1")
+	}
+
+	if _, err := language.Parse(c.Storage.Locale); err != nil {
+		return fmt.Errorf("invalid storage.locale %q: %w", c.Storage.Locale, err)
+	}
+
+	if c.Transcription.MaxConcurrent < 0 {
+		return fmt.Errorf("transcription.max_concurrent cannot be negative")
+	}
+
+	if c.Transcription.Daemon.PoolSize < 1 {
+		return fmt.Errorf("transcription.daemon.pool_size must be at least 1")
+	}
+	if c.Transcription.Daemon.ReplayAttempts < 0 {
+		return fmt.Errorf("transcription.daemon.replay_attempts cannot be negative")
+	}
+
+	if c.Transcription.Daemon.StartupFailureThreshold < 0 {
+		return fmt.Errorf("transcription.daemon.startup_failure_threshold cannot be negative")
+	}
+	if c.Transcription.Daemon.StartupFailureThreshold > 0 && c.Transcription.Daemon.StartupCooldown <= 0 {
+		return fmt.Errorf("transcription.daemon.startup_cooldown must be positive when startup_failure_threshold is set")
+	}
+
+	if c.Transcription.Cache.Enabled && c.Transcription.Cache.Dir == "" {
+		return fmt.Errorf("transcription.cache.dir is required when the transcription cache is enabled")
+	}
+	if c.Transcription.Cache.TTL < 0 {
+		return fmt.Errorf("transcription.cache.ttl cannot be negative")
+	}
+	if c.Transcription.Cache.MaxEntries < 0 {
+		return fmt.Errorf("transcription.cache.max_entries cannot be negative")
+	}
+
+	switch c.Storage.OutputCollisionPolicy {
+	case CollisionPolicyOverwrite, CollisionPolicyReject, CollisionPolicyVersion:
+	default:
+		return fmt.Errorf("invalid storage.output_collision_policy %q: must be overwrite, reject or version", c.Storage.OutputCollisionPolicy)
+	}
+
+	fileMode, err := parseFileMode(c.Storage.FileMode)
+	if err != nil {
+		return fmt.Errorf("invalid storage.file_mode %q: %w", c.Storage.FileMode, err)
+	}
+	if fileMode&0600 != 0600 {
+		return fmt.Errorf("storage.file_mode %q must let the owner read and write", c.Storage.FileMode)
+	}
+	dirMode, err := parseFileMode(c.Storage.DirMode)
+	if err != nil {
+		return fmt.Errorf("invalid storage.dir_mode %q: %w", c.Storage.DirMode, err)
+	}
+	if dirMode&0700 != 0700 {
+		return fmt.Errorf("storage.dir_mode %q must give the owner full access", c.Storage.DirMode)
+	}
+
+	if c.Storage.DownloadURLTTL <= 0 || c.Storage.DownloadURLMaxTTL <= 0 {
+		return fmt.Errorf("storage.download_url_ttl and storage.download_url_max_ttl must be positive")
+	}
+	if c.Storage.DownloadURLTTL > c.Storage.DownloadURLMaxTTL {
+		return fmt.Errorf("storage.download_url_ttl cannot exceed storage.download_url_max_ttl")
+	}
+	if c.Storage.DownloadURLMaxTTL > maxPresignedURLTTL {
+		return fmt.Errorf("storage.download_url_max_ttl cannot exceed %s", maxPresignedURLTTL)
+	}
+
+	switch c.Storage.Backend {
+	case StorageBackendFilesystem:
+	case StorageBackendS3:
+		if c.Storage.S3.Bucket == "" || c.Storage.S3.Region == "" {
+			return fmt.Errorf("storage.s3.bucket and storage.s3.region are required for the s3 backend")
+		}
+		if c.Storage.S3.AccessKeyID == "" || c.Storage.S3.SecretAccessKey == "" {
+			return fmt.Errorf("storage.s3.access_key_id and storage.s3.secret_access_key are required for the s3 backend")
+		}
+		if c.Storage.S3.Endpoint != "" {
+			if u, err := url.Parse(c.Storage.S3.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("invalid storage.s3.endpoint %q: must be an http or https URL", c.Storage.S3.Endpoint)
+			}
+		}
+	default:
+		return fmt.Errorf("invalid storage.backend %q: must be filesystem or s3", c.Storage.Backend)
+	}
+
+	if c.Storage.MaxUploadFileSize <= 0 || c.Storage.MaxUploadTotalSize < c.Storage.MaxUploadFileSize {
+		return fmt.Errorf("storage.max_upload_file_size must be positive and not exceed storage.max_upload_total_size")
+	}
+
+	if strings.TrimSpace(c.Storage.UserAgent) == "" {
+		return fmt.Errorf("storage.user_agent cannot be empty")
+	}
+	if strings.IndexFunc(c.Storage.UserAgent, unicode.IsControl) >= 0 {
+		return fmt.Errorf("storage.user_agent cannot contain control characters")
+	}
+
+	if c.Storage.HTTPProxy != "" {
+		proxyURL, err := url.Parse(c.Storage.HTTPProxy)
+		if err != nil || (proxyURL.Scheme != "http" && proxyURL.Scheme != "https") || proxyURL.Hostname() == "" {
+			return fmt.Errorf("invalid storage.http_proxy %q: must be an http or https URL with a host", c.Storage.HTTPProxy)
+		}
+		if (proxyURL.Path != "" && proxyURL.Path != "/") || proxyURL.RawQuery != "" {
+			return fmt.Errorf("invalid storage.http_proxy %q: must not have a path or query", c.Storage.HTTPProxy)
+		}
+	}
+
+	if c.Subtitles.OutlineRatio < 0 || c.Subtitles.OutlineRatio > 1 || c.Subtitles.ShadowRatio < 0 || c.Subtitles.ShadowRatio > 1 {
+		return fmt.Errorf("subtitles.outline_ratio and subtitles.shadow_ratio must be between 0 and 1")
+	}
+
+	if c.Subtitles.MaxEvents < 0 {
+		return fmt.Errorf("subtitles.max_events cannot be negative")
+	}
+	switch c.Subtitles.MaxEventsPolicy {
+	case MaxEventsPolicyFail, MaxEventsPolicyClassic:
+	default:
+		return fmt.Errorf("invalid subtitles.max_events_policy %q: must be fail or classic", c.Subtitles.MaxEventsPolicy)
+	}
+
+	switch c.Subtitles.EmojiHandling {
+	case EmojiHandlingKeep, EmojiHandlingStrip, EmojiHandlingReplace:
+	default:
+		return fmt.Errorf("invalid subtitles.emoji_handling %q: must be keep, strip or replace", c.Subtitles.EmojiHandling)
+	}
+
+	if c.Subtitles.MinWordConfidence < 0 || c.Subtitles.MinWordConfidence > 1 {
+		return fmt.Errorf("subtitles.min_word_confidence must be between 0 and 1")
+	}
+
+	switch c.Subtitles.LowConfidenceMode {
+	case LowConfidenceDrop, LowConfidenceDim:
+	default:
+		return fmt.Errorf("invalid subtitles.low_confidence_mode %q: must be drop or dim", c.Subtitles.LowConfidenceMode)
+	}
+
+	if c.Subtitles.MaxLineLength != 0 && (c.Subtitles.MaxLineLength < MinSubtitleLineLength || c.Subtitles.MaxLineLength > MaxSubtitleLineLength) {
+		return fmt.Errorf("subtitles.max_line_length must be 0 or between %d and %d", MinSubtitleLineLength, MaxSubtitleLineLength)
+	}
+
+	if c.Subtitles.MaxLines < 0 || c.Subtitles.MaxLines > MaxSubtitleLines {
+		return fmt.Errorf("subtitles.max_lines must be between 0 and %d", MaxSubtitleLines)
+	}
+
+	switch c.Subtitles.LineOverflow {
+	case LineOverflowSplit, LineOverflowTruncate:
+	default:
+		return fmt.Errorf("invalid subtitles.line_overflow %q: must be split or truncate", c.Subtitles.LineOverflow)
+	}
+
+	for _, font := range c.Subtitles.FallbackFonts {
+		if strings.TrimSpace(font) == "" || strings.ContainsAny(font, ",{}\\") || strings.IndexFunc(font, unicode.IsControl) >= 0 {
+			return fmt.Errorf("invalid subtitles.fallback_fonts entry %q", font)
+		}
+	}
+	// The directory is quoted inside the FFmpeg filter graph like the subtitle file
+	if strings.ContainsAny(c.Subtitles.FontsDir, "'") || strings.IndexFunc(c.Subtitles.FontsDir, unicode.IsControl) >= 0 {
+		return fmt.Errorf("invalid subtitles.fonts_dir %q: must not contain quotes or control characters", c.Subtitles.FontsDir)
+	}
+
+	switch c.Security.ProbeRedirects {
+	case ProbeRedirectsResolve, ProbeRedirectsDeny:
+	default:
+		return fmt.Errorf("invalid security.probe_redirects %q: must be resolve or deny", c.Security.ProbeRedirects)
+	}
+	if c.Security.MaxProbeRedirects < 0 {
+		return fmt.Errorf("security.max_probe_redirects cannot be negative")
+	}
+
+	return nil
+}
+
 func setDefaults() {
 	// Server defaults
 	viper.SetDefault("server.host", "0.0.0.0")
@@ -175,6 +902,25 @@ func setDefaults() {
 	viper.SetDefault("ffmpeg.timeout", "1h")
 	viper.SetDefault("ffmpeg.quality", 23)
 	viper.SetDefault("ffmpeg.preset", "medium")
+	viper.SetDefault("ffmpeg.faststart", true)
+	viper.SetDefault("ffmpeg.strip_metadata", false)
+	viper.SetDefault("ffmpeg.preview_max_seconds", 30)
+	viper.SetDefault("ffmpeg.preview_preset", "ultrafast")
+	viper.SetDefault("ffmpeg.oversized_image_policy", OversizedImageFit)
+	viper.SetDefault("ffmpeg.background_crossfade_seconds", 0.5)
+	viper.SetDefault("ffmpeg.background_loop_warn_threshold", 10)
+	viper.SetDefault("ffmpeg.hwaccel", HWAccelNone)
+	viper.SetDefault("ffmpeg.hwaccel_device", "/dev/dri/renderD128")
+	viper.SetDefault("ffmpeg.hwaccel_fallback", true)
+	viper.SetDefault("ffmpeg.initial_progress", 1)
+	viper.SetDefault("ffmpeg.progress_heartbeat", "10s")
+	viper.SetDefault("ffmpeg.integrity_check", IntegrityCheckOff)
+	viper.SetDefault("ffmpeg.normalize_audio", false)
+	viper.SetDefault("ffmpeg.loudnorm_mode", LoudnormSinglePass)
+
+	// Audio defaults
+	viper.SetDefault("audio.tail_padding", 2.0)
+	viper.SetDefault("audio.crossfade_seconds", 0.5)
 
 	// Transcription defaults
 	viper.SetDefault("transcription.enabled", true)
@@ -182,6 +928,10 @@ func setDefaults() {
 	viper.SetDefault("transcription.daemon.idle_timeout", "300s")
 	viper.SetDefault("transcription.daemon.startup_timeout", "30s")
 	viper.SetDefault("transcription.daemon.restart_max_attempts", 3)
+	viper.SetDefault("transcription.daemon.startup_failure_threshold", 3)
+	viper.SetDefault("transcription.daemon.startup_cooldown", "60s")
+	viper.SetDefault("transcription.daemon.pool_size", 1)
+	viper.SetDefault("transcription.daemon.replay_attempts", 1)
 	viper.SetDefault("transcription.python.path", "python3")
 	viper.SetDefault("transcription.python.script_path", "./scripts")
 	viper.SetDefault("transcription.python.model", "base")
@@ -189,6 +939,11 @@ func setDefaults() {
 	viper.SetDefault("transcription.python.device", "auto")
 	viper.SetDefault("transcription.processing.workers", 2)
 	viper.SetDefault("transcription.processing.timeout", "60s")
+	viper.SetDefault("transcription.max_concurrent", 1)
+	viper.SetDefault("transcription.cache.enabled", false)
+	viper.SetDefault("transcription.cache.dir", "./temp/transcription_cache")
+	viper.SetDefault("transcription.cache.ttl", "168h")
+	viper.SetDefault("transcription.cache.max_entries", 1000)
 
 	// Subtitles defaults
 	viper.SetDefault("subtitles.enabled", true)
@@ -198,19 +953,80 @@ func setDefaults() {
 	viper.SetDefault("subtitles.position", "center-bottom")
 	viper.SetDefault("subtitles.colors.word", "#FFFFFF")
 	viper.SetDefault("subtitles.colors.outline", "#000000")
+	viper.SetDefault("subtitles.reading_speed", 0)
+	viper.SetDefault("subtitles.reading_speed_unit", "words")
+	viper.SetDefault("subtitles.scale_outline_with_font", false)
+	viper.SetDefault("subtitles.outline_ratio", 0.08) // 2px at the default 24px font
+	viper.SetDefault("subtitles.shadow_ratio", 0.04)  // 1px at the default 24px font
+	viper.SetDefault("subtitles.max_events", 10000)
+	viper.SetDefault("subtitles.max_events_policy", MaxEventsPolicyClassic)
+	viper.SetDefault("subtitles.emoji_handling", EmojiHandlingKeep)
+	viper.SetDefault("subtitles.emoji_replacement", "*")
+	viper.SetDefault("subtitles.fallback_fonts", []string{})
+	viper.SetDefault("subtitles.fonts_dir", "")
+	viper.SetDefault("subtitles.fail_on_empty", false)
+	viper.SetDefault("subtitles.collapse_spaces", false)
+	viper.SetDefault("subtitles.fix_punctuation_spacing", false)
+	viper.SetDefault("subtitles.sentence_case", false)
+	viper.SetDefault("subtitles.min_word_confidence", 0.0)
+	viper.SetDefault("subtitles.low_confidence_mode", LowConfidenceDrop)
+	viper.SetDefault("subtitles.max_line_length", 0)
+	viper.SetDefault("subtitles.max_lines", 0)
+	viper.SetDefault("subtitles.line_overflow", LineOverflowSplit)
 
 	// Storage defaults
 	viper.SetDefault("storage.output_dir", "./generated_videos")
 	viper.SetDefault("storage.temp_dir", "./temp")
 	viper.SetDefault("storage.max_file_size", 1073741824) // 1GB
 	viper.SetDefault("storage.cleanup_interval", "1h")
+	viper.SetDefault("storage.cleanup_failure_threshold", 3)
 	viper.SetDefault("storage.retention_days", 7)
+	viper.SetDefault("storage.output_collision_policy", CollisionPolicyReject)
+	viper.SetDefault("storage.locale", "en")
+	viper.SetDefault("storage.backend", StorageBackendFilesystem)
+	viper.SetDefault("storage.file_mode", "0644")
+	viper.SetDefault("storage.dir_mode", "0755")
+	viper.SetDefault("storage.download_url_secret", "")
+	viper.SetDefault("storage.download_url_ttl", "1h")
+	viper.SetDefault("storage.download_url_max_ttl", "24h")
+	viper.SetDefault("storage.s3.endpoint", "")
+	viper.SetDefault("storage.s3.region", "us-east-1")
+	viper.SetDefault("storage.s3.bucket", "")
+	viper.SetDefault("storage.s3.prefix", "")
+	viper.SetDefault("storage.s3.path_style", false)
+	viper.SetDefault("storage.s3.cache_dir", "./temp/s3_cache")
+	viper.SetDefault("storage.max_upload_file_size", 268435456)   // 256MB
+	viper.SetDefault("storage.max_upload_total_size", 1073741824) // 1GB
+	viper.SetDefault("storage.http_proxy", "")
+	viper.SetDefault("storage.user_agent", "VideoCraft/1.0 (+https://github.com/activadee/videocraft)")
 
 	// Job defaults
 	viper.SetDefault("job.workers", 4)
 	viper.SetDefault("job.queue_size", 100)
 	viper.SetDefault("job.max_concurrent", 10)
 	viper.SetDefault("job.status_check_interval", "5s")
+	viper.SetDefault("job.slow_job_threshold", "30m")
+	viper.SetDefault("job.stuck_job_threshold", "10m")
+	viper.SetDefault("job.progress_buffer_size", 16)
+	viper.SetDefault("job.dead_letter_dir", "./dead_letter")
+	viper.SetDefault("job.analysis_timeout", "60s")
+	viper.SetDefault("job.max_retries", 0)
+	viper.SetDefault("job.retry_backoff", "10s")
+	viper.SetDefault("job.store_dir", "./jobs")
+	viper.SetDefault("job.interrupted_policy", InterruptedJobRequeue)
+	viper.SetDefault("job.callback_timeout", "5s")
+	viper.SetDefault("job.callback_retries", 2)
+
+	// Estimate defaults (1080p reference)
+	viper.SetDefault("estimate.render_seconds_per_second", 0.5)
+	viper.SetDefault("estimate.high_quality_multiplier", 1.5)
+	viper.SetDefault("estimate.bitrate_kbps", 5000)
+	viper.SetDefault("estimate.high_quality_bitrate_kbps", 8000)
+
+	// Health check defaults
+	viper.SetDefault("health.check_timeout", "2s")
+	viper.SetDefault("health.critical_checks", []string{"ffmpeg", "ffprobe", "storage", "disk"})
+	viper.SetDefault("health.min_free_disk_mb", 1024)
 
 	// Log defaults
 	viper.SetDefault("log.level", "debug")
@@ -222,6 +1038,8 @@ func setDefaults() {
 	viper.SetDefault("security.allowed_domains", []string{})
 	viper.SetDefault("security.enable_csrf", false)
 	viper.SetDefault("security.csrf_secret", "CHANGE_ME_64_CHAR_MINIMUM_ENTROPY_SECRET_FOR_CSRF_PROTECTION_REPLACE")
+	viper.SetDefault("security.probe_redirects", ProbeRedirectsResolve)
+	viper.SetDefault("security.max_probe_redirects", 5)
 }
 
 // generateSecureAPIKey generates a cryptographically secure API key
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoadGeneratesDownloadURLSecret(t *testing.T) {
+	first, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	second, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(first.Storage.DownloadURLSecret) != 64 {
+		t.Errorf("generated secret %q, want 64 hex characters", first.Storage.DownloadURLSecret)
+	}
+	if first.Storage.DownloadURLSecret == second.Storage.DownloadURLSecret {
+		t.Error("each load generated the same download URL secret")
+	}
+}
+
+func TestLoadKeepsConfiguredDownloadURLSecret(t *testing.T) {
+	t.Setenv("VIDEOCRAFT_STORAGE_DOWNLOAD_URL_SECRET", "configured-secret")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Storage.DownloadURLSecret != "configured-secret" {
+		t.Errorf("download URL secret = %q, want the configured one", cfg.Storage.DownloadURLSecret)
+	}
+}
+
+func TestLoadValidatesLowConfidenceSettings(t *testing.T) {
+	tests := []struct {
+		name       string
+		confidence string
+		mode       string
+		wantErr    bool
+	}{
+		{"defaults", "", "", false},
+		{"dim", "0.4", "dim", false},
+		{"confidence above one", "1.5", "", true},
+		{"negative confidence", "-0.1", "", true},
+		{"unknown mode", "", "hide", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.confidence != "" {
+				t.Setenv("VIDEOCRAFT_SUBTITLES_MIN_WORD_CONFIDENCE", tt.confidence)
+			}
+			if tt.mode != "" {
+				t.Setenv("VIDEOCRAFT_SUBTITLES_LOW_CONFIDENCE_MODE", tt.mode)
+			}
+			if _, err := Load(); (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStorageConfigPermissions(t *testing.T) {
+	tests := []struct {
+		name              string
+		fileMode, dirMode string
+		wantFile, wantDir os.FileMode
+	}{
+		{"configured", "0640", "0750", 0640, 0750},
+		{"go octal prefix", "0o600", "0o700", 0600, 0700},
+		{"unparseable falls back to defaults", "rw-r--r--", "", 0644, 0755},
+		{"non-permission bits fall back to defaults", "4755", "1777", 0644, 0755},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := StorageConfig{FileMode: tt.fileMode, DirMode: tt.dirMode}
+			if got := storage.FilePerm(); got != tt.wantFile {
+				t.Errorf("FilePerm() = %o, want %o", got, tt.wantFile)
+			}
+			if got := storage.DirPerm(); got != tt.wantDir {
+				t.Errorf("DirPerm() = %o, want %o", got, tt.wantDir)
+			}
+		})
+	}
+}
+
+func TestLoadValidatesPermissions(t *testing.T) {
+	tests := []struct {
+		name              string
+		fileMode, dirMode string
+		wantErr           bool
+	}{
+		{"defaults", "", "", false},
+		{"private", "0600", "0700", false},
+		{"not octal", "0648", "", true},
+		{"file not owner writable", "0444", "", true},
+		{"dir not owner searchable", "", "0650", true},
+		{"setuid bit", "", "4755", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.fileMode != "" {
+				t.Setenv("VIDEOCRAFT_STORAGE_FILE_MODE", tt.fileMode)
+			}
+			if tt.dirMode != "" {
+				t.Setenv("VIDEOCRAFT_STORAGE_DIR_MODE", tt.dirMode)
+			}
+			if _, err := Load(); (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -61,6 +61,7 @@ func NewService(cfg *app.Config, log logger.Logger) Service {
 }
 
 func (s *service) AnalyzeAudio(ctx context.Context, url string) (*AudioInfo, error) {
+	s = &service{cfg: s.cfg, log: logger.FromContext(ctx, s.log)}
 	s.log.Debugf("Analyzing audio URL with FFprobe: %s", url)
 
 	// Use FFprobe directly with URL - no download needed
@@ -129,10 +130,12 @@ func (s *service) DownloadAudio(ctx context.Context, url string) (string, error)
 	if err != nil {
 		return "", errors.DownloadFailed(url, err)
 	}
+	req.Header.Set("User-Agent", s.cfg.Storage.UserAgent)
 
 	// Execute request
 	client := &http.Client{
-		Timeout: 5 * time.Minute,
+		Timeout:   5 * time.Minute,
+		Transport: s.cfg.Storage.MediaTransport(),
 	}
 
 	resp, err := client.Do(req)
@@ -152,12 +155,12 @@ func (s *service) DownloadAudio(ctx context.Context, url string) (string, error)
 	tempFile := filepath.Join(s.cfg.Storage.TempDir, fmt.Sprintf("audio_%s%s", uuid.New().String()[:8], ext))
 
 	// Ensure temp directory exists
-	if mkdirErr := os.MkdirAll(s.cfg.Storage.TempDir, 0755); mkdirErr != nil {
+	if mkdirErr := os.MkdirAll(s.cfg.Storage.TempDir, s.cfg.Storage.DirPerm()); mkdirErr != nil {
 		return "", errors.StorageFailed(mkdirErr)
 	}
 
 	// Create output file
-	out, err := os.Create(tempFile)
+	out, err := os.OpenFile(tempFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, s.cfg.Storage.FilePerm())
 	if err != nil {
 		return "", errors.StorageFailed(err)
 	}
@@ -251,12 +254,19 @@ func (s *service) getAudioInfoFromURL(ctx context.Context, audioURL string) (*Au
 	s.log.Debugf("Getting audio info from URL: %s", audioURL)
 
 	// Use FFprobe directly with URL - more efficient than downloading
-	cmd := exec.CommandContext(ctx, "ffprobe",
+	args := []string{
 		"-v", "quiet",
 		"-print_format", "json",
 		"-show_format",
 		"-show_streams",
-		audioURL)
+		audioURL,
+	}
+	// ffprobe rejects the HTTP-only user agent and proxy options for uploaded local files
+	if !s.cfg.Storage.IsUploadedFile(audioURL) {
+		httpArgs := append([]string{"-user_agent", s.cfg.Storage.UserAgent}, s.cfg.Storage.HTTPProxyArgs()...)
+		args = append(httpArgs, args...)
+	}
+	cmd := exec.CommandContext(ctx, "ffprobe", args...)
 
 	output, err := cmd.Output()
 	if err != nil {
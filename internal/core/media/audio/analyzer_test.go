@@ -0,0 +1,38 @@
+package audio
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+func TestDownloadAudioSendsConfiguredUserAgent(t *testing.T) {
+	userAgents := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents <- r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write([]byte("ID3"))
+	}))
+	defer server.Close()
+
+	cfg := &app.Config{}
+	cfg.Storage.TempDir = t.TempDir()
+	cfg.Storage.UserAgent = "VideoCraft/1.0 (+https://example.com/bot)"
+	s := NewService(cfg, logger.NewWithWriter("error", io.Discard, "text"))
+
+	path, err := s.DownloadAudio(context.Background(), server.URL+"/voice.mp3")
+	if err != nil {
+		t.Fatalf("DownloadAudio() error = %v", err)
+	}
+	defer os.Remove(path)
+
+	if got := <-userAgents; got != cfg.Storage.UserAgent {
+		t.Errorf("User-Agent = %q, want %q", got, cfg.Storage.UserAgent)
+	}
+}
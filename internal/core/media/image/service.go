@@ -103,7 +103,7 @@ func (s *service) DownloadImage(ctx context.Context, imageURL string) (string, e
 
 	// Create temporary file
 	tempDir := s.cfg.Storage.TempDir
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
+	if err := os.MkdirAll(tempDir, s.cfg.Storage.DirPerm()); err != nil {
 		return "", errors.ProcessingFailed(fmt.Errorf("failed to create temp directory: %w", err))
 	}
 
@@ -124,10 +124,11 @@ func (s *service) DownloadImage(ctx context.Context, imageURL string) (string, e
 	}
 
 	// Add user agent to avoid blocking
-	req.Header.Set("User-Agent", "VideoCraft/1.0 (Image Processor)")
+	req.Header.Set("User-Agent", s.cfg.Storage.UserAgent)
 
 	client := &http.Client{
-		Timeout: s.cfg.FFmpeg.Timeout,
+		Timeout:   s.cfg.FFmpeg.Timeout,
+		Transport: s.cfg.Storage.MediaTransport(),
 		// Allow redirects (default behavior)
 	}
 
@@ -149,7 +150,7 @@ func (s *service) DownloadImage(ctx context.Context, imageURL string) (string, e
 	}
 
 	// Create output file
-	outFile, err := os.Create(tempPath)
+	outFile, err := os.OpenFile(tempPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, s.cfg.Storage.FilePerm())
 	if err != nil {
 		return "", errors.ProcessingFailed(fmt.Errorf("failed to create temp file: %w", err))
 	}
@@ -172,6 +173,11 @@ func (s *service) ValidateImage(imageURL string) error {
 		return fmt.Errorf("image URL cannot be empty")
 	}
 
+	// Files uploaded with the request are the only accepted local sources
+	if s.cfg.Storage.IsUploadedFile(imageURL) {
+		return nil
+	}
+
 	// Parse URL
 	parsedURL, err := url.Parse(imageURL)
 	if err != nil {
@@ -4,9 +4,10 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
 
 	"github.com/activadee/videocraft/internal/api/models"
 )
@@ -14,6 +15,16 @@ import (
 // ASSGenerator handles ASS subtitle file generation
 type ASSGenerator struct {
 	config ASSConfig
+	styles []NamedStyle
+
+	// directions of the styles, resolved from the events being generated
+	directions map[string]styleDirection
+}
+
+// NamedStyle is an additional ASS style that events reference by name
+type NamedStyle struct {
+	Name   string
+	Config ASSConfig
 }
 
 // ASSConfig defines styling configuration for ASS subtitles
@@ -26,11 +37,36 @@ type ASSConfig struct {
 	OutlineWidth int
 	ShadowOffset int
 
+	// Margins in pixels; 0 uses the default margins
+	MarginLeft     int
+	MarginRight    int
+	MarginVertical int
+
 	// Extended fields to support all SubtitleSettings fields
 	Style       string
 	LineColor   string
 	ShadowColor string
 	BoxColor    string
+
+	// Emoji handling for event text ("keep", "strip" or "replace"; empty keeps emoji)
+	EmojiHandling    string
+	EmojiReplacement string
+
+	// FallbackFonts render runs of non-Latin letters; libass falls back further on its own
+	FallbackFonts []string
+
+	// ScaledBorderAndShadow sets the script-wide header flag; nil keeps "yes".
+	// Only the Default style's value is used.
+	ScaledBorderAndShadow *bool
+
+	// TextDirection is "ltr", "rtl" or "auto" (empty), which detects
+	// right-to-left captions from their text
+	TextDirection string
+
+	// Font style flags; nil keeps bold on and italic and underline off
+	Bold      *bool
+	Italic    *bool
+	Underline *bool
 }
 
 // SubtitleEvent represents a single subtitle event
@@ -39,8 +75,30 @@ type SubtitleEvent struct {
 	EndTime   time.Duration
 	Text      string
 	Layer     int
+	Style     string // ASS style name; empty uses the Default style
+	Dimmed    bool   // Rendered translucent, e.g. for low-confidence words
+
+	// Karaoke words are rendered as \k-timed runs in place of Text
+	Karaoke []KaraokeWord
 }
 
+// KaraokeWord is a word of a karaoke line and how long it is highlighted
+type KaraokeWord struct {
+	Text     string
+	Duration time.Duration
+	Dimmed   bool
+}
+
+// defaultStyleName is the ASS style used by events without a style override
+const defaultStyleName = "Default"
+
+// Default style margins in pixels
+const (
+	defaultMarginLeft     = 10
+	defaultMarginRight    = 10
+	defaultMarginVertical = 20
+)
+
 // NewASSGenerator creates a new ASS generator with configuration
 func NewASSGenerator(config ASSConfig) *ASSGenerator {
 	return &ASSGenerator{config: config}
@@ -62,7 +120,27 @@ func NewASSGeneratorFromSubtitleSettings(settings models.SubtitleSettings, defau
 		LineColor:    firstNonEmpty(settings.LineColor, defaults.LineColor),
 		ShadowColor:  firstNonEmpty(settings.ShadowColor, defaults.ShadowColor),
 		BoxColor:     firstNonEmpty(settings.BoxColor, defaults.BoxColor),
+
+		MarginLeft:     firstNonZero(settings.MarginLeft, defaults.MarginLeft),
+		MarginRight:    firstNonZero(settings.MarginRight, defaults.MarginRight),
+		MarginVertical: firstNonZero(settings.MarginVertical, defaults.MarginVertical),
+
+		EmojiHandling:    defaults.EmojiHandling,
+		EmojiReplacement: defaults.EmojiReplacement,
+		FallbackFonts:    defaults.FallbackFonts,
+
+		ScaledBorderAndShadow: defaults.ScaledBorderAndShadow,
+		TextDirection:         firstNonEmpty(settings.TextDirection, defaults.TextDirection),
+	}
+	if settings.FallbackFonts != "" {
+		config.FallbackFonts = SplitFontStack(settings.FallbackFonts)
+	}
+	if settings.ScaledBorderAndShadow != nil {
+		config.ScaledBorderAndShadow = settings.ScaledBorderAndShadow
 	}
+	config.Bold = firstNonNil(settings.Bold, defaults.Bold)
+	config.Italic = firstNonNil(settings.Italic, defaults.Italic)
+	config.Underline = firstNonNil(settings.Underline, defaults.Underline)
 
 	return &ASSGenerator{config: config}
 }
@@ -82,6 +160,49 @@ func firstNonZero(a, b int) int {
 	return b
 }
 
+func firstNonNil(a, b *bool) *bool {
+	if a != nil {
+		return a
+	}
+	return b
+}
+
+// flagOrDefault returns an optional style flag's value, or fallback when unset
+func flagOrDefault(flag *bool, fallback bool) bool {
+	if flag != nil {
+		return *flag
+	}
+	return fallback
+}
+
+// assFlag renders a style flag as the ASS 1/0 value
+func assFlag(set bool) int {
+	if set {
+		return 1
+	}
+	return 0
+}
+
+// IsBold reports whether the style is bold, which it is unless turned off
+func (c ASSConfig) IsBold() bool {
+	return flagOrDefault(c.Bold, true)
+}
+
+// IsItalic reports whether the style is italic
+func (c ASSConfig) IsItalic() bool {
+	return flagOrDefault(c.Italic, false)
+}
+
+// IsUnderline reports whether the style is underlined
+func (c ASSConfig) IsUnderline() bool {
+	return flagOrDefault(c.Underline, false)
+}
+
+// AddStyle registers an additional style emitted after the Default style
+func (g *ASSGenerator) AddStyle(name string, config ASSConfig) {
+	g.styles = append(g.styles, NamedStyle{Name: name, Config: config})
+}
+
 // GetConfig returns the current ASS configuration (for testing)
 func (g *ASSGenerator) GetConfig() ASSConfig {
 	return g.config
@@ -91,6 +212,9 @@ func (g *ASSGenerator) GetConfig() ASSConfig {
 func (g *ASSGenerator) GenerateASS(events []SubtitleEvent) string {
 	var builder strings.Builder
 
+	// Right-to-left styles are mirrored in the header and marked in the events
+	g.directions = g.resolveDirections(events)
+
 	// Write header
 	builder.WriteString(g.generateHeader())
 	builder.WriteString("\n")
@@ -103,57 +227,94 @@ func (g *ASSGenerator) GenerateASS(events []SubtitleEvent) string {
 
 // generateHeader creates the ASS file header with styling
 func (g *ASSGenerator) generateHeader() string {
-	wordColor := g.parseColorToASS(g.config.WordColor)
-	outlineColor := g.parseColorToASS(g.config.OutlineColor)
-
-	// Use LineColor for secondary color, fallback to WordColor (same as primary)
-	lineColor := wordColor // Use the actual wordColor from config, not default
-	if g.config.LineColor != "" {
-		lineColor = g.parseColorToASS(g.config.LineColor)
+	styleLines := g.formatStyleLine(defaultStyleName, g.config)
+	for _, style := range g.styles {
+		styleLines += "\n" + g.formatStyleLine(style.Name, style.Config)
 	}
 
-	// Use BoxColor for background color, fallback to default black
-	boxColor := "&H00000000"
-	if g.config.BoxColor != "" {
-		boxColor = g.parseColorToASS(g.config.BoxColor)
-	}
-
-	alignment := g.getAlignment(g.config.Position)
-
 	// Include style in title if specified
 	title := "Generated Progressive Subtitles"
 	if g.config.Style != "" {
 		// Keep original case and also add capitalized version for readability
-		titleCase := cases.Title(language.Und, cases.NoLower).String(g.config.Style)
+		titleCase := titleCaseStyle(g.config.Style)
 		title = fmt.Sprintf("Generated %s (%s) Subtitles", titleCase, g.config.Style)
 	}
 
+	scaledBorderAndShadow := "yes"
+	if g.config.ScaledBorderAndShadow != nil && !*g.config.ScaledBorderAndShadow {
+		scaledBorderAndShadow = "no"
+	}
+
 	return fmt.Sprintf(`[Script Info]
 Title: %s
 ScriptType: v4.00+
 WrapStyle: 0
-ScaledBorderAndShadow: yes
+ScaledBorderAndShadow: %s
 YCbCr Matrix: TV.709
 
 [V4+ Styles]
 Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
-Style: Default,%s,%d,%s,%s,%s,%s,1,0,0,0,100,100,0,0,1,%d,%d,%d,10,10,20,1
+%s
 
 [Events]
 Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text`,
 		title, // Dynamic title with style
-		g.config.FontFamily,
-		g.config.FontSize,
+		scaledBorderAndShadow,
+		styleLines,
+	)
+}
+
+// formatStyleLine renders one "Style:" line of the [V4+ Styles] section
+func (g *ASSGenerator) formatStyleLine(name string, config ASSConfig) string {
+	wordColor := g.parseColorToASS(config.WordColor)
+	outlineColor := g.parseColorToASS(config.OutlineColor)
+
+	// Use LineColor for secondary color, fallback to WordColor (same as primary)
+	lineColor := wordColor // Use the actual wordColor from config, not default
+	if config.LineColor != "" {
+		lineColor = g.parseColorToASS(config.LineColor)
+	}
+
+	// Use BoxColor for background color, fallback to default black
+	boxColor := "&H00000000"
+	if config.BoxColor != "" {
+		boxColor = g.parseColorToASS(config.BoxColor)
+	}
+
+	direction, ok := g.directions[name]
+	if !ok {
+		direction.encoding = assEncodingDefault
+	}
+	alignment := g.getAlignment(config.Position, direction.rtl)
+
+	return fmt.Sprintf("Style: %s,%s,%d,%s,%s,%s,%s,%d,%d,%d,0,100,100,0,0,1,%d,%d,%d,%d,%d,%d,%d",
+		name,
+		config.FontFamily,
+		config.FontSize,
 		wordColor,    // PrimaryColour
 		lineColor,    // SecondaryColour (LineColor)
 		outlineColor, // OutlineColour
 		boxColor,     // BackColour (BoxColor)
-		g.config.OutlineWidth,
-		g.config.ShadowOffset,
+		assFlag(config.IsBold()),
+		assFlag(config.IsItalic()),
+		assFlag(config.IsUnderline()),
+		config.OutlineWidth,
+		config.ShadowOffset,
 		alignment,
+		firstNonZero(config.MarginLeft, defaultMarginLeft),
+		firstNonZero(config.MarginRight, defaultMarginRight),
+		firstNonZero(config.MarginVertical, defaultMarginVertical),
+		direction.encoding,
 	)
 }
 
+// dimmedTextTag renders an event's text at half opacity; undimmedTextTag
+// restores the opaque colors of the styles
+const (
+	dimmedTextTag   = `{\alpha&H80&}`
+	undimmedTextTag = `{\alpha&H00&}`
+)
+
 // generateEvents creates ASS dialogue events from subtitle events
 func (g *ASSGenerator) generateEvents(events []SubtitleEvent) string {
 	var builder strings.Builder
@@ -162,11 +323,27 @@ func (g *ASSGenerator) generateEvents(events []SubtitleEvent) string {
 		startTime := g.formatASSTime(event.StartTime)
 		endTime := g.formatASSTime(event.EndTime)
 		cleanText := g.cleanTextForASS(event.Text)
+		if len(event.Karaoke) > 0 {
+			cleanText = g.karaokeText(event.Karaoke)
+		}
+
+		style := event.Style
+		if style == "" {
+			style = defaultStyleName
+		}
+
+		if g.eventIsRTL(event, style) {
+			cleanText = markRTL(cleanText)
+		}
+		if event.Dimmed {
+			cleanText = dimmedTextTag + cleanText
+		}
 
-		line := fmt.Sprintf("Dialogue: %d,%s,%s,Default,,0,0,0,,%s\n",
+		line := fmt.Sprintf("Dialogue: %d,%s,%s,%s,,0,0,0,,%s\n",
 			event.Layer,
 			startTime,
 			endTime,
+			style,
 			cleanText,
 		)
 
@@ -176,6 +353,22 @@ func (g *ASSGenerator) generateEvents(events []SubtitleEvent) string {
 	return builder.String()
 }
 
+// karaokeText prefixes each word with its \k highlight time in centiseconds.
+// Words start in the style's SecondaryColour (line color) and switch to the
+// PrimaryColour (word color) when their turn comes.
+func (g *ASSGenerator) karaokeText(words []KaraokeWord) string {
+	parts := make([]string, 0, len(words))
+	for _, word := range words {
+		centiseconds := word.Duration.Round(10*time.Millisecond) / (10 * time.Millisecond)
+		text := g.cleanTextForASS(word.Text)
+		if word.Dimmed {
+			text = dimmedTextTag + text + undimmedTextTag
+		}
+		parts = append(parts, fmt.Sprintf(`{\k%d}%s`, centiseconds, text))
+	}
+	return strings.Join(parts, " ")
+}
+
 // formatASSTime converts time.Duration to ASS time format (H:MM:SS.CC)
 func (g *ASSGenerator) formatASSTime(duration time.Duration) string {
 	totalSeconds := duration.Seconds()
@@ -206,8 +399,9 @@ func (g *ASSGenerator) parseColorToASS(hexColor string) string {
 	return fmt.Sprintf("&H00%s%s%s", b, gComponent, r)
 }
 
-// getAlignment maps position string to ASS alignment number
-func (g *ASSGenerator) getAlignment(position string) int {
+// getAlignment maps position string to ASS alignment number; right-to-left
+// styles mirror left and right so "left" means the reading start
+func (g *ASSGenerator) getAlignment(position string, rtl bool) int {
 	alignmentMap := map[string]int{
 		"left-bottom":   1,
 		"center-bottom": 2,
@@ -231,15 +425,39 @@ func (g *ASSGenerator) getAlignment(position string) int {
 		"top-right":     9,
 	}
 
-	if alignment, exists := alignmentMap[position]; exists {
-		return alignment
+	alignment, exists := alignmentMap[position]
+	if !exists {
+		return 2 // Default to center-bottom
+	}
+	if rtl {
+		return mirrorAlignment(alignment)
 	}
+	return alignment
+}
 
-	return 2 // Default to center-bottom
+// titleCaseStyle capitalizes only the first letter of a style name. Full
+// title casing rewrites scripts without case (or with special casing rules
+// such as German sharp s), so non-Latin style names are left untouched.
+func titleCaseStyle(style string) string {
+	r, size := utf8.DecodeRuneInString(style)
+	if r == utf8.RuneError || !unicode.IsLower(r) || r > unicode.MaxLatin1 {
+		return style
+	}
+	return string(unicode.ToTitle(r)) + style[size:]
 }
 
 // cleanTextForASS escapes special characters for ASS format
 func (g *ASSGenerator) cleanTextForASS(text string) string {
+	// Drop invalid UTF-8 and compose combining marks so libass renders single glyphs
+	text = strings.ToValidUTF8(text, "")
+	text = norm.NFC.String(text)
+
+	text = g.handleEmoji(text)
+
+	// Non-breaking spaces would be collapsed by the whitespace cleanup below
+	text = strings.ReplaceAll(text, "\u00a0", "\\h")
+	text = strings.ReplaceAll(text, "\u202f", "\\h")
+
 	// Replace newlines with ASS line breaks
 	text = strings.ReplaceAll(text, "\n", "\\N")
 
@@ -253,9 +471,114 @@ func (g *ASSGenerator) cleanTextForASS(text string) string {
 	// Clean up extra whitespace
 	text = strings.Join(strings.Fields(text), " ")
 
+	// Added after escaping so the override tags are not escaped themselves
+	text = g.applyFontFallback(text)
+
 	return text
 }
 
+// applyFontFallback switches runs of letters outside the Latin script to the
+// first fallback font and back to the style font afterwards. Punctuation and
+// spaces stay in the current run to avoid needless font switches.
+func (g *ASSGenerator) applyFontFallback(text string) string {
+	if len(g.config.FallbackFonts) == 0 {
+		return text
+	}
+
+	var builder strings.Builder
+	inFallback := false
+	escaped := false
+	for _, r := range text {
+		// Keep escapes such as \N and \h intact
+		if escaped || r == '\\' {
+			escaped = !escaped && r == '\\'
+			builder.WriteRune(r)
+			continue
+		}
+		if unicode.IsLetter(r) {
+			latin := unicode.Is(unicode.Latin, r)
+			if !latin && !inFallback {
+				builder.WriteString(`{\fn` + g.config.FallbackFonts[0] + `}`)
+				inFallback = true
+			} else if latin && inFallback {
+				builder.WriteString(`{\fn}`) // Empty \fn restores the style font
+				inFallback = false
+			}
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
+
+// SplitFontStack parses a comma-separated font stack, dropping empty entries
+func SplitFontStack(stack string) []string {
+	var fonts []string
+	for _, font := range strings.Split(stack, ",") {
+		if font = strings.TrimSpace(font); font != "" {
+			fonts = append(fonts, font)
+		}
+	}
+	return fonts
+}
+
+// validateFontName rejects names that would break an ASS style line or override tag
+func validateFontName(font string) error {
+	if font == "" || len(font) > 128 {
+		return fmt.Errorf("font name must be 1-128 characters")
+	}
+	if strings.ContainsAny(font, ",{}\\") || strings.IndexFunc(font, unicode.IsControl) >= 0 {
+		return fmt.Errorf("font name %q contains invalid characters", font)
+	}
+	return nil
+}
+
+// handleEmoji strips or replaces emoji according to the configured mode. A
+// joined sequence (ZWJ, skin tone, variation selector, flag pair) is treated
+// as one emoji so it yields a single replacement.
+func (g *ASSGenerator) handleEmoji(text string) string {
+	mode := g.config.EmojiHandling
+	if mode != "strip" && mode != "replace" {
+		return text
+	}
+
+	var builder strings.Builder
+	inEmoji := false
+	for _, r := range text {
+		if isEmojiRune(r) || (inEmoji && isEmojiJoiner(r)) {
+			if !inEmoji && mode == "replace" {
+				builder.WriteString(g.config.EmojiReplacement)
+			}
+			inEmoji = true
+			continue
+		}
+		inEmoji = false
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
+
+// isEmojiRune reports whether r is a pictographic emoji or emoji modifier
+func isEmojiRune(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // Pictographs, emoticons, flags, skin tones
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // Misc symbols and dingbats
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // Arrows and stars used as emoji
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // Tag sequences (subdivision flags)
+		return true
+	}
+	return false
+}
+
+// isEmojiJoiner reports whether r only continues a preceding emoji
+func isEmojiJoiner(r rune) bool {
+	return r == 0x200D || r == 0xFE0F || r == 0x20E3
+}
+
 // CreateProgressiveEvents generates word-by-word subtitle events
 func CreateProgressiveEvents(words []WordTimestamp, sceneStartTime time.Duration) []SubtitleEvent {
 	var events []SubtitleEvent
@@ -307,6 +630,7 @@ func CreateProgressiveEvents(words []WordTimestamp, sceneStartTime time.Duration
 			EndTime:   endTime,
 			Text:      strings.TrimSpace(word.Word),
 			Layer:     0,
+			Dimmed:    word.Dimmed,
 		}
 
 		events = append(events, event)
@@ -357,6 +681,7 @@ func CreateProgressiveEventsWithSceneTiming(words []WordTimestamp, sceneTiming m
 			EndTime:   endTime,
 			Text:      strings.TrimSpace(word.Word),
 			Layer:     0,
+			Dimmed:    word.Dimmed,
 		}
 
 		events = append(events, event)
@@ -365,20 +690,242 @@ func CreateProgressiveEventsWithSceneTiming(words []WordTimestamp, sceneTiming m
 	return events
 }
 
-// CreateClassicEvents generates scene-based subtitle events (non-progressive)
-func CreateClassicEvents(text string, sceneStartTime, sceneDuration time.Duration) []SubtitleEvent {
+// Karaoke lines break after karaokeMaxLineWords words, at sentence ends and
+// at pauses longer than karaokeMaxLineGap
+const (
+	karaokeMaxLineWords = 7
+	karaokeMaxLineGap   = time.Second
+)
+
+// CreateKaraokeEventsWithSceneTiming groups words into lines that are shown
+// whole, with each word highlighted in turn from its timestamp. A word stays
+// highlighted until the next word of its line starts.
+func CreateKaraokeEventsWithSceneTiming(words []WordTimestamp, sceneTiming models.TimingSegment) []SubtitleEvent {
+	var events []SubtitleEvent
+
+	sceneStartTime := time.Duration(sceneTiming.StartTime * float64(time.Second))
+	sceneEndTime := time.Duration(sceneTiming.EndTime * float64(time.Second))
+	toTimeline := func(seconds float64) time.Duration {
+		return sceneStartTime + time.Duration(seconds*float64(time.Second))
+	}
+
+	for _, line := range groupKaraokeLines(words) {
+		startTime := toTimeline(line[0].Start)
+		endTime := toTimeline(line[len(line)-1].End)
+
+		karaoke := make([]KaraokeWord, len(line))
+		texts := make([]string, len(line))
+		for i, word := range line {
+			end := word.End
+			if i+1 < len(line) {
+				end = line[i+1].Start
+			}
+			texts[i] = strings.TrimSpace(word.Word)
+			karaoke[i] = KaraokeWord{
+				Text:     texts[i],
+				Duration: max(toTimeline(end)-toTimeline(word.Start), 0),
+				Dimmed:   word.Dimmed,
+			}
+		}
+
+		// Ensure we don't exceed scene boundaries
+		if startTime < sceneStartTime {
+			startTime = sceneStartTime
+		}
+		if endTime > sceneEndTime {
+			endTime = sceneEndTime
+		}
+		if endTime <= startTime {
+			continue
+		}
+
+		events = append(events, SubtitleEvent{
+			StartTime: startTime,
+			EndTime:   endTime,
+			Text:      strings.Join(texts, " "),
+			Layer:     0,
+			Karaoke:   karaoke,
+		})
+	}
+
+	return events
+}
+
+// groupKaraokeLines splits the non-empty words into karaoke lines
+func groupKaraokeLines(words []WordTimestamp) [][]WordTimestamp {
+	var lines [][]WordTimestamp
+	var current []WordTimestamp
+
+	for _, word := range words {
+		text := strings.TrimSpace(word.Word)
+		if text == "" {
+			continue
+		}
+
+		if len(current) > 0 {
+			previous := current[len(current)-1]
+			pause := time.Duration((word.Start - previous.End) * float64(time.Second))
+			if len(current) >= karaokeMaxLineWords || pause > karaokeMaxLineGap {
+				lines = append(lines, current)
+				current = nil
+			}
+		}
+		current = append(current, word)
+
+		if last, _ := utf8.DecodeLastRuneInString(text); isSentenceEnd(last) {
+			lines = append(lines, current)
+			current = nil
+		}
+	}
+
+	if len(current) > 0 {
+		lines = append(lines, current)
+	}
+
+	return lines
+}
+
+// skipKaraoke drops the first elapsed of a karaoke line's highlight timing,
+// shortening the word that is highlighted at that point
+func skipKaraoke(words []KaraokeWord, elapsed time.Duration) []KaraokeWord {
+	skipped := make([]KaraokeWord, len(words))
+	copy(skipped, words)
+	for i := range skipped {
+		consumed := min(elapsed, skipped[i].Duration)
+		skipped[i].Duration -= consumed
+		elapsed -= consumed
+	}
+	return skipped
+}
+
+// Reading speed units for classic caption pacing
+const (
+	ReadingSpeedUnitWords = "words"
+	ReadingSpeedUnitChars = "chars"
+)
+
+// classicChunkMaxDuration bounds how long a single reading-paced chunk may take to read
+const classicChunkMaxDuration = 3 * time.Second
+
+// ReadingSpeed defines the pace used to split classic captions into timed chunks
+type ReadingSpeed struct {
+	Rate float64 // Units per second; 0 disables chunking
+	Unit string  // ReadingSpeedUnitWords or ReadingSpeedUnitChars
+}
+
+// readingTime returns how long it takes to read text at this speed
+func (rs ReadingSpeed) readingTime(text string) time.Duration {
+	units := float64(len(strings.Fields(text)))
+	if rs.Unit == ReadingSpeedUnitChars {
+		units = float64(len([]rune(text)))
+	}
+	return time.Duration(units / rs.Rate * float64(time.Second))
+}
+
+// CreateClassicEvents generates scene-based subtitle events (non-progressive).
+// With a reading speed set, the text is split into chunks that are each readable within
+// classicChunkMaxDuration and shown back to back at that pace. The last chunk stays up
+// until the scene ends; if the text cannot be read at pace within the scene, the chunks
+// are compressed proportionally to fit the scene window.
+func CreateClassicEvents(text string, sceneStartTime, sceneDuration time.Duration, speed ReadingSpeed) []SubtitleEvent {
 	if strings.TrimSpace(text) == "" {
 		return []SubtitleEvent{}
 	}
 
-	event := SubtitleEvent{
-		StartTime: sceneStartTime,
-		EndTime:   sceneStartTime + sceneDuration,
-		Text:      strings.TrimSpace(text),
-		Layer:     0,
+	if speed.Rate <= 0 {
+		event := SubtitleEvent{
+			StartTime: sceneStartTime,
+			EndTime:   sceneStartTime + sceneDuration,
+			Text:      strings.TrimSpace(text),
+			Layer:     0,
+		}
+		return []SubtitleEvent{event}
+	}
+
+	chunks := splitByReadingSpeed(text, speed)
+
+	durations := make([]time.Duration, len(chunks))
+	var total time.Duration
+	for i, chunk := range chunks {
+		durations[i] = speed.readingTime(chunk)
+		total += durations[i]
+	}
+
+	// Compress to the scene window when the pace cannot be honored
+	scale := 1.0
+	if total > sceneDuration && total > 0 {
+		scale = float64(sceneDuration) / float64(total)
+	}
+
+	events := make([]SubtitleEvent, 0, len(chunks))
+	current := sceneStartTime
+	sceneEnd := sceneStartTime + sceneDuration
+	for i, chunk := range chunks {
+		end := current + time.Duration(float64(durations[i])*scale)
+		if i == len(chunks)-1 || end > sceneEnd {
+			end = sceneEnd
+		}
+
+		events = append(events, SubtitleEvent{
+			StartTime: current,
+			EndTime:   end,
+			Text:      chunk,
+			Layer:     0,
+		})
+		current = end
+	}
+
+	return events
+}
+
+// splitByReadingSpeed groups words into chunks readable within classicChunkMaxDuration
+func splitByReadingSpeed(text string, speed ReadingSpeed) []string {
+	var chunks []string
+	var current []string
+
+	for _, word := range strings.Fields(text) {
+		candidate := strings.Join(append(current, word), " ")
+		if len(current) > 0 && speed.readingTime(candidate) > classicChunkMaxDuration {
+			chunks = append(chunks, strings.Join(current, " "))
+			current = nil
+		}
+		current = append(current, word)
+	}
+
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, " "))
+	}
+
+	return chunks
+}
+
+// FilterEventsToWindow keeps only events overlapping [windowStart, windowEnd) and clips
+// events that cross a boundary. A zero windowEnd means the window is open-ended.
+func FilterEventsToWindow(events []SubtitleEvent, windowStart, windowEnd time.Duration) []SubtitleEvent {
+	filtered := make([]SubtitleEvent, 0, len(events))
+
+	for _, event := range events {
+		if event.EndTime <= windowStart {
+			continue
+		}
+		if windowEnd > 0 && event.StartTime >= windowEnd {
+			continue
+		}
+
+		if event.StartTime < windowStart {
+			if len(event.Karaoke) > 0 {
+				event.Karaoke = skipKaraoke(event.Karaoke, windowStart-event.StartTime)
+			}
+			event.StartTime = windowStart
+		}
+		if windowEnd > 0 && event.EndTime > windowEnd {
+			event.EndTime = windowEnd
+		}
+
+		filtered = append(filtered, event)
 	}
 
-	return []SubtitleEvent{event}
+	return filtered
 }
 
 // WordTimestamp represents a word with timing information
@@ -386,4 +933,8 @@ type WordTimestamp struct {
 	Word  string  `json:"word"`
 	Start float64 `json:"start"`
 	End   float64 `json:"end"`
+
+	// Confidence of the transcribed word (0-1); nil when unknown
+	Confidence *float64 `json:"confidence,omitempty"`
+	Dimmed     bool     `json:"-"`
 }
@@ -0,0 +1,153 @@
+package subtitle
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Subtitle text directions; auto decides per event from its text
+const (
+	TextDirectionAuto = "auto"
+	TextDirectionLTR  = "ltr"
+	TextDirectionRTL  = "rtl"
+)
+
+// ASS style encodings (Windows charsets) of right-to-left scripts
+const (
+	assEncodingDefault = 1
+	assEncodingHebrew  = 177
+	assEncodingArabic  = 178
+)
+
+// rightToLeftMark sets the base direction of a caption line without
+// rendering anything, so trailing punctuation stays on the left
+const rightToLeftMark = "\u200f"
+
+// rtlScripts are the scripts written right to left
+var rtlScripts = []*unicode.RangeTable{unicode.Arabic, unicode.Hebrew, unicode.Syriac, unicode.Thaana, unicode.Nko}
+
+func isRTLRune(r rune) bool {
+	return unicode.In(r, rtlScripts...)
+}
+
+// IsRTLText reports whether the first letter of text belongs to a
+// right-to-left script, which is how bidi rules pick a paragraph direction
+func IsRTLText(text string) bool {
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			return isRTLRune(r)
+		}
+	}
+	return false
+}
+
+// markRTL starts and ends every line of cleaned ASS text with a right-to-left
+// mark so libass lays the line out right to left
+func markRTL(text string) string {
+	lines := strings.Split(text, `\N`)
+	for i, line := range lines {
+		lines[i] = rightToLeftMark + line + rightToLeftMark
+	}
+	return strings.Join(lines, `\N`)
+}
+
+// mirrorAlignment swaps left and right ASS numpad alignments
+func mirrorAlignment(alignment int) int {
+	switch alignment % 3 {
+	case 1:
+		return alignment + 2
+	case 0:
+		return alignment - 2
+	}
+	return alignment
+}
+
+// styleDirection is the resolved direction of one ASS style
+type styleDirection struct {
+	rtl      bool
+	encoding int
+}
+
+// resolveDirections decides per style whether it is laid out right to left:
+// explicitly, or under auto when most of its events are right-to-left text.
+// RTL styles get the encoding of the script their events mostly use.
+func (g *ASSGenerator) resolveDirections(events []SubtitleEvent) map[string]styleDirection {
+	type tally struct{ events, rtl, arabic, hebrew int }
+	tallies := make(map[string]*tally)
+	for _, event := range events {
+		name := event.Style
+		if name == "" {
+			name = defaultStyleName
+		}
+		t := tallies[name]
+		if t == nil {
+			t = &tally{}
+			tallies[name] = t
+		}
+		t.events++
+		if IsRTLText(event.Text) {
+			t.rtl++
+		}
+		for _, r := range event.Text {
+			switch {
+			case unicode.Is(unicode.Arabic, r):
+				t.arabic++
+			case unicode.Is(unicode.Hebrew, r):
+				t.hebrew++
+			}
+		}
+	}
+
+	configs := map[string]ASSConfig{defaultStyleName: g.config}
+	for _, style := range g.styles {
+		configs[style.Name] = style.Config
+	}
+
+	directions := make(map[string]styleDirection, len(configs))
+	for name, config := range configs {
+		t := tallies[name]
+		if t == nil {
+			t = &tally{}
+		}
+
+		direction := styleDirection{encoding: assEncodingDefault}
+		switch config.TextDirection {
+		case TextDirectionRTL:
+			direction.rtl = true
+		case TextDirectionLTR:
+		default:
+			direction.rtl = t.rtl*2 > t.events
+		}
+		if direction.rtl && (t.arabic > 0 || t.hebrew > 0) {
+			direction.encoding = assEncodingArabic
+			if t.hebrew > t.arabic {
+				direction.encoding = assEncodingHebrew
+			}
+		}
+		directions[name] = direction
+	}
+
+	return directions
+}
+
+// eventIsRTL reports whether an event's lines get right-to-left marks: all
+// events of an explicitly RTL style, or right-to-left text under auto
+func (g *ASSGenerator) eventIsRTL(event SubtitleEvent, style string) bool {
+	switch g.styleConfig(style).TextDirection {
+	case TextDirectionRTL:
+		return true
+	case TextDirectionLTR:
+		return false
+	}
+	return IsRTLText(event.Text)
+}
+
+// styleConfig returns the configuration of a named style
+func (g *ASSGenerator) styleConfig(name string) ASSConfig {
+	for _, style := range g.styles {
+		if style.Name == name {
+			return style.Config
+		}
+	}
+	return g.config
+}
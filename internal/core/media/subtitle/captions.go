@@ -0,0 +1,120 @@
+package subtitle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/activadee/videocraft/internal/api/models"
+)
+
+// CaptionFile is a caption file exported in addition to the ASS or JSON subtitles
+type CaptionFile struct {
+	Format   string `json:"format"`
+	FilePath string `json:"file_path"`
+}
+
+// vttEscaper escapes the characters WebVTT cue text reserves for markup
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// GenerateSRT renders events as a SubRip file, one numbered cue per event
+func GenerateSRT(events []SubtitleEvent) string {
+	var builder strings.Builder
+	cue := 0
+	for _, event := range events {
+		text := captionText(event)
+		if text == "" {
+			continue
+		}
+		cue++
+		fmt.Fprintf(&builder, "%d\n%s --> %s\n%s\n\n",
+			cue, formatSRTTime(event.StartTime), formatSRTTime(event.EndTime), text)
+	}
+	return builder.String()
+}
+
+// GenerateWebVTT renders events as a WebVTT file
+func GenerateWebVTT(events []SubtitleEvent) string {
+	var builder strings.Builder
+	builder.WriteString("WEBVTT\n\n")
+	for _, event := range events {
+		text := captionText(event)
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&builder, "%s --> %s\n%s\n\n",
+			formatVTTTime(event.StartTime), formatVTTTime(event.EndTime), vttEscaper.Replace(text))
+	}
+	return builder.String()
+}
+
+// captionText is an event's plain text with blank lines removed, which
+// would otherwise end the cue early
+func captionText(event SubtitleEvent) string {
+	var lines []string
+	for _, line := range strings.Split(event.Text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatSRTTime formats a cue time as HH:MM:SS,mmm
+func formatSRTTime(d time.Duration) string {
+	return formatCaptionTime(d, ',')
+}
+
+// formatVTTTime formats a cue time as HH:MM:SS.mmm
+func formatVTTTime(d time.Duration) string {
+	return formatCaptionTime(d, '.')
+}
+
+// formatCaptionTime formats d with millisecond precision; negative times
+// are clamped to zero
+func formatCaptionTime(d time.Duration, separator byte) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	hours := ms / 3600000
+	minutes := ms / 60000 % 60
+	seconds := ms / 1000 % 60
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", hours, minutes, seconds, separator, ms%1000)
+}
+
+// createCaptionFiles writes the events in each requested caption format
+func (ss *service) createCaptionFiles(events []SubtitleEvent, formats []string) ([]CaptionFile, error) {
+	if len(formats) == 0 {
+		return nil, nil
+	}
+	if err := os.MkdirAll(ss.cfg.Storage.TempDir, ss.cfg.Storage.DirPerm()); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	files := make([]CaptionFile, 0, len(formats))
+	for _, format := range formats {
+		var content string
+		switch format {
+		case models.SubtitleFormatSRT:
+			content = GenerateSRT(events)
+		case models.SubtitleFormatVTT:
+			content = GenerateWebVTT(events)
+		default:
+			return files, fmt.Errorf("unsupported caption format %q", format)
+		}
+
+		filePath := filepath.Join(ss.cfg.Storage.TempDir, fmt.Sprintf("subtitles_%s.%s", uuid.New().String()[:8], format))
+		if err := os.WriteFile(filePath, []byte(content), ss.cfg.Storage.FilePerm()); err != nil {
+			return files, fmt.Errorf("failed to write %s captions: %w", format, err)
+		}
+		files = append(files, CaptionFile{Format: format, FilePath: filePath})
+		ss.log.Debugf("Caption file created: %s", filePath)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,24 @@
+package subtitle
+
+import "github.com/activadee/videocraft/internal/app"
+
+// FilterLowConfidenceWords drops words whose confidence is below threshold,
+// or marks them dimmed when mode is "dim". Words without a reported
+// confidence are kept as they are; a threshold of 0 disables filtering.
+func FilterLowConfidenceWords(words []WordTimestamp, threshold float64, mode string) []WordTimestamp {
+	if threshold <= 0 {
+		return words
+	}
+
+	result := make([]WordTimestamp, 0, len(words))
+	for _, w := range words {
+		if w.Confidence != nil && *w.Confidence < threshold {
+			if mode != app.LowConfidenceDim {
+				continue
+			}
+			w.Dimmed = true
+		}
+		result = append(result, w)
+	}
+	return result
+}
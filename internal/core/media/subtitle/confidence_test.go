@@ -0,0 +1,71 @@
+package subtitle
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/activadee/videocraft/internal/app"
+)
+
+func TestFilterLowConfidenceWords(t *testing.T) {
+	confidence := func(c float64) *float64 { return &c }
+	words := []WordTimestamp{
+		{Word: "clear", Start: 0, End: 0.5, Confidence: confidence(0.9)},
+		{Word: "mumbled", Start: 0.5, End: 1, Confidence: confidence(0.2)},
+		{Word: "unknown", Start: 1, End: 1.5},
+		{Word: "borderline", Start: 1.5, End: 2, Confidence: confidence(0.5)},
+	}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		mode      string
+		want      []string
+		dimmed    []string
+	}{
+		{"disabled", 0, app.LowConfidenceDrop, []string{"clear", "mumbled", "unknown", "borderline"}, nil},
+		{"drop", 0.5, app.LowConfidenceDrop, []string{"clear", "unknown", "borderline"}, nil},
+		{"dim", 0.5, app.LowConfidenceDim, []string{"clear", "mumbled", "unknown", "borderline"}, []string{"mumbled"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got, dimmed []string
+			for _, w := range FilterLowConfidenceWords(words, tt.threshold, tt.mode) {
+				got = append(got, w.Word)
+				if w.Dimmed {
+					dimmed = append(dimmed, w.Word)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("words = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(dimmed, tt.dimmed) {
+				t.Errorf("dimmed = %v, want %v", dimmed, tt.dimmed)
+			}
+		})
+	}
+
+	if words[1].Dimmed {
+		t.Error("FilterLowConfidenceWords modified its input")
+	}
+}
+
+func TestGenerateASSDimsLowConfidenceWords(t *testing.T) {
+	words := []WordTimestamp{
+		{Word: "clear", Start: 0, End: 0.5},
+		{Word: "mumbled", Start: 0.5, End: 1, Dimmed: true},
+	}
+	events := CreateProgressiveEvents(words, 0)
+	if len(events) != 2 || events[0].Dimmed || !events[1].Dimmed {
+		t.Fatalf("events = %+v, want only the second dimmed", events)
+	}
+
+	content := NewASSGenerator(ASSConfig{}).GenerateASS(events)
+	if !strings.Contains(content, `,{\alpha&H80&}mumbled`) {
+		t.Errorf("dimmed word not rendered translucent:\n%s", content)
+	}
+	if strings.Contains(content, `{\alpha&H80&}clear`) {
+		t.Errorf("confident word rendered translucent:\n%s", content)
+	}
+}
@@ -0,0 +1,162 @@
+package subtitle
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/activadee/videocraft/internal/api/models"
+)
+
+// EventsDocument is the JSON form of generated subtitles for players that
+// render captions themselves. Times are in seconds on the output timeline.
+type EventsDocument struct {
+	Style  string                `json:"style"`
+	Styles map[string]EventStyle `json:"styles"`
+	Events []EventJSON           `json:"events"`
+}
+
+// EventStyle is the resolved look of a named style; events without a style
+// use "Default"
+type EventStyle struct {
+	FontFamily   string `json:"font_family"`
+	FontSize     int    `json:"font_size"`
+	Position     string `json:"position"`
+	WordColor    string `json:"word_color"`
+	LineColor    string `json:"line_color,omitempty"`
+	OutlineColor string `json:"outline_color"`
+	OutlineWidth int    `json:"outline_width"`
+	ShadowColor  string `json:"shadow_color,omitempty"`
+	ShadowOffset int    `json:"shadow_offset"`
+	BoxColor     string `json:"box_color,omitempty"`
+
+	MarginLeft     int `json:"margin_left"`
+	MarginRight    int `json:"margin_right"`
+	MarginVertical int `json:"margin_vertical"`
+
+	TextDirection string `json:"text_direction,omitempty"`
+
+	Bold      bool `json:"bold"`
+	Italic    bool `json:"italic"`
+	Underline bool `json:"underline"`
+}
+
+// EventJSON is a single subtitle event
+type EventJSON struct {
+	Start  float64 `json:"start"`
+	End    float64 `json:"end"`
+	Text   string  `json:"text"`
+	Layer  int     `json:"layer"`
+	Style  string  `json:"style,omitempty"`
+	Dimmed bool    `json:"dimmed,omitempty"`
+
+	// Words are the highlight windows of a karaoke line
+	Words []EventWordJSON `json:"words,omitempty"`
+}
+
+// EventWordJSON is a karaoke word and when it is highlighted
+type EventWordJSON struct {
+	Text   string  `json:"text"`
+	Start  float64 `json:"start"`
+	End    float64 `json:"end"`
+	Dimmed bool    `json:"dimmed,omitempty"`
+}
+
+// NewEventsDocument converts subtitle events and their styles to JSON form
+func NewEventsDocument(events []SubtitleEvent, style string, defaults ASSConfig, styles []NamedStyle) EventsDocument {
+	doc := EventsDocument{
+		Style:  style,
+		Styles: map[string]EventStyle{defaultStyleName: newEventStyle(defaults)},
+		Events: make([]EventJSON, 0, len(events)),
+	}
+	for _, named := range styles {
+		doc.Styles[named.Name] = newEventStyle(named.Config)
+	}
+
+	for _, event := range events {
+		entry := EventJSON{
+			Start:  eventSeconds(event.StartTime),
+			End:    eventSeconds(event.EndTime),
+			Text:   event.Text,
+			Layer:  event.Layer,
+			Style:  event.Style,
+			Dimmed: event.Dimmed,
+		}
+
+		wordStart := event.StartTime
+		for _, word := range event.Karaoke {
+			entry.Words = append(entry.Words, EventWordJSON{
+				Text:   word.Text,
+				Start:  eventSeconds(wordStart),
+				End:    eventSeconds(wordStart + word.Duration),
+				Dimmed: word.Dimmed,
+			})
+			wordStart += word.Duration
+		}
+
+		doc.Events = append(doc.Events, entry)
+	}
+
+	return doc
+}
+
+func newEventStyle(config ASSConfig) EventStyle {
+	return EventStyle{
+		FontFamily:   config.FontFamily,
+		FontSize:     config.FontSize,
+		Position:     config.Position,
+		WordColor:    config.WordColor,
+		LineColor:    config.LineColor,
+		OutlineColor: config.OutlineColor,
+		OutlineWidth: config.OutlineWidth,
+		ShadowColor:  config.ShadowColor,
+		ShadowOffset: config.ShadowOffset,
+		BoxColor:     config.BoxColor,
+
+		MarginLeft:     firstNonZero(config.MarginLeft, defaultMarginLeft),
+		MarginRight:    firstNonZero(config.MarginRight, defaultMarginRight),
+		MarginVertical: firstNonZero(config.MarginVertical, defaultMarginVertical),
+
+		TextDirection: config.TextDirection,
+
+		Bold:      config.IsBold(),
+		Italic:    config.IsItalic(),
+		Underline: config.IsUnderline(),
+	}
+}
+
+// eventSeconds rounds to the millisecond so the JSON stays readable
+func eventSeconds(d time.Duration) float64 {
+	return math.Round(d.Seconds()*1000) / 1000
+}
+
+// createEventsFile writes the events as an EventsDocument, resolving styles
+// the same way as the ASS file so both describe identical captions
+func (ss *service) createEventsFile(events []SubtitleEvent, style string, settings models.SubtitleSettings, scenes []models.Scene) (string, error) {
+	if err := os.MkdirAll(ss.cfg.Storage.TempDir, ss.cfg.Storage.DirPerm()); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	assConfig, sceneStyles, err := ss.resolveStyles(settings, scenes)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(NewEventsDocument(events, style, assConfig, sceneStyles), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode subtitle events: %w", err)
+	}
+
+	filePath := filepath.Join(ss.cfg.Storage.TempDir, fmt.Sprintf("subtitles_%s.json", uuid.New().String()[:8]))
+	if err := os.WriteFile(filePath, data, ss.cfg.Storage.FilePerm()); err != nil {
+		return "", fmt.Errorf("failed to write subtitle events file: %w", err)
+	}
+
+	ss.log.Debugf("Subtitle events file created: %s", filePath)
+	return filePath, nil
+}
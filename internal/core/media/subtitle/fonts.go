@@ -0,0 +1,75 @@
+package subtitle
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fontExtensions are the font files libass loads from a fonts directory
+var fontExtensions = map[string]bool{".ttf": true, ".otf": true, ".ttc": true, ".otc": true}
+
+// fontFileKey normalizes a font family or file name for matching: lower case
+// without spaces, hyphens and underscores
+func fontFileKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// fontInDir reports whether dir holds a font file named after the family,
+// e.g. "OpenSans-Bold.ttf" for "Open Sans". Font files are not parsed, so a
+// family shipped under an unrelated file name is not recognized.
+func fontInDir(dir, family string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+
+	key := fontFileKey(family)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || !fontExtensions[strings.ToLower(ext)] {
+			continue
+		}
+		if strings.HasPrefix(fontFileKey(strings.TrimSuffix(entry.Name(), ext)), key) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// warnMissingFonts logs a warning for each style font missing from
+// subtitles.fonts_dir, since libass then silently renders another font
+func (ss *service) warnMissingFonts(defaults ASSConfig, styles []NamedStyle) {
+	dir := ss.cfg.Subtitles.FontsDir
+	if dir == "" {
+		return
+	}
+
+	checked := make(map[string]bool)
+	configs := []ASSConfig{defaults}
+	for _, style := range styles {
+		configs = append(configs, style.Config)
+	}
+	for _, config := range configs {
+		family := config.FontFamily
+		if family == "" || checked[family] {
+			continue
+		}
+		checked[family] = true
+
+		found, err := fontInDir(dir, family)
+		if err != nil {
+			ss.log.Warnf("Failed to read subtitles fonts directory %s: %v", dir, err)
+			return
+		}
+		if !found {
+			ss.log.Warnf("Subtitle font %q not found in fonts directory %s; it must be installed system-wide or another font is used", family, dir)
+		}
+	}
+}
@@ -2,10 +2,13 @@ package subtitle
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,8 +23,19 @@ import (
 
 const (
 	subtitleStyleProgressive = "progressive"
+	subtitleStyleClassic     = "classic"
+	subtitleStyleKaraoke     = "karaoke"
 )
 
+// isValidSubtitleStyle reports whether style is a supported subtitle style
+func isValidSubtitleStyle(style string) bool {
+	return style == subtitleStyleProgressive || style == subtitleStyleClassic || style == subtitleStyleKaraoke
+}
+
+// ErrNoSubtitleEvents is returned when a subtitle element was requested but
+// transcription yielded nothing to show
+var ErrNoSubtitleEvents = stderrors.New("subtitles requested but no subtitle events were produced")
+
 // Service provides subtitle generation capabilities
 type Service interface {
 	GenerateSubtitles(ctx context.Context, project models.VideoProject) (*SubtitleResult, error)
@@ -50,6 +64,13 @@ type SubtitleResult struct {
 	TotalDuration      time.Duration `json:"total_duration"`
 	TranscriptionCount int           `json:"transcription_count"`
 	Style              string        `json:"style"`
+
+	// Events are the generated subtitle events the file was written from
+	Events []SubtitleEvent `json:"-"`
+
+	// CaptionFiles are the additional caption files requested by the
+	// project's subtitle_formats, written from the same events
+	CaptionFiles []CaptionFile `json:"caption_files,omitempty"`
 }
 
 // NewService creates a new subtitle service
@@ -68,8 +89,12 @@ func newSubtitleService(cfg *app.Config, log logger.Logger) Service {
 }
 
 func (ss *service) GenerateSubtitles(ctx context.Context, project models.VideoProject) (*SubtitleResult, error) {
-	if !ss.cfg.Subtitles.Enabled {
-		ss.log.Debug("Subtitles disabled in configuration")
+	scoped := *ss
+	scoped.log = logger.FromContext(ctx, ss.log)
+	ss = &scoped
+
+	if !project.SubtitlesEnabledOr(ss.cfg.Subtitles.Enabled) {
+		ss.log.Debug("Subtitles disabled for project")
 		return nil, nil
 	}
 
@@ -93,33 +118,74 @@ func (ss *service) GenerateSubtitles(ctx context.Context, project models.VideoPr
 	audioElements := ss.collectAudioElements(project)
 	if len(audioElements) == 0 {
 		ss.log.Debug("No audio elements found for transcription")
-		return nil, nil
+		return nil, ErrNoSubtitleEvents
 	}
 
 	// Transcribe audio elements
-	transcriptionResults, err := ss.transcribeAudioElements(ctx, audioElements)
+	transcriptionResults, err := ss.transcribeAudioElements(ctx, audioElements, subtitleElement.Language)
 	if err != nil {
 		return nil, fmt.Errorf("failed to transcribe audio: %w", err)
 	}
 
-	// Generate subtitle events
-	events, err := ss.generateSubtitleEvents(project, transcriptionResults, audioElements)
+	if subtitleElement.Start != 0 || subtitleElement.End != 0 {
+		if err := ss.validateSubtitleWindow(*subtitleElement, audioElements); err != nil {
+			return nil, err
+		}
+	}
+
+	// Generate subtitle events in the requested style, defaulting to the configured one
+	style := firstNonEmpty(subtitleElement.Settings.Style, ss.cfg.Subtitles.Style)
+	events, err := ss.generateWindowedEvents(project, *subtitleElement, transcriptionResults, audioElements, style)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate subtitle events: %w", err)
+		return nil, err
+	}
+
+	// Guard against runaway event counts
+	if maxEvents := ss.cfg.Subtitles.MaxEvents; maxEvents > 0 && len(events) > maxEvents {
+		if ss.cfg.Subtitles.MaxEventsPolicy != app.MaxEventsPolicyClassic || style == subtitleStyleClassic {
+			return nil, errors.InvalidInput(fmt.Sprintf("subtitle generation produced %d events, exceeding the limit of %d", len(events), maxEvents))
+		}
+
+		ss.log.Warnf("Subtitle events (%d) exceed limit %d, switching to classic style", len(events), maxEvents)
+		style = subtitleStyleClassic
+		events, err = ss.generateWindowedEvents(project, *subtitleElement, transcriptionResults, audioElements, style)
+		if err != nil {
+			return nil, err
+		}
+		if len(events) > maxEvents {
+			return nil, errors.InvalidInput(fmt.Sprintf("subtitle generation produced %d events in classic style, exceeding the limit of %d", len(events), maxEvents))
+		}
 	}
 
 	if len(events) == 0 {
-		ss.log.Debug("No subtitle events generated")
-		return nil, nil
+		ss.log.Warn("No subtitle events generated")
+		return nil, ErrNoSubtitleEvents
 	}
 
 	// Extract subtitle settings from project
 	subtitleSettings := ss.extractSubtitleSettings(project)
 
-	// Create ASS file with settings
-	filePath, err := ss.createASSFileWithSettings(events, subtitleSettings)
+	// Create ASS file with settings, or the events as JSON for client-side rendering
+	var filePath string
+	if project.SubtitleMode == models.SubtitleModeJSON {
+		filePath, err = ss.createEventsFile(events, style, subtitleSettings, project.Scenes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create subtitle events file: %w", err)
+		}
+	} else {
+		filePath, err = ss.createASSFileWithSettings(events, subtitleSettings, project.Scenes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ASS file: %w", err)
+		}
+	}
+
+	captionFiles, err := ss.createCaptionFiles(events, project.SubtitleFormats)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create ASS file: %w", err)
+		_ = ss.CleanupTempFiles(filePath)
+		for _, file := range captionFiles {
+			_ = ss.CleanupTempFiles(file.FilePath)
+		}
+		return nil, fmt.Errorf("failed to create caption files: %w", err)
 	}
 
 	// Calculate total duration
@@ -135,11 +201,13 @@ func (ss *service) GenerateSubtitles(ctx context.Context, project models.VideoPr
 		EventCount:         len(events),
 		TotalDuration:      totalDuration,
 		TranscriptionCount: len(transcriptionResults),
-		Style:              ss.cfg.Subtitles.Style,
+		Style:              style,
+		Events:             events,
+		CaptionFiles:       captionFiles,
 	}
 
 	ss.log.Infof("Subtitles generated successfully: %d events, %s style, file: %s",
-		len(events), ss.cfg.Subtitles.Style, filePath)
+		len(events), style, filePath)
 
 	return result, nil
 }
@@ -159,37 +227,85 @@ func (ss *service) collectAudioElements(project models.VideoProject) []models.El
 	return audioElements
 }
 
-func (ss *service) transcribeAudioElements(ctx context.Context, audioElements []models.Element) ([]*transcription.TranscriptionResult, error) {
-	var results []*transcription.TranscriptionResult
+// transcribeAudioElements transcribes each audio element in its own language,
+// falling back to the subtitle element's and then the configured language.
+// Up to transcription.daemon.pool_size elements are transcribed in parallel;
+// results keep the order of audioElements.
+func (ss *service) transcribeAudioElements(ctx context.Context, audioElements []models.Element, subtitleLanguage string) ([]*transcription.TranscriptionResult, error) {
+	results := make([]*transcription.TranscriptionResult, len(audioElements))
+	parallel := make(chan struct{}, max(ss.cfg.Transcription.Daemon.PoolSize, 1))
+	var wg sync.WaitGroup
 
 	for i, audio := range audioElements {
-		ss.log.Debugf("Transcribing audio %d/%d: %s", i+1, len(audioElements), audio.Src)
-
-		result, err := ss.transcription.TranscribeAudio(ctx, audio.Src)
-		if err != nil {
-			ss.log.Warnf("Failed to transcribe audio %d: %v", i, err)
-			// Create failed result
-			result = &transcription.TranscriptionResult{
-				Text:    "",
-				Success: false,
-			}
+		language := audio.Language
+		if language == "" {
+			language = subtitleLanguage
 		}
 
-		results = append(results, result)
+		wg.Add(1)
+		parallel <- struct{}{}
+		go func(i int, src, language string) {
+			defer wg.Done()
+			defer func() { <-parallel }()
+
+			ss.log.Debugf("Transcribing audio %d/%d: %s", i+1, len(audioElements), src)
+			result, err := ss.transcription.TranscribeAudio(ctx, src, language)
+			if err != nil {
+				ss.log.Warnf("Failed to transcribe audio %d: %v", i, err)
+				// Create failed result
+				result = &transcription.TranscriptionResult{
+					Text:    "",
+					Success: false,
+				}
+			}
+			results[i] = result
+		}(i, audio.Src, language)
 	}
+	wg.Wait()
 
 	return results, nil
 }
 
+// generateWindowedEvents generates events in the given style and restricts them
+// to the subtitle element's time window, if any
+func (ss *service) generateWindowedEvents(
+	project models.VideoProject,
+	subtitleElement models.Element,
+	transcriptionResults []*transcription.TranscriptionResult,
+	audioElements []models.Element,
+	style string,
+) ([]SubtitleEvent, error) {
+	events, err := ss.generateSubtitleEvents(project, transcriptionResults, audioElements, style)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate subtitle events: %w", err)
+	}
+
+	if subtitleElement.Start != 0 || subtitleElement.End != 0 {
+		windowStart := time.Duration(subtitleElement.Start * float64(time.Second))
+		windowEnd := time.Duration(subtitleElement.End * float64(time.Second))
+		events = FilterEventsToWindow(events, windowStart, windowEnd)
+		ss.log.Debugf("Subtitle window %.2fs-%.2fs kept %d events", subtitleElement.Start, subtitleElement.End, len(events))
+	}
+
+	return events, nil
+}
+
 func (ss *service) generateSubtitleEvents(
 	project models.VideoProject,
 	transcriptionResults []*transcription.TranscriptionResult,
 	audioElements []models.Element,
+	style string,
 ) ([]SubtitleEvent, error) {
 	var allEvents []SubtitleEvent
 
+	settings := ss.extractSubtitleSettings(project)
+	readingSpeed := ss.resolveReadingSpeed(settings)
+	lineWrap := ss.resolveLineWrap(settings)
+	sceneStyles := ss.sceneStyleNames(project)
+	normalization := ss.textNormalization()
+
 	// Calculate scene timings based on actual audio durations (like Python implementation)
-	sceneTimings, err := ss.calculateSceneTimings(transcriptionResults, audioElements)
+	sceneTimings, err := ss.calculateSceneTimings(project, transcriptionResults, audioElements)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate scene timings: %w", err)
 	}
@@ -216,22 +332,39 @@ func (ss *service) generateSubtitleEvents(
 		var events []SubtitleEvent
 
 		// Generate events based on style
-		if ss.cfg.Subtitles.Style == subtitleStyleProgressive && len(transcriptionResult.WordTimestamps) > 0 {
-			// Progressive style - word by word with scene timing
+		wordLevel := style == subtitleStyleProgressive || style == subtitleStyleKaraoke
+		if wordLevel && len(transcriptionResult.WordTimestamps) > 0 {
+			// Progressive and karaoke styles - word timing within the scene
 			words := make([]WordTimestamp, len(transcriptionResult.WordTimestamps))
 			for j, wt := range transcriptionResult.WordTimestamps {
 				words[j] = WordTimestamp{
-					Word:  wt.Word,
-					Start: wt.Start,
-					End:   wt.End,
+					Word:       wt.Word,
+					Start:      wt.Start,
+					End:        wt.End,
+					Confidence: wt.Probability,
 				}
 			}
-			events = CreateProgressiveEventsWithSceneTiming(words, sceneTiming)
+			words = FilterLowConfidenceWords(words, ss.cfg.Subtitles.MinWordConfidence, ss.cfg.Subtitles.LowConfidenceMode)
+			words = NormalizeWords(words, normalization)
+			if style == subtitleStyleKaraoke {
+				events = CreateKaraokeEventsWithSceneTiming(words, sceneTiming)
+			} else {
+				events = CreateProgressiveEventsWithSceneTiming(words, sceneTiming)
+			}
 		} else {
 			// Classic style - full text at once
 			sceneStartTime := time.Duration(sceneTiming.StartTime * float64(time.Second))
 			sceneDuration := time.Duration((sceneTiming.EndTime - sceneTiming.StartTime) * float64(time.Second))
-			events = CreateClassicEvents(transcriptionResult.Text, sceneStartTime, sceneDuration)
+			text := NormalizeText(transcriptionResult.Text, normalization)
+			events = CreateClassicEvents(text, sceneStartTime, sceneDuration, readingSpeed)
+			events = WrapEvents(events, lineWrap)
+		}
+
+		// Assign the scene's style override, if any
+		if i < len(sceneStyles) && sceneStyles[i] != "" {
+			for j := range events {
+				events[j].Style = sceneStyles[i]
+			}
 		}
 
 		allEvents = append(allEvents, events...)
@@ -240,12 +373,137 @@ func (ss *service) generateSubtitleEvents(
 	return allEvents, nil
 }
 
-func (ss *service) calculateSceneTimings(transcriptionResults []*transcription.TranscriptionResult, audioElements []models.Element) ([]models.TimingSegment, error) {
-	ss.log.Debug("Calculating scene timings based on actual audio file durations (like Python ffprobe)")
+// textNormalization returns the configured transcription text cleanup rules
+func (ss *service) textNormalization() TextNormalization {
+	return TextNormalization{
+		CollapseSpaces:      ss.cfg.Subtitles.CollapseSpaces,
+		FixPunctuationSpace: ss.cfg.Subtitles.FixPunctuationSpacing,
+		SentenceCase:        ss.cfg.Subtitles.SentenceCase,
+	}
+}
 
-	var timings []models.TimingSegment
-	currentTime := 0.0
+// mergeScaleSettings combines project and scene settings for outline scaling:
+// a scene inherits the project's scaling flag and explicit outline values
+func mergeScaleSettings(project, scene models.SubtitleSettings) models.SubtitleSettings {
+	merged := scene
+	merged.ScaleOutlineWithFont = project.ScaleOutlineWithFont || scene.ScaleOutlineWithFont
+	if merged.OutlineWidth == 0 {
+		merged.OutlineWidth = project.OutlineWidth
+	}
+	if merged.ShadowOffset == 0 {
+		merged.ShadowOffset = project.ShadowOffset
+	}
+	return merged
+}
+
+// sceneStyleName returns the ASS style name used for a scene's override
+func sceneStyleName(sceneIndex int) string {
+	return fmt.Sprintf("Scene%d", sceneIndex+1)
+}
+
+// sceneStyleNames maps each audio element (in collection order) to the style
+// name of its scene's subtitle override, or "" when the scene has none
+func (ss *service) sceneStyleNames(project models.VideoProject) []string {
+	var names []string
+	for sceneIdx, scene := range project.Scenes {
+		name := ""
+		if scene.SubtitleSettings != (models.SubtitleSettings{}) {
+			name = sceneStyleName(sceneIdx)
+		}
+		for _, element := range scene.Elements {
+			if element.Type == "audio" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// validateSubtitleWindow ensures the subtitle window starts within the analyzed audio duration
+func (ss *service) validateSubtitleWindow(element models.Element, audioElements []models.Element) error {
+	var totalDuration float64
+	for _, audio := range audioElements {
+		totalDuration += audio.Duration
+	}
+
+	// Durations are unknown until media analysis ran; skip the bounds check in that case
+	if totalDuration <= 0 {
+		return nil
+	}
+
+	if element.Start >= totalDuration {
+		return errors.InvalidInput(fmt.Sprintf("subtitle window start %.2fs is beyond the total duration %.2fs", element.Start, totalDuration))
+	}
+
+	return nil
+}
+
+// resolveReadingSpeed returns the classic caption pace, JSON settings taking precedence over global config
+func (ss *service) resolveReadingSpeed(settings models.SubtitleSettings) ReadingSpeed {
+	speed := ReadingSpeed{
+		Rate: ss.cfg.Subtitles.ReadingSpeed,
+		Unit: ss.cfg.Subtitles.ReadingSpeedUnit,
+	}
+
+	if settings.ReadingSpeed != 0 {
+		speed.Rate = settings.ReadingSpeed
+	}
+	if settings.ReadingSpeedUnit != "" {
+		speed.Unit = settings.ReadingSpeedUnit
+	}
+	if speed.Unit == "" {
+		speed.Unit = ReadingSpeedUnitWords
+	}
+
+	return speed
+}
+
+// resolveLineWrap returns the classic caption wrapping, JSON settings taking precedence over global config
+func (ss *service) resolveLineWrap(settings models.SubtitleSettings) LineWrap {
+	wrap := LineWrap{
+		MaxLineLength: ss.cfg.Subtitles.MaxLineLength,
+		MaxLines:      ss.cfg.Subtitles.MaxLines,
+		Overflow:      ss.cfg.Subtitles.LineOverflow,
+	}
+
+	if settings.MaxLineLength != 0 {
+		wrap.MaxLineLength = settings.MaxLineLength
+	}
+	if settings.MaxLines != 0 {
+		wrap.MaxLines = settings.MaxLines
+	}
+	if settings.LineOverflow != "" {
+		wrap.Overflow = settings.LineOverflow
+	}
+
+	return wrap
+}
 
+// validateReadingSpeed checks the reading speed is within a humanly readable range for its unit
+func (ss *service) validateReadingSpeed(rate float64, unit string) error {
+	if unit != "" && unit != ReadingSpeedUnitWords && unit != ReadingSpeedUnitChars {
+		return errors.InvalidInput("reading speed unit must be 'words' or 'chars'")
+	}
+
+	if rate == 0 {
+		return nil
+	}
+
+	if unit == ReadingSpeedUnitChars {
+		if rate < 2 || rate > 50 {
+			return errors.InvalidInput("reading speed must be between 2 and 50 characters per second")
+		}
+	} else if rate < 0.5 || rate > 10 {
+		return errors.InvalidInput("reading speed must be between 0.5 and 10 words per second")
+	}
+
+	return nil
+}
+
+func (ss *service) calculateSceneTimings(project models.VideoProject, transcriptionResults []*transcription.TranscriptionResult, audioElements []models.Element) ([]models.TimingSegment, error) {
+	ss.log.Debug("Calculating scene timings based on actual audio file durations (like Python ffprobe)")
+
+	durations := make([]float64, len(transcriptionResults))
 	for i := range transcriptionResults {
 		// Get REAL audio file duration using AudioService (like Python ffprobe)
 		var duration float64
@@ -264,6 +522,20 @@ func (ss *service) calculateSceneTimings(transcriptionResults []*transcription.T
 		} else {
 			duration = 30.0 // Default fallback
 		}
+		durations[i] = duration
+	}
+
+	// Crossfaded scenes start while the previous narration fades out, like
+	// the engine's audio track
+	crossfades := project.AudioCrossfades(durations, ss.cfg.Audio.CrossfadeSeconds)
+
+	var timings []models.TimingSegment
+	currentTime := 0.0
+
+	for i, duration := range durations {
+		if i > 0 && i-1 < len(crossfades) {
+			currentTime -= crossfades[i-1]
+		}
 
 		timing := models.TimingSegment{
 			StartTime: currentTime,
@@ -292,7 +564,7 @@ func (ss *service) getAudioDuration(ctx context.Context, audioURL string) (*audi
 // Use createASSFileWithSettings for new implementations that need JSON SubtitleSettings support
 func (ss *service) createASSFile(events []SubtitleEvent) (string, error) {
 	// For backward compatibility, delegate to new method with empty settings (uses global config)
-	return ss.createASSFileWithSettings(events, models.SubtitleSettings{})
+	return ss.createASSFileWithSettings(events, models.SubtitleSettings{}, nil)
 }
 
 func (ss *service) ValidateSubtitleConfig(project models.VideoProject) error {
@@ -315,8 +587,12 @@ func (ss *service) ValidateSubtitleConfig(project models.VideoProject) error {
 	}
 
 	// Validate style
-	if ss.cfg.Subtitles.Style != "progressive" && ss.cfg.Subtitles.Style != "classic" {
-		return errors.InvalidInput("subtitle style must be 'progressive' or 'classic'")
+	if !isValidSubtitleStyle(ss.cfg.Subtitles.Style) {
+		return errors.InvalidInput("subtitle style must be 'progressive', 'classic' or 'karaoke'")
+	}
+
+	if err := ss.validateReadingSpeed(ss.cfg.Subtitles.ReadingSpeed, ss.cfg.Subtitles.ReadingSpeedUnit); err != nil {
+		return err
 	}
 
 	return nil
@@ -377,9 +653,10 @@ func (ss *service) extractSubtitleSettings(project models.VideoProject) models.S
 // createASSFileWithSettings creates ASS file using provided SubtitleSettings
 // This method replaces the original createASSFile to support JSON subtitle configuration
 // The provided settings are merged with global config before ASS generation
-func (ss *service) createASSFileWithSettings(events []SubtitleEvent, settings models.SubtitleSettings) (string, error) {
+// Scenes with subtitle overrides get an additional style layered on top of the merged settings
+func (ss *service) createASSFileWithSettings(events []SubtitleEvent, settings models.SubtitleSettings, scenes []models.Scene) (string, error) {
 	// Ensure temp directory exists
-	if err := os.MkdirAll(ss.cfg.Storage.TempDir, 0755); err != nil {
+	if err := os.MkdirAll(ss.cfg.Storage.TempDir, ss.cfg.Storage.DirPerm()); err != nil {
 		return "", fmt.Errorf("failed to create temp directory: %w", err)
 	}
 
@@ -387,20 +664,23 @@ func (ss *service) createASSFileWithSettings(events []SubtitleEvent, settings mo
 	filename := fmt.Sprintf("subtitles_%s.ass", uuid.New().String()[:8])
 	filePath := filepath.Join(ss.cfg.Storage.TempDir, filename)
 
-	// Merge JSON settings with global config to create ASS config
-	assConfig, err := ss.mergeSettingsWithGlobalConfig(settings)
+	assConfig, sceneStyles, err := ss.resolveStyles(settings, scenes)
 	if err != nil {
-		return "", fmt.Errorf("failed to merge settings: %w", err)
+		return "", err
 	}
+	ss.warnMissingFonts(assConfig, sceneStyles)
 
 	// Create ASS generator with merged configuration
 	generator := NewASSGenerator(assConfig)
+	for _, style := range sceneStyles {
+		generator.AddStyle(style.Name, style.Config)
+	}
 
 	// Generate ASS content
 	assContent := generator.GenerateASS(events)
 
 	// Write to file
-	if err := os.WriteFile(filePath, []byte(assContent), 0600); err != nil {
+	if err := os.WriteFile(filePath, []byte(assContent), ss.cfg.Storage.FilePerm()); err != nil {
 		return "", fmt.Errorf("failed to write ASS file: %w", err)
 	}
 
@@ -408,6 +688,30 @@ func (ss *service) createASSFileWithSettings(events []SubtitleEvent, settings mo
 	return filePath, nil
 }
 
+// resolveStyles merges the JSON settings with the global config into the
+// Default style and layers each scene's subtitle override on top of it
+func (ss *service) resolveStyles(settings models.SubtitleSettings, scenes []models.Scene) (ASSConfig, []NamedStyle, error) {
+	assConfig, err := ss.mergeSettingsWithGlobalConfig(settings)
+	if err != nil {
+		return ASSConfig{}, nil, fmt.Errorf("failed to merge settings: %w", err)
+	}
+
+	var sceneStyles []NamedStyle
+	for sceneIdx, scene := range scenes {
+		if scene.SubtitleSettings == (models.SubtitleSettings{}) {
+			continue
+		}
+		sceneConfig := ss.applyJSONSettingsOverrides(assConfig, scene.SubtitleSettings)
+		sceneConfig = ss.scaleOutlineWithFont(sceneConfig, mergeScaleSettings(settings, scene.SubtitleSettings))
+		if err := ss.validateMergedConfig(sceneConfig); err != nil {
+			return ASSConfig{}, nil, fmt.Errorf("invalid subtitle settings for scene %q: %w", scene.ID, err)
+		}
+		sceneStyles = append(sceneStyles, NamedStyle{Name: sceneStyleName(sceneIdx), Config: sceneConfig})
+	}
+
+	return assConfig, sceneStyles, nil
+}
+
 // mergeSettingsWithGlobalConfig merges JSON SubtitleSettings with global config
 // JSON settings take precedence over global config, with global config as fallback
 func (ss *service) mergeSettingsWithGlobalConfig(jsonSettings models.SubtitleSettings) (ASSConfig, error) {
@@ -429,6 +733,14 @@ func (ss *service) mergeSettingsWithGlobalConfig(jsonSettings models.SubtitleSet
 		LineColor:    ss.cfg.Subtitles.Colors.Word, // Default line color same as word color
 		ShadowColor:  "#808080",                    // TODO: Add ShadowColor to global config to avoid hard-coded defaults
 		BoxColor:     "#000000",                    // TODO: Add BoxColor to global config to avoid hard-coded defaults
+
+		MarginLeft:     defaultMarginLeft,
+		MarginRight:    defaultMarginRight,
+		MarginVertical: defaultMarginVertical,
+
+		EmojiHandling:    ss.cfg.Subtitles.EmojiHandling,
+		EmojiReplacement: ss.cfg.Subtitles.EmojiReplacement,
+		FallbackFonts:    ss.cfg.Subtitles.FallbackFonts,
 	}
 
 	// Use helper function to override with JSON settings where provided
@@ -439,6 +751,8 @@ func (ss *service) mergeSettingsWithGlobalConfig(jsonSettings models.SubtitleSet
 		config.LineColor = config.WordColor
 	}
 
+	config = ss.scaleOutlineWithFont(config, jsonSettings)
+
 	// Validate merged configuration
 	if err := ss.validateMergedConfig(config); err != nil {
 		return config, fmt.Errorf("invalid merged subtitle configuration: %w", err)
@@ -448,6 +762,32 @@ func (ss *service) mergeSettingsWithGlobalConfig(jsonSettings models.SubtitleSet
 	return config, nil
 }
 
+// scaleOutlineWithFont derives outline width and shadow offset from the font
+// size when scaling is enabled globally or in the JSON settings, so the look
+// stays proportional across resolutions. Explicit JSON values still win.
+func (ss *service) scaleOutlineWithFont(config ASSConfig, jsonSettings models.SubtitleSettings) ASSConfig {
+	if !ss.cfg.Subtitles.ScaleOutlineWithFont && !jsonSettings.ScaleOutlineWithFont {
+		return config
+	}
+
+	if jsonSettings.OutlineWidth == 0 {
+		config.OutlineWidth = scaledPixels(config.FontSize, ss.cfg.Subtitles.OutlineRatio)
+	}
+	if jsonSettings.ShadowOffset == 0 {
+		config.ShadowOffset = scaledPixels(config.FontSize, ss.cfg.Subtitles.ShadowRatio)
+	}
+
+	return config
+}
+
+// scaledPixels returns fontSize*ratio rounded, never below 1px for a positive ratio
+func scaledPixels(fontSize int, ratio float64) int {
+	if ratio <= 0 {
+		return 0
+	}
+	return max(1, int(math.Round(float64(fontSize)*ratio)))
+}
+
 // applyJSONSettingsOverrides applies non-empty JSON settings to the base config
 func (ss *service) applyJSONSettingsOverrides(baseConfig ASSConfig, jsonSettings models.SubtitleSettings) ASSConfig {
 	config := baseConfig
@@ -477,6 +817,20 @@ func (ss *service) applyJSONSettingsOverrides(baseConfig ASSConfig, jsonSettings
 	if jsonSettings.BoxColor != "" {
 		config.BoxColor = jsonSettings.BoxColor
 	}
+	if jsonSettings.FallbackFonts != "" {
+		config.FallbackFonts = SplitFontStack(jsonSettings.FallbackFonts)
+	}
+	if jsonSettings.ScaledBorderAndShadow != nil {
+		config.ScaledBorderAndShadow = jsonSettings.ScaledBorderAndShadow
+	}
+	if jsonSettings.TextDirection != "" {
+		config.TextDirection = jsonSettings.TextDirection
+	}
+
+	// Style flags: override if set, so false can turn bold off
+	config.Bold = firstNonNil(jsonSettings.Bold, config.Bold)
+	config.Italic = firstNonNil(jsonSettings.Italic, config.Italic)
+	config.Underline = firstNonNil(jsonSettings.Underline, config.Underline)
 
 	// Integer fields: override if non-zero
 	if jsonSettings.FontSize != 0 {
@@ -488,6 +842,15 @@ func (ss *service) applyJSONSettingsOverrides(baseConfig ASSConfig, jsonSettings
 	if jsonSettings.ShadowOffset != 0 {
 		config.ShadowOffset = jsonSettings.ShadowOffset
 	}
+	if jsonSettings.MarginLeft != 0 {
+		config.MarginLeft = jsonSettings.MarginLeft
+	}
+	if jsonSettings.MarginRight != 0 {
+		config.MarginRight = jsonSettings.MarginRight
+	}
+	if jsonSettings.MarginVertical != 0 {
+		config.MarginVertical = jsonSettings.MarginVertical
+	}
 
 	return config
 }
@@ -509,6 +872,11 @@ func (ss *service) validateMergedConfig(config ASSConfig) error {
 		return errors.InvalidInput("shadow offset must be between 0 and 20")
 	}
 
+	// Validate margins
+	if err := validateMargins(config.MarginLeft, config.MarginRight, config.MarginVertical); err != nil {
+		return err
+	}
+
 	// Validate colors if they look like hex colors
 	colorFields := map[string]string{
 		"word_color":    config.WordColor,
@@ -527,15 +895,50 @@ func (ss *service) validateMergedConfig(config ASSConfig) error {
 	return nil
 }
 
+// maxSubtitleMargin bounds subtitle margins in pixels
+const maxSubtitleMargin = 1000
+
+// validateMargins checks the left, right and vertical margins are within range
+func validateMargins(left, right, vertical int) error {
+	for name, margin := range map[string]int{"left": left, "right": right, "vertical": vertical} {
+		if margin < 0 || margin > maxSubtitleMargin {
+			return errors.InvalidInput(fmt.Sprintf("%s margin must be between 0 and %d pixels", name, maxSubtitleMargin))
+		}
+	}
+	return nil
+}
+
 // ValidateJSONSubtitleSettings validates SubtitleSettings from JSON
 func (ss *service) ValidateJSONSubtitleSettings(project models.VideoProject) error {
 	settings := ss.extractSubtitleSettings(project)
 
 	// If no subtitle settings found, validation passes
-	if settings == (models.SubtitleSettings{}) {
-		return nil
+	if settings != (models.SubtitleSettings{}) {
+		if err := ss.validateSubtitleSettings(settings); err != nil {
+			return err
+		}
+	}
+
+	// Scene overrides only restyle captions; caption timing stays project-wide
+	for _, scene := range project.Scenes {
+		override := scene.SubtitleSettings
+		if override == (models.SubtitleSettings{}) {
+			continue
+		}
+		if override.Style != "" || override.ReadingSpeed != 0 || override.ReadingSpeedUnit != "" || override.FallbackFonts != "" ||
+			override.ScaledBorderAndShadow != nil || override.MaxLineLength != 0 || override.MaxLines != 0 || override.LineOverflow != "" {
+			return errors.InvalidInput(fmt.Sprintf("scene %q: subtitle overrides only support visual settings", scene.ID))
+		}
+		if err := ss.validateSubtitleSettings(override); err != nil {
+			return errors.InvalidInput(fmt.Sprintf("scene %q: %s", scene.ID, err.Error()))
+		}
 	}
 
+	return nil
+}
+
+// validateSubtitleSettings validates a single set of JSON subtitle settings
+func (ss *service) validateSubtitleSettings(settings models.SubtitleSettings) error {
 	// Validate font size
 	if settings.FontSize != 0 && (settings.FontSize < 10 || settings.FontSize > 200) {
 		return errors.InvalidInput("font size must be between 10 and 200")
@@ -570,9 +973,53 @@ func (ss *service) ValidateJSONSubtitleSettings(project models.VideoProject) err
 		}
 	}
 
+	// Validate fallback fonts (if provided)
+	if settings.FallbackFonts != "" {
+		fonts := SplitFontStack(settings.FallbackFonts)
+		if len(fonts) == 0 {
+			return errors.InvalidInput("fallback fonts cannot be empty")
+		}
+		for _, font := range fonts {
+			if err := validateFontName(font); err != nil {
+				return errors.InvalidInput(fmt.Sprintf("invalid fallback font: %v", err))
+			}
+		}
+	}
+
 	// Validate style (if provided)
-	if settings.Style != "" && settings.Style != "progressive" && settings.Style != "classic" {
-		return errors.InvalidInput("subtitle style must be 'progressive' or 'classic'")
+	if settings.Style != "" && !isValidSubtitleStyle(settings.Style) {
+		return errors.InvalidInput("subtitle style must be 'progressive', 'classic' or 'karaoke'")
+	}
+
+	// Validate reading speed (if provided)
+	if settings.ReadingSpeed < 0 {
+		return errors.InvalidInput("reading speed cannot be negative")
+	}
+	if err := ss.validateReadingSpeed(settings.ReadingSpeed, settings.ReadingSpeedUnit); err != nil {
+		return err
+	}
+
+	// Validate margins (if provided)
+	if err := validateMargins(settings.MarginLeft, settings.MarginRight, settings.MarginVertical); err != nil {
+		return err
+	}
+
+	// Validate text direction (if provided)
+	switch settings.TextDirection {
+	case "", TextDirectionAuto, TextDirectionLTR, TextDirectionRTL:
+	default:
+		return errors.InvalidInput("text direction must be 'ltr', 'rtl' or 'auto'")
+	}
+
+	// Validate line wrapping (if provided)
+	if settings.MaxLineLength != 0 && (settings.MaxLineLength < app.MinSubtitleLineLength || settings.MaxLineLength > app.MaxSubtitleLineLength) {
+		return errors.InvalidInput(fmt.Sprintf("max line length must be between %d and %d characters", app.MinSubtitleLineLength, app.MaxSubtitleLineLength))
+	}
+	if settings.MaxLines < 0 || settings.MaxLines > app.MaxSubtitleLines {
+		return errors.InvalidInput(fmt.Sprintf("max lines must be between 1 and %d", app.MaxSubtitleLines))
+	}
+	if settings.LineOverflow != "" && settings.LineOverflow != app.LineOverflowSplit && settings.LineOverflow != app.LineOverflowTruncate {
+		return errors.InvalidInput("line overflow must be 'split' or 'truncate'")
 	}
 
 	return nil
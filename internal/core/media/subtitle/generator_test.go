@@ -0,0 +1,1046 @@
+package subtitle
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/core/media/audio"
+	"github.com/activadee/videocraft/internal/core/services/transcription"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+// fakeTranscription returns canned transcriptions by audio URL and, when
+// languages is set, records the language each URL was transcribed in
+type fakeTranscription struct {
+	results   map[string]*transcription.TranscriptionResult
+	languages map[string]string
+}
+
+func (f fakeTranscription) TranscribeAudio(_ context.Context, url, language string) (*transcription.TranscriptionResult, error) {
+	if f.languages != nil {
+		f.languages[url] = language
+	}
+	result, ok := f.results[url]
+	if !ok {
+		return nil, fmt.Errorf("no transcription for %s", url)
+	}
+	return result, nil
+}
+
+func (fakeTranscription) StartDaemon() error                   { return nil }
+func (fakeTranscription) StopDaemon() error                    { return nil }
+func (fakeTranscription) HealthCheck() error                   { return nil }
+func (fakeTranscription) ReadinessCheck(context.Context) error { return nil }
+func (fakeTranscription) Shutdown()                            {}
+
+// fakeAudio reports canned durations by audio URL
+type fakeAudio struct {
+	durations map[string]float64
+}
+
+func (f fakeAudio) AnalyzeAudio(_ context.Context, url string) (*audio.AudioInfo, error) {
+	duration, ok := f.durations[url]
+	if !ok {
+		return nil, fmt.Errorf("no duration for %s", url)
+	}
+	return &audio.AudioInfo{URL: url, Duration: duration}, nil
+}
+
+func (fakeAudio) CalculateSceneTiming([]models.Element) ([]models.TimingSegment, error) {
+	return nil, nil
+}
+
+func (fakeAudio) DownloadAudio(context.Context, string) (string, error) { return "", nil }
+
+// narration is a scene's audio clip and what Whisper hears in it
+type narration struct {
+	src      string
+	duration float64
+	result   *transcription.TranscriptionResult
+}
+
+// newTestConfig returns a config rendering classic captions in a plain style
+func newTestConfig(t *testing.T) *app.Config {
+	t.Helper()
+	cfg := &app.Config{}
+	cfg.Subtitles.Enabled = true
+	cfg.Subtitles.Style = subtitleStyleClassic
+	cfg.Subtitles.FontFamily = "Arial"
+	cfg.Subtitles.FontSize = 24
+	cfg.Subtitles.Position = "center-bottom"
+	cfg.Subtitles.Colors.Word = "#FFFFFF"
+	cfg.Subtitles.Colors.Outline = "#000000"
+	cfg.Storage.TempDir = t.TempDir()
+	return cfg
+}
+
+// newTestService returns a subtitle service transcribing and timing the given narrations
+func newTestService(cfg *app.Config, narrations ...narration) *service {
+	results := make(map[string]*transcription.TranscriptionResult, len(narrations))
+	durations := make(map[string]float64, len(narrations))
+	for _, n := range narrations {
+		results[n.src] = n.result
+		durations[n.src] = n.duration
+	}
+	log := logger.NewWithWriter("error", io.Discard, "text")
+	return NewService(cfg, log, fakeTranscription{results: results}, fakeAudio{durations: durations}).(*service)
+}
+
+// spoken returns a transcription of text whose words each take wordSeconds, back to back from 0
+func spoken(text string, wordSeconds float64) *transcription.TranscriptionResult {
+	result := &transcription.TranscriptionResult{Text: text, Success: true}
+	for i, word := range strings.Fields(text) {
+		start := float64(i) * wordSeconds
+		result.WordTimestamps = append(result.WordTimestamps, transcription.WhisperWordTimestamp{
+			Word: word, Start: start, End: start + wordSeconds,
+		})
+	}
+	return result
+}
+
+// newSubtitledProject returns a project with one scene per narration and a subtitle element
+func newSubtitledProject(settings models.SubtitleSettings, narrations ...narration) models.VideoProject {
+	project := models.VideoProject{
+		Elements: []models.Element{{Type: "subtitles", Settings: settings}},
+	}
+	for i, n := range narrations {
+		project.Scenes = append(project.Scenes, models.Scene{
+			ID:       fmt.Sprintf("scene%d", i+1),
+			Elements: []models.Element{{Type: "audio", Src: n.src, Duration: n.duration}},
+		})
+	}
+	return project
+}
+
+// generateFile runs GenerateSubtitles and returns the content of the written file
+func generateFile(t *testing.T, ss *service, project models.VideoProject) string {
+	t.Helper()
+	result, err := ss.GenerateSubtitles(context.Background(), project)
+	if err != nil {
+		t.Fatalf("GenerateSubtitles() error = %v", err)
+	}
+	content, err := os.ReadFile(result.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// dialogues returns the Dialogue lines of an ASS document
+func dialogues(ass string) []string {
+	var lines []string
+	for _, line := range strings.Split(ass, "\n") {
+		if strings.HasPrefix(line, "Dialogue: ") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// styleLines returns the Style lines of an ASS document
+func styleLines(ass string) []string {
+	var lines []string
+	for _, line := range strings.Split(ass, "\n") {
+		if strings.HasPrefix(line, "Style: ") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// compareLines reports the difference between got and want line by line
+func compareLines(t *testing.T, what string, got, want []string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("%s =\n%s\nwant\n%s", what, strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestGenerateSubtitlesPacesClassicCaptions(t *testing.T) {
+	intro := narration{src: "intro.mp3", duration: 6, result: spoken("one two three four five six seven eight nine ten", 0.5)}
+
+	tests := []struct {
+		name     string
+		settings models.SubtitleSettings
+		want     []string
+	}{
+		{
+			name: "whole scene without a reading speed",
+			want: []string{"Dialogue: 0,0:00:00.00,0:00:06.00,Default,,0,0,0,,one two three four five six seven eight nine ten"},
+		},
+		{
+			name:     "chunked at the reading speed",
+			settings: models.SubtitleSettings{ReadingSpeed: 2},
+			want: []string{
+				"Dialogue: 0,0:00:00.00,0:00:03.00,Default,,0,0,0,,one two three four five six",
+				"Dialogue: 0,0:00:03.00,0:00:06.00,Default,,0,0,0,,seven eight nine ten",
+			},
+		},
+		{
+			name:     "compressed into the scene",
+			settings: models.SubtitleSettings{ReadingSpeed: 1},
+			want: []string{
+				"Dialogue: 0,0:00:00.00,0:00:01.80,Default,,0,0,0,,one two three",
+				"Dialogue: 0,0:00:01.80,0:00:03.60,Default,,0,0,0,,four five six",
+				"Dialogue: 0,0:00:03.60,0:00:05.40,Default,,0,0,0,,seven eight nine",
+				"Dialogue: 0,0:00:05.40,0:00:06.00,Default,,0,0,0,,ten",
+			},
+		},
+		{
+			name:     "paced by characters",
+			settings: models.SubtitleSettings{ReadingSpeed: 15, ReadingSpeedUnit: ReadingSpeedUnitChars},
+			want: []string{
+				"Dialogue: 0,0:00:00.00,0:00:02.93,Default,,0,0,0,,one two three four five six seven eight nine",
+				"Dialogue: 0,0:00:02.93,0:00:06.00,Default,,0,0,0,,ten",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := newTestService(newTestConfig(t), intro)
+			ass := generateFile(t, ss, newSubtitledProject(tt.settings, intro))
+			compareLines(t, "dialogues", dialogues(ass), tt.want)
+		})
+	}
+}
+
+func TestGenerateSubtitlesWritesASSDocument(t *testing.T) {
+	intro := narration{src: "intro.mp3", duration: 2, result: spoken("Hello {world}", 1)}
+	ss := newTestService(newTestConfig(t), intro)
+
+	want := `[Script Info]
+Title: Generated Classic (classic) Subtitles
+ScriptType: v4.00+
+WrapStyle: 0
+ScaledBorderAndShadow: yes
+YCbCr Matrix: TV.709
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,Arial,24,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,1,0,0,0,100,100,0,0,1,2,1,2,10,10,20,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:00.00,0:00:02.00,Default,,0,0,0,,Hello \{world\}
+`
+	if got := generateFile(t, ss, newSubtitledProject(models.SubtitleSettings{}, intro)); got != want {
+		t.Errorf("ASS document =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerateSubtitlesWithinWindow(t *testing.T) {
+	intro := narration{src: "intro.mp3", duration: 4, result: spoken("one two three four", 1)}
+
+	tests := []struct {
+		name       string
+		style      string
+		start, end float64
+		want       []string
+	}{
+		{
+			name:  "progressive words clipped to the window",
+			style: subtitleStyleProgressive,
+			start: 1.5, end: 3.5,
+			want: []string{
+				"Dialogue: 0,0:00:01.50,0:00:02.00,Default,,0,0,0,,two",
+				"Dialogue: 0,0:00:02.00,0:00:03.00,Default,,0,0,0,,three",
+				"Dialogue: 0,0:00:03.00,0:00:03.50,Default,,0,0,0,,four",
+			},
+		},
+		{
+			name:  "open-ended window",
+			style: subtitleStyleProgressive,
+			start: 2,
+			want: []string{
+				"Dialogue: 0,0:00:02.00,0:00:03.00,Default,,0,0,0,,three",
+				"Dialogue: 0,0:00:03.00,0:00:04.00,Default,,0,0,0,,four",
+			},
+		},
+		{
+			name:  "karaoke highlight skips the time before the window",
+			style: subtitleStyleKaraoke,
+			start: 1.5,
+			want:  []string{`Dialogue: 0,0:00:01.50,0:00:04.00,Default,,0,0,0,,{\k0}one {\k50}two {\k100}three {\k100}four`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := newTestService(newTestConfig(t), intro)
+			project := newSubtitledProject(models.SubtitleSettings{Style: tt.style}, intro)
+			project.Elements[0].Start, project.Elements[0].End = tt.start, tt.end
+
+			compareLines(t, "dialogues", dialogues(generateFile(t, ss, project)), tt.want)
+		})
+	}
+}
+
+func TestGenerateSubtitlesRejectsWindowBeyondAudio(t *testing.T) {
+	intro := narration{src: "intro.mp3", duration: 4, result: spoken("one two three four", 1)}
+	ss := newTestService(newTestConfig(t), intro)
+	project := newSubtitledProject(models.SubtitleSettings{}, intro)
+	project.Elements[0].Start = 4
+
+	_, err := ss.GenerateSubtitles(context.Background(), project)
+
+	var vpe *errors.VideoProcessingError
+	if !stderrors.As(err, &vpe) || vpe.Code != errors.ErrCodeInvalidInput {
+		t.Errorf("GenerateSubtitles() error = %v, want invalid input", err)
+	}
+}
+
+func TestGenerateSubtitlesHandlesUnicode(t *testing.T) {
+	tests := []struct {
+		name        string
+		emoji       string
+		replacement string
+		text        string
+		want        string
+	}{
+		{"emoji kept by default", "", "", "Hi 👋🏽 there", "Hi 👋🏽 there"},
+		{"emoji stripped", "strip", "", "Hi 👋🏽 there ✨", "Hi there"},
+		{"joined emoji replaced once", "replace", "[emoji]", "Family 👨‍👩‍👧 time 🇩🇪!", "Family [emoji] time [emoji]!"},
+		{"non-breaking spaces kept", "", "", "100\u00a0km in 5\u202fmin", `100\hkm in 5\hmin`},
+		{"combining marks composed", "", "", "Cafe\u0301 cre\u0300me", "Caf\u00e9 cr\u00e8me"},
+		{"invalid UTF-8 dropped", "", "", "ok\xffay", "okay"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			cfg.Subtitles.EmojiHandling = tt.emoji
+			cfg.Subtitles.EmojiReplacement = tt.replacement
+			intro := narration{src: "intro.mp3", duration: 2, result: spoken(tt.text, 0.5)}
+			ss := newTestService(cfg, intro)
+
+			ass := generateFile(t, ss, newSubtitledProject(models.SubtitleSettings{}, intro))
+			compareLines(t, "dialogues", dialogues(ass), []string{"Dialogue: 0,0:00:00.00,0:00:02.00,Default,,0,0,0,," + tt.want})
+		})
+	}
+}
+
+func TestGenerateSubtitlesAppliesSceneStyleOverrides(t *testing.T) {
+	intro := narration{src: "intro.mp3", duration: 2, result: spoken("Welcome back", 1)}
+	quote := narration{src: "quote.mp3", duration: 3, result: spoken("Stay hungry stay foolish", 0.75)}
+	outro := narration{src: "outro.mp3", duration: 1, result: spoken("Bye", 1)}
+	ss := newTestService(newTestConfig(t), intro, quote, outro)
+
+	project := newSubtitledProject(models.SubtitleSettings{FontSize: 28}, intro, quote, outro)
+	project.Scenes[1].SubtitleSettings = models.SubtitleSettings{FontSize: 40, WordColor: "#FFFF00", Position: "center-top", OutlineWidth: 4}
+	ass := generateFile(t, ss, project)
+
+	compareLines(t, "styles", styleLines(ass), []string{
+		"Style: Default,Arial,28,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,1,0,0,0,100,100,0,0,1,2,1,2,10,10,20,1",
+		"Style: Scene2,Arial,40,&H0000FFFF,&H00FFFFFF,&H00000000,&H00000000,1,0,0,0,100,100,0,0,1,4,1,8,10,10,20,1",
+	})
+	compareLines(t, "dialogues", dialogues(ass), []string{
+		"Dialogue: 0,0:00:00.00,0:00:02.00,Default,,0,0,0,,Welcome back",
+		"Dialogue: 0,0:00:02.00,0:00:05.00,Scene2,,0,0,0,,Stay hungry stay foolish",
+		"Dialogue: 0,0:00:05.00,0:00:06.00,Default,,0,0,0,,Bye",
+	})
+}
+
+func TestValidateJSONSubtitleSettingsRestrictsSceneOverrides(t *testing.T) {
+	tests := []struct {
+		name     string
+		override models.SubtitleSettings
+		wantErr  bool
+	}{
+		{"visual settings", models.SubtitleSettings{FontSize: 40, BoxColor: "#101010", Position: "center-top"}, false},
+		{"caption style", models.SubtitleSettings{Style: subtitleStyleKaraoke}, true},
+		{"reading speed", models.SubtitleSettings{ReadingSpeed: 2}, true},
+		{"line wrapping", models.SubtitleSettings{MaxLineLength: 30}, true},
+		{"invalid color", models.SubtitleSettings{WordColor: "yellow"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := newTestService(newTestConfig(t))
+			project := newSubtitledProject(models.SubtitleSettings{})
+			project.Scenes = []models.Scene{{ID: "quote", SubtitleSettings: tt.override}}
+
+			if err := ss.ValidateJSONSubtitleSettings(project); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateJSONSubtitleSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateSubtitlesScalesOutlineWithFont(t *testing.T) {
+	intro := narration{src: "intro.mp3", duration: 2, result: spoken("Welcome back", 1)}
+	quote := narration{src: "quote.mp3", duration: 2, result: spoken("Big words", 1)}
+
+	tests := []struct {
+		name     string
+		global   bool
+		settings models.SubtitleSettings
+		want     []string
+	}{
+		{
+			name: "fixed outline by default",
+			want: []string{
+				"Style: Default,Arial,60,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,1,0,0,0,100,100,0,0,1,2,1,2,10,10,20,1",
+				"Style: Scene2,Arial,100,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,1,0,0,0,100,100,0,0,1,2,1,2,10,10,20,1",
+			},
+		},
+		{
+			name:   "scaled by config",
+			global: true,
+			want: []string{
+				"Style: Default,Arial,60,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,1,0,0,0,100,100,0,0,1,3,2,2,10,10,20,1",
+				"Style: Scene2,Arial,100,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,1,0,0,0,100,100,0,0,1,5,3,2,10,10,20,1",
+			},
+		},
+		{
+			name:     "scaled by the request with an explicit outline",
+			settings: models.SubtitleSettings{ScaleOutlineWithFont: true, OutlineWidth: 1},
+			want: []string{
+				"Style: Default,Arial,60,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,1,0,0,0,100,100,0,0,1,1,2,2,10,10,20,1",
+				"Style: Scene2,Arial,100,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,1,0,0,0,100,100,0,0,1,1,3,2,10,10,20,1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			cfg.Subtitles.FontSize = 60
+			cfg.Subtitles.ScaleOutlineWithFont = tt.global
+			cfg.Subtitles.OutlineRatio = 0.05
+			cfg.Subtitles.ShadowRatio = 0.03
+			ss := newTestService(cfg, intro, quote)
+
+			project := newSubtitledProject(tt.settings, intro, quote)
+			project.Scenes[1].SubtitleSettings = models.SubtitleSettings{FontSize: 100}
+			compareLines(t, "styles", styleLines(generateFile(t, ss, project)), tt.want)
+		})
+	}
+}
+
+func TestGenerateSubtitlesCapsEventCount(t *testing.T) {
+	intro := narration{src: "intro.mp3", duration: 3, result: spoken("one two three four five six", 0.5)}
+	outro := narration{src: "outro.mp3", duration: 1, result: spoken("bye", 1)}
+
+	tests := []struct {
+		name      string
+		maxEvents int
+		policy    string
+		wantStyle string
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "within the limit",
+			maxEvents: 7,
+			policy:    app.MaxEventsPolicyFail,
+			wantStyle: subtitleStyleProgressive,
+			want: []string{
+				"Dialogue: 0,0:00:00.00,0:00:00.50,Default,,0,0,0,,one",
+				"Dialogue: 0,0:00:00.50,0:00:01.00,Default,,0,0,0,,two",
+				"Dialogue: 0,0:00:01.00,0:00:01.50,Default,,0,0,0,,three",
+				"Dialogue: 0,0:00:01.50,0:00:02.00,Default,,0,0,0,,four",
+				"Dialogue: 0,0:00:02.00,0:00:02.50,Default,,0,0,0,,five",
+				"Dialogue: 0,0:00:02.50,0:00:03.00,Default,,0,0,0,,six",
+				"Dialogue: 0,0:00:03.00,0:00:04.00,Default,,0,0,0,,bye",
+			},
+		},
+		{
+			name:      "falls back to classic captions",
+			maxEvents: 3,
+			policy:    app.MaxEventsPolicyClassic,
+			wantStyle: subtitleStyleClassic,
+			want: []string{
+				"Dialogue: 0,0:00:00.00,0:00:03.00,Default,,0,0,0,,one two three four five six",
+				"Dialogue: 0,0:00:03.00,0:00:04.00,Default,,0,0,0,,bye",
+			},
+		},
+		{name: "fails over the limit", maxEvents: 3, policy: app.MaxEventsPolicyFail, wantErr: true},
+		{name: "classic captions over the limit", maxEvents: 1, policy: app.MaxEventsPolicyClassic, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			cfg.Subtitles.MaxEvents = tt.maxEvents
+			cfg.Subtitles.MaxEventsPolicy = tt.policy
+			ss := newTestService(cfg, intro, outro)
+			project := newSubtitledProject(models.SubtitleSettings{Style: subtitleStyleProgressive}, intro, outro)
+
+			result, err := ss.GenerateSubtitles(context.Background(), project)
+			if tt.wantErr {
+				var vpe *errors.VideoProcessingError
+				if !stderrors.As(err, &vpe) || vpe.Code != errors.ErrCodeInvalidInput {
+					t.Fatalf("GenerateSubtitles() error = %v, want invalid input", err)
+				}
+				if entries, _ := os.ReadDir(cfg.Storage.TempDir); len(entries) != 0 {
+					t.Errorf("rejected subtitles left %d files", len(entries))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateSubtitles() error = %v", err)
+			}
+
+			if result.Style != tt.wantStyle || result.EventCount != len(tt.want) {
+				t.Errorf("result style %s with %d events, want %s with %d", result.Style, result.EventCount, tt.wantStyle, len(tt.want))
+			}
+			content, err := os.ReadFile(result.FilePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compareLines(t, "dialogues", dialogues(string(content)), tt.want)
+		})
+	}
+}
+
+func TestGenerateSubtitlesSwitchesToFallbackFonts(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   []string
+		settings models.SubtitleSettings
+		text     string
+		want     string
+	}{
+		{"no fallback fonts", nil, models.SubtitleSettings{}, "Hello 世界, friends", "Hello 世界, friends"},
+		{"configured fallback", []string{"Noto Sans CJK", "Noto Sans Arabic"}, models.SubtitleSettings{},
+			"Hello 世界, friends", `Hello {\fnNoto Sans CJK}世界, {\fn}friends`},
+		{"request fallback wins", []string{"Noto Sans CJK"}, models.SubtitleSettings{FallbackFonts: "Noto Sans Arabic, Noto Sans"},
+			"Say مرحبا now", `Say {\fnNoto Sans Arabic}مرحبا {\fn}now`},
+		{"Latin-only text", []string{"Noto Sans CJK"}, models.SubtitleSettings{}, "Crème brûlée", "Crème brûlée"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			cfg.Subtitles.FallbackFonts = tt.config
+			intro := narration{src: "intro.mp3", duration: 2, result: spoken(tt.text, 0.5)}
+			ss := newTestService(cfg, intro)
+
+			ass := generateFile(t, ss, newSubtitledProject(tt.settings, intro))
+			compareLines(t, "dialogues", dialogues(ass), []string{"Dialogue: 0,0:00:00.00,0:00:02.00,Default,,0,0,0,," + tt.want})
+		})
+	}
+}
+
+func TestValidateJSONSubtitleSettingsRejectsInvalidFallbackFonts(t *testing.T) {
+	for _, fonts := range []string{" , ", "Noto{Sans}", `Noto\Sans`, strings.Repeat("x", 129)} {
+		ss := newTestService(newTestConfig(t))
+		project := newSubtitledProject(models.SubtitleSettings{FallbackFonts: fonts})
+		if err := ss.ValidateJSONSubtitleSettings(project); err == nil {
+			t.Errorf("ValidateJSONSubtitleSettings() accepted fallback fonts %q", fonts)
+		}
+	}
+}
+
+// rawWhisper returns a transcription with Whisper's spacing: leading spaces
+// and punctuation transcribed as separate words
+func rawWhisper() *transcription.TranscriptionResult {
+	return &transcription.TranscriptionResult{
+		Text:    "  hello ,  world .  how are you ?",
+		Success: true,
+		WordTimestamps: []transcription.WhisperWordTimestamp{
+			{Word: " hello", Start: 0, End: 0.5},
+			{Word: " ,", Start: 0.5, End: 0.6},
+			{Word: " world", Start: 0.6, End: 1},
+			{Word: " .", Start: 1, End: 1.1},
+			{Word: " how", Start: 1.5, End: 1.8},
+			{Word: " are", Start: 1.8, End: 2},
+			{Word: " you", Start: 2, End: 2.4},
+			{Word: " ?", Start: 2.4, End: 2.5},
+		},
+	}
+}
+
+func TestGenerateSubtitlesNormalizesText(t *testing.T) {
+	intro := narration{src: "intro.mp3", duration: 3, result: rawWhisper()}
+
+	tests := []struct {
+		name      string
+		style     string
+		normalize func(cfg *app.Config)
+		want      []string
+	}{
+		{
+			name:      "classic text kept as transcribed",
+			style:     subtitleStyleClassic,
+			normalize: func(*app.Config) {},
+			want:      []string{"Dialogue: 0,0:00:00.00,0:00:03.00,Default,,0,0,0,,hello , world . how are you ?"},
+		},
+		{
+			name:      "classic punctuation spacing only",
+			style:     subtitleStyleClassic,
+			normalize: func(cfg *app.Config) { cfg.Subtitles.FixPunctuationSpacing = true },
+			want:      []string{"Dialogue: 0,0:00:00.00,0:00:03.00,Default,,0,0,0,,hello, world. how are you?"},
+		},
+		{
+			name:  "classic with every rule",
+			style: subtitleStyleClassic,
+			normalize: func(cfg *app.Config) {
+				cfg.Subtitles.CollapseSpaces, cfg.Subtitles.FixPunctuationSpacing, cfg.Subtitles.SentenceCase = true, true, true
+			},
+			want: []string{"Dialogue: 0,0:00:00.00,0:00:03.00,Default,,0,0,0,,Hello, world. How are you?"},
+		},
+		{
+			name:  "progressive words merge their punctuation",
+			style: subtitleStyleProgressive,
+			normalize: func(cfg *app.Config) {
+				cfg.Subtitles.CollapseSpaces, cfg.Subtitles.FixPunctuationSpacing, cfg.Subtitles.SentenceCase = true, true, true
+			},
+			want: []string{
+				"Dialogue: 0,0:00:00.00,0:00:00.60,Default,,0,0,0,,Hello,",
+				"Dialogue: 0,0:00:00.60,0:00:01.50,Default,,0,0,0,,world.",
+				"Dialogue: 0,0:00:01.50,0:00:01.80,Default,,0,0,0,,How",
+				"Dialogue: 0,0:00:01.80,0:00:02.00,Default,,0,0,0,,are",
+				"Dialogue: 0,0:00:02.00,0:00:02.50,Default,,0,0,0,,you?",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			tt.normalize(cfg)
+			ss := newTestService(cfg, intro)
+
+			ass := generateFile(t, ss, newSubtitledProject(models.SubtitleSettings{Style: tt.style}, intro))
+			compareLines(t, "dialogues", dialogues(ass), tt.want)
+		})
+	}
+}
+
+func TestGenerateSubtitlesSetsScaledBorderAndShadow(t *testing.T) {
+	intro := narration{src: "intro.mp3", duration: 2, result: spoken("Welcome back", 1)}
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name  string
+		value *bool
+		want  string
+	}{
+		{"scaled by default", nil, "ScaledBorderAndShadow: yes"},
+		{"scaled", &enabled, "ScaledBorderAndShadow: yes"},
+		{"unscaled", &disabled, "ScaledBorderAndShadow: no"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := newTestService(newTestConfig(t), intro)
+			ass := generateFile(t, ss, newSubtitledProject(models.SubtitleSettings{ScaledBorderAndShadow: tt.value}, intro))
+
+			var got []string
+			for _, line := range strings.Split(ass, "\n") {
+				if strings.HasPrefix(line, "ScaledBorderAndShadow:") {
+					got = append(got, line)
+				}
+			}
+			compareLines(t, "header", got, []string{tt.want})
+		})
+	}
+}
+
+func TestValidateJSONSubtitleSettingsRejectsSceneScaledBorderAndShadow(t *testing.T) {
+	disabled := false
+	ss := newTestService(newTestConfig(t))
+	project := newSubtitledProject(models.SubtitleSettings{})
+	project.Scenes = []models.Scene{{ID: "quote", SubtitleSettings: models.SubtitleSettings{ScaledBorderAndShadow: &disabled}}}
+
+	if err := ss.ValidateJSONSubtitleSettings(project); err == nil {
+		t.Error("ValidateJSONSubtitleSettings() accepted a scene ScaledBorderAndShadow override of the script-wide header")
+	}
+}
+
+// karaokeWhisper returns a transcription that ends a sentence, pauses for
+// more than a second and runs past the karaoke line length, with a low
+// confidence "two"
+func karaokeWhisper() *transcription.TranscriptionResult {
+	low, high := 0.2, 0.9
+	result := &transcription.TranscriptionResult{
+		Text:    "Hello there. one two three four five six seven eight nine ten",
+		Success: true,
+		WordTimestamps: []transcription.WhisperWordTimestamp{
+			{Word: " Hello", Start: 0, End: 0.5, Probability: &high},
+			{Word: " there.", Start: 0.5, End: 1, Probability: &high},
+			{Word: " one", Start: 1.25, End: 1.5, Probability: &high},
+			{Word: " two", Start: 1.5, End: 1.75, Probability: &low},
+		},
+	}
+	for i, word := range strings.Fields("three four five six seven eight nine ten") {
+		start := 3 + float64(i)*0.5
+		result.WordTimestamps = append(result.WordTimestamps, transcription.WhisperWordTimestamp{
+			Word: " " + word, Start: start, End: start + 0.5,
+		})
+	}
+	return result
+}
+
+func TestGenerateSubtitlesHighlightsKaraokeWords(t *testing.T) {
+	intro := narration{src: "intro.mp3", duration: 7, result: karaokeWhisper()}
+	afterPause := "Dialogue: 0,0:00:03.00,0:00:06.50,Default,,0,0,0,," +
+		`{\k50}three {\k50}four {\k50}five {\k50}six {\k50}seven {\k50}eight {\k50}nine`
+
+	tests := []struct {
+		name       string
+		confidence func(cfg *app.Config)
+		want       []string
+	}{
+		{
+			name:       "every word highlighted",
+			confidence: func(*app.Config) {},
+			want: []string{
+				`Dialogue: 0,0:00:00.00,0:00:01.00,Default,,0,0,0,,{\k50}Hello {\k50}there.`,
+				`Dialogue: 0,0:00:01.25,0:00:01.75,Default,,0,0,0,,{\k25}one {\k25}two`,
+				afterPause,
+				`Dialogue: 0,0:00:06.50,0:00:07.00,Default,,0,0,0,,{\k50}ten`,
+			},
+		},
+		{
+			name: "low confidence word dimmed",
+			confidence: func(cfg *app.Config) {
+				cfg.Subtitles.MinWordConfidence, cfg.Subtitles.LowConfidenceMode = 0.5, app.LowConfidenceDim
+			},
+			want: []string{
+				`Dialogue: 0,0:00:00.00,0:00:01.00,Default,,0,0,0,,{\k50}Hello {\k50}there.`,
+				`Dialogue: 0,0:00:01.25,0:00:01.75,Default,,0,0,0,,{\k25}one {\k25}{\alpha&H80&}two{\alpha&H00&}`,
+				afterPause,
+				`Dialogue: 0,0:00:06.50,0:00:07.00,Default,,0,0,0,,{\k50}ten`,
+			},
+		},
+		{
+			name: "low confidence word dropped",
+			confidence: func(cfg *app.Config) {
+				cfg.Subtitles.MinWordConfidence, cfg.Subtitles.LowConfidenceMode = 0.5, app.LowConfidenceDrop
+			},
+			want: []string{
+				`Dialogue: 0,0:00:00.00,0:00:01.00,Default,,0,0,0,,{\k50}Hello {\k50}there.`,
+				`Dialogue: 0,0:00:01.25,0:00:01.50,Default,,0,0,0,,{\k25}one`,
+				afterPause,
+				`Dialogue: 0,0:00:06.50,0:00:07.00,Default,,0,0,0,,{\k50}ten`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			tt.confidence(cfg)
+			ss := newTestService(cfg, intro)
+
+			ass := generateFile(t, ss, newSubtitledProject(models.SubtitleSettings{Style: subtitleStyleKaraoke}, intro))
+			compareLines(t, "dialogues", dialogues(ass), tt.want)
+		})
+	}
+}
+
+func TestGenerateSubtitlesWritesJSONEvents(t *testing.T) {
+	result := spoken("Hello there.", 0.5)
+	low := 0.2
+	result.WordTimestamps[1].Probability = &low
+	intro := narration{src: "intro.mp3", duration: 2, result: result}
+
+	cfg := newTestConfig(t)
+	cfg.Subtitles.MinWordConfidence, cfg.Subtitles.LowConfidenceMode = 0.5, app.LowConfidenceDim
+	project := newSubtitledProject(models.SubtitleSettings{Style: subtitleStyleKaraoke}, intro)
+	project.SubtitleMode = models.SubtitleModeJSON
+
+	want := `{
+  "style": "karaoke",
+  "styles": {
+    "Default": {
+      "font_family": "Arial",
+      "font_size": 24,
+      "position": "center-bottom",
+      "word_color": "#FFFFFF",
+      "line_color": "#FFFFFF",
+      "outline_color": "#000000",
+      "outline_width": 2,
+      "shadow_color": "#808080",
+      "shadow_offset": 1,
+      "box_color": "#000000",
+      "margin_left": 10,
+      "margin_right": 10,
+      "margin_vertical": 20,
+      "bold": true,
+      "italic": false,
+      "underline": false
+    }
+  },
+  "events": [
+    {
+      "start": 0,
+      "end": 1,
+      "text": "Hello there.",
+      "layer": 0,
+      "words": [
+        {
+          "text": "Hello",
+          "start": 0,
+          "end": 0.5
+        },
+        {
+          "text": "there.",
+          "start": 0.5,
+          "end": 1,
+          "dimmed": true
+        }
+      ]
+    }
+  ]
+}`
+	if got := generateFile(t, newTestService(cfg, intro), project); got != want {
+		t.Errorf("events file =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerateSubtitlesLaysOutRightToLeft(t *testing.T) {
+	const style = "Style: Default,Arial,24,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,1,0,0,0,100,100,0,0,1,2,1,"
+
+	tests := []struct {
+		name      string
+		text      string
+		direction string
+		position  string
+		wantStyle string
+		wantText  string
+	}{
+		{
+			name:      "auto detects arabic",
+			text:      "مرحبا بالعالم",
+			position:  "left-bottom",
+			wantStyle: style + "3,10,10,20,178",
+			wantText:  "\u200fمرحبا بالعالم\u200f",
+		},
+		{
+			name:      "auto detects hebrew",
+			text:      "שלום עולם",
+			direction: TextDirectionAuto,
+			position:  "left-bottom",
+			wantStyle: style + "3,10,10,20,177",
+			wantText:  "\u200fשלום עולם\u200f",
+		},
+		{
+			name:      "ltr keeps hebrew unmarked",
+			text:      "שלום עולם",
+			direction: TextDirectionLTR,
+			position:  "left-bottom",
+			wantStyle: style + "1,10,10,20,1",
+			wantText:  "שלום עולם",
+		},
+		{
+			name:      "rtl forces latin text",
+			text:      "Hello world.",
+			direction: TextDirectionRTL,
+			position:  "right-top",
+			wantStyle: style + "7,10,10,20,1",
+			wantText:  "\u200fHello world.\u200f",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intro := narration{src: "intro.mp3", duration: 2, result: spoken(tt.text, 1)}
+			settings := models.SubtitleSettings{TextDirection: tt.direction, Position: tt.position}
+
+			ass := generateFile(t, newTestService(newTestConfig(t), intro), newSubtitledProject(settings, intro))
+			compareLines(t, "styles", styleLines(ass), []string{tt.wantStyle})
+			compareLines(t, "dialogues", dialogues(ass), []string{"Dialogue: 0,0:00:00.00,0:00:02.00,Default,,0,0,0,," + tt.wantText})
+		})
+	}
+}
+
+func TestGenerateSubtitlesSetsFontStyleFlags(t *testing.T) {
+	intro := narration{src: "intro.mp3", duration: 2, result: spoken("Welcome back", 1)}
+	quote := narration{src: "quote.mp3", duration: 2, result: spoken("Stay hungry", 1)}
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name     string
+		settings models.SubtitleSettings
+		scene    models.SubtitleSettings
+		want     []string
+	}{
+		{
+			name: "unset flags keep bold upright text",
+			want: []string{
+				"Style: Default,Arial,24,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,1,0,0,0,100,100,0,0,1,2,1,2,10,10,20,1",
+			},
+		},
+		{
+			name:     "element flags",
+			settings: models.SubtitleSettings{Bold: &disabled, Italic: &enabled, Underline: &enabled},
+			want: []string{
+				"Style: Default,Arial,24,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,0,1,1,0,100,100,0,0,1,2,1,2,10,10,20,1",
+			},
+		},
+		{
+			name:     "scene overrides inherit unset flags",
+			settings: models.SubtitleSettings{Italic: &enabled},
+			scene:    models.SubtitleSettings{Bold: &disabled, Underline: &enabled},
+			want: []string{
+				"Style: Default,Arial,24,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,1,1,0,0,100,100,0,0,1,2,1,2,10,10,20,1",
+				"Style: Scene2,Arial,24,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,0,1,1,0,100,100,0,0,1,2,1,2,10,10,20,1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := newSubtitledProject(tt.settings, intro, quote)
+			project.Scenes[1].SubtitleSettings = tt.scene
+
+			ass := generateFile(t, newTestService(newTestConfig(t), intro, quote), project)
+			compareLines(t, "styles", styleLines(ass), tt.want)
+		})
+	}
+}
+
+func TestGenerateSubtitlesHonorsProjectSubtitlesEnabled(t *testing.T) {
+	intro := narration{src: "intro.mp3", duration: 2, result: spoken("Welcome back", 1)}
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name      string
+		byDefault bool
+		project   *bool
+		want      []string
+	}{
+		{"enabled by default", true, nil, []string{"Dialogue: 0,0:00:00.00,0:00:02.00,Default,,0,0,0,,Welcome back"}},
+		{"disabled by default", false, nil, nil},
+		{"project enables", false, &enabled, []string{"Dialogue: 0,0:00:00.00,0:00:02.00,Default,,0,0,0,,Welcome back"}},
+		{"project disables", true, &disabled, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			cfg.Subtitles.Enabled = tt.byDefault
+			ss := newTestService(cfg, intro)
+			project := newSubtitledProject(models.SubtitleSettings{}, intro)
+			project.SubtitlesEnabled = tt.project
+
+			if tt.want == nil {
+				result, err := ss.GenerateSubtitles(context.Background(), project)
+				if err != nil || result != nil {
+					t.Fatalf("GenerateSubtitles() = %v, %v, want no subtitles", result, err)
+				}
+				if entries, _ := os.ReadDir(cfg.Storage.TempDir); len(entries) != 0 {
+					t.Errorf("disabled subtitles wrote %d files", len(entries))
+				}
+				return
+			}
+			compareLines(t, "dialogues", dialogues(generateFile(t, ss, project)), tt.want)
+		})
+	}
+}
+
+func TestGenerateSubtitlesWritesCaptionFiles(t *testing.T) {
+	intro := narration{src: "intro.mp3", duration: 2, result: spoken("Fish & chips", 0.5)}
+	outro := narration{src: "outro.mp3", duration: 1.5, result: spoken("a<b", 1.25)}
+	ss := newTestService(newTestConfig(t), intro, outro)
+	project := newSubtitledProject(models.SubtitleSettings{}, intro, outro)
+	project.SubtitleFormats = []string{models.SubtitleFormatSRT, models.SubtitleFormatVTT}
+
+	result, err := ss.GenerateSubtitles(context.Background(), project)
+	if err != nil {
+		t.Fatalf("GenerateSubtitles() error = %v", err)
+	}
+
+	want := map[string]string{
+		models.SubtitleFormatSRT: "1\n00:00:00,000 --> 00:00:02,000\nFish & chips\n\n" +
+			"2\n00:00:02,000 --> 00:00:03,500\na<b\n\n",
+		models.SubtitleFormatVTT: "WEBVTT\n\n" +
+			"00:00:00.000 --> 00:00:02.000\nFish &amp; chips\n\n" +
+			"00:00:02.000 --> 00:00:03.500\na&lt;b\n\n",
+	}
+	if len(result.CaptionFiles) != len(want) {
+		t.Fatalf("got %d caption files, want %d", len(result.CaptionFiles), len(want))
+	}
+	for i, file := range result.CaptionFiles {
+		if file.Format != project.SubtitleFormats[i] {
+			t.Errorf("caption file %d format = %s, want %s", i, file.Format, project.SubtitleFormats[i])
+		}
+		content, err := os.ReadFile(file.FilePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != want[file.Format] {
+			t.Errorf("%s captions =\n%s\nwant\n%s", file.Format, content, want[file.Format])
+		}
+	}
+}
+
+func TestGenerateSubtitlesReportsNoEvents(t *testing.T) {
+	silent := narration{src: "silent.mp3", duration: 2, result: &transcription.TranscriptionResult{Success: true}}
+
+	tests := []struct {
+		name    string
+		project models.VideoProject
+	}{
+		{"silent narration", newSubtitledProject(models.SubtitleSettings{}, silent)},
+		{"no narration", newSubtitledProject(models.SubtitleSettings{})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			_, err := newTestService(cfg, silent).GenerateSubtitles(context.Background(), tt.project)
+			if !stderrors.Is(err, ErrNoSubtitleEvents) {
+				t.Fatalf("GenerateSubtitles() error = %v, want ErrNoSubtitleEvents", err)
+			}
+			if entries, _ := os.ReadDir(cfg.Storage.TempDir); len(entries) != 0 {
+				t.Errorf("empty subtitles wrote %d files", len(entries))
+			}
+		})
+	}
+}
+
+func TestTranscribeAudioElementsChoosesLanguage(t *testing.T) {
+	audioElements := []models.Element{
+		{Type: "audio", Src: "https://example.com/default.mp3"},
+		{Type: "audio", Src: "https://example.com/german.mp3", Language: "de"},
+	}
+
+	tests := []struct {
+		name             string
+		subtitleLanguage string
+		want             map[string]string
+	}{
+		{
+			name: "configured language",
+			want: map[string]string{"https://example.com/default.mp3": "", "https://example.com/german.mp3": "de"},
+		},
+		{
+			name:             "subtitle element language",
+			subtitleLanguage: "ja",
+			want:             map[string]string{"https://example.com/default.mp3": "ja", "https://example.com/german.mp3": "de"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakeTranscription{
+				results: map[string]*transcription.TranscriptionResult{
+					"https://example.com/default.mp3": spoken("hello", 0.5),
+					"https://example.com/german.mp3":  spoken("hallo", 0.5),
+				},
+				languages: make(map[string]string),
+			}
+			ss := NewService(newTestConfig(t), logger.NewWithWriter("error", io.Discard, "text"), fake, fakeAudio{}).(*service)
+
+			if _, err := ss.transcribeAudioElements(context.Background(), audioElements, tt.subtitleLanguage); err != nil {
+				t.Fatalf("transcribeAudioElements() error = %v", err)
+			}
+			if !reflect.DeepEqual(fake.languages, tt.want) {
+				t.Errorf("languages = %v, want %v", fake.languages, tt.want)
+			}
+		})
+	}
+}
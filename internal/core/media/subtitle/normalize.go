@@ -0,0 +1,145 @@
+package subtitle
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextNormalization selects the cleanup rules applied to transcription text
+// before subtitle events are generated
+type TextNormalization struct {
+	CollapseSpaces      bool
+	FixPunctuationSpace bool
+	SentenceCase        bool
+}
+
+// Enabled reports whether any normalization rule is active
+func (n TextNormalization) Enabled() bool {
+	return n.CollapseSpaces || n.FixPunctuationSpace || n.SentenceCase
+}
+
+// isTrailingPunctuation reports whether r attaches to the preceding word
+func isTrailingPunctuation(r rune) bool {
+	switch r {
+	case ',', '.', '!', '?', ';', ':', '…':
+		return true
+	}
+	return false
+}
+
+// isSentenceEnd reports whether a word ending in r closes a sentence
+func isSentenceEnd(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
+
+// NormalizeText applies the enabled rules to a full transcription text
+func NormalizeText(text string, n TextNormalization) string {
+	if !n.Enabled() {
+		return text
+	}
+
+	if !n.CollapseSpaces {
+		// Without collapsing, only punctuation and case rules touch the text
+		return normalizeRawText(text, n)
+	}
+
+	fields := strings.Fields(text)
+	words := make([]WordTimestamp, len(fields))
+	for i, field := range fields {
+		words[i] = WordTimestamp{Word: field}
+	}
+	words = NormalizeWords(words, n)
+	parts := make([]string, len(words))
+	for i, w := range words {
+		parts[i] = w.Word
+	}
+	return strings.Join(parts, " ")
+}
+
+// normalizeRawText applies punctuation and case rules while keeping the
+// original spacing between words
+func normalizeRawText(text string, n TextNormalization) string {
+	var b strings.Builder
+	runes := []rune(text)
+	capitalizeNext := n.SentenceCase
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if n.FixPunctuationSpace && unicode.IsSpace(r) {
+			j := i
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+			if j < len(runes) && isTrailingPunctuation(runes[j]) && b.Len() > 0 {
+				i = j - 1
+				continue
+			}
+		}
+		if capitalizeNext && unicode.IsLetter(r) {
+			r = unicode.ToUpper(r)
+			capitalizeNext = false
+		}
+		if n.SentenceCase && isSentenceEnd(r) {
+			capitalizeNext = true
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// NormalizeWords applies the enabled rules to word timestamps. Words are
+// trimmed when collapsing spaces, and punctuation-only words are merged into
+// the preceding word (extending its end time) when fixing punctuation spacing.
+func NormalizeWords(words []WordTimestamp, n TextNormalization) []WordTimestamp {
+	if !n.Enabled() {
+		return words
+	}
+
+	result := make([]WordTimestamp, 0, len(words))
+	for _, w := range words {
+		if n.CollapseSpaces {
+			w.Word = strings.Join(strings.Fields(w.Word), " ")
+			if w.Word == "" {
+				continue
+			}
+		}
+		if n.FixPunctuationSpace && len(result) > 0 {
+			trimmed := strings.TrimSpace(w.Word)
+			if first, _ := utf8.DecodeRuneInString(trimmed); trimmed != "" && isTrailingPunctuation(first) && strings.IndexFunc(trimmed, unicode.IsLetter) < 0 {
+				prev := &result[len(result)-1]
+				prev.Word = strings.TrimRightFunc(prev.Word, unicode.IsSpace) + trimmed
+				if w.End > prev.End {
+					prev.End = w.End
+				}
+				continue
+			}
+		}
+		result = append(result, w)
+	}
+
+	if n.SentenceCase {
+		capitalizeNext := true
+		for i := range result {
+			word := strings.TrimSpace(result[i].Word)
+			if capitalizeNext {
+				result[i].Word = capitalizeFirstLetter(result[i].Word)
+			}
+			if last, _ := utf8.DecodeLastRuneInString(word); word != "" {
+				capitalizeNext = isSentenceEnd(last)
+			}
+		}
+	}
+
+	return result
+}
+
+// capitalizeFirstLetter upper-cases the first letter of s, skipping leading
+// quotes or other non-letters
+func capitalizeFirstLetter(s string) string {
+	for i, r := range s {
+		if unicode.IsLetter(r) {
+			return s[:i] + string(unicode.ToUpper(r)) + s[i+utf8.RuneLen(r):]
+		}
+	}
+	return s
+}
@@ -0,0 +1,123 @@
+package subtitle
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/activadee/videocraft/internal/app"
+)
+
+// ellipsis marks a caption truncated by LineOverflowTruncate
+const ellipsis = "…"
+
+// LineWrap defines how classic captions are broken into lines
+type LineWrap struct {
+	MaxLineLength int    // Characters per line; 0 disables wrapping
+	MaxLines      int    // Lines per caption; 0 = unlimited
+	Overflow      string // app.LineOverflowSplit or app.LineOverflowTruncate
+}
+
+// WrapText breaks text into lines of at most maxLineLength characters at word
+// boundaries. A word longer than a line gets a line of its own.
+func WrapText(text string, maxLineLength int) []string {
+	words := strings.Fields(text)
+	if maxLineLength <= 0 {
+		return []string{strings.Join(words, " ")}
+	}
+
+	var lines []string
+	var current string
+	for _, word := range words {
+		if current != "" && utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > maxLineLength {
+			lines = append(lines, current)
+			current = ""
+		}
+		if current != "" {
+			current += " "
+		}
+		current += word
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+
+	return lines
+}
+
+// WrapEvents wraps each event's text into "\n"-separated lines, which the ASS
+// generator writes as \N. Events with more than MaxLines lines are split into
+// sequential events sharing the original time window in proportion to their
+// length, or truncated with an ellipsis.
+func WrapEvents(events []SubtitleEvent, wrap LineWrap) []SubtitleEvent {
+	if wrap.MaxLineLength <= 0 {
+		return events
+	}
+
+	wrapped := make([]SubtitleEvent, 0, len(events))
+	for _, event := range events {
+		lines := WrapText(event.Text, wrap.MaxLineLength)
+		if wrap.MaxLines <= 0 || len(lines) <= wrap.MaxLines {
+			event.Text = strings.Join(lines, "\n")
+			wrapped = append(wrapped, event)
+			continue
+		}
+
+		if wrap.Overflow == app.LineOverflowTruncate {
+			lines = lines[:wrap.MaxLines]
+			lines[len(lines)-1] = truncateLine(lines[len(lines)-1], wrap.MaxLineLength)
+			event.Text = strings.Join(lines, "\n")
+			wrapped = append(wrapped, event)
+			continue
+		}
+
+		wrapped = append(wrapped, splitEventLines(event, lines, wrap.MaxLines)...)
+	}
+
+	return wrapped
+}
+
+// truncateLine ends line with an ellipsis, dropping words until it fits
+func truncateLine(line string, maxLineLength int) string {
+	words := strings.Fields(line)
+	for len(words) > 1 && utf8.RuneCountInString(strings.Join(words, " "))+utf8.RuneCountInString(ellipsis) > maxLineLength {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ") + ellipsis
+}
+
+// splitEventLines spreads lines over sequential events of at most maxLines
+// lines each, timed in proportion to their character count
+func splitEventLines(event SubtitleEvent, lines []string, maxLines int) []SubtitleEvent {
+	var chunks []string
+	for start := 0; start < len(lines); start += maxLines {
+		end := min(start+maxLines, len(lines))
+		chunks = append(chunks, strings.Join(lines[start:end], "\n"))
+	}
+
+	var total int
+	for _, chunk := range chunks {
+		total += utf8.RuneCountInString(chunk)
+	}
+
+	duration := event.EndTime - event.StartTime
+	events := make([]SubtitleEvent, 0, len(chunks))
+	current := event.StartTime
+	var elapsed int
+	for i, chunk := range chunks {
+		elapsed += utf8.RuneCountInString(chunk)
+		end := event.StartTime + time.Duration(float64(duration)*float64(elapsed)/float64(total))
+		if i == len(chunks)-1 {
+			end = event.EndTime
+		}
+
+		part := event
+		part.StartTime = current
+		part.EndTime = end
+		part.Text = chunk
+		events = append(events, part)
+		current = end
+	}
+
+	return events
+}
@@ -0,0 +1,74 @@
+package subtitle
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/app"
+)
+
+func TestWrapEvents(t *testing.T) {
+	event := SubtitleEvent{StartTime: time.Second, EndTime: 3 * time.Second, Text: "one two three four five six", Style: "Default"}
+
+	tests := []struct {
+		name string
+		wrap LineWrap
+		want []SubtitleEvent
+	}{
+		{
+			name: "disabled",
+			wrap: LineWrap{},
+			want: []SubtitleEvent{event},
+		},
+		{
+			name: "wraps within max lines",
+			wrap: LineWrap{MaxLineLength: 14},
+			want: []SubtitleEvent{
+				{StartTime: time.Second, EndTime: 3 * time.Second, Text: "one two three\nfour five six", Style: "Default"},
+			},
+		},
+		{
+			name: "splits overflow in proportion to length",
+			wrap: LineWrap{MaxLineLength: 9, MaxLines: 2, Overflow: app.LineOverflowSplit},
+			want: []SubtitleEvent{
+				{StartTime: time.Second, EndTime: 2 * time.Second, Text: "one two\nthree", Style: "Default"},
+				{StartTime: 2 * time.Second, EndTime: 3 * time.Second, Text: "four five\nsix", Style: "Default"},
+			},
+		},
+		{
+			name: "truncates overflow",
+			wrap: LineWrap{MaxLineLength: 9, MaxLines: 2, Overflow: app.LineOverflowTruncate},
+			want: []SubtitleEvent{
+				{StartTime: time.Second, EndTime: 3 * time.Second, Text: "one two\nthree…", Style: "Default"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WrapEvents([]SubtitleEvent{event}, tt.wrap)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WrapEvents() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateLine(t *testing.T) {
+	tests := []struct {
+		line          string
+		maxLineLength int
+		want          string
+	}{
+		{"three", 9, "three…"},
+		{"four five", 9, "four…"},
+		{"extraordinarily", 9, "extraordinarily…"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateLine(tt.line, tt.maxLineLength); got != tt.want {
+			t.Errorf("truncateLine(%q, %d) = %q, want %q", tt.line, tt.maxLineLength, got, tt.want)
+		}
+	}
+}
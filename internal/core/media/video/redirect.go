@@ -0,0 +1,123 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/activadee/videocraft/internal/app"
+)
+
+// resolveProbeURL follows redirects for a probe URL one hop at a time,
+// re-validating every hop, and returns the final URL to hand to ffprobe.
+// The final URL itself must not redirect, so ffprobe has nothing left to follow.
+func (s *service) resolveProbeURL(ctx context.Context, videoURL string) (string, error) {
+	client := &http.Client{
+		Timeout:   s.cfg.FFmpeg.Timeout,
+		Transport: s.cfg.Storage.MediaTransport(),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse // Hops are followed manually below
+		},
+	}
+
+	currentURL := videoURL
+	for hop := 0; ; hop++ {
+		if hop > 0 {
+			if err := s.validateRedirectTarget(ctx, currentURL); err != nil {
+				return "", fmt.Errorf("redirect hop %d rejected: %w", hop, err)
+			}
+		}
+
+		location, err := s.probeLocation(ctx, client, currentURL)
+		if err != nil {
+			return "", err
+		}
+		if location == "" {
+			return currentURL, nil
+		}
+
+		if s.cfg.Security.ProbeRedirects == app.ProbeRedirectsDeny {
+			return "", fmt.Errorf("redirects are not allowed for video URLs")
+		}
+		if hop >= s.cfg.Security.MaxProbeRedirects {
+			return "", fmt.Errorf("too many redirects (max %d)", s.cfg.Security.MaxProbeRedirects)
+		}
+
+		s.log.Debugf("Video URL redirects: %s -> %s", currentURL, location)
+		currentURL = location
+	}
+}
+
+// probeLocation requests the first byte of a URL and returns the absolute
+// redirect target, or "" if the URL does not redirect
+func (s *service) probeLocation(ctx context.Context, client *http.Client, rawURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Range", "bytes=0-0")
+	req.Header.Set("User-Agent", s.cfg.Storage.UserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach video URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+		return "", nil
+	}
+
+	location, err := resp.Location()
+	if err != nil {
+		return "", fmt.Errorf("redirect without valid location: %w", err)
+	}
+	return location.String(), nil
+}
+
+// validateRedirectTarget applies the URL validators to a redirect hop and
+// rejects hosts that resolve to loopback, private or link-local addresses
+func (s *service) validateRedirectTarget(ctx context.Context, rawURL string) error {
+	if err := s.ValidateVideo(rawURL); err != nil {
+		return err
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL format: %w", err)
+	}
+
+	if len(s.cfg.Security.AllowedDomains) > 0 && !s.isAllowedDomain(parsedURL.Host) {
+		return fmt.Errorf("domain not in allowlist: %s", parsedURL.Host)
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, parsedURL.Hostname())
+	if err != nil {
+		return fmt.Errorf("failed to resolve host %s: %w", parsedURL.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if isInternalIP(addr.IP) {
+			return fmt.Errorf("host %s resolves to internal address %s", parsedURL.Hostname(), addr.IP)
+		}
+	}
+
+	return nil
+}
+
+// isAllowedDomain mirrors the engine's allowlist check on exact host match
+func (s *service) isAllowedDomain(host string) bool {
+	for _, allowedDomain := range s.cfg.Security.AllowedDomains {
+		if host == allowedDomain {
+			return true
+		}
+	}
+	return false
+}
+
+// isInternalIP reports whether an address is not publicly routable
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
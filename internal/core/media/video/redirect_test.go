@@ -0,0 +1,148 @@
+package video
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+func newTestService(cfg *app.Config) *service {
+	return NewService(cfg, logger.NewWithWriter("error", io.Discard, "text")).(*service)
+}
+
+func newRedirectConfig() *app.Config {
+	cfg := &app.Config{}
+	cfg.FFmpeg.Timeout = 5 * time.Second
+	cfg.Security.ProbeRedirects = app.ProbeRedirectsResolve
+	cfg.Security.MaxProbeRedirects = 5
+	return cfg
+}
+
+// newRedirectServer redirects every request to location, in which "{self}"
+// stands for the server's own address, and counts requests
+func newRedirectServer(t *testing.T, location string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if location == "" {
+			w.WriteHeader(http.StatusPartialContent)
+			return
+		}
+		http.Redirect(w, r, strings.ReplaceAll(location, "{self}", r.Host), http.StatusFound)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestResolveProbeURLWithoutRedirect(t *testing.T) {
+	server, _ := newRedirectServer(t, "")
+	s := newTestService(newRedirectConfig())
+
+	got, err := s.resolveProbeURL(context.Background(), server.URL+"/video.mp4")
+	if err != nil {
+		t.Fatalf("resolveProbeURL() error = %v", err)
+	}
+	if got != server.URL+"/video.mp4" {
+		t.Errorf("resolveProbeURL() = %q, want the original URL", got)
+	}
+}
+
+func TestResolveProbeURLRejectsRedirects(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		config   func(cfg *app.Config)
+		wantErr  string
+	}{
+		{
+			name:     "loopback address",
+			location: "http://{self}/admin",
+			wantErr:  "internal address 127.0.0.1",
+		},
+		{
+			name:     "localhost name",
+			location: "http://localhost:9/admin",
+			wantErr:  "resolves to internal address",
+		},
+		{
+			name:     "cloud metadata address",
+			location: "http://169.254.169.254/latest/meta-data/",
+			wantErr:  "internal address 169.254.169.254",
+		},
+		{
+			name:     "host outside allowlist",
+			location: "https://cdn.untrusted.test/video.mp4",
+			config: func(cfg *app.Config) {
+				cfg.Security.AllowedDomains = []string{"cdn.example.com"}
+			},
+			wantErr: "domain not in allowlist: cdn.untrusted.test",
+		},
+		{
+			name:     "non-HTTP scheme",
+			location: "file:///etc/passwd",
+			wantErr:  "only HTTP and HTTPS",
+		},
+		{
+			name:     "redirects denied",
+			location: "https://cdn.example.com/video.mp4",
+			config: func(cfg *app.Config) {
+				cfg.Security.ProbeRedirects = app.ProbeRedirectsDeny
+			},
+			wantErr: "redirects are not allowed",
+		},
+		{
+			name:     "hop limit",
+			location: "https://cdn.example.com/video.mp4",
+			config: func(cfg *app.Config) {
+				cfg.Security.MaxProbeRedirects = 0
+			},
+			wantErr: "too many redirects (max 0)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newRedirectServer(t, tt.location)
+			cfg := newRedirectConfig()
+			if tt.config != nil {
+				tt.config(cfg)
+			}
+			s := newTestService(cfg)
+
+			_, err := s.resolveProbeURL(context.Background(), server.URL+"/video.mp4")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("resolveProbeURL() error = %v, want %q", err, tt.wantErr)
+			}
+			// The rejected target is never requested
+			if n := requests.Load(); n != 1 {
+				t.Errorf("server got %d requests, want 1", n)
+			}
+		})
+	}
+}
+
+func TestResolveProbeURLSendsConfiguredUserAgent(t *testing.T) {
+	userAgents := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents <- r.Header.Get("User-Agent")
+		w.WriteHeader(http.StatusPartialContent)
+	}))
+	defer server.Close()
+
+	cfg := newRedirectConfig()
+	cfg.Storage.UserAgent = "VideoCraft/1.0 (+https://example.com/bot)"
+	if _, err := newTestService(cfg).resolveProbeURL(context.Background(), server.URL+"/video.mp4"); err != nil {
+		t.Fatalf("resolveProbeURL() error = %v", err)
+	}
+	if got := <-userAgents; got != cfg.Storage.UserAgent {
+		t.Errorf("User-Agent = %q, want %q", got, cfg.Storage.UserAgent)
+	}
+}
@@ -3,6 +3,7 @@ package video
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
@@ -40,6 +41,7 @@ func NewService(cfg *app.Config, log logger.Logger) Service {
 
 // AnalyzeVideo analyzes a video file directly from URL using FFprobe
 func (s *service) AnalyzeVideo(ctx context.Context, videoURL string) (*models.VideoInfo, error) {
+	s = &service{cfg: s.cfg, log: logger.FromContext(ctx, s.log)}
 	s.log.Debugf("Analyzing video URL with FFprobe: %s", videoURL)
 
 	// Validate URL first
@@ -68,7 +70,7 @@ func (s *service) DownloadVideo(ctx context.Context, videoURL string) (string, e
 
 	// Create temporary file
 	tempDir := s.cfg.Storage.TempDir
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
+	if err := os.MkdirAll(tempDir, s.cfg.Storage.DirPerm()); err != nil {
 		return "", errors.ProcessingFailed(fmt.Errorf("failed to create temp directory: %w", err))
 	}
 
@@ -81,9 +83,11 @@ func (s *service) DownloadVideo(ctx context.Context, videoURL string) (string, e
 	if err != nil {
 		return "", errors.ProcessingFailed(fmt.Errorf("failed to create request: %w", err))
 	}
+	req.Header.Set("User-Agent", s.cfg.Storage.UserAgent)
 
 	client := &http.Client{
-		Timeout: s.cfg.FFmpeg.Timeout,
+		Timeout:   s.cfg.FFmpeg.Timeout,
+		Transport: s.cfg.Storage.MediaTransport(),
 	}
 
 	resp, err := client.Do(req)
@@ -97,7 +101,7 @@ func (s *service) DownloadVideo(ctx context.Context, videoURL string) (string, e
 	}
 
 	// Create output file
-	outFile, err := os.Create(tempPath)
+	outFile, err := os.OpenFile(tempPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, s.cfg.Storage.FilePerm())
 	if err != nil {
 		return "", errors.ProcessingFailed(fmt.Errorf("failed to create temp file: %w", err))
 	}
@@ -120,6 +124,11 @@ func (s *service) ValidateVideo(videoURL string) error {
 		return fmt.Errorf("video URL cannot be empty")
 	}
 
+	// Files uploaded with the request are the only accepted local sources
+	if s.cfg.Storage.IsUploadedFile(videoURL) {
+		return nil
+	}
+
 	// Parse URL
 	parsedURL, err := url.Parse(videoURL)
 	if err != nil {
@@ -179,14 +188,27 @@ func (s *service) GetVideoMetadata(filePath string) (*models.VideoInfo, error) {
 func (s *service) GetVideoMetadataFromURL(ctx context.Context, videoURL string) (*models.VideoInfo, error) {
 	s.log.Debugf("Getting video metadata from URL: %s", videoURL)
 
+	// Uploaded files are probed from disk like any local file
+	if s.cfg.Storage.IsUploadedFile(videoURL) {
+		return s.GetVideoMetadata(videoURL)
+	}
+
+	// Resolve redirects ourselves so ffprobe never follows one to an unvalidated host
+	finalURL, err := s.resolveProbeURL(ctx, videoURL)
+	if err != nil {
+		return nil, fmt.Errorf("unsafe video URL %s: %w", videoURL, err)
+	}
+	videoURL = finalURL
+
 	// Build FFprobe command for URL
-	args := []string{
+	args := append([]string{"-user_agent", s.cfg.Storage.UserAgent}, s.cfg.Storage.HTTPProxyArgs()...)
+	args = append(args,
 		"-v", "quiet",
 		"-print_format", "json",
 		"-show_format",
 		"-show_streams",
 		videoURL,
-	}
+	)
 
 	cmd := exec.CommandContext(ctx, s.cfg.FFmpeg.FFprobePath, args...)
 	output, err := cmd.Output()
@@ -210,6 +232,10 @@ func (s *service) parseFFprobeOutput(output string) (*models.VideoInfo, error) {
 		Format: "mp4", // default
 	}
 
+	// Frame rates of the first video stream
+	var inVideoStream, frameRatesParsed bool
+	var nominalRate, averageRate float64
+
 	lines := strings.Split(output, "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -247,8 +273,34 @@ func (s *service) parseFFprobeOutput(output string) (*models.VideoInfo, error) {
 				videoInfo.Codec = codec
 			}
 		}
+
+		// Detect an audio track and track which stream the following lines belong to
+		if strings.Contains(line, `"codec_type"`) {
+			if codecType, err := s.extractJSONValue(line, "codec_type"); err == nil {
+				if codecType == "audio" {
+					videoInfo.HasAudio = true
+				}
+				inVideoStream = codecType == "video" && !frameRatesParsed
+			}
+		}
+
+		// Parse frame rates (ffprobe prints codec_type before the rates)
+		if inVideoStream && strings.Contains(line, `"r_frame_rate"`) {
+			if rate, err := s.extractJSONValue(line, "r_frame_rate"); err == nil {
+				nominalRate = parseFrameRate(rate)
+			}
+		}
+		if inVideoStream && strings.Contains(line, `"avg_frame_rate"`) {
+			if rate, err := s.extractJSONValue(line, "avg_frame_rate"); err == nil {
+				averageRate = parseFrameRate(rate)
+				frameRatesParsed = true
+			}
+		}
 	}
 
+	videoInfo.FrameRate = averageRate
+	videoInfo.VariableFrameRate = isVariableFrameRate(nominalRate, averageRate)
+
 	// Validate required fields
 	if videoInfo.Duration <= 0 {
 		return nil, fmt.Errorf("invalid duration: %f", videoInfo.Duration)
@@ -261,6 +313,36 @@ func (s *service) parseFFprobeOutput(output string) (*models.VideoInfo, error) {
 	return videoInfo, nil
 }
 
+// vfrTolerance is the relative difference between nominal and average frame
+// rate above which a stream is treated as variable frame rate
+const vfrTolerance = 0.01
+
+// parseFrameRate parses an ffprobe rational frame rate such as "30000/1001"
+func parseFrameRate(rate string) float64 {
+	num, den, found := strings.Cut(rate, "/")
+	numerator, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0
+	}
+	if !found {
+		return numerator
+	}
+
+	denominator, err := strconv.ParseFloat(den, 64)
+	if err != nil || denominator == 0 {
+		return 0
+	}
+	return numerator / denominator
+}
+
+// isVariableFrameRate reports whether the average frame rate deviates from the nominal one
+func isVariableFrameRate(nominalRate, averageRate float64) bool {
+	if nominalRate <= 0 || averageRate <= 0 {
+		return false
+	}
+	return math.Abs(nominalRate-averageRate)/nominalRate > vfrTolerance
+}
+
 // extractJSONValue extracts a value from a JSON line
 func (s *service) extractJSONValue(line, key string) (string, error) {
 	// Find the key
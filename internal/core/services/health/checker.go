@@ -0,0 +1,244 @@
+package health
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/core/services/transcription"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+// Component names reported by the readiness check
+const (
+	CheckFFmpeg  = "ffmpeg"
+	CheckFFprobe = "ffprobe"
+	CheckWhisper = "whisper"
+	CheckStorage = "storage"
+	CheckDisk    = "disk"
+	CheckCleanup = "cleanup"
+)
+
+// Component statuses
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDisabled = "disabled"
+)
+
+// TranscriptionChecker probes whether the Whisper daemons answer requests
+type TranscriptionChecker interface {
+	ReadinessCheck(ctx context.Context) error
+}
+
+// CleanupChecker reports whether periodic file cleanup keeps failing
+type CleanupChecker interface {
+	CleanupHealth() error
+}
+
+// ComponentStatus is the result of a single dependency check
+type ComponentStatus struct {
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Message  string `json:"message,omitempty"`
+	Latency  string `json:"latency"`
+}
+
+// Report aggregates all dependency checks
+type Report struct {
+	Ready      bool                       `json:"ready"`
+	Components map[string]ComponentStatus `json:"components"`
+	Timestamp  time.Time                  `json:"timestamp"`
+}
+
+// Service runs dependency checks for readiness probes
+type Service interface {
+	Check(ctx context.Context) *Report
+}
+
+type checkFunc func(ctx context.Context) (string, error)
+
+type service struct {
+	cfg           *app.Config
+	log           logger.Logger
+	transcription TranscriptionChecker
+	cleanup       CleanupChecker
+	checks        map[string]checkFunc
+}
+
+// NewService creates a new health check service
+func NewService(cfg *app.Config, log logger.Logger, transcription TranscriptionChecker, cleanup CleanupChecker) Service {
+	s := &service{
+		cfg:           cfg,
+		log:           log,
+		transcription: transcription,
+		cleanup:       cleanup,
+	}
+	s.checks = map[string]checkFunc{
+		CheckFFmpeg:  s.checkBinary(cfg.FFmpeg.BinaryPath),
+		CheckFFprobe: s.checkBinary(cfg.FFmpeg.FFprobePath),
+		CheckWhisper: s.checkWhisper,
+		CheckStorage: s.checkStorage,
+		CheckDisk:    s.checkDisk,
+		CheckCleanup: s.checkCleanup,
+	}
+	return s
+}
+
+// Check runs all checks concurrently, each bounded by the configured timeout.
+// The report is ready only if every critical component is up or disabled.
+func (s *service) Check(ctx context.Context) *Report {
+	report := &Report{
+		Ready:      true,
+		Components: make(map[string]ComponentStatus, len(s.checks)),
+		Timestamp:  time.Now().UTC(),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range s.checks {
+		wg.Add(1)
+		go func(name string, check checkFunc) {
+			defer wg.Done()
+			status := s.runCheck(ctx, check)
+			status.Critical = s.isCritical(name)
+
+			mu.Lock()
+			report.Components[name] = status
+			if status.Critical && status.Status == StatusDown {
+				report.Ready = false
+			}
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	if !report.Ready {
+		s.log.Warnf("Readiness check failed: %+v", report.Components)
+	}
+
+	return report
+}
+
+// runCheck executes a check with a timeout so a hung dependency cannot stall the probe
+func (s *service) runCheck(ctx context.Context, check checkFunc) ComponentStatus {
+	checkCtx, cancel := context.WithTimeout(ctx, s.cfg.Health.CheckTimeout)
+	defer cancel()
+
+	started := time.Now()
+	type result struct {
+		status string
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		status, err := check(checkCtx)
+		done <- result{status, err}
+	}()
+
+	select {
+	case r := <-done:
+		component := ComponentStatus{Status: r.status, Latency: time.Since(started).String()}
+		if r.err != nil {
+			component.Status = StatusDown
+			component.Message = r.err.Error()
+		}
+		return component
+	case <-checkCtx.Done():
+		return ComponentStatus{
+			Status:  StatusDown,
+			Message: fmt.Sprintf("check timed out after %s", s.cfg.Health.CheckTimeout),
+			Latency: time.Since(started).String(),
+		}
+	}
+}
+
+func (s *service) isCritical(name string) bool {
+	for _, critical := range s.cfg.Health.CriticalChecks {
+		if critical == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *service) checkBinary(path string) checkFunc {
+	return func(ctx context.Context) (string, error) {
+		cmd := exec.CommandContext(ctx, path, "-version") // #nosec G204 - binary path comes from trusted configuration
+		if err := cmd.Run(); err != nil {
+			return StatusDown, fmt.Errorf("%s is not executable: %w", filepath.Base(path), err)
+		}
+		return StatusUp, nil
+	}
+}
+
+// checkWhisper is down when running daemons do not answer a status request
+// with their model loaded. Without a running daemon it is up as long as the
+// Python interpreter and daemon script are available to start one on demand.
+func (s *service) checkWhisper(ctx context.Context) (string, error) {
+	if !s.cfg.Transcription.Enabled {
+		return StatusDisabled, nil
+	}
+	if s.transcription != nil {
+		err := s.transcription.ReadinessCheck(ctx)
+		if err == nil {
+			return StatusUp, nil
+		}
+		if !stderrors.Is(err, transcription.ErrNoDaemonRunning) {
+			return StatusDown, err
+		}
+	}
+
+	if _, err := exec.LookPath(s.cfg.Transcription.Python.Path); err != nil {
+		return StatusDown, fmt.Errorf("python interpreter not found: %w", err)
+	}
+	if _, err := os.Stat(s.cfg.Transcription.Python.ScriptPath); err != nil {
+		return StatusDown, fmt.Errorf("whisper daemon script not found: %w", err)
+	}
+	return StatusUp, nil
+}
+
+func (s *service) checkStorage(ctx context.Context) (string, error) {
+	for _, dir := range []string{s.cfg.Storage.OutputDir, s.cfg.Storage.TempDir} {
+		if err := os.MkdirAll(dir, s.cfg.Storage.DirPerm()); err != nil {
+			return StatusDown, fmt.Errorf("directory %s is not available: %w", dir, err)
+		}
+		probe, err := os.CreateTemp(dir, ".healthcheck-*")
+		if err != nil {
+			return StatusDown, fmt.Errorf("directory %s is not writable: %w", dir, err)
+		}
+		_ = probe.Close()
+		_ = os.Remove(probe.Name())
+	}
+	return StatusUp, nil
+}
+
+func (s *service) checkDisk(ctx context.Context) (string, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(s.cfg.Storage.OutputDir, &stat); err != nil {
+		return StatusDown, fmt.Errorf("failed to read disk usage: %w", err)
+	}
+
+	freeMB := int64(stat.Bavail) * int64(stat.Bsize) / 1024 / 1024 // #nosec G115 - block counts fit in int64
+	if freeMB < s.cfg.Health.MinFreeDiskMB {
+		return StatusDown, fmt.Errorf("only %d MB free, minimum is %d MB", freeMB, s.cfg.Health.MinFreeDiskMB)
+	}
+	return StatusUp, nil
+}
+
+func (s *service) checkCleanup(ctx context.Context) (string, error) {
+	if s.cleanup == nil {
+		return StatusDisabled, nil
+	}
+	if err := s.cleanup.CleanupHealth(); err != nil {
+		return StatusDown, err
+	}
+	return StatusUp, nil
+}
@@ -0,0 +1,189 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/core/services/transcription"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+type fakeTranscription struct{ err error }
+
+func (f fakeTranscription) ReadinessCheck(context.Context) error { return f.err }
+
+type fakeCleanup struct{ err error }
+
+func (f fakeCleanup) CleanupHealth() error { return f.err }
+
+// newTestConfig returns a config under which every check passes
+func newTestConfig(t *testing.T) *app.Config {
+	t.Helper()
+	script := filepath.Join(t.TempDir(), "whisper_daemon.py")
+	if err := os.WriteFile(script, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &app.Config{}
+	cfg.FFmpeg.BinaryPath = "true"
+	cfg.FFmpeg.FFprobePath = "true"
+	cfg.Transcription.Enabled = true
+	cfg.Transcription.Python.Path = "sh"
+	cfg.Transcription.Python.ScriptPath = script
+	cfg.Storage.OutputDir = t.TempDir()
+	cfg.Storage.TempDir = t.TempDir()
+	cfg.Health.CheckTimeout = 5 * time.Second
+	cfg.Health.CriticalChecks = []string{CheckFFmpeg, CheckFFprobe, CheckStorage}
+	return cfg
+}
+
+func newTestChecker(cfg *app.Config, transcription TranscriptionChecker, cleanup CleanupChecker) *service {
+	return NewService(cfg, logger.NewWithWriter("error", io.Discard, "text"), transcription, cleanup).(*service)
+}
+
+func TestCheckAllComponentsUp(t *testing.T) {
+	report := newTestChecker(newTestConfig(t), fakeTranscription{}, fakeCleanup{}).Check(context.Background())
+	if !report.Ready {
+		t.Errorf("report not ready: %+v", report.Components)
+	}
+	for _, name := range []string{CheckFFmpeg, CheckFFprobe, CheckWhisper, CheckStorage, CheckDisk, CheckCleanup} {
+		if got := report.Components[name].Status; got != StatusUp {
+			t.Errorf("%s = %s (%s), want %s", name, got, report.Components[name].Message, StatusUp)
+		}
+	}
+}
+
+func TestCheckReadiness(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(cfg *app.Config)
+		component string
+		wantReady bool
+	}{
+		{
+			name:      "critical binary missing",
+			configure: func(cfg *app.Config) { cfg.FFmpeg.BinaryPath = "/nonexistent/ffmpeg" },
+			component: CheckFFmpeg,
+		},
+		{
+			name:      "critical binary failing",
+			configure: func(cfg *app.Config) { cfg.FFmpeg.FFprobePath = "false" },
+			component: CheckFFprobe,
+		},
+		{
+			name: "critical storage not writable",
+			configure: func(cfg *app.Config) {
+				file := filepath.Join(cfg.Storage.TempDir, "file")
+				_ = os.WriteFile(file, nil, 0600)
+				cfg.Storage.OutputDir = filepath.Join(file, "output")
+			},
+			component: CheckStorage,
+		},
+		{
+			name:      "non-critical disk below minimum",
+			configure: func(cfg *app.Config) { cfg.Health.MinFreeDiskMB = 1 << 50 },
+			component: CheckDisk,
+			wantReady: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			tt.configure(cfg)
+			report := newTestChecker(cfg, fakeTranscription{}, fakeCleanup{}).Check(context.Background())
+
+			if got := report.Components[tt.component]; got.Status != StatusDown || got.Message == "" {
+				t.Errorf("%s = %+v, want down with a message", tt.component, got)
+			}
+			if report.Ready != tt.wantReady {
+				t.Errorf("ready = %v, want %v", report.Ready, tt.wantReady)
+			}
+		})
+	}
+}
+
+func TestCheckWhisper(t *testing.T) {
+	tests := []struct {
+		name          string
+		disabled      bool
+		transcription TranscriptionChecker
+		pythonPath    string
+		missingScript bool
+		want          string
+		wantMessage   string
+	}{
+		{name: "disabled", disabled: true, transcription: fakeTranscription{err: fmt.Errorf("down")}, want: StatusDisabled},
+		{name: "daemon answers", transcription: fakeTranscription{}, want: StatusUp},
+		{
+			name:          "daemon unresponsive",
+			transcription: fakeTranscription{err: fmt.Errorf("transcription daemon unresponsive")},
+			want:          StatusDown,
+			wantMessage:   "unresponsive",
+		},
+		{name: "no daemon, can start one", transcription: fakeTranscription{err: transcription.ErrNoDaemonRunning}, want: StatusUp},
+		{
+			name:          "no daemon, python missing",
+			transcription: fakeTranscription{err: transcription.ErrNoDaemonRunning},
+			pythonPath:    "/nonexistent/python3",
+			want:          StatusDown,
+			wantMessage:   "python interpreter not found",
+		},
+		{
+			name:          "no daemon, script missing",
+			transcription: fakeTranscription{err: transcription.ErrNoDaemonRunning},
+			missingScript: true,
+			want:          StatusDown,
+			wantMessage:   "whisper daemon script not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			cfg.Transcription.Enabled = !tt.disabled
+			if tt.pythonPath != "" {
+				cfg.Transcription.Python.Path = tt.pythonPath
+			}
+			if tt.missingScript {
+				cfg.Transcription.Python.ScriptPath += ".missing"
+			}
+
+			got := newTestChecker(cfg, tt.transcription, nil).Check(context.Background()).Components[CheckWhisper]
+			if got.Status != tt.want || !strings.Contains(got.Message, tt.wantMessage) {
+				t.Errorf("whisper = %+v, want %s with %q", got, tt.want, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestCheckCleanup(t *testing.T) {
+	cfg := newTestConfig(t)
+	if got := newTestChecker(cfg, nil, nil).Check(context.Background()).Components[CheckCleanup]; got.Status != StatusDisabled {
+		t.Errorf("cleanup without checker = %+v, want %s", got, StatusDisabled)
+	}
+	failing := fakeCleanup{err: fmt.Errorf("3 consecutive cleanup runs failed")}
+	if got := newTestChecker(cfg, nil, failing).Check(context.Background()).Components[CheckCleanup]; got.Status != StatusDown {
+		t.Errorf("failing cleanup = %+v, want %s", got, StatusDown)
+	}
+}
+
+func TestRunCheckTimesOut(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.Health.CheckTimeout = 10 * time.Millisecond
+	s := newTestChecker(cfg, nil, nil)
+
+	got := s.runCheck(context.Background(), func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond) // A check that ignores its context
+		return StatusUp, nil
+	})
+	if got.Status != StatusDown || !strings.Contains(got.Message, "timed out") {
+		t.Errorf("runCheck() = %+v, want down after timing out", got)
+	}
+}
@@ -0,0 +1,141 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/activadee/videocraft/internal/api/models"
+)
+
+const (
+	// callbackSignatureHeader carries "sha256=<hex HMAC of the body>"
+	callbackSignatureHeader = "X-Videocraft-Signature"
+
+	// callbackRetryDelay is the pause before the first retry of a callback,
+	// growing linearly with every further retry
+	callbackRetryDelay = 2 * time.Second
+)
+
+// callbackPayload is POSTed to a job's callback URL once it finishes
+type callbackPayload struct {
+	JobID   string           `json:"job_id"`
+	Status  models.JobStatus `json:"status"`
+	VideoID string           `json:"video_id,omitempty"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// newCallbackClient returns the HTTP client for job callbacks. It refuses
+// connections to internal addresses at dial time, so neither DNS rebinding
+// nor redirects can point a callback at the service's own network.
+func newCallbackClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isInternalIP(ip) {
+				return fmt.Errorf("callback to internal address %s refused", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isInternalIP reports whether an address is not publicly routable
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// notifyCallback delivers the outcome of a finished job to its callback URL
+// in the background
+func (js *service) notifyCallback(job models.Job) {
+	callbackURL := job.Config.CallbackURL()
+	if callbackURL == "" {
+		return
+	}
+
+	payload := callbackPayload{
+		JobID:   job.ID,
+		Status:  job.Status,
+		VideoID: job.VideoID,
+		Error:   job.Error,
+	}
+	go js.deliverCallback(callbackURL, payload)
+}
+
+// deliverCallback POSTs the payload, retrying network errors and 5xx
+// responses up to job.callback_retries times
+func (js *service) deliverCallback(callbackURL string, payload callbackPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		js.log.Errorf("Failed to encode callback of job %s: %v", payload.JobID, err)
+		return
+	}
+
+	attempts := js.cfg.Job.CallbackRetries + 1
+	for attempt := 1; attempt <= attempts; attempt++ {
+		retryable, err := js.postCallback(callbackURL, body)
+		if err == nil {
+			js.log.Debugf("Callback of job %s delivered", payload.JobID)
+			return
+		}
+		if !retryable || attempt == attempts {
+			js.log.Errorf("Failed to deliver callback of job %s after %d attempts: %v", payload.JobID, attempt, err)
+			return
+		}
+		js.log.Warnf("Callback of job %s failed (attempt %d of %d): %v", payload.JobID, attempt, attempts, err)
+		time.Sleep(time.Duration(attempt) * callbackRetryDelay)
+	}
+}
+
+// postCallback sends one callback request and reports whether a failure is
+// worth retrying
+func (js *service) postCallback(callbackURL string, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), js.cfg.Job.CallbackTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("invalid callback request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", js.cfg.Storage.UserAgent)
+	if js.cfg.Job.CallbackSecret != "" {
+		mac := hmac.New(sha256.New, []byte(js.cfg.Job.CallbackSecret))
+		mac.Write(body)
+		req.Header.Set(callbackSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := js.callbacks.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return true, fmt.Errorf("callback returned status %d", resp.StatusCode)
+	case resp.StatusCode >= 300:
+		return false, fmt.Errorf("callback returned status %d", resp.StatusCode)
+	}
+	return false, nil
+}
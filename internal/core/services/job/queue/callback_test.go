@@ -0,0 +1,174 @@
+package queue
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+// receivedCallback is one callback request seen by the test server
+type receivedCallback struct {
+	signature string
+	body      []byte
+}
+
+// newCallbackServer records every callback it receives
+func newCallbackServer(t *testing.T) (*httptest.Server, <-chan receivedCallback) {
+	t.Helper()
+	received := make(chan receivedCallback, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedCallback{signature: r.Header.Get(callbackSignatureHeader), body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestCallbackIsSignedForEveryOutcome(t *testing.T) {
+	const secret = "callback-secret"
+
+	tests := []struct {
+		name       string
+		render     func(ctx context.Context, _ *models.VideoConfigArray) (string, error)
+		cancel     bool
+		wantStatus models.JobStatus
+	}{
+		{name: "completed", wantStatus: models.JobStatusCompleted},
+		{
+			name: "failed",
+			render: func(context.Context, *models.VideoConfigArray) (string, error) {
+				return "", errors.InvalidInput("broken filter graph")
+			},
+			wantStatus: models.JobStatusFailed,
+		},
+		{name: "cancelled", cancel: true, wantStatus: models.JobStatusCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig()
+			cfg.Job.CallbackSecret = secret
+			js := newTestJobService(t, cfg)
+			js.ffmpeg.generate = tt.render
+			// The production client refuses loopback addresses such as the test server
+			js.callbacks = &http.Client{Timeout: time.Second}
+
+			server, received := newCallbackServer(t)
+			config := newTestVideoConfig()
+			(*config)[0].CallbackURL = server.URL + "/hook"
+
+			job, err := js.CreateJob(config, "")
+			if err != nil {
+				t.Fatalf("CreateJob() error = %v", err)
+			}
+			if tt.cancel {
+				if err := js.CancelJob(job.ID); err != nil {
+					t.Fatalf("CancelJob() error = %v", err)
+				}
+			} else if err := js.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			var callback receivedCallback
+			select {
+			case callback = <-received:
+			case <-time.After(5 * time.Second):
+				t.Fatal("no callback received")
+			}
+
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write(callback.body)
+			if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); callback.signature != want {
+				t.Errorf("%s = %q, want %q", callbackSignatureHeader, callback.signature, want)
+			}
+
+			var payload callbackPayload
+			if err := json.Unmarshal(callback.body, &payload); err != nil {
+				t.Fatalf("callback body %s: %v", callback.body, err)
+			}
+			if payload.JobID != job.ID || payload.Status != tt.wantStatus {
+				t.Errorf("payload = %+v, want job %s with status %s", payload, job.ID, tt.wantStatus)
+			}
+			if tt.wantStatus == models.JobStatusFailed && payload.Error == "" {
+				t.Error("failed callback has no error")
+			}
+		})
+	}
+}
+
+func TestCallbackUnsignedWithoutSecret(t *testing.T) {
+	js := newTestJobService(t, newTestConfig())
+	js.callbacks = &http.Client{Timeout: time.Second}
+	server, received := newCallbackServer(t)
+
+	js.deliverCallback(server.URL, callbackPayload{JobID: "job-1", Status: models.JobStatusCompleted})
+	select {
+	case callback := <-received:
+		if callback.signature != "" {
+			t.Errorf("%s = %q, want no signature", callbackSignatureHeader, callback.signature)
+		}
+	default:
+		t.Fatal("no callback received")
+	}
+}
+
+func TestCallbackClientRefusesInternalAddresses(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	client := newCallbackClient(time.Second)
+	for _, target := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+		resp, err := client.Post(target, "application/json", strings.NewReader("{}"))
+		if err == nil {
+			resp.Body.Close()
+			t.Errorf("POST %s succeeded, want the dial refused", target)
+			continue
+		}
+		if !strings.Contains(err.Error(), "callback to internal address") {
+			t.Errorf("POST %s error = %v, want internal address refusal", target, err)
+		}
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("server got %d requests, want none", n)
+	}
+}
+
+func TestIsInternalIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		{"fd00::1", true},
+		{"0.0.0.0", true},
+		{"93.184.216.34", false},
+		{"2606:4700::1111", false},
+	}
+	for _, tt := range tests {
+		if got := isInternalIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("isInternalIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package queue
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+// deadLetterRecord is the offline record of a failed job
+type deadLetterRecord struct {
+	JobID      string                  `json:"job_id"`
+	Config     models.VideoConfigArray `json:"config"`
+	Error      string                  `json:"error"`
+	ErrorCode  string                  `json:"error_code,omitempty"`
+	FFmpegLog  string                  `json:"ffmpeg_log,omitempty"`
+	Warnings   []string                `json:"warnings,omitempty"`
+	Progress   int                     `json:"progress"`
+	Attempts   int                     `json:"attempts"`
+	CreatedAt  time.Time               `json:"created_at"`
+	StartedAt  *time.Time              `json:"started_at,omitempty"`
+	FailedAt   time.Time               `json:"failed_at"`
+	RecordedAt time.Time               `json:"recorded_at"`
+}
+
+// recordDeadLetter writes a failed job to the dead-letter directory as
+// "<jobID>.json". Jobs that did not end in the failed status are ignored.
+func (js *service) recordDeadLetter(jobID string, cause error) {
+	dir := js.cfg.Job.DeadLetterDir
+	if dir == "" {
+		return
+	}
+
+	js.mu.RLock()
+	job, exists := js.jobs[jobID]
+	if !exists || job.Status != models.JobStatusFailed {
+		js.mu.RUnlock()
+		return
+	}
+	record := deadLetterRecord{
+		JobID:      job.ID,
+		Config:     job.Config,
+		Error:      job.Error,
+		Warnings:   append([]string(nil), job.Warnings...),
+		Progress:   job.Progress,
+		Attempts:   job.RetryCount + 1,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FailedAt:   job.UpdatedAt,
+		RecordedAt: time.Now(),
+	}
+	if job.CompletedAt != nil {
+		record.FailedAt = *job.CompletedAt
+	}
+	js.mu.RUnlock()
+
+	if record.Error == "" && cause != nil {
+		record.Error = cause.Error()
+	}
+	var vpe *errors.VideoProcessingError
+	if stderrors.As(cause, &vpe) {
+		record.ErrorCode = vpe.Code
+		if log, ok := vpe.Details["ffmpeg_log"].(string); ok {
+			record.FFmpegLog = log
+		}
+	}
+
+	if err := writeDeadLetter(dir, record); err != nil {
+		js.log.Errorf("Failed to write dead-letter record for job %s: %v", jobID, err)
+		return
+	}
+	js.log.Infof("Failed job %s recorded in dead-letter directory %s", jobID, dir)
+}
+
+// writeDeadLetter stores a record as indented JSON; records can hold source
+// URLs with credentials, so they are readable by the service user only
+func writeDeadLetter(dir string, record deadLetterRecord) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode dead-letter record: %w", err)
+	}
+
+	path := filepath.Join(dir, record.JobID+".json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write dead-letter record: %w", err)
+	}
+	return nil
+}
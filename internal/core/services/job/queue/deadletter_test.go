@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+func TestExhaustedRetriesRecordDeadLetter(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Job.MaxRetries = 2
+	cfg.Job.RetryBackoff = time.Millisecond
+	cfg.Job.DeadLetterDir = t.TempDir()
+	js := newTestJobService(t, cfg)
+
+	var attempts atomic.Int32
+	js.ffmpeg.generate = func(context.Context, *models.VideoConfigArray) (string, error) {
+		n := attempts.Add(1)
+		return "", errors.FFmpegFailedWithLog(fmt.Errorf("attempt %d failed", n), "encoder crashed")
+	}
+	if err := js.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := js.CreateJob(newTestVideoConfig(), "")
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	waitForStatus(t, js, job.ID, models.JobStatusFailed)
+
+	path := filepath.Join(cfg.Job.DeadLetterDir, job.ID+".json")
+	waitFor(t, "dead-letter record", func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record deadLetterRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("dead-letter record %s: %v", data, err)
+	}
+
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("rendered %d times, want 3", got)
+	}
+	if record.Attempts != 3 {
+		t.Errorf("record attempts = %d, want 3", record.Attempts)
+	}
+	if want := "attempt 3 failed"; !strings.Contains(record.Error, want) {
+		t.Errorf("record error = %q, want the last error %q", record.Error, want)
+	}
+	if record.ErrorCode != errors.ErrCodeFFmpegFailed {
+		t.Errorf("record error code = %q, want %q", record.ErrorCode, errors.ErrCodeFFmpegFailed)
+	}
+	if record.FFmpegLog != "encoder crashed" {
+		t.Errorf("record ffmpeg log = %q, want %q", record.FFmpegLog, "encoder crashed")
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() != 0600 {
+		t.Errorf("record mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestRecordDeadLetterIgnoresJobsThatDidNotFail(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Job.DeadLetterDir = t.TempDir()
+	js := newTestJobService(t, cfg)
+
+	job, err := js.CreateJob(newTestVideoConfig(), "")
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	js.recordDeadLetter(job.ID, errors.FFmpegFailed(fmt.Errorf("boom")))
+
+	if entries, _ := os.ReadDir(cfg.Job.DeadLetterDir); len(entries) != 0 {
+		t.Errorf("dead-letter directory has %d records for a pending job", len(entries))
+	}
+}
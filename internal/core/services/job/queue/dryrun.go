@@ -0,0 +1,43 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+// DryRun analyzes media and builds the FFmpeg command for the first project
+// without creating a job or spawning FFmpeg
+func (js *service) DryRun(ctx context.Context, config *models.VideoConfigArray) (*models.RenderCommand, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.InvalidInput(err.Error())
+	}
+
+	analyzed := cloneConfig(*config)
+	if _, err := js.analyzeMediaWithServices(ctx, &analyzed); err != nil {
+		return nil, errors.InvalidInput(fmt.Sprintf("media analysis failed: %v", err))
+	}
+
+	cmd, err := js.ffmpeg.BuildCommand(&analyzed)
+	if err != nil {
+		return nil, errors.InvalidInput(fmt.Sprintf("failed to build command: %v", err))
+	}
+
+	// Chapter metadata and similar inputs are written while building; the
+	// command never runs, so they are removed right away
+	for _, path := range cmd.TempFiles {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			js.log.Warnf("Failed to remove dry-run temp file %s: %v", path, err)
+		}
+	}
+
+	return &models.RenderCommand{
+		Binary:           js.cfg.FFmpeg.BinaryPath,
+		Args:             cmd.Args,
+		OutputPath:       cmd.OutputPath,
+		SubtitlesOmitted: js.needsSubtitles(analyzed[0]),
+	}, nil
+}
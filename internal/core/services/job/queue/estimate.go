@@ -0,0 +1,127 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+const (
+	// Reference resolution the estimate coefficients are expressed for
+	estimateReferenceWidth  = 1920
+	estimateReferenceHeight = 1080
+)
+
+// EstimateRender analyzes media durations and predicts render time and output size
+// for the first project without creating a job or spawning FFmpeg
+func (js *service) EstimateRender(ctx context.Context, config *models.VideoConfigArray) (*models.RenderEstimate, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.InvalidInput(err.Error())
+	}
+
+	// Work on a copy so analysis results never leak into the caller's config
+	analyzed := cloneConfig(*config)
+	if _, err := js.analyzeMediaWithServices(ctx, &analyzed); err != nil {
+		return nil, errors.InvalidInput(fmt.Sprintf("media analysis failed: %v", err))
+	}
+
+	return js.estimateProject(analyzed[0]), nil
+}
+
+// cloneConfig copies the projects with their scene and element slices so
+// media analysis can fill in durations without touching the original
+func cloneConfig(config models.VideoConfigArray) models.VideoConfigArray {
+	cloned := make(models.VideoConfigArray, len(config))
+	copy(cloned, config)
+	for i := range cloned {
+		cloned[i].Scenes = make([]models.Scene, len(config[i].Scenes))
+		for j, scene := range config[i].Scenes {
+			cloned[i].Scenes[j] = scene
+			cloned[i].Scenes[j].Elements = append([]models.Element(nil), scene.Elements...)
+		}
+		cloned[i].Elements = append([]models.Element(nil), config[i].Elements...)
+	}
+	return cloned
+}
+
+// estimateProject applies the configured cost model to an analyzed project
+func (js *service) estimateProject(project models.VideoProject) *models.RenderEstimate {
+	estimate := &models.RenderEstimate{
+		Width:   project.Width,
+		Height:  project.Height,
+		Quality: project.Quality,
+	}
+
+	var audioDuration, videoDuration float64
+	var audioDurations []float64
+	for _, scene := range project.Scenes {
+		for _, element := range scene.Elements {
+			if element.Type != "audio" {
+				continue
+			}
+			audioDuration += element.Duration
+			audioDurations = append(audioDurations, element.Duration)
+			estimate.Elements = append(estimate.Elements, models.ElementDuration{
+				SceneID:  scene.ID,
+				Type:     element.Type,
+				Src:      element.Src,
+				Duration: element.Duration,
+			})
+		}
+	}
+
+	for _, overlap := range project.AudioCrossfades(audioDurations, js.cfg.Audio.CrossfadeSeconds) {
+		audioDuration -= overlap
+	}
+
+	for _, element := range project.Elements {
+		if element.Type != "video" {
+			continue
+		}
+		if videoDuration == 0 {
+			videoDuration = element.Duration
+		}
+		estimate.Elements = append(estimate.Elements, models.ElementDuration{
+			Type:     element.Type,
+			Src:      element.Src,
+			Duration: element.Duration,
+		})
+	}
+
+	// Mirror the engine: audio drives the output length, background video is the fallback
+	// Timelines carry explicit clip windows
+	if project.Timeline != nil {
+		estimate.TotalDuration = project.Timeline.Duration()
+	} else if audioDuration > 0 {
+		estimate.TotalDuration = audioDuration + js.cfg.Audio.TailPadding
+	} else {
+		estimate.TotalDuration = videoDuration
+	}
+
+	if estimate.Width <= 0 || estimate.Height <= 0 {
+		estimate.Width = estimateReferenceWidth
+		estimate.Height = estimateReferenceHeight
+	}
+	pixelFactor := float64(estimate.Width*estimate.Height) / float64(estimateReferenceWidth*estimateReferenceHeight)
+
+	coefficients := js.cfg.Estimate
+	renderFactor := coefficients.RenderSecondsPerSecond
+	bitrateKbps := coefficients.BitrateKbps
+	if project.Quality == "high" {
+		renderFactor *= coefficients.HighQualityMultiplier
+		bitrateKbps = coefficients.HighQualityBitrateKbps
+	}
+	if project.MaxBitrateKbps > 0 {
+		bitrateKbps = min(bitrateKbps, project.MaxBitrateKbps)
+	}
+
+	estimate.EstimatedRenderSeconds = estimate.TotalDuration * renderFactor * pixelFactor
+	estimate.EstimatedSizeBytes = int64(estimate.TotalDuration * float64(bitrateKbps) * pixelFactor * 1000 / 8)
+
+	js.log.Debugf("Render estimate: %.2fs output, %.2fs render, %d bytes",
+		estimate.TotalDuration, estimate.EstimatedRenderSeconds, estimate.EstimatedSizeBytes)
+
+	return estimate
+}
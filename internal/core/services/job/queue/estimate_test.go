@@ -0,0 +1,93 @@
+package queue
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/activadee/videocraft/internal/api/models"
+)
+
+func TestEstimateRender(t *testing.T) {
+	tests := []struct {
+		name           string
+		quality        string
+		maxBitrateKbps int
+		noNarration    bool
+		wantDuration   float64
+		wantRender     float64
+		wantSize       int64
+	}{
+		// 4s narration plus 1s tail padding at 720p, 4/9 of the 1080p reference
+		{name: "narrated", wantDuration: 5, wantRender: 5 * 2 * 4.0 / 9, wantSize: 1388888},
+		{name: "high quality", quality: "high", wantDuration: 5, wantRender: 5 * 2 * 1.5 * 4.0 / 9, wantSize: 2222222},
+		{name: "bitrate cap", maxBitrateKbps: 1000, wantDuration: 5, wantRender: 5 * 2 * 4.0 / 9, wantSize: 277777},
+		// Without narration the background video sets the length
+		{name: "background video only", noNarration: true, wantDuration: 30, wantRender: 30 * 2 * 4.0 / 9, wantSize: 8333333},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig()
+			cfg.Audio.TailPadding = 1
+			cfg.Estimate.RenderSecondsPerSecond = 2
+			cfg.Estimate.BitrateKbps = 5000
+			cfg.Estimate.HighQualityMultiplier = 1.5
+			cfg.Estimate.HighQualityBitrateKbps = 8000
+			js := newTestJobService(t, cfg)
+
+			config := newTestVideoConfig()
+			(*config)[0].Quality = tt.quality
+			(*config)[0].MaxBitrateKbps = tt.maxBitrateKbps
+			if tt.noNarration {
+				(*config)[0].Scenes[0].Elements = nil
+			}
+
+			estimate, err := js.EstimateRender(context.Background(), config)
+			if err != nil {
+				t.Fatalf("EstimateRender() error = %v", err)
+			}
+			if estimate.TotalDuration != tt.wantDuration {
+				t.Errorf("total duration = %v, want %v", estimate.TotalDuration, tt.wantDuration)
+			}
+			if math.Abs(estimate.EstimatedRenderSeconds-tt.wantRender) > 1e-9 {
+				t.Errorf("render seconds = %v, want %v", estimate.EstimatedRenderSeconds, tt.wantRender)
+			}
+			if estimate.EstimatedSizeBytes != tt.wantSize {
+				t.Errorf("size = %d, want %d", estimate.EstimatedSizeBytes, tt.wantSize)
+			}
+
+			// Analysis fills in a copy, never the caller's config
+			for _, element := range (*config)[0].Scenes[0].Elements {
+				if element.Duration != 0 {
+					t.Errorf("caller's %s element got duration %v", element.Type, element.Duration)
+				}
+			}
+		})
+	}
+}
+
+func TestEstimateRenderReportsElementDurations(t *testing.T) {
+	js := newTestJobService(t, newTestConfig())
+	estimate, err := js.EstimateRender(context.Background(), newTestVideoConfig())
+	if err != nil {
+		t.Fatalf("EstimateRender() error = %v", err)
+	}
+
+	if len(estimate.Elements) != 2 {
+		t.Fatalf("elements = %+v, want narration and background video", estimate.Elements)
+	}
+	narration, background := estimate.Elements[0], estimate.Elements[1]
+	if narration.SceneID != "intro" || narration.Type != "audio" || narration.Duration != 4 {
+		t.Errorf("narration = %+v, want 4s audio of scene intro", narration)
+	}
+	if background.Type != "video" || background.Duration != 30 {
+		t.Errorf("background = %+v, want 30s video", background)
+	}
+}
+
+func TestEstimateRenderRejectsInvalidConfig(t *testing.T) {
+	js := newTestJobService(t, newTestConfig())
+	if _, err := js.EstimateRender(context.Background(), &models.VideoConfigArray{}); err == nil {
+		t.Error("EstimateRender() without projects error = nil")
+	}
+}
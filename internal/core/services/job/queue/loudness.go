@@ -0,0 +1,50 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+// measureLoudness runs the first pass of two-pass loudness normalization
+// once per job, before any rendition is built, and stores the result on
+// each audible narration element. A clip that cannot be measured is
+// normalized in a single pass instead; only cancellation fails the job.
+func (js *service) measureLoudness(ctx context.Context, job *models.Job) error {
+	if js.cfg.FFmpeg.LoudnormMode != app.LoudnormTwoPass {
+		return nil
+	}
+	log := logger.FromContext(ctx, js.log)
+
+	for projectIdx := range job.Config {
+		project := &job.Config[projectIdx]
+		if project.Timeline != nil || !project.NormalizeAudioOr(js.cfg.FFmpeg.NormalizeAudio) {
+			continue
+		}
+
+		for sceneIdx := range project.Scenes {
+			scene := &project.Scenes[sceneIdx]
+			for elementIdx := range scene.Elements {
+				element := &scene.Elements[elementIdx]
+				if element.Type != "audio" || project.AudioMuted(scene, *element) {
+					continue
+				}
+
+				measurement, err := js.ffmpeg.MeasureLoudness(ctx, *element)
+				if ctx.Err() != nil {
+					return fmt.Errorf("loudness measurement cancelled: %w", ctx.Err())
+				}
+				if err != nil {
+					log.Warnf("Loudness measurement of %s failed, normalizing in a single pass: %v", element.Src, err)
+					js.addJobWarning(job.ID, fmt.Sprintf("loudness of %s could not be measured; normalized in a single pass", element.Src))
+					continue
+				}
+				element.Loudness = measurement
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/core/media/subtitle"
+)
+
+// manifestSubtitles is what the pipeline knows about delivered subtitles;
+// result is nil when the video has none
+type manifestSubtitles struct {
+	result     *subtitle.SubtitleResult
+	sidecar    bool
+	subtitleID string
+}
+
+// storeManifest builds the render manifest of a video rendered from project
+// and stores it next to the video. Failures are reported to the caller as non-fatal.
+func (js *service) storeManifest(job *models.Job, project models.VideoProject, videoID string, subtitles manifestSubtitles) (string, error) {
+	videoPath, err := js.storage.GetVideo(videoID)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate rendered video: %w", err)
+	}
+
+	outputInfo, err := js.video.GetVideoMetadata(videoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to probe rendered video: %w", err)
+	}
+
+	manifest := js.buildManifest(job, project, outputInfo, videoID, subtitles)
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	return js.storage.StoreManifest(data, videoID)
+}
+
+// buildManifest aggregates analysis, timing and render settings of the
+// rendered project (the job's first, sized for its rendition)
+func (js *service) buildManifest(job *models.Job, project models.VideoProject, outputInfo *models.VideoInfo, videoID string, subtitles manifestSubtitles) *models.RenderManifest {
+	manifest := &models.RenderManifest{
+		JobID:     job.ID,
+		VideoID:   videoID,
+		CreatedAt: time.Now(),
+		Output: models.ManifestOutput{
+			Width:      outputInfo.Width,
+			Height:     outputInfo.Height,
+			Duration:   outputInfo.Duration,
+			Format:     outputInfo.Format,
+			VideoCodec: outputInfo.Codec,
+			AudioCodec: project.OutputAudioCodec(),
+			FrameRate:  outputInfo.FrameRate,
+			HasAudio:   outputInfo.HasAudio,
+		},
+		Sources: make([]models.ManifestSource, 0),
+		Parameters: models.ManifestParameters{
+			Resolution:           project.Resolution,
+			Quality:              project.Quality,
+			Width:                project.Width,
+			Height:               project.Height,
+			AudioCodec:           project.OutputAudioCodec(),
+			AudioSampleFormat:    project.AudioSampleFormat,
+			AudioProfile:         project.AudioProfile,
+			KeyframeInterval:     project.KeyframeInterval,
+			KeyframeIntervalUnit: project.KeyframeIntervalUnit,
+			OutputID:             project.OutputID,
+			OutputSubdir:         project.OutputSubdir,
+			Timeline:             project.Timeline != nil,
+		},
+	}
+
+	if subtitles.result != nil {
+		mode := models.SubtitleModeBurn
+		if subtitles.sidecar {
+			mode = project.SubtitleMode
+		}
+		manifest.Subtitles = models.ManifestSubtitles{
+			Present:    true,
+			Mode:       mode,
+			Format:     strings.TrimPrefix(filepath.Ext(subtitles.result.FilePath), "."),
+			Style:      subtitles.result.Style,
+			EventCount: subtitles.result.EventCount,
+			SubtitleID: subtitles.subtitleID,
+		}
+	}
+
+	for _, element := range project.Elements {
+		if element.Src == "" {
+			continue
+		}
+		manifest.Sources = append(manifest.Sources, models.ManifestSource{
+			Type:     element.Type,
+			Src:      js.redactSrc(element.Src),
+			Duration: element.Duration,
+		})
+	}
+
+	// Scenes are laid out back to back by their analyzed audio durations;
+	// crossfaded narration starts while the previous clip fades out
+	var audioDurations []float64
+	for _, scene := range project.Scenes {
+		for _, element := range scene.Elements {
+			if element.Type == "audio" {
+				audioDurations = append(audioDurations, element.Duration)
+			}
+		}
+	}
+	crossfades := project.AudioCrossfades(audioDurations, js.cfg.Audio.CrossfadeSeconds)
+
+	var sceneStart float64
+	audioIndex := 0
+	for _, scene := range project.Scenes {
+		var sceneDuration float64
+		for _, element := range scene.Elements {
+			if element.Type == "audio" {
+				if audioIndex > 0 && audioIndex-1 < len(crossfades) {
+					overlap := crossfades[audioIndex-1]
+					if sceneDuration == 0 {
+						sceneStart -= overlap
+					} else {
+						sceneDuration -= overlap
+					}
+				}
+				sceneDuration += element.Duration
+				audioIndex++
+			}
+			if element.Src == "" {
+				continue
+			}
+			manifest.Sources = append(manifest.Sources, models.ManifestSource{
+				SceneID:  scene.ID,
+				Type:     element.Type,
+				Src:      js.redactSrc(element.Src),
+				Duration: element.Duration,
+			})
+		}
+		manifest.Scenes = append(manifest.Scenes, models.ManifestScene{
+			ID:        scene.ID,
+			StartTime: sceneStart,
+			EndTime:   sceneStart + sceneDuration,
+		})
+		sceneStart += sceneDuration
+	}
+
+	if project.Timeline != nil {
+		for _, track := range project.Timeline.Tracks {
+			for _, clip := range track.Clips {
+				if clip.Src == "" {
+					continue
+				}
+				manifest.Sources = append(manifest.Sources, models.ManifestSource{
+					Type: string(track.Type),
+					Src:  js.redactSrc(clip.Src),
+				})
+			}
+		}
+	}
+
+	manifest.Locale = js.cfg.Storage.Locale
+	manifest.Display = manifestDisplay(manifest, language.Make(js.cfg.Storage.Locale))
+
+	return manifest
+}
+
+// manifestDisplay formats the manifest's durations and frame rate for a locale
+func manifestDisplay(manifest *models.RenderManifest, tag language.Tag) models.ManifestDisplay {
+	printer := message.NewPrinter(tag)
+
+	display := models.ManifestDisplay{
+		Duration: formatClock(printer, manifest.Output.Duration),
+	}
+	if manifest.Output.FrameRate > 0 {
+		display.FrameRate = printer.Sprintf("%v fps", number.Decimal(manifest.Output.FrameRate, number.MaxFractionDigits(3)))
+	}
+	for _, scene := range manifest.Scenes {
+		display.Scenes = append(display.Scenes, fmt.Sprintf("%s: %s – %s",
+			scene.ID, formatClock(printer, scene.StartTime), formatClock(printer, scene.EndTime)))
+	}
+
+	return display
+}
+
+// formatClock renders seconds as [h:]mm:ss.ff with the locale's decimal separator
+func formatClock(printer *message.Printer, seconds float64) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	hours := int(seconds / 3600)
+	minutes := int(seconds/60) % 60
+	secs := seconds - float64(hours*3600+minutes*60)
+
+	secText := printer.Sprintf("%v", number.Decimal(secs, number.MinFractionDigits(2), number.MaxFractionDigits(2), number.MinIntegerDigits(2)))
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%s", hours, minutes, secText)
+	}
+	return fmt.Sprintf("%d:%s", minutes, secText)
+}
+
+// redactSrc strips credentials, query strings and fragments from a source URL
+// and reduces uploaded files to their file name
+func (js *service) redactSrc(src string) string {
+	if js.cfg.Storage.IsUploadedFile(src) {
+		return "upload:" + filepath.Base(src)
+	}
+
+	parsed, err := url.Parse(src)
+	if err != nil || parsed.Host == "" {
+		return "[redacted]"
+	}
+	parsed.User = nil
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	return parsed.String()
+}
@@ -0,0 +1,127 @@
+package queue
+
+import (
+	"container/heap"
+	"sync"
+
+	"github.com/activadee/videocraft/internal/api/models"
+)
+
+// jobQueue holds queued jobs ordered by priority, oldest first within a
+// priority. Workers block in pop until a job is queued or the queue closes.
+type jobQueue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	items    jobHeap
+	capacity int
+	nextSeq  uint64
+	closed   bool
+}
+
+// queuedJob is a heap entry; seq keeps FIFO order among equal priorities
+type queuedJob struct {
+	job  *models.Job
+	rank int
+	seq  uint64
+}
+
+func newJobQueue(capacity int) *jobQueue {
+	q := &jobQueue{capacity: capacity}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push queues a job and reports false when the queue is full or closed
+func (q *jobQueue) push(job *models.Job) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed || len(q.items) >= q.capacity {
+		return false
+	}
+	heap.Push(&q.items, queuedJob{job: job, rank: job.Priority.Rank(), seq: q.nextSeq})
+	q.nextSeq++
+	q.cond.Signal()
+	return true
+}
+
+// pop blocks until it can return the highest-priority job. It returns nil
+// when quit is closed, or once a closed queue has been drained.
+func (q *jobQueue) pop(quit <-chan struct{}) *models.Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		select {
+		case <-quit:
+			return nil
+		default:
+		}
+		if len(q.items) > 0 {
+			return heap.Pop(&q.items).(queuedJob).job
+		}
+		if q.closed {
+			return nil
+		}
+		q.cond.Wait()
+	}
+}
+
+// remove drops a queued job so it never reaches a worker
+func (q *jobQueue) remove(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, item := range q.items {
+		if item.job.ID == id {
+			heap.Remove(&q.items, i)
+			return true
+		}
+	}
+	return false
+}
+
+// len returns the number of queued jobs
+func (q *jobQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// wake lets blocked workers check their quit channel
+func (q *jobQueue) wake() {
+	q.mu.Lock()
+	q.cond.Broadcast()
+	q.mu.Unlock()
+}
+
+// close rejects further jobs; workers drain the queued ones and then exit
+func (q *jobQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+}
+
+// jobHeap implements heap.Interface with the highest rank on top
+type jobHeap []queuedJob
+
+func (h jobHeap) Len() int { return len(h) }
+
+func (h jobHeap) Less(i, j int) bool {
+	if h[i].rank != h[j].rank {
+		return h[i].rank > h[j].rank
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h jobHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *jobHeap) Push(x any) { *h = append(*h, x.(queuedJob)) }
+
+func (h *jobHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,137 @@
+package queue
+
+import (
+	"sync"
+
+	"github.com/activadee/videocraft/internal/api/models"
+)
+
+// ProgressUpdate is a job progress or status change delivered to subscribers
+type ProgressUpdate struct {
+	JobID    string           `json:"job_id"`
+	Status   models.JobStatus `json:"status"`
+	Progress int              `json:"progress"`
+
+	// Render details the running render; set only on render progress updates
+	Render *models.RenderProgress `json:"render,omitempty"`
+}
+
+// progressSubscriber receives updates for a single job. The buffered channel
+// drops its oldest update when full so publishers never block.
+type progressSubscriber struct {
+	updates chan ProgressUpdate
+	last    int
+}
+
+// progressHub fans job updates out to subscribers. It has its own lock so
+// publishing never happens under the jobs mutex.
+type progressHub struct {
+	mu          sync.Mutex
+	bufferSize  int
+	subscribers map[string]map[*progressSubscriber]struct{}
+}
+
+func newProgressHub(bufferSize int) *progressHub {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	return &progressHub{
+		bufferSize:  bufferSize,
+		subscribers: make(map[string]map[*progressSubscriber]struct{}),
+	}
+}
+
+// subscribe registers a subscriber for the job of initial, which is queued as
+// its first update, and returns the update channel and an unsubscribe function.
+// The channel is closed on unsubscribe or when the job reaches a terminal status.
+func (h *progressHub) subscribe(initial ProgressUpdate) (<-chan ProgressUpdate, func()) {
+	jobID := initial.JobID
+	sub := &progressSubscriber{
+		updates: make(chan ProgressUpdate, h.bufferSize),
+		last:    initial.Progress,
+	}
+	sub.updates <- initial
+
+	if isTerminalStatus(initial.Status) {
+		close(sub.updates)
+		return sub.updates, func() {}
+	}
+
+	h.mu.Lock()
+	if h.subscribers[jobID] == nil {
+		h.subscribers[jobID] = make(map[*progressSubscriber]struct{})
+	}
+	h.subscribers[jobID][sub] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if subs, ok := h.subscribers[jobID]; ok {
+				if _, ok := subs[sub]; ok {
+					delete(subs, sub)
+					close(sub.updates)
+				}
+				if len(subs) == 0 {
+					delete(h.subscribers, jobID)
+				}
+			}
+		})
+	}
+
+	return sub.updates, unsubscribe
+}
+
+// publish delivers an update to every subscriber of the job without blocking.
+// Updates with a lower progress than one already sent are skipped so each
+// subscriber sees monotonic progress; terminal updates close the channels.
+func (h *progressHub) publish(update ProgressUpdate) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs := h.subscribers[update.JobID]
+	terminal := isTerminalStatus(update.Status)
+	for sub := range subs {
+		u := update
+		if u.Progress < sub.last {
+			if !terminal {
+				continue
+			}
+			u.Progress = sub.last
+		}
+		sub.last = u.Progress
+		sub.send(u)
+		if terminal {
+			close(sub.updates)
+		}
+	}
+	if terminal {
+		delete(h.subscribers, update.JobID)
+	}
+}
+
+// send enqueues an update, discarding the oldest queued one when the buffer is full
+func (s *progressSubscriber) send(update ProgressUpdate) {
+	for {
+		select {
+		case s.updates <- update:
+			return
+		default:
+		}
+		select {
+		case <-s.updates:
+		default:
+		}
+	}
+}
+
+// isTerminalStatus reports whether a job will receive no further updates
+func isTerminalStatus(status models.JobStatus) bool {
+	switch status {
+	case models.JobStatusCompleted, models.JobStatusFailed, models.JobStatusCancelled:
+		return true
+	}
+	return false
+}
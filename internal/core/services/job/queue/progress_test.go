@@ -0,0 +1,86 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/activadee/videocraft/internal/api/models"
+)
+
+// drain returns the updates queued on a subscription without blocking
+func drain(updates <-chan ProgressUpdate) []ProgressUpdate {
+	var got []ProgressUpdate
+	for {
+		select {
+		case u, ok := <-updates:
+			if !ok {
+				return got
+			}
+			got = append(got, u)
+		default:
+			return got
+		}
+	}
+}
+
+func TestProgressHubDropsOldestUpdateWhenFull(t *testing.T) {
+	hub := newProgressHub(3)
+	updates, unsubscribe := hub.subscribe(ProgressUpdate{JobID: "job", Status: models.JobStatusProcessing})
+	defer unsubscribe()
+
+	// A subscriber that never reads must not block the publisher
+	for progress := 10; progress <= 50; progress += 10 {
+		hub.publish(ProgressUpdate{JobID: "job", Status: models.JobStatusProcessing, Progress: progress})
+	}
+
+	got := drain(updates)
+	want := []int{30, 40, 50}
+	if len(got) != len(want) {
+		t.Fatalf("got %d updates %+v, want progress %v", len(got), got, want)
+	}
+	for i, u := range got {
+		if u.Progress != want[i] {
+			t.Errorf("update %d progress = %d, want %d", i, u.Progress, want[i])
+		}
+	}
+}
+
+func TestProgressHubKeepsProgressMonotonic(t *testing.T) {
+	hub := newProgressHub(10)
+	updates, unsubscribe := hub.subscribe(ProgressUpdate{JobID: "job", Status: models.JobStatusProcessing, Progress: 20})
+	defer unsubscribe()
+
+	hub.publish(ProgressUpdate{JobID: "job", Status: models.JobStatusProcessing, Progress: 10})
+	hub.publish(ProgressUpdate{JobID: "job", Status: models.JobStatusProcessing, Progress: 40})
+	hub.publish(ProgressUpdate{JobID: "job", Status: models.JobStatusFailed, Progress: 0})
+
+	got := drain(updates)
+	want := []ProgressUpdate{
+		{JobID: "job", Status: models.JobStatusProcessing, Progress: 20},
+		{JobID: "job", Status: models.JobStatusProcessing, Progress: 40},
+		{JobID: "job", Status: models.JobStatusFailed, Progress: 40},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("update %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if _, ok := <-updates; ok {
+		t.Error("channel still open after a terminal update")
+	}
+}
+
+func TestProgressHubClosesTerminalSubscriptionImmediately(t *testing.T) {
+	hub := newProgressHub(1)
+	updates, unsubscribe := hub.subscribe(ProgressUpdate{JobID: "job", Status: models.JobStatusCompleted, Progress: 100})
+	defer unsubscribe()
+
+	if got := drain(updates); len(got) != 1 || got[0].Status != models.JobStatusCompleted {
+		t.Fatalf("got %+v, want only the completed snapshot", got)
+	}
+	if len(hub.subscribers) != 0 {
+		t.Errorf("hub keeps %d subscriptions for a finished job", len(hub.subscribers))
+	}
+}
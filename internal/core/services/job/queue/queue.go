@@ -2,8 +2,13 @@ package queue
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,27 +17,50 @@ import (
 	"github.com/activadee/videocraft/internal/app"
 	"github.com/activadee/videocraft/internal/core/media/audio"
 	"github.com/activadee/videocraft/internal/core/media/subtitle"
+	"github.com/activadee/videocraft/internal/core/video/engine"
 	"github.com/activadee/videocraft/internal/pkg/errors"
 	"github.com/activadee/videocraft/internal/pkg/logger"
 )
 
 // Service provides job queue management
 type Service interface {
-	CreateJob(config *models.VideoConfigArray) (*models.Job, error)
+	CreateJob(config *models.VideoConfigArray, priority models.JobPriority) (*models.Job, error)
 	GetJob(jobID string) (*models.Job, error)
 	ListJobs() ([]*models.Job, error)
 	ProcessJob(ctx context.Context, job *models.Job) error
 	CancelJob(jobID string) error
 	UpdateJobStatus(id string, status models.JobStatus, errorMsg string) error
 	UpdateJobProgress(id string, progress int) error
+	EstimateRender(ctx context.Context, config *models.VideoConfigArray) (*models.RenderEstimate, error)
+	DryRun(ctx context.Context, config *models.VideoConfigArray) (*models.RenderCommand, error)
+	SubscribeProgress(jobID string) (<-chan ProgressUpdate, func(), error)
+	Pause()
+	Resume()
+	IsPaused() bool
+	WorkerStats() WorkerStats
+	SetWorkers(count int) error
+	ExpireJobs(cutoff time.Time) int
 	Start() error
 	Stop() error
 }
 
+// maxWorkers bounds runtime resizing of the worker pool
+const maxWorkers = 64
+
+// WorkerStats is a snapshot of the worker pool
+type WorkerStats struct {
+	Workers    int `json:"workers"`
+	ActiveJobs int `json:"active_jobs"`
+	QueueDepth int `json:"queue_depth"`
+}
+
 // Forward declaration - these will be injected
 type FFmpegService interface {
-	GenerateVideo(ctx context.Context, config *models.VideoConfigArray, progressChan chan<- int) (string, error)
-	GenerateVideoWithSubtitles(ctx context.Context, config *models.VideoConfigArray, subtitleFilePath string, progressChan chan<- int) (string, error)
+	GenerateVideo(ctx context.Context, config *models.VideoConfigArray, progressChan chan<- models.RenderProgress) (string, error)
+	GenerateVideoWithSubtitles(ctx context.Context, config *models.VideoConfigArray, subtitleFilePath string, progressChan chan<- models.RenderProgress) (string, error)
+	BuildCommand(config *models.VideoConfigArray) (*engine.FFmpegCommand, error)
+	VerifyDecode(ctx context.Context, videoPath string) ([]string, error)
+	MeasureLoudness(ctx context.Context, audio models.Element) (*models.LoudnessMeasurement, error)
 }
 
 type SubtitleService interface {
@@ -42,7 +70,14 @@ type SubtitleService interface {
 }
 
 type StorageService interface {
-	StoreVideo(videoPath string) (string, error)
+	StoreVideo(videoPath, subdir string) (string, error)
+	StoreVideoWithID(videoPath, desiredID, subdir string) (string, error)
+	StoreSubtitle(subtitlePath, videoID string) (string, error)
+	StoreCaption(captionPath, videoID, format string) (string, error)
+	StoreManifest(data []byte, videoID string) (string, error)
+	VideoExists(videoID string) bool
+	GetVideo(videoID string) (string, error)
+	DeleteVideo(videoID string) error
 }
 
 // Media service interfaces for URL analysis
@@ -52,6 +87,7 @@ type AudioService interface {
 
 type VideoService interface {
 	AnalyzeVideo(ctx context.Context, videoURL string) (*models.VideoInfo, error)
+	GetVideoMetadata(filePath string) (*models.VideoInfo, error)
 }
 
 type ImageService interface {
@@ -62,10 +98,39 @@ type service struct {
 	cfg *app.Config
 	log logger.Logger
 
-	jobs     map[string]*models.Job
-	mu       sync.RWMutex
-	jobQueue chan *models.Job
-	workers  int
+	jobs    map[string]*models.Job
+	mu      sync.RWMutex
+	queue   *jobQueue
+	workers int
+
+	// running holds the context cancel func of each job a worker is
+	// processing, so CancelJob can stop it; guarded by mu
+	running map[string]context.CancelFunc
+
+	// Worker pool - each running worker owns a quit channel; closing it makes
+	// the worker exit after its current job
+	poolMu       sync.Mutex
+	workerQuits  []chan struct{}
+	nextWorkerID int
+	activeJobs   atomic.Int32
+	stopped      bool
+
+	// Pause state - workers wait on pauseCond while paused
+	paused    bool
+	pauseMu   sync.Mutex
+	pauseCond *sync.Cond
+
+	// Fans progress and status changes out to subscribers
+	progress *progressHub
+
+	// Closed on Stop to end the slow job watcher
+	stopWatcher chan struct{}
+
+	// Persists job state across restarts; nil keeps jobs in memory only
+	store JobStore
+
+	// Delivers callback_url notifications of finished jobs
+	callbacks *http.Client
 
 	// Service dependencies
 	ffmpeg   FFmpegService
@@ -78,26 +143,40 @@ type service struct {
 	image ImageService
 }
 
-// NewService creates a new job service
-func NewService(cfg *app.Config, log logger.Logger, ffmpeg FFmpegService, subtitle SubtitleService, storage StorageService, audio AudioService, video VideoService, image ImageService) Service {
-	return &service{
-		cfg:      cfg,
-		log:      log,
-		jobs:     make(map[string]*models.Job),
-		jobQueue: make(chan *models.Job, cfg.Job.QueueSize),
-		workers:  cfg.Job.Workers,
-		ffmpeg:   ffmpeg,
-		subtitle: subtitle,
-		storage:  storage,
-		audio:    audio,
-		video:    video,
-		image:    image,
+// NewService creates a new job service. A nil store keeps jobs in memory only.
+func NewService(cfg *app.Config, log logger.Logger, store JobStore, ffmpeg FFmpegService, subtitle SubtitleService, storage StorageService, audio AudioService, video VideoService, image ImageService) Service {
+	js := &service{
+		cfg:       cfg,
+		log:       log,
+		jobs:      make(map[string]*models.Job),
+		running:   make(map[string]context.CancelFunc),
+		queue:     newJobQueue(cfg.Job.QueueSize),
+		workers:   cfg.Job.Workers,
+		progress:  newProgressHub(cfg.Job.ProgressBufferSize),
+		store:     store,
+		ffmpeg:    ffmpeg,
+		callbacks: newCallbackClient(cfg.Job.CallbackTimeout),
+		subtitle:  subtitle,
+		storage:   storage,
+		audio:     audio,
+		video:     video,
+		image:     image,
 	}
+	js.pauseCond = sync.NewCond(&js.pauseMu)
+	return js
 }
 
-func (js *service) CreateJob(config *models.VideoConfigArray) (*models.Job, error) {
+// CreateJob queues a job for the config; an empty priority is normal
+func (js *service) CreateJob(config *models.VideoConfigArray, priority models.JobPriority) (*models.Job, error) {
 	js.log.Debug("Creating new job")
 
+	if priority == "" {
+		priority = models.JobPriorityNormal
+	}
+	if !priority.IsValid() {
+		return nil, errors.InvalidInput(fmt.Sprintf("invalid job priority %q: must be low, normal or high", priority))
+	}
+
 	// Validate configuration
 	if err := config.Validate(); err != nil {
 		return nil, errors.InvalidInput(err.Error())
@@ -110,11 +189,33 @@ func (js *service) CreateJob(config *models.VideoConfigArray) (*models.Job, erro
 		}
 	}
 
+	// Fail fast on deterministic output IDs that would be rejected at store time
+	if js.cfg.Storage.OutputCollisionPolicy == app.CollisionPolicyReject {
+		for _, project := range *config {
+			if project.OutputID == "" {
+				continue
+			}
+			videoIDs := []string{project.OutputID}
+			if len(project.Renditions) > 0 {
+				videoIDs = videoIDs[:0]
+				for _, rendition := range project.Renditions {
+					videoIDs = append(videoIDs, models.RenditionVideoID(project.OutputID, rendition))
+				}
+			}
+			for _, videoID := range videoIDs {
+				if js.storage.VideoExists(videoID) {
+					return nil, errors.Conflict(fmt.Sprintf("video already exists: %s", videoID))
+				}
+			}
+		}
+	}
+
 	job := &models.Job{
 		ID:        uuid.New().String(),
 		Status:    models.JobStatusPending,
 		Config:    *config,
 		Progress:  0,
+		Priority:  priority,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -122,29 +223,34 @@ func (js *service) CreateJob(config *models.VideoConfigArray) (*models.Job, erro
 	// Store job
 	js.mu.Lock()
 	js.jobs[job.ID] = job
+	snapshot := *job
 	js.mu.Unlock()
 
 	// Queue job for processing
-	select {
-	case js.jobQueue <- job:
-		js.log.Infof("Job created and queued: %s", job.ID)
-	default:
+	if !js.queue.push(job) {
+		// A job that was never queued must not linger as pending
+		js.mu.Lock()
+		delete(js.jobs, job.ID)
+		js.mu.Unlock()
 		return nil, errors.InternalError(fmt.Errorf("job queue is full"))
 	}
+	js.log.Infof("Job created and queued: %s (priority %s)", job.ID, priority)
+	js.saveJob(snapshot)
 
 	return job, nil
 }
 
 func (js *service) GetJob(id string) (*models.Job, error) {
 	js.mu.RLock()
-	job, exists := js.jobs[id]
-	js.mu.RUnlock()
+	defer js.mu.RUnlock()
 
+	job, exists := js.jobs[id]
 	if !exists {
 		return nil, errors.JobNotFound(id)
 	}
 
-	// Return a copy to prevent external modifications
+	// Return a copy to prevent external modifications; it is taken under the
+	// lock because workers update the job in place
 	jobCopy := *job
 	return &jobCopy, nil
 }
@@ -170,31 +276,62 @@ func (js *service) CancelJob(id string) error {
 		return errors.JobNotFound(id)
 	}
 
-	if job.Status == models.JobStatusCompleted || job.Status == models.JobStatusFailed {
+	if !canTransition(job.Status, models.JobStatusCancelled) {
 		js.mu.Unlock()
-		return errors.InvalidInput("cannot cancel completed or failed job")
+		return errors.InvalidInput(fmt.Sprintf("cannot cancel %s job", job.Status))
 	}
 
 	job.Status = models.JobStatusCancelled
 	job.UpdatedAt = time.Now()
+	update := ProgressUpdate{JobID: id, Status: job.Status, Progress: job.Progress}
+	snapshot := *job
+	stop := js.running[id]
 	js.mu.Unlock()
 
+	// Cancelling the job's context kills its FFmpeg process
+	if stop != nil {
+		js.log.Infof("Stopping running job %s", id)
+		stop()
+	}
+
+	// A job still waiting in the queue never reaches a worker
+	if js.queue.remove(id) {
+		js.log.Debugf("Removed cancelled job %s from the queue", id)
+	}
+	js.saveJob(snapshot)
+	js.progress.publish(update)
+	js.notifyCallback(snapshot)
 	js.log.Infof("Job cancelled: %s", id)
 	return nil
 }
 
 func (js *service) UpdateJobStatus(id string, status models.JobStatus, errorMsg string) error {
 	js.mu.Lock()
-	defer js.mu.Unlock()
-
 	job, exists := js.jobs[id]
 	if !exists {
+		js.mu.Unlock()
 		return errors.JobNotFound(id)
 	}
 
+	// Transitions are checked under the jobs lock, so racing updates cannot
+	// move a finished job back into the lifecycle
+	if !canTransition(job.Status, status) {
+		from := job.Status
+		js.mu.Unlock()
+		return invalidTransition(id, from, status)
+	}
+
 	job.Status = status
 	job.UpdatedAt = time.Now()
 
+	if status == models.JobStatusProcessing {
+		now := time.Now()
+		job.StartedAt = &now
+		job.LastProgressAt = now
+	} else {
+		job.Health = ""
+	}
+
 	if errorMsg != "" {
 		job.Error = errorMsg
 	}
@@ -203,102 +340,265 @@ func (js *service) UpdateJobStatus(id string, status models.JobStatus, errorMsg
 		now := time.Now()
 		job.CompletedAt = &now
 	}
+	update := ProgressUpdate{JobID: id, Status: job.Status, Progress: job.Progress}
+	snapshot := *job
+	js.mu.Unlock()
 
+	js.saveJob(snapshot)
+	js.progress.publish(update)
+	if isTerminalStatus(status) {
+		js.notifyCallback(snapshot)
+	}
 	return nil
 }
 
 func (js *service) UpdateJobProgress(id string, progress int) error {
-	js.mu.Lock()
-	defer js.mu.Unlock()
+	return js.updateProgress(id, progress, nil)
+}
 
+// updateProgress sets a job's percent and, when render is set, the details
+// of its running render
+func (js *service) updateProgress(id string, progress int, render *models.RenderProgress) error {
+	js.mu.Lock()
 	job, exists := js.jobs[id]
 	if !exists {
+		js.mu.Unlock()
 		return errors.JobNotFound(id)
 	}
 
+	changed := progress != job.Progress
+	if changed {
+		job.LastProgressAt = time.Now()
+		if job.Health == models.JobHealthPossiblyStuck {
+			js.log.Infof("Job %s is progressing again", id)
+			job.Health = ""
+		}
+	}
 	job.Progress = progress
+	if render != nil {
+		job.RenderProgress = render
+	}
 	job.UpdatedAt = time.Now()
+	update := ProgressUpdate{JobID: id, Status: job.Status, Progress: job.Progress, Render: render}
+	snapshot := *job
+	js.mu.Unlock()
+
+	// Render details change several times a second; only the percent is persisted
+	if changed {
+		js.saveJob(snapshot)
+	}
 
+	// Fan out after releasing the jobs mutex so subscribers never contend with it
+	js.progress.publish(update)
 	return nil
 }
 
+// SubscribeProgress streams progress and status updates of a job, starting
+// with its current state. Slow subscribers lose intermediate updates rather
+// than blocking the job; the channel closes once the job finishes.
+func (js *service) SubscribeProgress(jobID string) (<-chan ProgressUpdate, func(), error) {
+	// Hold the jobs lock while subscribing so no status change is missed
+	// between the snapshot and registration
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	job, exists := js.jobs[jobID]
+	if !exists {
+		return nil, nil, errors.JobNotFound(jobID)
+	}
+
+	updates, unsubscribe := js.progress.subscribe(ProgressUpdate{
+		JobID:    job.ID,
+		Status:   job.Status,
+		Progress: job.Progress,
+	})
+	return updates, unsubscribe, nil
+}
+
 func (js *service) ProcessJob(ctx context.Context, job *models.Job) error {
-	js.log.Infof("Processing job: %s", job.ID)
+	// The job's logger reaches the services below through ctx
+	log := js.jobLogger(job)
+	ctx = logger.NewContext(ctx, log)
+
+	log.Infof("Processing job: %s", job.ID)
+	defer func() {
+		// A scheduled retry still needs the uploaded sources
+		if !js.retryScheduled(job.ID) {
+			js.cleanupUploads(job.Config)
+		}
+	}()
 
 	// Update status to processing
 	if err := js.UpdateJobStatus(job.ID, models.JobStatusProcessing, ""); err != nil {
 		return err
 	}
 
-	// Create progress channel
-	progressChan := make(chan int, 10)
-	go func() {
-		for progress := range progressChan {
-			if err := js.UpdateJobProgress(job.ID, progress); err != nil {
-				js.log.Errorf("Failed to update job progress: %v", err)
-			}
+	// Step 1: Analyze media URLs to get durations using media services
+	log.Info("Analyzing media URLs for metadata")
+	resolved, err := js.analyzeMediaWithServices(ctx, &job.Config)
+	if err != nil {
+		log.Errorf("Media analysis failed: %v", err)
+		if updateErr := js.failJob(job.ID, fmt.Sprintf("media analysis failed: %v", err), err); updateErr != nil {
+			log.Errorf("Failed to update job status: %v", updateErr)
 		}
-	}()
+		return err
+	}
 
-	// Step 1: Analyze media URLs to get durations using media services
-	js.log.Info("Analyzing media URLs for metadata")
-	if err := js.analyzeMediaWithServices(ctx, &job.Config); err != nil {
-		js.log.Errorf("Media analysis failed: %v", err)
-		if updateErr := js.UpdateJobStatus(job.ID, models.JobStatusFailed, fmt.Sprintf("media analysis failed: %v", err)); updateErr != nil {
-			js.log.Errorf("Failed to update job status: %v", updateErr)
+	js.addVariableFrameRateWarnings(job)
+	js.addBackgroundLoopWarnings(job)
+	js.recordResolvedSrcs(job.ID, resolved)
+
+	// Measured once with the job's context; renditions share the result
+	if err := js.measureLoudness(ctx, job); err != nil {
+		log.Errorf("Loudness measurement failed: %v", err)
+		if updateErr := js.failJob(job.ID, err.Error(), err); updateErr != nil {
+			log.Errorf("Failed to update job status: %v", updateErr)
 		}
 		return err
 	}
 
 	// Step 2: Generate subtitles if needed
 	var subtitleFilePath string
+	var subtitleInfo *subtitle.SubtitleResult
+	var sidecar bool
 	for _, project := range job.Config {
 		if js.needsSubtitles(project) {
-			js.log.Info("Generating subtitles for project")
+			log.Info("Generating subtitles for project")
 			subtitleResult, err := js.subtitle.GenerateSubtitles(ctx, project)
+			if stderrors.Is(err, subtitle.ErrNoSubtitleEvents) && !js.cfg.Subtitles.FailOnEmpty {
+				js.addJobWarning(job.ID, "subtitles were requested but transcription produced no text; rendered without subtitles")
+				break
+			}
 			if err != nil {
-				js.log.Errorf("Failed to generate subtitles: %v", err)
-				if updateErr := js.UpdateJobStatus(job.ID, models.JobStatusFailed, fmt.Sprintf("subtitle generation failed: %v", err)); updateErr != nil {
-					js.log.Errorf("Failed to update job status: %v", updateErr)
+				log.Errorf("Failed to generate subtitles: %v", err)
+				if updateErr := js.failJob(job.ID, fmt.Sprintf("subtitle generation failed: %v", err), err); updateErr != nil {
+					log.Errorf("Failed to update job status: %v", updateErr)
 				}
 				return err
 			}
+			if subtitleResult == nil {
+				log.Info("Subtitle generation skipped, rendering without subtitles")
+				break
+			}
 			subtitleFilePath = subtitleResult.FilePath
-			js.log.Infof("Subtitles generated: %s (%d events)", subtitleFilePath, subtitleResult.EventCount)
+			subtitleInfo = subtitleResult
+			sidecar = project.DetachedSubtitles()
+			log.Infof("Subtitles generated: %s (%d events)", subtitleFilePath, subtitleResult.EventCount)
 			break // Only generate subtitles for the first project that needs them
 		}
 	}
 
-	// Process the video generation
-	var videoPath string
-	var err error
-	if subtitleFilePath != "" {
-		videoPath, err = js.ffmpeg.GenerateVideoWithSubtitles(ctx, &job.Config, subtitleFilePath, progressChan)
-	} else {
-		videoPath, err = js.ffmpeg.GenerateVideo(ctx, &job.Config, progressChan)
-	}
-	// Note: progressChan is closed by the FFmpeg service
-
-	if err != nil {
-		if updateErr := js.UpdateJobStatus(job.ID, models.JobStatusFailed, err.Error()); updateErr != nil {
-			js.log.Errorf("Failed to update job status to failed: %v", updateErr)
+	// Render each target; renditions share the analysis and subtitles above
+	targets := js.renderTargets(job)
+	outputs := make([]models.RenditionOutput, 0, len(targets))
+
+	// A failed render discards the videos already stored for the job, so a
+	// retry can store them again under the same output IDs
+	var stored []string
+	failRender := func(err error) error {
+		js.discardVideos(log, stored)
+		if updateErr := js.failJob(job.ID, err.Error(), err); updateErr != nil {
+			log.Errorf("Failed to update job status to failed: %v", updateErr)
 		}
 		return err
 	}
+	for i, target := range targets {
+		progressChan := js.forwardProgress(job.ID, i, len(targets))
 
-	// Store the generated video
-	videoID, err := js.storage.StoreVideo(videoPath)
-	if err != nil {
-		if updateErr := js.UpdateJobStatus(job.ID, models.JobStatusFailed, err.Error()); updateErr != nil {
-			js.log.Errorf("Failed to update job status to failed: %v", updateErr)
+		var videoPath string
+		if subtitleFilePath != "" && !sidecar {
+			videoPath, err = js.ffmpeg.GenerateVideoWithSubtitles(ctx, &target.config, subtitleFilePath, progressChan)
+		} else {
+			videoPath, err = js.ffmpeg.GenerateVideo(ctx, &target.config, progressChan)
 		}
-		return err
+		// Note: progressChan is closed by the FFmpeg service
+
+		if err != nil {
+			return failRender(err)
+		}
+
+		// Subtitles that silently went missing are flagged on the job
+		for _, discrepancy := range js.verifySubtitleDelivery(target.config[0], videoPath, subtitleInfo, sidecar) {
+			log.Warnf("Subtitle verification failed: %s", discrepancy)
+			js.addJobWarning(job.ID, "subtitle verification: "+discrepancy)
+		}
+
+		// Outputs that were written but do not decode cleanly are caught before delivery
+		if err := js.checkOutputIntegrity(ctx, job.ID, videoPath); err != nil {
+			return failRender(err)
+		}
+
+		// Store the generated video, honoring a client-supplied or rendition ID
+		// and the project's output subdirectory
+		var videoID string
+		subdir := target.config[0].OutputSubdir
+		if target.videoID != "" {
+			videoID, err = js.storage.StoreVideoWithID(videoPath, target.videoID, subdir)
+		} else {
+			videoID, err = js.storage.StoreVideo(videoPath, subdir)
+		}
+		if err != nil {
+			return failRender(err)
+		}
+		stored = append(stored, videoID)
+
+		// Sidecar subtitles are stored next to the video instead of being burned in
+		var subtitleID string
+		if sidecar {
+			subtitleID, err = js.storage.StoreSubtitle(subtitleFilePath, videoID)
+			if err != nil {
+				return failRender(err)
+			}
+		}
+
+		// Caption exports are stored next to the video as "<videoID>-<format>-subtitles"
+		var subtitleFiles map[string]string
+		if subtitleInfo != nil && len(subtitleInfo.CaptionFiles) > 0 {
+			subtitleFiles = make(map[string]string, len(subtitleInfo.CaptionFiles))
+			for _, file := range subtitleInfo.CaptionFiles {
+				captionID, err := js.storage.StoreCaption(file.FilePath, videoID, file.Format)
+				if err != nil {
+					return failRender(err)
+				}
+				subtitleFiles[file.Format] = captionID
+			}
+		}
+
+		// The manifest documents the output; a failure to write it does not fail the job
+		manifestID, err := js.storeManifest(job, target.config[0], videoID, manifestSubtitles{
+			result:     subtitleInfo,
+			sidecar:    sidecar,
+			subtitleID: subtitleID,
+		})
+		if err != nil {
+			log.Warnf("Failed to store render manifest for job %s: %v", job.ID, err)
+			js.addJobWarning(job.ID, fmt.Sprintf("render manifest unavailable: %v", err))
+		}
+
+		project := target.config[0]
+		outputs = append(outputs, models.RenditionOutput{
+			Name:       target.name,
+			Width:      project.Width,
+			Height:     project.Height,
+			VideoID:    videoID,
+			SubtitleID: subtitleID,
+			ManifestID: manifestID,
+
+			SubtitleFiles: subtitleFiles,
+		})
 	}
 
 	// Update job with video ID and completion status
+	primary := outputs[0]
 	js.mu.Lock()
 	if jobPtr, exists := js.jobs[job.ID]; exists {
-		jobPtr.VideoID = videoID
+		jobPtr.VideoID = primary.VideoID
+		jobPtr.SubtitleID = primary.SubtitleID
+		jobPtr.ManifestID = primary.ManifestID
+		jobPtr.SubtitleFiles = primary.SubtitleFiles
+		if primary.Name != "" {
+			jobPtr.Renditions = outputs
+		}
 		jobPtr.Progress = 100
 	}
 	js.mu.Unlock()
@@ -310,16 +610,165 @@ func (js *service) ProcessJob(ctx context.Context, job *models.Job) error {
 	// Cleanup subtitle files if any were generated
 	if subtitleFilePath != "" {
 		if err := js.subtitle.CleanupTempFiles(subtitleFilePath); err != nil {
-			js.log.Warnf("Failed to cleanup subtitle file %s: %v", subtitleFilePath, err)
+			log.Warnf("Failed to cleanup subtitle file %s: %v", subtitleFilePath, err)
+		}
+	}
+	if subtitleInfo != nil {
+		for _, file := range subtitleInfo.CaptionFiles {
+			if err := js.subtitle.CleanupTempFiles(file.FilePath); err != nil {
+				log.Warnf("Failed to cleanup caption file %s: %v", file.FilePath, err)
+			}
 		}
 	}
 
-	js.log.Infof("Job completed successfully: %s, video ID: %s", job.ID, videoID)
+	log.Infof("Job completed successfully: %s, video ID: %s", job.ID, primary.VideoID)
 	return nil
 }
 
-// needsSubtitles checks if a project needs subtitle generation
+// jobCancelled reports whether a job was cancelled
+func (js *service) jobCancelled(id string) bool {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	job, exists := js.jobs[id]
+	return exists && job.Status == models.JobStatusCancelled
+}
+
+// cleanupUploads removes the upload directories of media uploaded with the job request
+func (js *service) cleanupUploads(config models.VideoConfigArray) {
+	dirs := make(map[string]bool)
+	collect := func(src string) {
+		// Only per-request directories, never the upload root itself
+		if dir := filepath.Dir(src); js.cfg.Storage.IsUploadedFile(src) && js.cfg.Storage.IsUploadedFile(dir) {
+			dirs[dir] = true
+		}
+	}
+
+	for _, project := range config {
+		for _, element := range project.Elements {
+			collect(element.Src)
+		}
+		for _, scene := range project.Scenes {
+			for _, element := range scene.Elements {
+				collect(element.Src)
+			}
+		}
+		if project.Timeline != nil {
+			for _, track := range project.Timeline.Tracks {
+				for _, clip := range track.Clips {
+					collect(clip.Src)
+				}
+			}
+		}
+	}
+
+	for dir := range dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			js.log.Warnf("Failed to remove upload directory %s: %v", dir, err)
+		}
+	}
+}
+
+// jobLogger returns the logger for processing a job: tagged with its ID, and
+// at debug level when its config asks for debug logging
+func (js *service) jobLogger(job *models.Job) logger.Logger {
+	log := js.log.WithField("job_id", job.ID)
+	if job.Config.DebugLogging() {
+		log = log.WithLevel("debug")
+	}
+	return log
+}
+
+// addVariableFrameRateWarnings records a job warning for every VFR background video
+func (js *service) addVariableFrameRateWarnings(job *models.Job) {
+	for _, project := range job.Config {
+		for _, element := range project.Elements {
+			if element.Type == "video" && element.VariableFrameRate {
+				js.addJobWarning(job.ID, fmt.Sprintf("background video %s has a variable frame rate; converted to constant %.3f fps", element.Src, element.FrameRate))
+			}
+		}
+	}
+}
+
+// addBackgroundLoopWarnings records a job warning when a single background
+// video must repeat more than ffmpeg.background_loop_warn_threshold times,
+// as the output will visibly loop
+func (js *service) addBackgroundLoopWarnings(job *models.Job) {
+	threshold := js.cfg.FFmpeg.BackgroundLoopWarnThreshold
+	if threshold <= 0 {
+		return
+	}
+
+	for _, project := range job.Config {
+		if project.Timeline != nil {
+			continue
+		}
+		var videos []models.Element
+		for _, element := range project.Elements {
+			if element.Type == "video" {
+				videos = append(videos, element)
+			}
+		}
+		if len(videos) != 1 {
+			continue
+		}
+
+		totalDuration := js.estimateProject(project).TotalDuration
+		if loops := engine.BackgroundLoopCount(videos[0].Duration, totalDuration); loops > threshold {
+			js.addJobWarning(job.ID, fmt.Sprintf("background video %s (%.2fs) repeats %d times to cover %.2fs; the output will visibly loop",
+				videos[0].Src, videos[0].Duration, loops, totalDuration))
+		}
+	}
+}
+
+// recordResolvedSrcs stores which fallback srcs replaced failing primary srcs
+func (js *service) recordResolvedSrcs(jobID string, resolved map[string]string) {
+	if len(resolved) == 0 {
+		return
+	}
+
+	js.mu.Lock()
+	job, exists := js.jobs[jobID]
+	var snapshot models.Job
+	if exists {
+		job.ResolvedSrcs = resolved
+		job.UpdatedAt = time.Now()
+		snapshot = *job
+	}
+	js.mu.Unlock()
+
+	if exists {
+		js.saveJob(snapshot)
+	}
+
+	for primary, fallback := range resolved {
+		js.addJobWarning(jobID, fmt.Sprintf("source %s failed analysis; used fallback %s", primary, fallback))
+	}
+}
+
+// addJobWarning attaches a non-fatal warning to a job
+func (js *service) addJobWarning(jobID, warning string) {
+	js.mu.Lock()
+	job, exists := js.jobs[jobID]
+	if !exists {
+		js.mu.Unlock()
+		return
+	}
+	job.Warnings = append(job.Warnings, warning)
+	job.UpdatedAt = time.Now()
+	snapshot := *job
+	js.mu.Unlock()
+
+	js.saveJob(snapshot)
+}
+
+// needsSubtitles checks if a project needs subtitle generation: it has a
+// subtitle element and subtitles are enabled for it
 func (js *service) needsSubtitles(project models.VideoProject) bool {
+	if !project.SubtitlesEnabledOr(js.cfg.Subtitles.Enabled) {
+		return false
+	}
+
 	// Check if there are any subtitle elements in the project
 	for _, element := range project.Elements {
 		if element.Type == "subtitles" {
@@ -339,9 +788,14 @@ func (js *service) needsSubtitles(project models.VideoProject) bool {
 	return false
 }
 
-// analyzeMediaWithServices uses media services to analyze URLs without downloading
-func (js *service) analyzeMediaWithServices(ctx context.Context, config *models.VideoConfigArray) error {
-	js.log.Info("Starting media URL analysis with media services")
+// analyzeMediaWithServices uses media services to analyze URLs without downloading.
+// Elements whose src fails analysis switch to the first working fallback src;
+// the returned map records each such primary src and its replacement.
+func (js *service) analyzeMediaWithServices(ctx context.Context, config *models.VideoConfigArray) (map[string]string, error) {
+	log := logger.FromContext(ctx, js.log)
+	log.Info("Starting media URL analysis with media services")
+
+	resolved := make(map[string]string)
 
 	for projectIdx := range *config {
 		project := &(*config)[projectIdx] // Get pointer to modify original
@@ -353,22 +807,38 @@ func (js *service) analyzeMediaWithServices(ctx context.Context, config *models.
 
 				switch element.Type {
 				case "audio":
-					js.log.Debugf("Analyzing audio URL: %s", element.Src)
-					audioInfo, err := js.audio.AnalyzeAudio(ctx, element.Src)
+					if element.AudioFromVideo {
+						if err := js.analyzeWithFallbacks(ctx, element, resolved, func(ctx context.Context, src string) error {
+							return js.analyzeAudioFromVideo(ctx, element, src)
+						}); err != nil {
+							return nil, err
+						}
+						continue
+					}
+					err := js.analyzeWithFallbacks(ctx, element, resolved, func(ctx context.Context, src string) error {
+						log.Debugf("Analyzing audio URL: %s", src)
+						audioInfo, err := js.audio.AnalyzeAudio(ctx, src)
+						if err != nil {
+							return err
+						}
+						element.Duration = audioInfo.GetDuration()
+						log.Debugf("Audio duration: %.2fs", element.Duration)
+						return nil
+					})
 					if err != nil {
-						js.log.Warnf("Failed to analyze audio '%s': %v, using default duration", element.Src, err)
+						if len(element.FallbackSrcs) > 0 || ctx.Err() != nil {
+							return nil, err
+						}
+						log.Warnf("Failed to analyze audio '%s': %v, using default duration", element.Src, err)
 						element.Duration = 10.0 // Fallback duration
-					} else {
-						element.Duration = audioInfo.GetDuration()
-						js.log.Debugf("Audio duration: %.2fs", element.Duration)
 					}
 				case "image":
-					js.log.Debugf("Validating image URL: %s", element.Src)
+					log.Debugf("Validating image URL: %s", element.Src)
 					if err := js.image.ValidateImage(element.Src); err != nil {
-						js.log.Errorf("Failed to validate image '%s': %v", element.Src, err)
-						return fmt.Errorf("invalid image URL '%s': %w", element.Src, err)
+						log.Errorf("Failed to validate image '%s': %v", element.Src, err)
+						return nil, fmt.Errorf("invalid image URL '%s': %w", element.Src, err)
 					}
-					js.log.Debugf("Image URL validated successfully")
+					log.Debugf("Image URL validated successfully")
 				}
 			}
 		}
@@ -378,53 +848,223 @@ func (js *service) analyzeMediaWithServices(ctx context.Context, config *models.
 			element := &project.Elements[elementIdx]
 			switch element.Type {
 			case "video":
-				js.log.Debugf("Analyzing background video URL: %s", element.Src)
-				videoInfo, err := js.video.AnalyzeVideo(ctx, element.Src)
+				err := js.analyzeWithFallbacks(ctx, element, resolved, func(ctx context.Context, src string) error {
+					log.Debugf("Analyzing background video URL: %s", src)
+					videoInfo, err := js.video.AnalyzeVideo(ctx, src)
+					if err != nil {
+						return err
+					}
+					element.Duration = videoInfo.GetDuration()
+					element.FrameRate = videoInfo.FrameRate
+					element.VariableFrameRate = videoInfo.VariableFrameRate
+					element.SourceWidth = videoInfo.Width
+					element.SourceHeight = videoInfo.Height
+					log.Debugf("Video duration: %.2fs", element.Duration)
+					if videoInfo.VariableFrameRate {
+						log.Warnf("Background video '%s' has a variable frame rate, rendering at constant %.3f fps", src, videoInfo.FrameRate)
+					}
+					return nil
+				})
 				if err != nil {
-					js.log.Warnf("Failed to analyze video '%s': %v, using default duration", element.Src, err)
+					if len(element.FallbackSrcs) > 0 || ctx.Err() != nil {
+						return nil, err
+					}
+					log.Warnf("Failed to analyze video '%s': %v, using default duration", element.Src, err)
 					element.Duration = 30.0 // Fallback duration
-				} else {
-					element.Duration = videoInfo.GetDuration()
-					js.log.Debugf("Video duration: %.2fs", element.Duration)
+				}
+			case "audio":
+				// Background music loops for the whole video, so its duration
+				// is not needed, but a broken source must fail before render
+				err := js.analyzeWithFallbacks(ctx, element, resolved, func(ctx context.Context, src string) error {
+					log.Debugf("Analyzing background audio URL: %s", src)
+					audioInfo, err := js.audio.AnalyzeAudio(ctx, src)
+					if err != nil {
+						return err
+					}
+					element.Duration = audioInfo.GetDuration()
+					return nil
+				})
+				if err != nil {
+					log.Errorf("Failed to analyze background audio '%s': %v", element.Src, err)
+					return nil, fmt.Errorf("invalid background audio URL '%s': %w", element.Src, err)
 				}
 			case "image":
-				js.log.Debugf("Validating background image URL: %s", element.Src)
+				log.Debugf("Validating background image URL: %s", element.Src)
 				if err := js.image.ValidateImage(element.Src); err != nil {
-					js.log.Errorf("Failed to validate background image '%s': %v", element.Src, err)
-					return fmt.Errorf("invalid background image URL '%s': %w", element.Src, err)
+					log.Errorf("Failed to validate background image '%s': %v", element.Src, err)
+					return nil, fmt.Errorf("invalid background image URL '%s': %w", element.Src, err)
 				}
-				js.log.Debugf("Background image URL validated successfully")
+				log.Debugf("Background image URL validated successfully")
+			}
+		}
+	}
+
+	log.Info("Media URL analysis completed")
+	return resolved, nil
+}
+
+// analyzeWithFallbacks runs analyze on the element's src and then on each
+// fallback src until one succeeds. A winning fallback replaces the element's
+// src and is recorded in resolved; if every src fails, all errors are returned.
+// Each attempt gets its own job.analysis_timeout; cancelling ctx stops them all.
+func (js *service) analyzeWithFallbacks(ctx context.Context, element *models.Element, resolved map[string]string, analyze func(ctx context.Context, src string) error) error {
+	candidates := append([]string{element.Src}, element.FallbackSrcs...)
+
+	var errs []error
+	for _, src := range candidates {
+		if ctx.Err() != nil {
+			return fmt.Errorf("analysis of %s cancelled: %w", src, ctx.Err())
+		}
+		err := js.analyzeWithTimeout(ctx, src, analyze)
+		if err == nil {
+			if src != element.Src {
+				js.log.Warnf("Source '%s' failed analysis, using fallback '%s'", element.Src, src)
+				resolved[element.Src] = src
+				element.Src = src
 			}
+			return nil
 		}
+		errs = append(errs, fmt.Errorf("%s: %w", src, err))
 	}
 
-	js.log.Info("Media URL analysis completed")
+	if len(candidates) == 1 {
+		return errs[0]
+	}
+	return fmt.Errorf("all %d sources failed: %w", len(candidates), stderrors.Join(errs...))
+}
+
+// analyzeWithTimeout runs a single analysis under job.analysis_timeout and
+// reports a timeout against the source instead of a bare context error
+func (js *service) analyzeWithTimeout(ctx context.Context, src string, analyze func(ctx context.Context, src string) error) error {
+	timeout := js.cfg.Job.AnalysisTimeout
+	if timeout <= 0 {
+		return analyze(ctx, src)
+	}
+
+	analysisCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := analyze(analysisCtx, src)
+	if err != nil && ctx.Err() == nil && stderrors.Is(analysisCtx.Err(), context.DeadlineExceeded) {
+		js.log.Warnf("Analysis of '%s' timed out after %s", src, timeout)
+		return fmt.Errorf("analysis timed out after %s: %w", timeout, err)
+	}
+	return err
+}
+
+// analyzeAudioFromVideo validates that an audio element's video source carries
+// an audio track and takes the narration duration from the video
+func (js *service) analyzeAudioFromVideo(ctx context.Context, element *models.Element, src string) error {
+	js.log.Debugf("Analyzing video URL for audio track: %s", src)
+	videoInfo, err := js.video.AnalyzeVideo(ctx, src)
+	if err != nil {
+		js.log.Errorf("Failed to analyze audio source video '%s': %v", src, err)
+		return errors.InvalidInput(fmt.Sprintf("invalid audio_from_video source '%s': %v", src, err))
+	}
+	if !videoInfo.HasAudio {
+		return errors.InvalidInput(fmt.Sprintf("audio_from_video source '%s' has no audio track", src))
+	}
+
+	element.Duration = videoInfo.GetDuration()
+	js.log.Debugf("Audio duration from video: %.2fs", element.Duration)
 	return nil
 }
 
 func (js *service) startWorkers() {
-	for i := 0; i < js.workers; i++ {
-		go js.worker(i)
-	}
+	js.poolMu.Lock()
+	js.addWorkersLocked(js.workers)
+	js.poolMu.Unlock()
 	js.log.Infof("Started %d job workers", js.workers)
 }
 
-func (js *service) worker(id int) {
+// addWorkersLocked spawns count workers; poolMu must be held
+func (js *service) addWorkersLocked(count int) {
+	for i := 0; i < count; i++ {
+		quit := make(chan struct{})
+		js.workerQuits = append(js.workerQuits, quit)
+		go js.worker(js.nextWorkerID, quit)
+		js.nextWorkerID++
+	}
+}
+
+// SetWorkers resizes the worker pool. New workers start immediately; removed
+// workers finish their current job before exiting.
+func (js *service) SetWorkers(count int) error {
+	if count < 1 || count > maxWorkers {
+		return errors.InvalidInput(fmt.Sprintf("worker count must be between 1 and %d", maxWorkers))
+	}
+
+	js.poolMu.Lock()
+	defer js.poolMu.Unlock()
+
+	if js.stopped {
+		return errors.InvalidInput("job service is stopped")
+	}
+
+	current := len(js.workerQuits)
+	switch {
+	case count > current:
+		js.addWorkersLocked(count - current)
+	case count < current:
+		for _, quit := range js.workerQuits[count:] {
+			close(quit)
+		}
+		js.workerQuits = js.workerQuits[:count]
+		// Wake paused and idle workers so removed ones can exit
+		js.pauseMu.Lock()
+		js.pauseCond.Broadcast()
+		js.pauseMu.Unlock()
+		js.queue.wake()
+	}
+
+	js.log.Infof("Worker pool resized from %d to %d", current, count)
+	return nil
+}
+
+// WorkerStats reports the worker pool size, jobs in progress and queue depth
+func (js *service) WorkerStats() WorkerStats {
+	js.poolMu.Lock()
+	workers := len(js.workerQuits)
+	js.poolMu.Unlock()
+
+	return WorkerStats{
+		Workers:    workers,
+		ActiveJobs: int(js.activeJobs.Load()),
+		QueueDepth: js.queue.len(),
+	}
+}
+
+func (js *service) worker(id int, quit <-chan struct{}) {
 	js.log.Debugf("Job worker %d started", id)
 
-	for job := range js.jobQueue {
-		// Check if job was cancelled
-		js.mu.RLock()
+	for {
+		if !js.waitWhilePaused(quit) {
+			break
+		}
+
+		job := js.queue.pop(quit)
+		if job == nil {
+			break
+		}
+
+		// A pause may have started while this worker was waiting for a job;
+		// a job already taken is still processed if the worker is removed
+		js.waitWhilePaused(nil)
+
+		// Check if job was cancelled; the job's cancel func is registered
+		// under the same lock so a later CancelJob always finds it
+		js.mu.Lock()
 		currentJob, exists := js.jobs[job.ID]
 		if !exists || currentJob.Status == models.JobStatusCancelled {
-			js.mu.RUnlock()
+			js.mu.Unlock()
 			js.log.Debugf("Skipping cancelled job: %s", job.ID)
 			continue
 		}
-		js.mu.RUnlock()
 
 		// Process the job with timeout
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		js.running[job.ID] = cancel
+		js.mu.Unlock()
 
 		workerLog := js.log.WithFields(map[string]interface{}{
 			"worker": id,
@@ -433,26 +1073,152 @@ func (js *service) worker(id int) {
 
 		workerLog.Info("Worker processing job")
 
-		if err := js.ProcessJob(ctx, job); err != nil {
-			workerLog.Errorf("Job processing failed: %v", err)
-		} else {
+		js.activeJobs.Add(1)
+		err := js.ProcessJob(ctx, job)
+		switch {
+		case err == nil:
 			workerLog.Info("Job processing completed")
+		case js.jobCancelled(job.ID):
+			workerLog.Infof("Job processing stopped by cancellation: %v", err)
+		default:
+			workerLog.Errorf("Job processing failed: %v", err)
+			js.recordDeadLetter(job.ID, err)
 		}
+		js.activeJobs.Add(-1)
 
+		js.mu.Lock()
+		delete(js.running, job.ID)
+		js.mu.Unlock()
 		cancel()
 	}
 
 	js.log.Debugf("Job worker %d stopped", id)
 }
 
+// Pause stops workers from starting new jobs. Queued jobs are preserved and
+// in-flight jobs run to completion.
+func (js *service) Pause() {
+	js.pauseMu.Lock()
+	js.paused = true
+	js.pauseMu.Unlock()
+
+	js.log.Info("Job queue paused")
+}
+
+// Resume lets workers drain the queued backlog again
+func (js *service) Resume() {
+	js.pauseMu.Lock()
+	js.paused = false
+	js.pauseMu.Unlock()
+	js.pauseCond.Broadcast()
+
+	js.log.Info("Job queue resumed")
+}
+
+// IsPaused reports whether the queue is currently paused
+func (js *service) IsPaused() bool {
+	js.pauseMu.Lock()
+	defer js.pauseMu.Unlock()
+	return js.paused
+}
+
+// waitWhilePaused blocks while the queue is paused. It returns false if quit
+// is closed first; a nil quit waits for resume only.
+func (js *service) waitWhilePaused(quit <-chan struct{}) bool {
+	js.pauseMu.Lock()
+	defer js.pauseMu.Unlock()
+	for js.paused {
+		select {
+		case <-quit:
+			return false
+		default:
+		}
+		js.pauseCond.Wait()
+	}
+	return true
+}
+
 func (js *service) Start() error {
 	js.log.Info("Starting job service")
+	if err := js.restoreJobs(); err != nil {
+		js.log.Errorf("Failed to restore jobs from the job store: %v", err)
+	}
 	js.startWorkers()
+
+	if js.cfg.Job.SlowJobThreshold > 0 || js.cfg.Job.StuckJobThreshold > 0 {
+		js.stopWatcher = make(chan struct{})
+		go js.watchSlowJobs(js.stopWatcher)
+	}
 	return nil
 }
 
 func (js *service) Stop() error {
 	js.log.Info("Stopping job service")
-	close(js.jobQueue)
+	if js.stopWatcher != nil {
+		close(js.stopWatcher)
+		js.stopWatcher = nil
+	}
+	js.poolMu.Lock()
+	js.stopped = true
+	js.poolMu.Unlock()
+	js.queue.close()
+
+	// Release workers blocked on a pause so they can observe the closed queue
+	js.Resume()
 	return nil
 }
+
+// watchSlowJobs periodically flags slow and possibly stuck processing jobs until stop is closed
+func (js *service) watchSlowJobs(stop <-chan struct{}) {
+	interval := js.cfg.Job.StatusCheckInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			js.flagSlowJobs(now)
+		}
+	}
+}
+
+// flagSlowJobs updates the health flag of processing jobs. A job whose
+// progress has not moved within the stuck threshold is possibly stuck; one
+// that keeps progressing but exceeds the slow threshold is merely slow.
+func (js *service) flagSlowJobs(now time.Time) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	for _, job := range js.jobs {
+		if job.Status != models.JobStatusProcessing || job.StartedAt == nil {
+			continue
+		}
+
+		var health models.JobHealth
+		stalled := now.Sub(job.LastProgressAt)
+		running := now.Sub(*job.StartedAt)
+		switch {
+		case js.cfg.Job.StuckJobThreshold > 0 && stalled > js.cfg.Job.StuckJobThreshold:
+			health = models.JobHealthPossiblyStuck
+		case js.cfg.Job.SlowJobThreshold > 0 && running > js.cfg.Job.SlowJobThreshold:
+			health = models.JobHealthSlow
+		}
+
+		if health == job.Health {
+			continue
+		}
+		job.Health = health
+
+		switch health {
+		case models.JobHealthPossiblyStuck:
+			js.log.Warnf("Job %s possibly stuck: no progress for %s (at %d%%)", job.ID, stalled.Round(time.Second), job.Progress)
+		case models.JobHealthSlow:
+			js.log.Warnf("Job %s is slow: processing for %s (at %d%%)", job.ID, running.Round(time.Second), job.Progress)
+		}
+	}
+}
@@ -0,0 +1,891 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/core/media/audio"
+	"github.com/activadee/videocraft/internal/core/media/subtitle"
+	"github.com/activadee/videocraft/internal/core/video/engine"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+// fakeFFmpeg renders through generate, or returns a fixed temp path, and
+// decode-checks renders through verifyDecode, or finds them clean
+type fakeFFmpeg struct {
+	generate     func(ctx context.Context, config *models.VideoConfigArray) (string, error)
+	verifyDecode func(ctx context.Context, videoPath string) ([]string, error)
+}
+
+func (f *fakeFFmpeg) GenerateVideo(ctx context.Context, config *models.VideoConfigArray, progressChan chan<- models.RenderProgress) (string, error) {
+	if progressChan != nil {
+		defer close(progressChan)
+	}
+	if f.generate == nil {
+		return "/tmp/render.mp4", nil
+	}
+	return f.generate(ctx, config)
+}
+
+func (f *fakeFFmpeg) GenerateVideoWithSubtitles(ctx context.Context, config *models.VideoConfigArray, _ string, progressChan chan<- models.RenderProgress) (string, error) {
+	return f.GenerateVideo(ctx, config, progressChan)
+}
+
+func (f *fakeFFmpeg) BuildCommand(*models.VideoConfigArray) (*engine.FFmpegCommand, error) {
+	return &engine.FFmpegCommand{}, nil
+}
+
+func (f *fakeFFmpeg) VerifyDecode(ctx context.Context, videoPath string) ([]string, error) {
+	if f.verifyDecode == nil {
+		return nil, nil
+	}
+	return f.verifyDecode(ctx, videoPath)
+}
+
+func (f *fakeFFmpeg) MeasureLoudness(context.Context, models.Element) (*models.LoudnessMeasurement, error) {
+	return &models.LoudnessMeasurement{}, nil
+}
+
+// fakeSubtitles generates subtitles through generate, or none at all
+type fakeSubtitles struct {
+	generate func(project models.VideoProject) (*subtitle.SubtitleResult, error)
+}
+
+func (*fakeSubtitles) ValidateJSONSubtitleSettings(models.VideoProject) error { return nil }
+
+func (f *fakeSubtitles) GenerateSubtitles(_ context.Context, project models.VideoProject) (*subtitle.SubtitleResult, error) {
+	if f.generate == nil {
+		return nil, nil
+	}
+	return f.generate(project)
+}
+
+func (*fakeSubtitles) CleanupTempFiles(string) error { return nil }
+
+// fakeStorage keeps stored video IDs in memory and rejects existing IDs
+type fakeStorage struct {
+	mu        sync.Mutex
+	videos    map[string]bool
+	manifests map[string][]byte
+	deleted   []string
+	storeErr  func(videoID string) error
+	nextID    int
+}
+
+func newFakeStorage() *fakeStorage {
+	return &fakeStorage{videos: make(map[string]bool), manifests: make(map[string][]byte)}
+}
+
+func (s *fakeStorage) StoreVideo(videoPath, subdir string) (string, error) {
+	s.mu.Lock()
+	s.nextID++
+	id := fmt.Sprintf("video-%d", s.nextID)
+	s.mu.Unlock()
+	return s.StoreVideoWithID(videoPath, id, subdir)
+}
+
+func (s *fakeStorage) StoreVideoWithID(_, desiredID, _ string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.storeErr != nil {
+		if err := s.storeErr(desiredID); err != nil {
+			return "", err
+		}
+	}
+	if s.videos[desiredID] {
+		return "", errors.Conflict("video already exists: " + desiredID)
+	}
+	s.videos[desiredID] = true
+	return desiredID, nil
+}
+
+func (s *fakeStorage) StoreSubtitle(_, videoID string) (string, error) {
+	return videoID + "-subtitles", nil
+}
+
+func (s *fakeStorage) StoreCaption(_, videoID, format string) (string, error) {
+	return videoID + "-" + format + "-subtitles", nil
+}
+
+func (s *fakeStorage) StoreManifest(data []byte, videoID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.manifests[videoID+"-manifest"] = data
+	return videoID + "-manifest", nil
+}
+
+// manifest returns the stored manifest with the given ID
+func (s *fakeStorage) manifest(t *testing.T, manifestID string) models.RenderManifest {
+	t.Helper()
+	s.mu.Lock()
+	data, ok := s.manifests[manifestID]
+	s.mu.Unlock()
+	if !ok {
+		t.Fatalf("manifest %s was not stored", manifestID)
+	}
+	var manifest models.RenderManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("stored manifest is not JSON: %v", err)
+	}
+	return manifest
+}
+
+func (s *fakeStorage) VideoExists(videoID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.videos[videoID]
+}
+
+func (s *fakeStorage) GetVideo(videoID string) (string, error) {
+	if !s.VideoExists(videoID) {
+		return "", errors.FileNotFound(videoID)
+	}
+	return "/tmp/" + videoID + ".mp4", nil
+}
+
+func (s *fakeStorage) DeleteVideo(videoID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.videos, videoID)
+	s.deleted = append(s.deleted, videoID)
+	return nil
+}
+
+func (s *fakeStorage) stored() map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	videos := make(map[string]bool, len(s.videos))
+	for id := range s.videos {
+		videos[id] = true
+	}
+	return videos
+}
+
+// fakeMedia reports 4s audio and 30s video, or the error of analyze
+type fakeMedia struct {
+	analyze func(ctx context.Context, url string) error
+}
+
+func (f *fakeMedia) check(ctx context.Context, url string) error {
+	if f.analyze == nil {
+		return nil
+	}
+	return f.analyze(ctx, url)
+}
+
+func (f *fakeMedia) AnalyzeAudio(ctx context.Context, url string) (*audio.AudioInfo, error) {
+	if err := f.check(ctx, url); err != nil {
+		return nil, err
+	}
+	return &audio.AudioInfo{URL: url, Duration: 4}, nil
+}
+
+func (f *fakeMedia) AnalyzeVideo(ctx context.Context, url string) (*models.VideoInfo, error) {
+	if err := f.check(ctx, url); err != nil {
+		return nil, err
+	}
+	return &models.VideoInfo{Duration: 30, Width: 1280, Height: 720, HasAudio: true}, nil
+}
+
+func (*fakeMedia) GetVideoMetadata(string) (*models.VideoInfo, error) {
+	return &models.VideoInfo{Duration: 6, Width: 1280, Height: 720}, nil
+}
+
+func (*fakeMedia) ValidateImage(string) error { return nil }
+
+// testJobService bundles a job service with its fakes
+type testJobService struct {
+	*service
+	ffmpeg    *fakeFFmpeg
+	subtitles *fakeSubtitles
+	storage   *fakeStorage
+	media     *fakeMedia
+}
+
+func newTestConfig() *app.Config {
+	cfg := &app.Config{}
+	cfg.Job.Workers = 1
+	cfg.Job.QueueSize = 10
+	cfg.Job.ProgressBufferSize = 10
+	cfg.Job.CallbackTimeout = time.Second
+	cfg.Storage.OutputCollisionPolicy = app.CollisionPolicyReject
+	return cfg
+}
+
+// newTestJobService returns a job service whose workers are not started
+func newTestJobService(t *testing.T, cfg *app.Config) *testJobService {
+	t.Helper()
+	ffmpeg := &fakeFFmpeg{}
+	subtitles := &fakeSubtitles{}
+	storage := newFakeStorage()
+	media := &fakeMedia{}
+	js := NewService(cfg, logger.NewWithWriter("error", io.Discard, "text"), nil,
+		ffmpeg, subtitles, storage, media, media, media).(*service)
+	t.Cleanup(func() { _ = js.Stop() })
+	return &testJobService{service: js, ffmpeg: ffmpeg, subtitles: subtitles, storage: storage, media: media}
+}
+
+func newTestVideoConfig() *models.VideoConfigArray {
+	return &models.VideoConfigArray{{
+		Width:    1280,
+		Height:   720,
+		Elements: []models.Element{{Type: "video", Src: "https://example.com/bg.mp4"}},
+		Scenes: []models.Scene{
+			{ID: "intro", Elements: []models.Element{{Type: "audio", Src: "https://example.com/intro.mp3"}}},
+		},
+	}}
+}
+
+// waitForStatus waits until the job reaches status
+func waitForStatus(t *testing.T, js *testJobService, jobID string, status models.JobStatus) *models.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := js.GetJob(jobID)
+		if err != nil {
+			t.Fatalf("GetJob(%s) error = %v", jobID, err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s, want %s", jobID, job.Status, status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitFor waits until cond holds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCancelJobStopsRunningRender(t *testing.T) {
+	js := newTestJobService(t, newTestConfig())
+	rendering := make(chan struct{})
+	renderErr := make(chan error, 1)
+	js.ffmpeg.generate = func(ctx context.Context, _ *models.VideoConfigArray) (string, error) {
+		close(rendering)
+		// Like runFFmpeg, the render ends when its context is cancelled
+		<-ctx.Done()
+		renderErr <- ctx.Err()
+		return "", errors.FFmpegFailed(fmt.Errorf("render cancelled: %w", ctx.Err()))
+	}
+	if err := js.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := js.CreateJob(newTestVideoConfig(), "")
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	<-rendering
+
+	if err := js.CancelJob(job.ID); err != nil {
+		t.Fatalf("CancelJob() error = %v", err)
+	}
+	select {
+	case err := <-renderErr:
+		if err != context.Canceled {
+			t.Errorf("render context error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("render context was not cancelled")
+	}
+
+	waitFor(t, "worker to finish the job", func() bool { return js.WorkerStats().ActiveJobs == 0 })
+	if got := waitForStatus(t, js, job.ID, models.JobStatusCancelled); got.VideoID != "" {
+		t.Errorf("cancelled job has video %s", got.VideoID)
+	}
+}
+
+// gatedRender makes the fake FFmpeg block every render until release is
+// closed and counts the renders started
+func gatedRender(js *testJobService) (started <-chan struct{}, release chan struct{}) {
+	startedCh := make(chan struct{}, 100)
+	release = make(chan struct{})
+	js.ffmpeg.generate = func(ctx context.Context, _ *models.VideoConfigArray) (string, error) {
+		startedCh <- struct{}{}
+		select {
+		case <-release:
+			return "/tmp/render.mp4", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	return startedCh, release
+}
+
+func TestPauseFinishesInFlightJobsAndResumeDrainsBacklog(t *testing.T) {
+	js := newTestJobService(t, newTestConfig())
+	started, release := gatedRender(js)
+	if err := js.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	inFlight, err := js.CreateJob(newTestVideoConfig(), "")
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	<-started
+
+	js.Pause()
+	var backlog []string
+	for i := 0; i < 3; i++ {
+		job, err := js.CreateJob(newTestVideoConfig(), "")
+		if err != nil {
+			t.Fatalf("CreateJob() error = %v", err)
+		}
+		backlog = append(backlog, job.ID)
+	}
+
+	// The job taken before the pause still finishes
+	close(release)
+	waitForStatus(t, js, inFlight.ID, models.JobStatusCompleted)
+
+	// Give the idle worker a chance to dequeue, which it must not do while paused
+	time.Sleep(50 * time.Millisecond)
+	if n := len(started); n != 0 {
+		t.Fatalf("%d renders started while paused", n)
+	}
+	if depth := js.WorkerStats().QueueDepth; depth != len(backlog) {
+		t.Errorf("queue depth while paused = %d, want %d", depth, len(backlog))
+	}
+	for _, id := range backlog {
+		waitForStatus(t, js, id, models.JobStatusPending)
+	}
+
+	js.Resume()
+	for _, id := range backlog {
+		waitForStatus(t, js, id, models.JobStatusCompleted)
+	}
+	if depth := js.WorkerStats().QueueDepth; depth != 0 {
+		t.Errorf("queue depth after resume = %d, want 0", depth)
+	}
+}
+
+func TestSetWorkersResizesPoolWhileJobsRun(t *testing.T) {
+	js := newTestJobService(t, newTestConfig())
+	started, release := gatedRender(js)
+	baseline := runtime.NumGoroutine()
+	if err := js.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	var jobs []string
+	for i := 0; i < 6; i++ {
+		job, err := js.CreateJob(newTestVideoConfig(), "")
+		if err != nil {
+			t.Fatalf("CreateJob() error = %v", err)
+		}
+		jobs = append(jobs, job.ID)
+	}
+	<-started
+
+	// Growing starts new workers right away
+	if err := js.SetWorkers(4); err != nil {
+		t.Fatalf("SetWorkers(4) error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+	if stats := js.WorkerStats(); stats.Workers != 4 || stats.ActiveJobs != 4 {
+		t.Fatalf("after growing: %+v, want 4 workers with 4 active jobs", stats)
+	}
+
+	// Shrinking lets removed workers finish their current job
+	if err := js.SetWorkers(1); err != nil {
+		t.Fatalf("SetWorkers(1) error = %v", err)
+	}
+	if stats := js.WorkerStats(); stats.Workers != 1 || stats.ActiveJobs != 4 {
+		t.Fatalf("after shrinking: %+v, want 1 worker with 4 active jobs", stats)
+	}
+	close(release)
+
+	for _, id := range jobs {
+		waitForStatus(t, js, id, models.JobStatusCompleted)
+	}
+	if got := len(js.storage.stored()); got != len(jobs) {
+		t.Errorf("stored %d videos, want %d", got, len(jobs))
+	}
+
+	// Only the remaining worker is left running
+	waitFor(t, "removed workers to exit", func() bool { return runtime.NumGoroutine() <= baseline+1 })
+}
+
+func TestSetWorkersRejectsInvalidCounts(t *testing.T) {
+	js := newTestJobService(t, newTestConfig())
+	for _, count := range []int{0, -1, maxWorkers + 1} {
+		if err := js.SetWorkers(count); err == nil {
+			t.Errorf("SetWorkers(%d) error = nil", count)
+		}
+	}
+
+	if err := js.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := js.SetWorkers(2); err == nil {
+		t.Error("SetWorkers() on a stopped service error = nil")
+	}
+}
+
+func TestCreateJobRejectsFullQueue(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Job.QueueSize = 2
+	js := newTestJobService(t, cfg)
+
+	for i := 0; i < cfg.Job.QueueSize; i++ {
+		if _, err := js.CreateJob(newTestVideoConfig(), ""); err != nil {
+			t.Fatalf("CreateJob() %d error = %v", i, err)
+		}
+	}
+	if _, err := js.CreateJob(newTestVideoConfig(), ""); err == nil || !strings.Contains(err.Error(), "job queue is full") {
+		t.Fatalf("CreateJob() on a full queue error = %v, want queue full", err)
+	}
+
+	jobs, err := js.ListJobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != cfg.Job.QueueSize {
+		t.Errorf("ListJobs() returned %d jobs, want the %d queued ones", len(jobs), cfg.Job.QueueSize)
+	}
+}
+
+func TestCreateJobChecksOutputIDsUnderRejectPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		existing   string
+		renditions []models.Rendition
+		wantErr    bool
+	}{
+		{name: "free id", policy: app.CollisionPolicyReject, existing: "other"},
+		{name: "existing id", policy: app.CollisionPolicyReject, existing: "launch", wantErr: true},
+		{
+			name:       "existing rendition id",
+			policy:     app.CollisionPolicyReject,
+			existing:   "launch-720p",
+			renditions: []models.Rendition{{Width: 640, Height: 360}, {Width: 1280, Height: 720}},
+			wantErr:    true,
+		},
+		{
+			name:       "base id of renditions is not stored",
+			policy:     app.CollisionPolicyReject,
+			existing:   "launch",
+			renditions: []models.Rendition{{Width: 1280, Height: 720}},
+		},
+		{name: "overwrite", policy: app.CollisionPolicyOverwrite, existing: "launch"},
+		{name: "version", policy: app.CollisionPolicyVersion, existing: "launch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig()
+			cfg.Storage.OutputCollisionPolicy = tt.policy
+			js := newTestJobService(t, cfg)
+			js.storage.videos[tt.existing] = true
+
+			config := newTestVideoConfig()
+			(*config)[0].OutputID = "launch"
+			if tt.renditions != nil {
+				(*config)[0].Width, (*config)[0].Height = 0, 0
+				(*config)[0].Renditions = tt.renditions
+			}
+			_, err := js.CreateJob(config, "")
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("CreateJob() error = %v", err)
+				}
+				return
+			}
+
+			var vpe *errors.VideoProcessingError
+			if !stderrors.As(err, &vpe) || vpe.Code != errors.ErrCodeConflict {
+				t.Fatalf("CreateJob() error = %v, want conflict", err)
+			}
+			if jobs, _ := js.ListJobs(); len(jobs) != 0 {
+				t.Errorf("rejected request left %d jobs", len(jobs))
+			}
+		})
+	}
+}
+
+// startProcessing moves a new job to processing without a worker and
+// returns it with the time processing started
+func startProcessing(t *testing.T, js *testJobService) (*models.Job, time.Time) {
+	t.Helper()
+	job, err := js.CreateJob(newTestVideoConfig(), "")
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	if err := js.UpdateJobStatus(job.ID, models.JobStatusProcessing, ""); err != nil {
+		t.Fatalf("UpdateJobStatus() error = %v", err)
+	}
+	job, _ = js.GetJob(job.ID)
+	return job, *job.StartedAt
+}
+
+func TestFlagSlowJobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    models.JobHealth
+	}{
+		{name: "within thresholds", elapsed: 5 * time.Second},
+		{name: "slow", elapsed: 15 * time.Second, want: models.JobHealthSlow},
+		{name: "possibly stuck", elapsed: 25 * time.Second, want: models.JobHealthPossiblyStuck},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig()
+			cfg.Job.SlowJobThreshold = 10 * time.Second
+			cfg.Job.StuckJobThreshold = 20 * time.Second
+			js := newTestJobService(t, cfg)
+			job, started := startProcessing(t, js)
+
+			js.flagSlowJobs(started.Add(tt.elapsed))
+			if got, _ := js.GetJob(job.ID); got.Health != tt.want {
+				t.Errorf("health = %q, want %q", got.Health, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagSlowJobsClearsStuckFlagOnProgress(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Job.StuckJobThreshold = 20 * time.Second
+	js := newTestJobService(t, cfg)
+	job, started := startProcessing(t, js)
+
+	js.flagSlowJobs(started.Add(30 * time.Second))
+	if got, _ := js.GetJob(job.ID); got.Health != models.JobHealthPossiblyStuck {
+		t.Fatalf("health = %q, want %q", got.Health, models.JobHealthPossiblyStuck)
+	}
+
+	if err := js.UpdateJobProgress(job.ID, 40); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := js.GetJob(job.ID); got.Health != "" {
+		t.Errorf("health after progress = %q, want none", got.Health)
+	}
+}
+
+func TestSlowJobWatcherFlagsRunningJob(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Job.SlowJobThreshold = 20 * time.Millisecond
+	cfg.Job.StatusCheckInterval = 5 * time.Millisecond
+	js := newTestJobService(t, cfg)
+	started, release := gatedRender(js)
+	defer close(release)
+	if err := js.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := js.CreateJob(newTestVideoConfig(), "")
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	<-started
+	waitFor(t, "job to be flagged slow", func() bool {
+		got, _ := js.GetJob(job.ID)
+		return got.Health == models.JobHealthSlow
+	})
+}
+
+func TestEmptySubtitlesWarnOrFailJob(t *testing.T) {
+	tests := []struct {
+		name        string
+		failOnEmpty bool
+		wantStatus  models.JobStatus
+	}{
+		{"rendered without subtitles", false, models.JobStatusCompleted},
+		{"failed", true, models.JobStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig()
+			cfg.Subtitles.Enabled = true
+			cfg.Subtitles.FailOnEmpty = tt.failOnEmpty
+			js := newTestJobService(t, cfg)
+			js.subtitles.generate = func(models.VideoProject) (*subtitle.SubtitleResult, error) {
+				return nil, subtitle.ErrNoSubtitleEvents
+			}
+			if err := js.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			config := newTestVideoConfig()
+			(*config)[0].Elements = append((*config)[0].Elements, models.Element{Type: "subtitles"})
+			job, err := js.CreateJob(config, "")
+			if err != nil {
+				t.Fatalf("CreateJob() error = %v", err)
+			}
+
+			got := waitForStatus(t, js, job.ID, tt.wantStatus)
+			if tt.failOnEmpty {
+				if !strings.Contains(got.Error, subtitle.ErrNoSubtitleEvents.Error()) {
+					t.Errorf("job error = %q, want the missing subtitle events", got.Error)
+				}
+				return
+			}
+			if len(got.Warnings) != 1 || !strings.Contains(got.Warnings[0], "rendered without subtitles") {
+				t.Errorf("job warnings = %q, want one about the missing subtitles", got.Warnings)
+			}
+		})
+	}
+}
+
+func TestAnalysisFallsBackToWorkingSrc(t *testing.T) {
+	const (
+		primary = "https://a.example.com/bg.mp4"
+		broken  = "https://b.example.com/bg.mp4"
+		mirror  = "https://c.example.com/bg.mp4"
+	)
+
+	tests := []struct {
+		name       string
+		failing    map[string]bool
+		wantStatus models.JobStatus
+		wantSrc    string
+	}{
+		{"primary works", map[string]bool{}, models.JobStatusCompleted, primary},
+		{"second fallback works", map[string]bool{primary: true, broken: true}, models.JobStatusCompleted, mirror},
+		{"every src fails", map[string]bool{primary: true, broken: true, mirror: true}, models.JobStatusFailed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			js := newTestJobService(t, newTestConfig())
+			var mu sync.Mutex
+			var analyzed []string
+			js.media.analyze = func(_ context.Context, url string) error {
+				if !strings.HasSuffix(url, "bg.mp4") {
+					return nil
+				}
+				mu.Lock()
+				analyzed = append(analyzed, url)
+				mu.Unlock()
+				if tt.failing[url] {
+					return fmt.Errorf("probe of %s failed", url)
+				}
+				return nil
+			}
+			var rendered string
+			js.ffmpeg.generate = func(_ context.Context, config *models.VideoConfigArray) (string, error) {
+				rendered = (*config)[0].Elements[0].Src
+				return "/tmp/render.mp4", nil
+			}
+			if err := js.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			config := newTestVideoConfig()
+			(*config)[0].Elements[0].Src = primary
+			(*config)[0].Elements[0].FallbackSrcs = []string{broken, mirror}
+			job, err := js.CreateJob(config, "")
+			if err != nil {
+				t.Fatalf("CreateJob() error = %v", err)
+			}
+			got := waitForStatus(t, js, job.ID, tt.wantStatus)
+
+			mu.Lock()
+			wantAnalyzed := min(len(tt.failing)+1, 3)
+			if len(analyzed) != wantAnalyzed {
+				t.Errorf("analyzed %q, want the first %d srcs in order", analyzed, wantAnalyzed)
+			}
+			mu.Unlock()
+
+			if tt.wantStatus == models.JobStatusFailed {
+				if !strings.Contains(got.Error, "all 3 sources failed") {
+					t.Errorf("job error = %q, want every src reported", got.Error)
+				}
+				if rendered != "" {
+					t.Errorf("job rendered %s after every src failed", rendered)
+				}
+				return
+			}
+			if rendered != tt.wantSrc {
+				t.Errorf("rendered background %s, want %s", rendered, tt.wantSrc)
+			}
+			if tt.wantSrc == primary {
+				if len(got.ResolvedSrcs) != 0 || len(got.Warnings) != 0 {
+					t.Errorf("job resolved %v with warnings %q, want neither", got.ResolvedSrcs, got.Warnings)
+				}
+				return
+			}
+			if got.ResolvedSrcs[primary] != mirror || len(got.ResolvedSrcs) != 1 {
+				t.Errorf("job resolved srcs = %v, want %s -> %s", got.ResolvedSrcs, primary, mirror)
+			}
+			if len(got.Warnings) != 1 || !strings.Contains(got.Warnings[0], "used fallback "+mirror) {
+				t.Errorf("job warnings = %q, want one naming the fallback", got.Warnings)
+			}
+		})
+	}
+}
+
+func TestAnalyzeWithFallbacksTimesOutEachSrc(t *testing.T) {
+	const (
+		stalled = "https://a.example.com/bg.mp4"
+		mirror  = "https://b.example.com/bg.mp4"
+	)
+
+	tests := []struct {
+		name    string
+		stalls  map[string]bool
+		wantSrc string
+		wantErr string
+	}{
+		{"fallback after a stalled src", map[string]bool{stalled: true}, mirror, ""},
+		{"every src stalls", map[string]bool{stalled: true, mirror: true}, "", "analysis timed out after 20ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig()
+			cfg.Job.AnalysisTimeout = 20 * time.Millisecond
+			js := newTestJobService(t, cfg)
+			element := &models.Element{Type: "video", Src: stalled, FallbackSrcs: []string{mirror}}
+
+			var attempts []string
+			err := js.analyzeWithFallbacks(context.Background(), element, map[string]string{}, func(ctx context.Context, src string) error {
+				attempts = append(attempts, src)
+				if _, ok := ctx.Deadline(); !ok {
+					t.Errorf("analysis of %s has no deadline", src)
+				}
+				if tt.stalls[src] {
+					<-ctx.Done()
+					return ctx.Err()
+				}
+				return nil
+			})
+
+			if len(attempts) != 2 {
+				t.Errorf("analyzed %q, want both srcs", attempts)
+			}
+			if tt.wantErr != "" {
+				if err == nil || strings.Count(err.Error(), tt.wantErr) != 2 {
+					t.Fatalf("analyzeWithFallbacks() error = %v, want a timeout for each src", err)
+				}
+				return
+			}
+			if err != nil || element.Src != tt.wantSrc {
+				t.Fatalf("analyzeWithFallbacks() = %v with src %s, want %s", err, element.Src, tt.wantSrc)
+			}
+		})
+	}
+}
+
+func TestCancelJobStopsMediaAnalysis(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Job.AnalysisTimeout = time.Minute
+	js := newTestJobService(t, cfg)
+	analyzing := make(chan struct{})
+	var once sync.Once
+	js.media.analyze = func(ctx context.Context, _ string) error {
+		once.Do(func() { close(analyzing) })
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	rendered := make(chan struct{}, 1)
+	js.ffmpeg.generate = func(context.Context, *models.VideoConfigArray) (string, error) {
+		rendered <- struct{}{}
+		return "/tmp/render.mp4", nil
+	}
+	if err := js.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := js.CreateJob(newTestVideoConfig(), "")
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	<-analyzing
+
+	if err := js.CancelJob(job.ID); err != nil {
+		t.Fatalf("CancelJob() error = %v", err)
+	}
+	waitFor(t, "worker to finish the job", func() bool { return js.WorkerStats().ActiveJobs == 0 })
+	waitForStatus(t, js, job.ID, models.JobStatusCancelled)
+	if len(rendered) != 0 {
+		t.Error("cancelled job was rendered")
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes of workers
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestDebugJobLogsAtDebugLevel(t *testing.T) {
+	var out syncBuffer
+	js := newTestJobService(t, newTestConfig())
+	js.log = logger.NewWithWriter("info", &out, "text")
+	if err := js.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	quiet, err := js.CreateJob(newTestVideoConfig(), "")
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	waitForStatus(t, js, quiet.ID, models.JobStatusCompleted)
+
+	config := newTestVideoConfig()
+	(*config)[0].Debug = true
+	debug, err := js.CreateJob(config, "")
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	waitForStatus(t, js, debug.ID, models.JobStatusCompleted)
+
+	var debugLines int
+	for _, line := range strings.Split(out.String(), "\n") {
+		if !strings.Contains(line, "level=DEBUG") {
+			continue
+		}
+		debugLines++
+		if !strings.Contains(line, "job_id="+debug.ID) {
+			t.Errorf("debug line not from the debug job: %s", line)
+		}
+	}
+	if debugLines == 0 {
+		t.Errorf("debug job logged no debug lines:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "Analyzing audio URL: https://example.com/intro.mp3") {
+		t.Errorf("media analysis did not log through the job logger:\n%s", out.String())
+	}
+}
@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+// renderTarget is one video rendered by a job
+type renderTarget struct {
+	name    string // rendition name; empty for a job without renditions
+	config  models.VideoConfigArray
+	videoID string // desired video ID; empty lets storage generate one
+}
+
+// renderTargets returns the videos a job renders: its first project as
+// configured, or one target per rendition. Rendition video IDs share a base
+// ID, the project's output_id or a generated one.
+func (js *service) renderTargets(job *models.Job) []renderTarget {
+	project := job.Config[0]
+	if len(project.Renditions) == 0 {
+		return []renderTarget{{config: job.Config, videoID: project.OutputID}}
+	}
+
+	baseID := project.OutputID
+	if baseID == "" {
+		baseID = uuid.New().String()
+	}
+
+	targets := make([]renderTarget, 0, len(project.Renditions))
+	for _, rendition := range project.Renditions {
+		config := append(models.VideoConfigArray(nil), job.Config...)
+		config[0] = project.ForRendition(rendition)
+		targets = append(targets, renderTarget{
+			name:    rendition.OutputName(),
+			config:  config,
+			videoID: models.RenditionVideoID(baseID, rendition),
+		})
+	}
+	return targets
+}
+
+// forwardProgress returns a progress channel for the index-th of count
+// renders, reporting its percent as that share of the job's progress
+func (js *service) forwardProgress(jobID string, index, count int) chan<- models.RenderProgress {
+	progressChan := make(chan models.RenderProgress, 10)
+	go func() {
+		for progress := range progressChan {
+			render := progress
+			if err := js.updateProgress(jobID, (index*100+progress.Percent)/count, &render); err != nil {
+				js.log.Errorf("Failed to update job progress: %v", err)
+			}
+		}
+	}()
+	return progressChan
+}
+
+// discardVideos deletes videos a failed job already stored. Failures are
+// logged; retention cleanup removes what is left.
+func (js *service) discardVideos(log logger.Logger, videoIDs []string) {
+	for _, videoID := range videoIDs {
+		if err := js.storage.DeleteVideo(videoID); err != nil {
+			log.Warnf("Failed to discard video %s of failed job: %v", videoID, err)
+			continue
+		}
+		log.Infof("Discarded video %s of failed job", videoID)
+	}
+}
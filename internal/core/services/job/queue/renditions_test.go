@@ -0,0 +1,124 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+// newRenditionsConfig renders the test project at 360p, 720p and 1080p
+func newRenditionsConfig() *models.VideoConfigArray {
+	config := newTestVideoConfig()
+	project := &(*config)[0]
+	project.Width, project.Height = 0, 0
+	project.OutputID = "launch"
+	project.Renditions = []models.Rendition{
+		{Width: 640, Height: 360},
+		{Width: 1280, Height: 720},
+		{Width: 1920, Height: 1080},
+	}
+	return config
+}
+
+func TestFailedRenditionDiscardsStoredVideos(t *testing.T) {
+	tests := []struct {
+		name        string
+		renderErr   func(config *models.VideoConfigArray) error
+		storeErr    func(videoID string) error
+		wantDeleted []string
+	}{
+		{
+			name: "render fails",
+			renderErr: func(config *models.VideoConfigArray) error {
+				if (*config)[0].Height == 720 {
+					return errors.InvalidInput("unsupported codec")
+				}
+				return nil
+			},
+			wantDeleted: []string{"launch-360p"},
+		},
+		{
+			name: "store fails",
+			storeErr: func(videoID string) error {
+				if videoID == "launch-1080p" {
+					return errors.InvalidInput("disk quota exceeded")
+				}
+				return nil
+			},
+			wantDeleted: []string{"launch-360p", "launch-720p"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			js := newTestJobService(t, newTestConfig())
+			js.storage.storeErr = tt.storeErr
+			if tt.renderErr != nil {
+				js.ffmpeg.generate = func(_ context.Context, config *models.VideoConfigArray) (string, error) {
+					return "/tmp/render.mp4", tt.renderErr(config)
+				}
+			}
+			if err := js.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			job, err := js.CreateJob(newRenditionsConfig(), "")
+			if err != nil {
+				t.Fatalf("CreateJob() error = %v", err)
+			}
+			if got := waitForStatus(t, js, job.ID, models.JobStatusFailed); got.VideoID != "" || got.Renditions != nil {
+				t.Errorf("failed job reports outputs: video %q, renditions %+v", got.VideoID, got.Renditions)
+			}
+
+			if stored := js.storage.stored(); len(stored) != 0 {
+				t.Errorf("videos left in storage: %v", stored)
+			}
+			js.storage.mu.Lock()
+			deleted := append([]string(nil), js.storage.deleted...)
+			js.storage.mu.Unlock()
+			sort.Strings(deleted)
+			if !reflect.DeepEqual(deleted, tt.wantDeleted) {
+				t.Errorf("deleted %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestRetryStoresRenditionsUnderSameIDs(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Job.MaxRetries = 1
+	cfg.Job.RetryBackoff = time.Millisecond
+	js := newTestJobService(t, cfg)
+
+	// The 720p render fails once with a transient error
+	var failed atomic.Bool
+	js.ffmpeg.generate = func(_ context.Context, config *models.VideoConfigArray) (string, error) {
+		if (*config)[0].Height == 720 && failed.CompareAndSwap(false, true) {
+			return "", errors.FFmpegFailed(fmt.Errorf("encoder crashed"))
+		}
+		return "/tmp/render.mp4", nil
+	}
+	if err := js.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := js.CreateJob(newRenditionsConfig(), "")
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	got := waitForStatus(t, js, job.ID, models.JobStatusCompleted)
+
+	want := map[string]bool{"launch-360p": true, "launch-720p": true, "launch-1080p": true}
+	if stored := js.storage.stored(); !reflect.DeepEqual(stored, want) {
+		t.Errorf("stored %v, want %v", stored, want)
+	}
+	if got.VideoID != "launch-360p" || len(got.Renditions) != 3 {
+		t.Errorf("job video %q with %d renditions, want launch-360p with 3", got.VideoID, len(got.Renditions))
+	}
+}
@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+// maxRetryBackoffShift caps the doubling of job.retry_backoff
+const maxRetryBackoffShift = 10
+
+// failJob marks a job failed, or schedules its retry when the cause is
+// transient and job.max_retries is not exhausted. A job waiting for its
+// retry is pending, so progress subscribers stay subscribed.
+func (js *service) failJob(id, errorMsg string, cause error) error {
+	// A cancelled job fails because it was stopped; it stays cancelled
+	if js.jobCancelled(id) {
+		return nil
+	}
+
+	if !errors.IsRetryable(cause) {
+		return js.UpdateJobStatus(id, models.JobStatusFailed, errorMsg)
+	}
+
+	js.mu.Lock()
+	job, exists := js.jobs[id]
+	if !exists {
+		js.mu.Unlock()
+		return errors.JobNotFound(id)
+	}
+	if !canTransition(job.Status, models.JobStatusPending) || job.RetryCount >= js.cfg.Job.MaxRetries {
+		js.mu.Unlock()
+		return js.UpdateJobStatus(id, models.JobStatusFailed, errorMsg)
+	}
+
+	job.RetryCount++
+	delay := js.retryDelay(job.RetryCount)
+	retryAt := time.Now().Add(delay)
+	job.Status = models.JobStatusPending
+	job.Error = errorMsg
+	job.Progress = 0
+	job.RenderProgress = nil
+	job.Health = ""
+	job.NextRetryAt = &retryAt
+	job.UpdatedAt = time.Now()
+	attempt := job.RetryCount
+	update := ProgressUpdate{JobID: id, Status: job.Status, Progress: job.Progress}
+	snapshot := *job
+	js.mu.Unlock()
+
+	js.saveJob(snapshot)
+	js.progress.publish(update)
+	js.log.Warnf("Job %s failed with a transient error, retry %d of %d in %s: %s", id, attempt, js.cfg.Job.MaxRetries, delay, errorMsg)
+	time.AfterFunc(delay, func() { js.requeue(job) })
+	return nil
+}
+
+// retryDelay is job.retry_backoff doubled for every retry after the first
+func (js *service) retryDelay(retry int) time.Duration {
+	return js.cfg.Job.RetryBackoff << min(retry-1, maxRetryBackoffShift)
+}
+
+// retryScheduled reports whether a job is waiting for a retry
+func (js *service) retryScheduled(id string) bool {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	job, exists := js.jobs[id]
+	return exists && job.Status == models.JobStatusPending && job.NextRetryAt != nil
+}
+
+// requeue queues a job again once its retry delay has passed. A job
+// cancelled in the meantime is dropped; one that cannot be queued fails.
+func (js *service) requeue(job *models.Job) {
+	js.mu.Lock()
+	if job.Status != models.JobStatusPending {
+		js.mu.Unlock()
+		return
+	}
+	job.NextRetryAt = nil
+	job.UpdatedAt = time.Now()
+	lastError, attempt := job.Error, job.RetryCount
+	snapshot := *job
+	js.mu.Unlock()
+	js.saveJob(snapshot)
+
+	if js.queue.push(job) {
+		js.log.Infof("Job %s queued for retry %d", job.ID, attempt)
+		return
+	}
+
+	js.log.Errorf("Failed to queue retry of job %s", job.ID)
+	if err := js.UpdateJobStatus(job.ID, models.JobStatusFailed, fmt.Sprintf("%s (retry could not be queued)", lastError)); err != nil {
+		js.log.Errorf("Failed to update job status: %v", err)
+	}
+	js.recordDeadLetter(job.ID, nil)
+}
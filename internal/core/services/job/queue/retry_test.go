@@ -0,0 +1,120 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+func TestRetryDelay(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Job.RetryBackoff = 2 * time.Second
+	js := newTestJobService(t, cfg)
+
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 8 * time.Second},
+		{5, 32 * time.Second},
+		{11, 2048 * time.Second},
+		// The doubling is capped after ten retries
+		{12, 2048 * time.Second},
+		{40, 2048 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := js.retryDelay(tt.retry); got != tt.want {
+			t.Errorf("retryDelay(%d) = %s, want %s", tt.retry, got, tt.want)
+		}
+	}
+}
+
+func TestFailJobSchedulesRetryWithBackoff(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Job.MaxRetries = 3
+	cfg.Job.RetryBackoff = time.Hour
+	js := newTestJobService(t, cfg)
+	job, _ := startProcessing(t, js)
+
+	before := time.Now()
+	if err := js.failJob(job.ID, "download failed", errors.DownloadFailed("https://example.com/intro.mp3", fmt.Errorf("timeout"))); err != nil {
+		t.Fatalf("failJob() error = %v", err)
+	}
+
+	got, _ := js.GetJob(job.ID)
+	if got.Status != models.JobStatusPending || got.RetryCount != 1 || got.Error != "download failed" {
+		t.Fatalf("job = status %s, retry %d, error %q; want a pending first retry", got.Status, got.RetryCount, got.Error)
+	}
+	if got.NextRetryAt == nil || got.NextRetryAt.Before(before.Add(time.Hour)) {
+		t.Errorf("next retry at %v, want one backoff (1h) after %v", got.NextRetryAt, before)
+	}
+}
+
+func TestFailJobCutoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryCount int
+		cause      error
+		want       models.JobStatus
+	}{
+		{name: "transient error with retries left", retryCount: 1, cause: errors.Timeout("render", "30m"), want: models.JobStatusPending},
+		{name: "transient error after max retries", retryCount: 2, cause: errors.Timeout("render", "30m"), want: models.JobStatusFailed},
+		{name: "invalid input is not retried", cause: errors.InvalidInput("bad config"), want: models.JobStatusFailed},
+		{name: "plain error is not retried", cause: fmt.Errorf("boom"), want: models.JobStatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig()
+			cfg.Job.MaxRetries = 2
+			cfg.Job.RetryBackoff = time.Hour
+			js := newTestJobService(t, cfg)
+			job, _ := startProcessing(t, js)
+			js.jobs[job.ID].RetryCount = tt.retryCount
+
+			if err := js.failJob(job.ID, "render failed", tt.cause); err != nil {
+				t.Fatalf("failJob() error = %v", err)
+			}
+			if got, _ := js.GetJob(job.ID); got.Status != tt.want {
+				t.Errorf("status = %s, want %s", got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransientFailuresStopAtMaxRetries(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Job.MaxRetries = 2
+	cfg.Job.RetryBackoff = time.Millisecond
+	js := newTestJobService(t, cfg)
+
+	var renders atomic.Int32
+	js.ffmpeg.generate = func(context.Context, *models.VideoConfigArray) (string, error) {
+		renders.Add(1)
+		return "", errors.FFmpegFailed(fmt.Errorf("encoder crashed"))
+	}
+	if err := js.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := js.CreateJob(newTestVideoConfig(), "")
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	got := waitForStatus(t, js, job.ID, models.JobStatusFailed)
+	if got.RetryCount != cfg.Job.MaxRetries {
+		t.Errorf("retry count = %d, want %d", got.RetryCount, cfg.Job.MaxRetries)
+	}
+
+	// No further retry is scheduled once the job failed
+	time.Sleep(20 * time.Millisecond)
+	if n := renders.Load(); n != int32(cfg.Job.MaxRetries+1) {
+		t.Errorf("rendered %d times, want %d", n, cfg.Job.MaxRetries+1)
+	}
+}
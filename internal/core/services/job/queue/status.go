@@ -0,0 +1,42 @@
+package queue
+
+import (
+	"fmt"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+// jobTransitions lists the statuses a job may move to from each status.
+// Besides the pending → processing → completed/failed lifecycle and
+// cancellation, a processing job returns to pending when a retry is scheduled
+// or it is requeued after a restart, and a pending job fails when its retry
+// cannot be queued. Terminal statuses have no transitions.
+var jobTransitions = map[models.JobStatus][]models.JobStatus{
+	models.JobStatusPending: {
+		models.JobStatusProcessing,
+		models.JobStatusCancelled,
+		models.JobStatusFailed,
+	},
+	models.JobStatusProcessing: {
+		models.JobStatusCompleted,
+		models.JobStatusFailed,
+		models.JobStatusCancelled,
+		models.JobStatusPending,
+	},
+}
+
+// canTransition reports whether a job may move from one status to another
+func canTransition(from, to models.JobStatus) bool {
+	for _, status := range jobTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidTransition is the error returned for a rejected status change
+func invalidTransition(id string, from, to models.JobStatus) error {
+	return errors.Conflict(fmt.Sprintf("job %s cannot move from %s to %s", id, from, to))
+}
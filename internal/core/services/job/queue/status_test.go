@@ -0,0 +1,79 @@
+package queue
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+func TestCanTransition(t *testing.T) {
+	const (
+		pending    = models.JobStatusPending
+		processing = models.JobStatusProcessing
+		completed  = models.JobStatusCompleted
+		failed     = models.JobStatusFailed
+		cancelled  = models.JobStatusCancelled
+	)
+
+	tests := []struct {
+		from, to models.JobStatus
+		want     bool
+	}{
+		{pending, processing, true},
+		{pending, cancelled, true},
+		{pending, failed, true},
+		{pending, completed, false},
+		{pending, pending, false},
+
+		{processing, completed, true},
+		{processing, failed, true},
+		{processing, cancelled, true},
+		{processing, pending, true},
+		{processing, processing, false},
+
+		{completed, processing, false},
+		{completed, pending, false},
+		{completed, failed, false},
+		{completed, cancelled, false},
+
+		{failed, processing, false},
+		{failed, pending, false},
+		{failed, completed, false},
+
+		{cancelled, completed, false},
+		{cancelled, processing, false},
+		{cancelled, pending, false},
+		{cancelled, failed, false},
+
+		{"unknown", processing, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := canTransition(tt.from, tt.to); got != tt.want {
+				t.Errorf("canTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateJobStatusRejectsForbiddenTransition(t *testing.T) {
+	js := newTestJobService(t, newTestConfig())
+	job, err := js.CreateJob(newTestVideoConfig(), "")
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	if err := js.CancelJob(job.ID); err != nil {
+		t.Fatalf("CancelJob() error = %v", err)
+	}
+
+	err = js.UpdateJobStatus(job.ID, models.JobStatusCompleted, "")
+	var vpe *errors.VideoProcessingError
+	if !stderrors.As(err, &vpe) || vpe.Code != errors.ErrCodeConflict {
+		t.Fatalf("UpdateJobStatus(completed) error = %v, want conflict", err)
+	}
+	if got, _ := js.GetJob(job.ID); got.Status != models.JobStatusCancelled {
+		t.Errorf("status = %s, want %s", got.Status, models.JobStatusCancelled)
+	}
+}
@@ -0,0 +1,243 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+)
+
+// JobStore persists job state so jobs survive restarts. The service keeps
+// its in-memory job map as a cache in front of the store.
+type JobStore interface {
+	// Save writes the current state of a job
+	Save(job *models.Job) error
+	// Load returns every saved job
+	Load() ([]*models.Job, error)
+	// Delete removes a saved job; deleting an unknown job is not an error
+	Delete(id string) error
+}
+
+// fileJobStore keeps each job as a JSON file named after its ID
+type fileJobStore struct {
+	dir string
+
+	// Saves run outside the jobs lock, so an older snapshot can arrive after
+	// a newer one; saved tracks the UpdatedAt written per job to drop those
+	mu    sync.Mutex
+	saved map[string]time.Time
+}
+
+// NewFileJobStore returns a JobStore writing one JSON file per job to dir.
+// Job configs can hold source URLs with credentials, so files are readable
+// by the service user only.
+func NewFileJobStore(dir string) (JobStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create job store directory: %w", err)
+	}
+	return &fileJobStore{dir: dir, saved: make(map[string]time.Time)}, nil
+}
+
+func (s *fileJobStore) Save(job *models.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.saved[job.ID]; ok && job.UpdatedAt.Before(last) {
+		return nil
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job %s: %w", job.ID, err)
+	}
+
+	// Write and rename so a crash never leaves a truncated job file
+	path := filepath.Join(s.dir, job.ID+".json")
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write job %s: %w", job.ID, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to write job %s: %w", job.ID, err)
+	}
+
+	s.saved[job.ID] = job.UpdatedAt
+	return nil
+}
+
+func (s *fileJobStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(filepath.Join(s.dir, id+".json")); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete job %s: %w", id, err)
+	}
+	delete(s.saved, id)
+	return nil
+}
+
+func (s *fileJobStore) Load() ([]*models.Job, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job store directory: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var jobs []*models.Job
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read job file %s: %w", entry.Name(), err)
+		}
+		var job models.Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			return nil, fmt.Errorf("failed to decode job file %s: %w", entry.Name(), err)
+		}
+		s.saved[job.ID] = job.UpdatedAt
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// saveJob writes a snapshot of a job, taken under the jobs lock, to the
+// store. Failures are logged; the in-memory state stays authoritative.
+func (js *service) saveJob(snapshot models.Job) {
+	if js.store == nil {
+		return
+	}
+	if err := js.store.Save(&snapshot); err != nil {
+		js.log.Errorf("Failed to persist job %s: %v", snapshot.ID, err)
+	}
+}
+
+// ExpireJobs drops finished jobs that completed before cutoff from the cache
+// and the job store, and returns how many were dropped. It runs with storage
+// cleanup, so job records expire together with their videos.
+func (js *service) ExpireJobs(cutoff time.Time) int {
+	js.mu.Lock()
+	var expired []string
+	for id, job := range js.jobs {
+		if jobExpired(job, cutoff) {
+			delete(js.jobs, id)
+			expired = append(expired, id)
+		}
+	}
+	js.mu.Unlock()
+
+	js.deleteSavedJobs(expired)
+	return len(expired)
+}
+
+// jobExpired reports whether a job finished before cutoff
+func jobExpired(job *models.Job, cutoff time.Time) bool {
+	if !isTerminalStatus(job.Status) {
+		return false
+	}
+	finished := job.UpdatedAt
+	if job.CompletedAt != nil {
+		finished = *job.CompletedAt
+	}
+	return finished.Before(cutoff)
+}
+
+// deleteSavedJobs removes jobs from the store. Failures are logged; a job
+// file left behind is deleted again when jobs are next restored.
+func (js *service) deleteSavedJobs(ids []string) {
+	if js.store == nil {
+		return
+	}
+	for _, id := range ids {
+		if err := js.store.Delete(id); err != nil {
+			js.log.Errorf("Failed to delete expired job %s: %v", id, err)
+		}
+	}
+}
+
+// jobRetentionCutoff returns the completion time before which finished jobs
+// expire, following storage.retention_days
+func (js *service) jobRetentionCutoff() time.Time {
+	return time.Now().AddDate(0, 0, -js.cfg.Storage.RetentionDays)
+}
+
+// restoreJobs loads saved jobs into the cache and queues the unfinished
+// ones. Jobs interrupted while processing are queued again or failed
+// according to job.interrupted_policy. Finished jobs past retention are
+// deleted instead of restored.
+func (js *service) restoreJobs() error {
+	if js.store == nil {
+		return nil
+	}
+
+	jobs, err := js.store.Load()
+	if err != nil {
+		return err
+	}
+
+	var requeued, failed int
+	var expired []string
+	cutoff := js.jobRetentionCutoff()
+	for _, job := range jobs {
+		if jobExpired(job, cutoff) {
+			expired = append(expired, job.ID)
+			continue
+		}
+		wasFailed := job.Status == models.JobStatusFailed
+		js.mu.Lock()
+		js.jobs[job.ID] = job
+		if job.Status != models.JobStatusPending && job.Status != models.JobStatusProcessing {
+			js.mu.Unlock()
+			continue
+		}
+
+		if job.Status == models.JobStatusProcessing && js.cfg.Job.InterruptedPolicy == app.InterruptedJobFail {
+			js.failRestoredJob(job, "job was interrupted by a service restart")
+			failed++
+		} else {
+			// Unfinished jobs start over from the queue
+			job.Status = models.JobStatusPending
+			job.Progress = 0
+			job.RenderProgress = nil
+			job.Health = ""
+			job.NextRetryAt = nil
+			job.UpdatedAt = time.Now()
+
+			if js.queue.push(job) {
+				requeued++
+			} else {
+				js.failRestoredJob(job, "job could not be queued again after a service restart: job queue is full")
+				failed++
+			}
+		}
+		snapshot := *job
+		js.mu.Unlock()
+		js.saveJob(snapshot)
+		if !wasFailed && snapshot.Status == models.JobStatusFailed {
+			js.notifyCallback(snapshot)
+		}
+	}
+
+	js.deleteSavedJobs(expired)
+	js.log.Infof("Restored %d jobs from the job store: %d queued, %d failed, %d expired", len(jobs)-len(expired), requeued, failed, len(expired))
+	return nil
+}
+
+// failRestoredJob marks a restored job failed; the caller holds the jobs lock
+func (js *service) failRestoredJob(job *models.Job, reason string) {
+	now := time.Now()
+	job.Status = models.JobStatusFailed
+	job.Error = reason
+	job.Health = ""
+	job.CompletedAt = &now
+	job.UpdatedAt = now
+}
@@ -0,0 +1,86 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/core/media/subtitle"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+// subtitleDurationTolerance is how much shorter than its subtitles a burned
+// render may be before trailing subtitles count as cut off
+const subtitleDurationTolerance = 0.5
+
+// verifySubtitleDelivery checks a rendered video against the subtitles it
+// should carry and returns the discrepancies found. Burned subtitles cannot
+// be told apart from the frames, so the render must probe as a complete video
+// covering the subtitle timeline; sidecar subtitles must be a non-empty file.
+func (js *service) verifySubtitleDelivery(project models.VideoProject, videoPath string, result *subtitle.SubtitleResult, sidecar bool) []string {
+	if result == nil {
+		return nil
+	}
+
+	if sidecar {
+		info, err := os.Stat(result.FilePath)
+		switch {
+		case err != nil:
+			return []string{fmt.Sprintf("sidecar subtitles are missing: %v", err)}
+		case info.Size() == 0:
+			return []string{"sidecar subtitle file is empty"}
+		}
+		return nil
+	}
+
+	outputInfo, err := js.video.GetVideoMetadata(videoPath)
+	if err != nil {
+		return []string{fmt.Sprintf("video with burned subtitles could not be verified: %v", err)}
+	}
+
+	subtitleEnd := result.TotalDuration.Seconds()
+	if !project.IsPreview() && outputInfo.Duration+subtitleDurationTolerance < subtitleEnd {
+		return []string{fmt.Sprintf("video ends at %.2fs but its burned subtitles run until %.2fs; trailing subtitles are missing",
+			outputInfo.Duration, subtitleEnd)}
+	}
+	return nil
+}
+
+// checkOutputIntegrity decodes a render once more when ffmpeg.integrity_check
+// is enabled. Decode errors fail the job in "fail" mode, removing the broken
+// render, and become a job warning in "warn" mode; a check that could not
+// run only warns.
+func (js *service) checkOutputIntegrity(ctx context.Context, jobID, videoPath string) error {
+	mode := js.cfg.FFmpeg.IntegrityCheck
+	if mode == "" || mode == app.IntegrityCheckOff {
+		return nil
+	}
+	log := logger.FromContext(ctx, js.log)
+
+	decodeErrors, err := js.ffmpeg.VerifyDecode(ctx, videoPath)
+	if err != nil {
+		log.Warnf("Output integrity check could not run: %v", err)
+		js.addJobWarning(jobID, fmt.Sprintf("output integrity check could not run: %v", err))
+		return nil
+	}
+	if len(decodeErrors) == 0 {
+		return nil
+	}
+
+	summary := strings.Join(decodeErrors, "; ")
+	if mode == app.IntegrityCheckFail {
+		if err := os.Remove(videoPath); err != nil {
+			log.Warnf("Failed to remove render that failed the integrity check %s: %v", videoPath, err)
+		}
+		return errors.FFmpegFailedWithLog(fmt.Errorf("output failed the integrity check: %s", decodeErrors[0]),
+			strings.Join(decodeErrors, "\n"))
+	}
+
+	log.Warnf("Output integrity check found decode errors: %s", summary)
+	js.addJobWarning(jobID, "output integrity check: "+summary)
+	return nil
+}
@@ -0,0 +1,161 @@
+package queue
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/core/media/subtitle"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+func TestVerifySubtitleDelivery(t *testing.T) {
+	dir := t.TempDir()
+	written := filepath.Join(dir, "subtitles.ass")
+	if err := os.WriteFile(written, []byte("[Script Info]"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty.ass")
+	if err := os.WriteFile(empty, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	subtitles := func(path string, seconds float64) *subtitle.SubtitleResult {
+		return &subtitle.SubtitleResult{FilePath: path, TotalDuration: time.Duration(seconds * float64(time.Second))}
+	}
+
+	// The fake probe reports every render as 6s long
+	tests := []struct {
+		name    string
+		preview float64
+		result  *subtitle.SubtitleResult
+		sidecar bool
+		want    []string
+	}{
+		{name: "no subtitles"},
+		{name: "burned within the video", result: subtitles(written, 6)},
+		{name: "burned within the tolerance", result: subtitles(written, 6.5)},
+		{
+			name:   "burned past the video",
+			result: subtitles(written, 7),
+			want:   []string{"video ends at 6.00s but its burned subtitles run until 7.00s; trailing subtitles are missing"},
+		},
+		{name: "preview cut short", preview: 3, result: subtitles(written, 7)},
+		{name: "sidecar written", result: subtitles(written, 7), sidecar: true},
+		{name: "sidecar empty", result: subtitles(empty, 6), sidecar: true, want: []string{"sidecar subtitle file is empty"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			js := newTestJobService(t, newTestConfig())
+			project := (*newTestVideoConfig())[0]
+			project.PreviewSeconds = tt.preview
+
+			got := js.verifySubtitleDelivery(project, "/tmp/render.mp4", tt.result, tt.sidecar)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("verifySubtitleDelivery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifySubtitleDeliveryReportsMissingSidecar(t *testing.T) {
+	js := newTestJobService(t, newTestConfig())
+	result := &subtitle.SubtitleResult{FilePath: filepath.Join(t.TempDir(), "gone.ass")}
+
+	got := js.verifySubtitleDelivery((*newTestVideoConfig())[0], "/tmp/render.mp4", result, true)
+	if len(got) != 1 || !strings.HasPrefix(got[0], "sidecar subtitles are missing: ") {
+		t.Errorf("verifySubtitleDelivery() = %q, want the missing sidecar reported", got)
+	}
+}
+
+func TestCheckOutputIntegrity(t *testing.T) {
+	decodeErrors := []string{"[h264 @ 0x1] corrupt macroblock", "Error while decoding stream #0:0"}
+	checkFailed := errors.FFmpegFailed(stderrors.New("exec: no such file"))
+
+	tests := []struct {
+		name         string
+		mode         string
+		decodeErrors []string
+		checkErr     error
+		wantChecked  bool
+		wantErr      bool
+		wantWarnings []string
+	}{
+		{name: "unset", decodeErrors: decodeErrors},
+		{name: "off", mode: app.IntegrityCheckOff, decodeErrors: decodeErrors},
+		{name: "warn clean", mode: app.IntegrityCheckWarn, wantChecked: true},
+		{
+			name:         "warn with decode errors",
+			mode:         app.IntegrityCheckWarn,
+			decodeErrors: decodeErrors,
+			wantChecked:  true,
+			wantWarnings: []string{"output integrity check: [h264 @ 0x1] corrupt macroblock; Error while decoding stream #0:0"},
+		},
+		{name: "fail clean", mode: app.IntegrityCheckFail, wantChecked: true},
+		{name: "fail with decode errors", mode: app.IntegrityCheckFail, decodeErrors: decodeErrors, wantChecked: true, wantErr: true},
+		{
+			name:         "fail when the check cannot run",
+			mode:         app.IntegrityCheckFail,
+			checkErr:     checkFailed,
+			wantChecked:  true,
+			wantWarnings: []string{"output integrity check could not run: " + checkFailed.Error()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig()
+			cfg.FFmpeg.IntegrityCheck = tt.mode
+			js := newTestJobService(t, cfg)
+			job, _ := startProcessing(t, js)
+
+			render := filepath.Join(t.TempDir(), "render.mp4")
+			if err := os.WriteFile(render, []byte("video"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			checked := false
+			js.ffmpeg.verifyDecode = func(_ context.Context, videoPath string) ([]string, error) {
+				checked = true
+				if videoPath != render {
+					t.Errorf("VerifyDecode(%s), want %s", videoPath, render)
+				}
+				return tt.decodeErrors, tt.checkErr
+			}
+
+			err := js.checkOutputIntegrity(context.Background(), job.ID, render)
+			if checked != tt.wantChecked {
+				t.Errorf("decode check ran = %v, want %v", checked, tt.wantChecked)
+			}
+			if tt.wantErr {
+				var vpe *errors.VideoProcessingError
+				if !stderrors.As(err, &vpe) || vpe.Code != errors.ErrCodeFFmpegFailed {
+					t.Fatalf("checkOutputIntegrity() error = %v, want FFmpeg failure", err)
+				}
+				if vpe.Details["ffmpeg_log"] != strings.Join(decodeErrors, "\n") {
+					t.Errorf("ffmpeg_log = %q, want the decode errors", vpe.Details["ffmpeg_log"])
+				}
+				if _, err := os.Stat(render); !os.IsNotExist(err) {
+					t.Errorf("render that failed the check was kept: %v", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("checkOutputIntegrity() error = %v", err)
+				}
+				if _, err := os.Stat(render); err != nil {
+					t.Errorf("render was removed: %v", err)
+				}
+			}
+
+			got, _ := js.GetJob(job.ID)
+			if !reflect.DeepEqual(got.Warnings, tt.wantWarnings) {
+				t.Errorf("warnings = %q, want %q", got.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
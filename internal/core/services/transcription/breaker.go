@@ -0,0 +1,77 @@
+package transcription
+
+import (
+	"sync"
+	"time"
+
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+// startupBreaker stops doomed daemon startups from holding up every request.
+// After threshold consecutive failures it opens and rejects startups until
+// cooldown has passed, then lets a single probe through (half-open): success
+// closes it, failure reopens it for another cooldown.
+type startupBreaker struct {
+	mu        sync.Mutex
+	threshold int // 0 disables the breaker
+	cooldown  time.Duration
+	now       func() time.Time
+
+	failures  int
+	openUntil time.Time
+	probing   bool
+	lastErr   error
+}
+
+func newStartupBreaker(threshold int, cooldown time.Duration) *startupBreaker {
+	return &startupBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow returns nil when a startup may be attempted, or a transcription
+// unavailable error saying when the next attempt will be made
+func (b *startupBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return nil
+	}
+
+	if wait := b.openUntil.Sub(b.now()); wait > 0 {
+		return errors.TranscriptionUnavailable(wait, b.lastErr)
+	}
+
+	// Half-open: one probe at a time while the others keep failing fast
+	if b.probing {
+		return errors.TranscriptionUnavailable(b.cooldown, b.lastErr)
+	}
+	b.probing = true
+	return nil
+}
+
+// success closes the breaker
+func (b *startupBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	b.lastErr = nil
+}
+
+// failure records a failed startup, opening the breaker at the threshold.
+// It returns true when the failure opened the breaker.
+func (b *startupBreaker) failure(err error) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	b.lastErr = err
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return false
+	}
+
+	b.openUntil = b.now().Add(b.cooldown)
+	return true
+}
@@ -0,0 +1,75 @@
+package transcription
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestStartupBreaker(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := newStartupBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+	startErr := fmt.Errorf("daemon exited")
+
+	// Closed: failures below the threshold keep allowing startups
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() on closed breaker = %v, want nil", err)
+	}
+	if b.failure(startErr) {
+		t.Fatal("failure() below threshold opened the breaker")
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() below threshold = %v, want nil", err)
+	}
+
+	// Open: the threshold rejects startups until the cooldown has passed
+	if !b.failure(startErr) {
+		t.Fatal("failure() at threshold did not open the breaker")
+	}
+	if err := b.allow(); err == nil {
+		t.Fatal("allow() on open breaker = nil, want an error")
+	}
+
+	// Half-open: a single probe is let through
+	now = now.Add(time.Minute + time.Second)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() after cooldown = %v, want nil", err)
+	}
+	if err := b.allow(); err == nil {
+		t.Fatal("allow() during probe = nil, want an error")
+	}
+
+	// A failed probe reopens the breaker for another cooldown
+	if !b.failure(startErr) {
+		t.Fatal("failed probe did not reopen the breaker")
+	}
+	if err := b.allow(); err == nil {
+		t.Fatal("allow() after failed probe = nil, want an error")
+	}
+
+	// A successful probe closes it
+	now = now.Add(time.Minute + time.Second)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() after second cooldown = %v, want nil", err)
+	}
+	b.success()
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() after success = %v, want nil", err)
+	}
+	if b.failure(startErr) {
+		t.Error("first failure after success opened the breaker")
+	}
+}
+
+func TestStartupBreakerDisabled(t *testing.T) {
+	b := newStartupBreaker(0, time.Minute)
+	for i := 0; i < 5; i++ {
+		if b.failure(fmt.Errorf("daemon exited")) {
+			t.Fatal("disabled breaker opened")
+		}
+		if err := b.allow(); err != nil {
+			t.Fatalf("allow() on disabled breaker = %v, want nil", err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package transcription
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+// cacheExtension is the file extension of cached transcription results
+const cacheExtension = ".json"
+
+// resultCache stores transcription results on disk, one JSON file per
+// transcription key. A file's modification time is its last use: entries
+// unused for ttl expire and beyond maxEntries the least recently used are
+// evicted.
+type resultCache struct {
+	dir        string
+	ttl        time.Duration
+	maxEntries int
+	filePerm   os.FileMode
+	dirPerm    os.FileMode
+	log        logger.Logger
+	mu         sync.Mutex
+}
+
+// newResultCache returns the configured cache, or nil when it is disabled
+func newResultCache(cfg *app.Config, log logger.Logger) *resultCache {
+	cacheCfg := cfg.Transcription.Cache
+	if !cacheCfg.Enabled {
+		return nil
+	}
+	return &resultCache{
+		dir:        cacheCfg.Dir,
+		ttl:        cacheCfg.TTL,
+		maxEntries: cacheCfg.MaxEntries,
+		filePerm:   cfg.Storage.FilePerm(),
+		dirPerm:    cfg.Storage.DirPerm(),
+		log:        log,
+	}
+}
+
+// path returns the cache file of a transcription key; keys are hashed
+// since they contain URLs
+func (rc *resultCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(rc.dir, hex.EncodeToString(sum[:])+cacheExtension)
+}
+
+// get returns the cached result for key, or nil on a miss. Expired and
+// unreadable entries are removed.
+func (rc *resultCache) get(key string) *TranscriptionResult {
+	if rc == nil {
+		return nil
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	path := rc.path(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	if rc.expired(info.ModTime(), time.Now()) {
+		rc.remove(path)
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		rc.log.Warnf("Failed to read cached transcription %s: %v", path, err)
+		return nil
+	}
+	var result TranscriptionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		rc.log.Warnf("Discarding corrupt cached transcription %s: %v", path, err)
+		rc.remove(path)
+		return nil
+	}
+
+	// Mark the entry as recently used
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		rc.log.Debugf("Failed to touch cached transcription %s: %v", path, err)
+	}
+	return &result
+}
+
+// put stores result under key and evicts entries beyond the cache limits.
+// Failures only cost a future cache hit, so they are logged, not returned.
+func (rc *resultCache) put(key string, result *TranscriptionResult) {
+	if rc == nil || result == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if err := rc.write(rc.path(key), result); err != nil {
+		rc.log.Warnf("Failed to cache transcription: %v", err)
+		return
+	}
+	rc.evict()
+}
+
+// write stores result atomically so concurrent readers never see a partial file
+func (rc *resultCache) write(path string, result *TranscriptionResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	if err := os.MkdirAll(rc.dir, rc.dirPerm); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(rc.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), rc.filePerm); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// cacheEntry is a cached result file and its last use
+type cacheEntry struct {
+	path     string
+	lastUsed time.Time
+}
+
+// evict removes expired entries, then the least recently used ones until
+// at most maxEntries remain
+func (rc *resultCache) evict() {
+	dirEntries, err := os.ReadDir(rc.dir)
+	if err != nil {
+		rc.log.Warnf("Failed to read transcription cache %s: %v", rc.dir, err)
+		return
+	}
+
+	now := time.Now()
+	var entries []cacheEntry
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != cacheExtension {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(rc.dir, name)
+		if rc.expired(info.ModTime(), now) {
+			rc.remove(path)
+			continue
+		}
+		entries = append(entries, cacheEntry{path: path, lastUsed: info.ModTime()})
+	}
+
+	if rc.maxEntries <= 0 || len(entries) <= rc.maxEntries {
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUsed.Before(entries[j].lastUsed)
+	})
+	for _, entry := range entries[:len(entries)-rc.maxEntries] {
+		rc.remove(entry.path)
+	}
+}
+
+// expired reports whether an entry last used at lastUsed has outlived the TTL
+func (rc *resultCache) expired(lastUsed, now time.Time) bool {
+	return rc.ttl > 0 && now.Sub(lastUsed) > rc.ttl
+}
+
+func (rc *resultCache) remove(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		rc.log.Debugf("Failed to remove cached transcription %s: %v", path, err)
+	}
+}
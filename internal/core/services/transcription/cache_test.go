@@ -0,0 +1,147 @@
+package transcription
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+func newTestCache(t *testing.T, ttl time.Duration, maxEntries int) *resultCache {
+	t.Helper()
+	return &resultCache{
+		dir:        t.TempDir(),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		filePerm:   0600,
+		dirPerm:    0700,
+		log:        logger.NewWithWriter("error", io.Discard, "text"),
+	}
+}
+
+// age sets the last use of a cached key
+func age(t *testing.T, rc *resultCache, key string, lastUsed time.Time) {
+	t.Helper()
+	if err := os.Chtimes(rc.path(key), lastUsed, lastUsed); err != nil {
+		t.Fatalf("failed to age %s: %v", key, err)
+	}
+}
+
+func TestResultCacheGetPut(t *testing.T) {
+	rc := newTestCache(t, 0, 0)
+
+	if got := rc.get("a"); got != nil {
+		t.Fatalf("get() on empty cache = %+v, want nil", got)
+	}
+	rc.put("a", &TranscriptionResult{Text: "hello", Success: true})
+
+	got := rc.get("a")
+	if got == nil || got.Text != "hello" || !got.Success {
+		t.Fatalf("get() = %+v, want the stored result", got)
+	}
+	if other := rc.get("b"); other != nil {
+		t.Errorf("get() of another key = %+v, want nil", other)
+	}
+}
+
+func TestResultCacheExpiresUnusedEntries(t *testing.T) {
+	rc := newTestCache(t, time.Hour, 0)
+	rc.put("stale", &TranscriptionResult{Text: "stale"})
+	rc.put("fresh", &TranscriptionResult{Text: "fresh"})
+	age(t, rc, "stale", time.Now().Add(-2*time.Hour))
+
+	if got := rc.get("stale"); got != nil {
+		t.Errorf("get() of expired entry = %+v, want nil", got)
+	}
+	if _, err := os.Stat(rc.path("stale")); !os.IsNotExist(err) {
+		t.Errorf("expired entry was not removed: %v", err)
+	}
+	if got := rc.get("fresh"); got == nil {
+		t.Error("get() of fresh entry = nil, want a hit")
+	}
+}
+
+func TestResultCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	rc := newTestCache(t, 0, 2)
+	now := time.Now()
+	rc.put("first", &TranscriptionResult{Text: "first"})
+	age(t, rc, "first", now.Add(-3*time.Minute))
+	rc.put("second", &TranscriptionResult{Text: "second"})
+	age(t, rc, "second", now.Add(-2*time.Minute))
+
+	// Using the oldest entry makes the second one least recently used
+	if rc.get("first") == nil {
+		t.Fatal("get() of first entry = nil, want a hit")
+	}
+	rc.put("third", &TranscriptionResult{Text: "third"})
+
+	for key, want := range map[string]bool{"first": true, "second": false, "third": true} {
+		if got := rc.get(key) != nil; got != want {
+			t.Errorf("entry %s cached = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestResultCacheDisabled(t *testing.T) {
+	var rc *resultCache
+	rc.put("a", &TranscriptionResult{Text: "a"})
+	if got := rc.get("a"); got != nil {
+		t.Errorf("get() on disabled cache = %+v, want nil", got)
+	}
+}
+
+func TestTranscriptionKey(t *testing.T) {
+	cfg := &app.Config{}
+	cfg.Transcription.Python.Model = "base"
+	ts := &service{cfg: cfg}
+
+	key := ts.transcriptionKey("https://example.com/a.mp3", "en")
+	differing := map[string]string{
+		"url":      ts.transcriptionKey("https://example.com/b.mp3", "en"),
+		"language": ts.transcriptionKey("https://example.com/a.mp3", "de"),
+	}
+	cfg.Transcription.Python.Model = "small"
+	differing["model"] = ts.transcriptionKey("https://example.com/a.mp3", "en")
+
+	for field, other := range differing {
+		if other == key {
+			t.Errorf("keys differing only in %s are equal: %s", field, key)
+		}
+	}
+	cfg.Transcription.Python.Model = "base"
+	if again := ts.transcriptionKey("https://example.com/a.mp3", "en"); again != key {
+		t.Errorf("transcriptionKey() = %s, then %s for the same request", key, again)
+	}
+}
+
+func TestTranscribeAudioCacheHitSkipsDaemon(t *testing.T) {
+	cfg := &app.Config{}
+	cfg.Transcription.Enabled = true
+	cfg.Transcription.Daemon.Enabled = true
+	cfg.Transcription.Daemon.PoolSize = 1
+	cfg.Transcription.Python.Path = filepath.Join(t.TempDir(), "missing-python")
+	cfg.Transcription.Python.Model = "base"
+	cfg.Transcription.Python.Language = "en"
+	cfg.Transcription.Cache = app.TranscriptionCacheConfig{Enabled: true, Dir: t.TempDir()}
+	ts := NewService(cfg, logger.NewWithWriter("error", io.Discard, "text")).(*service)
+
+	const url = "https://example.com/narration.mp3"
+	ts.cache.put(ts.transcriptionKey(url, "en"), &TranscriptionResult{Text: "cached", Success: true})
+
+	// The daemon cannot start, so only a cache hit succeeds
+	result, err := ts.TranscribeAudio(context.Background(), url, "")
+	if err != nil {
+		t.Fatalf("TranscribeAudio() on a cache hit error = %v", err)
+	}
+	if result.Text != "cached" {
+		t.Errorf("TranscribeAudio() text = %q, want the cached result", result.Text)
+	}
+	if _, err := ts.TranscribeAudio(context.Background(), url, "de"); err == nil {
+		t.Error("TranscribeAudio() of an uncached language reached no daemon and succeeded")
+	}
+}
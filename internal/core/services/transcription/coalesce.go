@@ -0,0 +1,89 @@
+package transcription
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+// inflightCall is a transcription in progress that later identical requests wait on
+type inflightCall struct {
+	done   chan struct{}
+	result *TranscriptionResult
+	err    error
+
+	// waiters counts the callers still waiting; the last one to give up
+	// cancels the call. Guarded by the coalescer's mutex.
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// requestCoalescer collapses concurrent identical transcription requests into
+// a single daemon round-trip whose result is shared by every caller
+type requestCoalescer struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+func newRequestCoalescer() *requestCoalescer {
+	return &requestCoalescer{calls: make(map[string]*inflightCall)}
+}
+
+// do runs fn once per key at a time. Callers arriving while fn runs wait for
+// and receive the same result; shared reports whether the result was reused.
+// fn runs under a context of its own, so one caller giving up does not fail
+// the others: each caller returns when its ctx ends, and fn is cancelled only
+// once every caller has.
+func (rc *requestCoalescer) do(ctx context.Context, key string, fn func(ctx context.Context) (*TranscriptionResult, error)) (result *TranscriptionResult, shared bool, err error) {
+	rc.mu.Lock()
+	call, shared := rc.calls[key]
+	if shared {
+		call.waiters++
+	} else {
+		// Keep the first caller's values such as its logger, not its cancellation
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &inflightCall{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		rc.calls[key] = call
+		go rc.run(callCtx, key, call, fn)
+	}
+	rc.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.result, shared, call.err
+	case <-ctx.Done():
+		rc.leave(key, call)
+		return nil, shared, errors.TranscriptionFailed(fmt.Errorf("waiting for transcription: %w", ctx.Err()))
+	}
+}
+
+// run executes fn for call and publishes its result
+func (rc *requestCoalescer) run(ctx context.Context, key string, call *inflightCall, fn func(ctx context.Context) (*TranscriptionResult, error)) {
+	defer call.cancel()
+	call.result, call.err = fn(ctx)
+
+	rc.mu.Lock()
+	if rc.calls[key] == call {
+		delete(rc.calls, key)
+	}
+	rc.mu.Unlock()
+	close(call.done)
+}
+
+// leave drops a caller that stopped waiting and cancels the call once no
+// caller is left; later requests for the key then start a fresh call
+func (rc *requestCoalescer) leave(key string, call *inflightCall) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	call.cancel()
+	if rc.calls[key] == call {
+		delete(rc.calls, key)
+	}
+}
@@ -0,0 +1,132 @@
+package transcription
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestCoalescerSharesResult(t *testing.T) {
+	rc := newRequestCoalescer()
+	release := make(chan struct{})
+	var calls atomic.Int32
+	fn := func(ctx context.Context) (*TranscriptionResult, error) {
+		calls.Add(1)
+		<-release
+		return &TranscriptionResult{Text: "shared"}, nil
+	}
+
+	const callers = 3
+	var wg sync.WaitGroup
+	var sharedCount atomic.Int32
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, shared, err := rc.do(context.Background(), "key", fn)
+			if err != nil || result == nil || result.Text != "shared" {
+				t.Errorf("do() = %+v, %v, want the shared result", result, err)
+			}
+			if shared {
+				sharedCount.Add(1)
+			}
+		}()
+	}
+	waitForWaiters(t, rc, "key", callers)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("fn ran %d times, want 1", calls.Load())
+	}
+	if sharedCount.Load() != callers-1 {
+		t.Errorf("%d callers shared the result, want %d", sharedCount.Load(), callers-1)
+	}
+}
+
+func TestRequestCoalescerSurvivesLeaderCancellation(t *testing.T) {
+	rc := newRequestCoalescer()
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (*TranscriptionResult, error) {
+		select {
+		case <-release:
+			return &TranscriptionResult{Text: "done"}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, _, err := rc.do(leaderCtx, "key", fn)
+		leaderErr <- err
+	}()
+	waitForWaiters(t, rc, "key", 1)
+
+	followerResult := make(chan *TranscriptionResult, 1)
+	go func() {
+		result, _, err := rc.do(context.Background(), "key", fn)
+		if err != nil {
+			t.Errorf("follower do() error = %v", err)
+		}
+		followerResult <- result
+	}()
+	waitForWaiters(t, rc, "key", 2)
+
+	cancelLeader()
+	if err := <-leaderErr; err == nil {
+		t.Fatal("cancelled leader got no error")
+	}
+	close(release)
+	if result := <-followerResult; result == nil || result.Text != "done" {
+		t.Errorf("follower result = %+v, want the shared result", result)
+	}
+}
+
+func TestRequestCoalescerCancelsAbandonedCall(t *testing.T) {
+	rc := newRequestCoalescer()
+	cancelled := make(chan struct{})
+	fn := func(ctx context.Context) (*TranscriptionResult, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, _, err := rc.do(ctx, "key", fn); err == nil {
+			t.Error("cancelled caller got no error")
+		}
+	}()
+	waitForWaiters(t, rc, "key", 1)
+	cancel()
+	<-done
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("call was not cancelled after its last caller left")
+	}
+}
+
+// waitForWaiters waits until count callers wait on the call for key
+func waitForWaiters(t *testing.T, rc *requestCoalescer, key string, count int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		rc.mu.Lock()
+		call := rc.calls[key]
+		waiting := call != nil && call.waiters == count
+		rc.mu.Unlock()
+		if waiting {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d callers on %s", count, key)
+}
@@ -0,0 +1,216 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+// daemonWorker is one slot of the daemon pool. Its daemon is started on
+// first use and restarted independently of the other workers.
+type daemonWorker struct {
+	id     int
+	mutex  sync.Mutex // guards daemon while it is started or stopped
+	daemon *WhisperDaemon
+
+	restartCount int
+	lastRestart  time.Time
+}
+
+// newDaemonPool creates size workers and queues them all as idle
+func newDaemonPool(size int) ([]*daemonWorker, chan *daemonWorker) {
+	if size < 1 {
+		size = 1
+	}
+	workers := make([]*daemonWorker, size)
+	idle := make(chan *daemonWorker, size)
+	for i := range workers {
+		workers[i] = &daemonWorker{id: i + 1}
+		idle <- workers[i]
+	}
+	return workers, idle
+}
+
+// acquireWorker blocks until a daemon worker is idle or the context ends
+func (ts *service) acquireWorker(ctx context.Context) (*daemonWorker, error) {
+	select {
+	case worker := <-ts.idle:
+		return worker, nil
+	case <-ctx.Done():
+		return nil, errors.TranscriptionFailed(fmt.Errorf("waiting for transcription daemon: %w", ctx.Err()))
+	}
+}
+
+// releaseWorker returns a worker to the idle queue
+func (ts *service) releaseWorker(worker *daemonWorker) {
+	ts.idle <- worker
+}
+
+// runningDaemon returns the worker's daemon if it is running
+func (w *daemonWorker) runningDaemon() *WhisperDaemon {
+	w.mutex.Lock()
+	daemon := w.daemon
+	w.mutex.Unlock()
+
+	if daemon == nil || !daemon.isRunning() {
+		return nil
+	}
+	return daemon
+}
+
+func (d *WhisperDaemon) isRunning() bool {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.running
+}
+
+// probeTimeout bounds a readiness probe of a single daemon
+const probeTimeout = 2 * time.Second
+
+// ErrNoDaemonRunning is returned by ReadinessCheck when no daemon is running
+// to probe; daemons are started on demand, so this alone is not a failure
+var ErrNoDaemonRunning = stderrors.New("no transcription daemon running")
+
+// ReadinessCheck sends a status request to every idle running daemon and
+// passes once one of them reports its model loaded. Daemons busy with a
+// transcription are taken as working and are not interrupted.
+func (ts *service) ReadinessCheck(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	// Take every idle worker; those without a daemon go back once all are taken
+	var probed, stopped []*daemonWorker
+	busy := len(ts.workers)
+	for taking := true; taking; {
+		select {
+		case worker := <-ts.idle:
+			busy--
+			if worker.runningDaemon() == nil {
+				stopped = append(stopped, worker)
+				continue
+			}
+			probed = append(probed, worker)
+		default:
+			taking = false
+		}
+	}
+	for _, worker := range stopped {
+		ts.releaseWorker(worker)
+	}
+
+	// Workers still waiting on an earlier timed out probe are not working
+	stalled := min(int(ts.stalledProbes.Load()), busy)
+	busy -= stalled
+
+	if len(probed) == 0 {
+		switch {
+		case busy > 0:
+			return nil
+		case stalled > 0:
+			return fmt.Errorf("transcription daemon unresponsive: %d daemons have not answered an earlier status request", stalled)
+		}
+		return ErrNoDaemonRunning
+	}
+
+	errs := make([]error, len(probed))
+	var wg sync.WaitGroup
+	for i, worker := range probed {
+		wg.Add(1)
+		go func(i int, worker *daemonWorker) {
+			defer wg.Done()
+			if err := ts.probeWorker(ctx, worker); err != nil {
+				errs[i] = fmt.Errorf("daemon %d: %w", worker.id, err)
+			}
+		}(i, worker)
+	}
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) == len(probed) && busy == 0 {
+		return fmt.Errorf("transcription daemon unresponsive: %w", stderrors.Join(failed...))
+	}
+	if len(failed) > 0 {
+		ts.log.Warnf("%d of %d probed transcription daemons unresponsive: %v", len(failed), len(probed), stderrors.Join(failed...))
+	}
+	return nil
+}
+
+// probeWorker sends a status request to an acquired worker's daemon and waits
+// for it to report its model loaded. The worker is released once the response
+// has been read, so a late answer is never taken for a transcription result.
+func (ts *service) probeWorker(ctx context.Context, worker *daemonWorker) error {
+	daemon := worker.runningDaemon()
+	if daemon == nil {
+		ts.releaseWorker(worker)
+		return fmt.Errorf("daemon not running")
+	}
+
+	requestJSON, err := json.Marshal(TranscriptionRequest{ID: uuid.New().String(), Action: "status"})
+	if err != nil {
+		ts.releaseWorker(worker)
+		return fmt.Errorf("failed to marshal status request: %w", err)
+	}
+
+	if _, err := daemon.stdin.Write(append(requestJSON, '\n')); err != nil {
+		ts.releaseWorker(worker)
+		return fmt.Errorf("failed to send status request: %w", err)
+	}
+
+	const (
+		probePending int32 = iota
+		probeAnswered
+		probeStalled
+	)
+	var state atomic.Int32
+
+	done := make(chan error, 1)
+	go func() {
+		defer ts.releaseWorker(worker)
+		defer func() {
+			if !state.CompareAndSwap(probePending, probeAnswered) {
+				ts.stalledProbes.Add(-1)
+			}
+		}()
+
+		for daemon.scanner.Scan() {
+			var response TranscriptionResponse
+			if err := json.Unmarshal(daemon.scanner.Bytes(), &response); err != nil {
+				continue // Skip non-JSON output (like warnings)
+			}
+			switch {
+			case !response.Success:
+				done <- fmt.Errorf("status request failed: %s", response.Error)
+			case !response.ModelLoaded:
+				done <- fmt.Errorf("model not loaded")
+			default:
+				done <- nil
+			}
+			return
+		}
+		done <- fmt.Errorf("daemon closed unexpectedly")
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if !state.CompareAndSwap(probePending, probeStalled) {
+			return <-done
+		}
+		ts.stalledProbes.Add(1)
+		return fmt.Errorf("no status response within %s", probeTimeout)
+	}
+}
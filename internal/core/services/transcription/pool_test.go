@@ -0,0 +1,149 @@
+package transcription
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+// newTestPoolService returns a transcription service with poolSize idle
+// workers and no daemon started
+func newTestPoolService(poolSize int) *service {
+	cfg := &app.Config{}
+	cfg.Transcription.Daemon.PoolSize = poolSize
+	return NewService(cfg, logger.NewWithWriter("error", io.Discard, "text")).(*service)
+}
+
+// attachFakeDaemon gives worker a running daemon whose status responses come
+// from respond; a nil respond never answers
+func attachFakeDaemon(t *testing.T, worker *daemonWorker, respond func(TranscriptionRequest) *TranscriptionResponse) {
+	t.Helper()
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	t.Cleanup(func() {
+		stdinWriter.Close()
+		stdoutWriter.Close()
+	})
+
+	go func() {
+		requests := bufio.NewScanner(stdinReader)
+		for requests.Scan() {
+			var request TranscriptionRequest
+			if err := json.Unmarshal(requests.Bytes(), &request); err != nil || respond == nil {
+				continue
+			}
+			response := respond(request)
+			if response == nil {
+				continue
+			}
+			response.ID = request.ID
+			line, _ := json.Marshal(response)
+			output := string(line) + "\n"
+			// Daemons print warnings that are not JSON, which status probes skip
+			if request.Action == "status" {
+				output = "UserWarning: FP16 not supported\n" + output
+			}
+			if _, err := io.WriteString(stdoutWriter, output); err != nil {
+				return
+			}
+		}
+	}()
+
+	worker.daemon = &WhisperDaemon{
+		stdin:   stdinWriter,
+		scanner: bufio.NewScanner(stdoutReader),
+		running: true,
+		exited:  make(chan struct{}),
+	}
+}
+
+func TestReadinessCheck(t *testing.T) {
+	loaded := func(TranscriptionRequest) *TranscriptionResponse {
+		return &TranscriptionResponse{Success: true, ModelLoaded: true, Model: "base"}
+	}
+	loading := func(TranscriptionRequest) *TranscriptionResponse {
+		return &TranscriptionResponse{Success: true}
+	}
+	failing := func(TranscriptionRequest) *TranscriptionResponse {
+		return &TranscriptionResponse{Error: "CUDA out of memory"}
+	}
+
+	tests := []struct {
+		name    string
+		daemons []func(TranscriptionRequest) *TranscriptionResponse
+		wantErr string
+	}{
+		{name: "model loaded", daemons: []func(TranscriptionRequest) *TranscriptionResponse{loaded}},
+		{name: "model not loaded", daemons: []func(TranscriptionRequest) *TranscriptionResponse{loading}, wantErr: "model not loaded"},
+		{name: "status request fails", daemons: []func(TranscriptionRequest) *TranscriptionResponse{failing}, wantErr: "CUDA out of memory"},
+		{name: "one of two daemons answers", daemons: []func(TranscriptionRequest) *TranscriptionResponse{failing, loaded}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestPoolService(len(tt.daemons))
+			for i, respond := range tt.daemons {
+				attachFakeDaemon(t, ts.workers[i], respond)
+			}
+
+			err := ts.ReadinessCheck(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ReadinessCheck() error = %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ReadinessCheck() error = %v, want %q", err, tt.wantErr)
+			}
+
+			// Probed workers are released for transcriptions
+			if idle := len(ts.idle); idle != len(tt.daemons) {
+				t.Errorf("%d idle workers after the probe, want %d", idle, len(tt.daemons))
+			}
+		})
+	}
+}
+
+func TestReadinessCheckWithoutRunningDaemon(t *testing.T) {
+	ts := newTestPoolService(2)
+	if err := ts.ReadinessCheck(context.Background()); !stderrors.Is(err, ErrNoDaemonRunning) {
+		t.Fatalf("ReadinessCheck() error = %v, want ErrNoDaemonRunning", err)
+	}
+	if idle := len(ts.idle); idle != 2 {
+		t.Errorf("%d idle workers, want 2", idle)
+	}
+}
+
+func TestReadinessCheckBusyDaemonIsHealthy(t *testing.T) {
+	ts := newTestPoolService(1)
+	attachFakeDaemon(t, ts.workers[0], nil)
+	worker := <-ts.idle // Held by a transcription
+
+	if err := ts.ReadinessCheck(context.Background()); err != nil {
+		t.Fatalf("ReadinessCheck() error = %v", err)
+	}
+	ts.releaseWorker(worker)
+}
+
+func TestReadinessCheckUnresponsiveDaemon(t *testing.T) {
+	ts := newTestPoolService(1)
+	attachFakeDaemon(t, ts.workers[0], nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := ts.ReadinessCheck(ctx); err == nil || !strings.Contains(err.Error(), "no status response") {
+		t.Fatalf("ReadinessCheck() error = %v, want a status timeout", err)
+	}
+
+	// The worker stays held by the stalled probe and is not counted as busy
+	err := ts.ReadinessCheck(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "have not answered an earlier status request") {
+		t.Fatalf("second ReadinessCheck() error = %v, want the stalled probe reported", err)
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"os/exec"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,28 +22,44 @@ import (
 
 // Service provides transcription capabilities using Whisper AI
 type Service interface {
-	TranscribeAudio(ctx context.Context, audioURL string) (*TranscriptionResult, error)
+	// TranscribeAudio transcribes the audio at audioURL in the given language;
+	// an empty language uses transcription.python.language
+	TranscribeAudio(ctx context.Context, audioURL, language string) (*TranscriptionResult, error)
 	StartDaemon() error
 	StopDaemon() error
 	HealthCheck() error
+	ReadinessCheck(ctx context.Context) error
 	Shutdown()
 }
 
 type service struct {
-	cfg    *app.Config
-	log    logger.Logger
-	daemon *WhisperDaemon
-	mutex  sync.RWMutex
+	cfg      *app.Config
+	log      logger.Logger
+	workers  []*daemonWorker
+	idle     chan *daemonWorker // workers whose daemon is free for a request
+	inflight *requestCoalescer
+	slots    chan struct{} // nil when transcription concurrency is unlimited
+	breaker  *startupBreaker
+	cache    *resultCache // nil when the transcription cache is disabled
+
+	// stalledProbes counts workers held by a status probe that timed out
+	stalledProbes atomic.Int32
 }
 
 // NewService creates a new transcription service
 func NewService(cfg *app.Config, log logger.Logger) Service {
-	return &service{
-		cfg:    cfg,
-		log:    log,
-		daemon: nil,
-		mutex:  sync.RWMutex{},
-	}
+	ts := &service{
+		cfg:      cfg,
+		log:      log,
+		inflight: newRequestCoalescer(),
+		breaker:  newStartupBreaker(cfg.Transcription.Daemon.StartupFailureThreshold, cfg.Transcription.Daemon.StartupCooldown),
+		cache:    newResultCache(cfg, log),
+	}
+	ts.workers, ts.idle = newDaemonPool(cfg.Transcription.Daemon.PoolSize)
+	if cfg.Transcription.MaxConcurrent > 0 {
+		ts.slots = make(chan struct{}, cfg.Transcription.MaxConcurrent)
+	}
+	return ts
 }
 
 type WhisperDaemon struct {
@@ -51,12 +69,11 @@ type WhisperDaemon struct {
 	stderr  io.ReadCloser
 	scanner *bufio.Scanner
 
-	cfg          *app.Config
-	log          logger.Logger
-	running      bool
-	mutex        sync.RWMutex
-	restartCount int
-	lastRestart  time.Time
+	cfg     *app.Config
+	log     logger.Logger
+	running bool
+	mutex   sync.RWMutex  // guards running
+	exited  chan struct{} // closed once the process has been waited for
 }
 
 type TranscriptionRequest struct {
@@ -96,6 +113,10 @@ type WhisperWordTimestamp struct {
 	Word  string  `json:"word"`
 	Start float64 `json:"start"`
 	End   float64 `json:"end"`
+
+	// Probability is Whisper's confidence in the word (0-1); nil when the
+	// daemon did not report one
+	Probability *float64 `json:"probability,omitempty"`
 }
 
 // TranscriptionResult represents the result of audio transcription
@@ -112,8 +133,11 @@ func newTranscriptionService(cfg *app.Config, log logger.Logger) Service {
 	return NewService(cfg, log)
 }
 
-func (ts *service) TranscribeAudio(ctx context.Context, url string) (*TranscriptionResult, error) {
-	ts.log.Debugf("Transcribing audio: %s", url)
+func (ts *service) TranscribeAudio(ctx context.Context, url, language string) (*TranscriptionResult, error) {
+	if language == "" {
+		language = ts.cfg.Transcription.Python.Language
+	}
+	ts.log.Debugf("Transcribing audio: %s (language %s)", url, language)
 
 	if !ts.cfg.Transcription.Enabled {
 		ts.log.Debug("Transcription disabled in configuration")
@@ -125,30 +149,113 @@ func (ts *service) TranscribeAudio(ctx context.Context, url string) (*Transcript
 		return nil, errors.InvalidInput("daemon mode is required but disabled")
 	}
 
-	return ts.transcribeWithDaemon(ctx, url)
+	key := ts.transcriptionKey(url, language)
+	if cached := ts.cache.get(key); cached != nil {
+		ts.log.Debugf("Reused cached transcription for: %s", url)
+		return cached, nil
+	}
+
+	// Identical concurrent requests share one daemon round-trip
+	result, shared, err := ts.inflight.do(ctx, key, func(ctx context.Context) (*TranscriptionResult, error) {
+		release, err := ts.acquireSlot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		result, err := ts.transcribeWithDaemon(ctx, url, language)
+		if err == nil {
+			ts.cache.put(key, result)
+		}
+		return result, err
+	})
+	if shared {
+		ts.log.Debugf("Reused in-flight transcription for: %s", url)
+	}
+	return result, err
 }
 
-func (ts *service) transcribeWithDaemon(ctx context.Context, url string) (*TranscriptionResult, error) {
-	// Ensure daemon is running
-	if err := ts.ensureDaemon(); err != nil {
-		return nil, fmt.Errorf("failed to start daemon: %w", err)
+// acquireSlot blocks until a transcription slot is free or the context ends
+func (ts *service) acquireSlot(ctx context.Context) (func(), error) {
+	if ts.slots == nil {
+		return func() {}, nil
 	}
 
+	select {
+	case ts.slots <- struct{}{}:
+		return func() { <-ts.slots }, nil
+	case <-ctx.Done():
+		return nil, errors.TranscriptionFailed(fmt.Errorf("waiting for transcription slot: %w", ctx.Err()))
+	}
+}
+
+// transcriptionKey identifies requests that produce the same transcription
+func (ts *service) transcriptionKey(url, language string) string {
+	return fmt.Sprintf("%s|%s|%s", ts.cfg.Transcription.Python.Model, language, url)
+}
+
+func (ts *service) transcribeWithDaemon(ctx context.Context, url, language string) (*TranscriptionResult, error) {
+	// Wait for an idle daemon of the pool
+	worker, err := ts.acquireWorker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer ts.releaseWorker(worker)
+
 	// Create request
 	request := TranscriptionRequest{
 		ID:             uuid.New().String(),
 		Action:         "transcribe",
 		URL:            url,
-		Language:       ts.cfg.Transcription.Python.Language,
+		Language:       language,
 		WordTimestamps: true,
 	}
 
-	// Send request to daemon
-	ts.daemon.mutex.Lock()
-	defer ts.daemon.mutex.Unlock()
+	// A request lost to a daemon crash is replayed against its restarted daemon
+	replayAttempts := ts.cfg.Transcription.Daemon.ReplayAttempts
+	for attempt := 1; ; attempt++ {
+		result, err := ts.roundTrip(ctx, worker, request)
+		if err == nil || !stderrors.Is(err, errDaemonExited) || attempt > replayAttempts || ctx.Err() != nil {
+			return result, err
+		}
+		ts.log.Warnf("Replaying transcription request %s on restarted Whisper daemon %d (replay %d/%d): %v",
+			request.ID, worker.id, attempt, replayAttempts, err)
+	}
+}
 
-	if !ts.daemon.running {
-		return nil, fmt.Errorf("daemon not running")
+// errDaemonExited marks requests that failed because the daemon went away
+var errDaemonExited = stderrors.New("whisper daemon exited")
+
+// roundTrip sends a request to the worker's daemon, starting it if needed,
+// and waits for the response. Failures caused by the daemon crashing wrap
+// errDaemonExited and return once the exited daemon has been reaped; a
+// daemon that was stopped on purpose is not replayed on.
+func (ts *service) roundTrip(ctx context.Context, worker *daemonWorker, request TranscriptionRequest) (*TranscriptionResult, error) {
+	// Ensure daemon is running
+	daemon, err := ts.ensureDaemon(worker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start daemon: %w", err)
+	}
+
+	exited := func(err error) error {
+		select {
+		case <-daemon.exited:
+		case <-ctx.Done():
+			return err
+		}
+
+		worker.mutex.Lock()
+		stopped := worker.daemon == nil
+		worker.mutex.Unlock()
+		if stopped {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errDaemonExited, err)
+	}
+
+	// Send request to daemon; holding the worker gives exclusive use of its pipes
+	if !daemon.isRunning() {
+		return nil, exited(fmt.Errorf("daemon not running"))
 	}
 
 	// Send JSON request
@@ -157,9 +264,9 @@ func (ts *service) transcribeWithDaemon(ctx context.Context, url string) (*Trans
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	_, err = ts.daemon.stdin.Write(append(requestJSON, '\n'))
+	_, err = daemon.stdin.Write(append(requestJSON, '\n'))
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request to daemon: %w", err)
+		return nil, exited(fmt.Errorf("failed to send request to daemon: %w", err))
 	}
 
 	// Read response with timeout
@@ -170,20 +277,24 @@ func (ts *service) transcribeWithDaemon(ctx context.Context, url string) (*Trans
 	errorChan := make(chan error, 1)
 
 	go func() {
-		if !ts.daemon.scanner.Scan() {
-			if err := ts.daemon.scanner.Err(); err != nil {
-				errorChan <- err
+		if !daemon.scanner.Scan() {
+			if err := daemon.scanner.Err(); err != nil {
+				errorChan <- exited(err)
 			} else {
-				errorChan <- fmt.Errorf("unexpected EOF from daemon")
+				errorChan <- exited(fmt.Errorf("unexpected EOF from daemon"))
 			}
 			return
 		}
 
 		var response TranscriptionResponse
-		if err := json.Unmarshal([]byte(ts.daemon.scanner.Text()), &response); err != nil {
+		if err := json.Unmarshal([]byte(daemon.scanner.Text()), &response); err != nil {
 			errorChan <- fmt.Errorf("failed to parse response: %w", err)
 			return
 		}
+		if response.ID != "" && response.ID != request.ID {
+			errorChan <- fmt.Errorf("response %s does not answer request %s", response.ID, request.ID)
+			return
+		}
 
 		responseChan <- response
 	}()
@@ -197,25 +308,68 @@ func (ts *service) transcribeWithDaemon(ctx context.Context, url string) (*Trans
 	case err := <-errorChan:
 		return nil, err
 	case <-responseCtx.Done():
+		// The reader is still waiting on the daemon's answer; the daemon goes
+		// with it so the next request on this worker never reads it
+		ts.discardDaemon(worker, daemon)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("transcription cancelled: %w", ctx.Err())
+		}
 		return nil, fmt.Errorf("transcription timeout")
 	}
 }
 
-func (ts *service) ensureDaemon() error {
-	ts.mutex.Lock()
-	defer ts.mutex.Unlock()
+// discardDaemon kills a daemon left working on an abandoned request. Its
+// late response would otherwise be read as the answer to the worker's next
+// request; the worker starts a fresh daemon when it is next used.
+func (ts *service) discardDaemon(worker *daemonWorker, daemon *WhisperDaemon) {
+	worker.mutex.Lock()
+	defer worker.mutex.Unlock()
+
+	if worker.daemon != daemon {
+		return
+	}
+	ts.log.Warnf("Discarding Whisper daemon %d after an abandoned request", worker.id)
+	worker.daemon = nil
+
+	if err := daemon.cmd.Process.Kill(); err != nil {
+		ts.log.Errorf("Failed to kill daemon process: %v", err)
+	}
+	daemon.stdin.Close()
+
+	// monitorDaemon reaps it without restarting, as it is no longer current
+	<-daemon.exited
+}
+
+// ensureDaemon returns the worker's running daemon, starting it if needed
+func (ts *service) ensureDaemon(worker *daemonWorker) (*WhisperDaemon, error) {
+	worker.mutex.Lock()
+	defer worker.mutex.Unlock()
 
 	// Check if daemon is already running
-	if ts.daemon != nil && ts.daemon.running {
-		return nil
+	if worker.daemon != nil && worker.daemon.running {
+		return worker.daemon, nil
+	}
+
+	// Fail fast while startups keep failing instead of waiting out the startup timeout
+	if err := ts.breaker.allow(); err != nil {
+		return nil, err
 	}
 
 	// Start new daemon
-	return ts.startDaemon()
+	if err := ts.startDaemon(worker); err != nil {
+		if ts.breaker.failure(err) {
+			ts.log.Errorf("Whisper daemon failed to start %d times in a row, failing transcriptions for %s: %v",
+				ts.cfg.Transcription.Daemon.StartupFailureThreshold, ts.cfg.Transcription.Daemon.StartupCooldown, err)
+		}
+		return nil, err
+	}
+	ts.breaker.success()
+	return worker.daemon, nil
 }
 
-func (ts *service) startDaemon() error {
-	ts.log.Info("Starting Whisper daemon")
+// startDaemon starts the worker's daemon; the caller holds worker.mutex
+func (ts *service) startDaemon(worker *daemonWorker) error {
+	ts.log.Infof("Starting Whisper daemon %d", worker.id)
 
 	// Build command
 	scriptPath := filepath.Join(ts.cfg.Transcription.Python.ScriptPath, "whisper_daemon.py")
@@ -264,25 +418,26 @@ func (ts *service) startDaemon() error {
 		cfg:     ts.cfg,
 		log:     ts.log,
 		running: true,
+		exited:  make(chan struct{}),
 	}
 
-	ts.daemon = daemon
+	worker.daemon = daemon
 
 	// Start monitoring goroutines
-	go ts.monitorDaemon()
-	go ts.logDaemonErrors()
+	go ts.monitorDaemon(worker, daemon)
+	go ts.logDaemonErrors(worker, daemon)
 
 	// Wait for daemon to be ready (with timeout)
-	if err := ts.waitForDaemonReady(); err != nil {
-		ts.stopDaemon()
+	if err := ts.waitForDaemonReady(daemon); err != nil {
+		ts.stopDaemonLocked(worker)
 		return fmt.Errorf("daemon startup failed: %w", err)
 	}
 
-	ts.log.Info("Whisper daemon started successfully")
+	ts.log.Infof("Whisper daemon %d started successfully", worker.id)
 	return nil
 }
 
-func (ts *service) waitForDaemonReady() error {
+func (ts *service) waitForDaemonReady(daemon *WhisperDaemon) error {
 	ctx, cancel := context.WithTimeout(context.Background(), ts.cfg.Transcription.Daemon.StartupTimeout)
 	defer cancel()
 
@@ -299,7 +454,7 @@ func (ts *service) waitForDaemonReady() error {
 		return fmt.Errorf("failed to marshal status request: %w", err)
 	}
 
-	_, err = ts.daemon.stdin.Write(append(requestJSON, '\n'))
+	_, err = daemon.stdin.Write(append(requestJSON, '\n'))
 	if err != nil {
 		return fmt.Errorf("failed to send status: %w", err)
 	}
@@ -310,8 +465,8 @@ func (ts *service) waitForDaemonReady() error {
 
 	go func() {
 		for {
-			if !ts.daemon.scanner.Scan() {
-				if err := ts.daemon.scanner.Err(); err != nil {
+			if !daemon.scanner.Scan() {
+				if err := daemon.scanner.Err(); err != nil {
 					errorChan <- err
 				} else {
 					errorChan <- fmt.Errorf("daemon closed unexpectedly")
@@ -319,7 +474,7 @@ func (ts *service) waitForDaemonReady() error {
 				return
 			}
 
-			responseText := ts.daemon.scanner.Text()
+			responseText := daemon.scanner.Text()
 			ts.log.Debugf("Daemon response: %s", responseText)
 
 			var response TranscriptionResponse
@@ -351,111 +506,128 @@ func (ts *service) waitForDaemonReady() error {
 	}
 }
 
-func (ts *service) monitorDaemon() {
-	if ts.daemon == nil {
-		return
-	}
-
+func (ts *service) monitorDaemon(worker *daemonWorker, daemon *WhisperDaemon) {
 	// Wait for process to exit
-	err := ts.daemon.cmd.Wait()
+	err := daemon.cmd.Wait()
 
-	ts.daemon.mutex.Lock()
-	ts.daemon.running = false
-	ts.daemon.mutex.Unlock()
+	daemon.mutex.Lock()
+	daemon.running = false
+	daemon.mutex.Unlock()
+	close(daemon.exited)
 
 	if err != nil {
-		ts.log.Errorf("Whisper daemon exited with error: %v", err)
+		ts.log.Errorf("Whisper daemon %d exited with error: %v", worker.id, err)
 	} else {
-		ts.log.Info("Whisper daemon exited normally")
+		ts.log.Infof("Whisper daemon %d exited normally", worker.id)
+	}
+
+	// A daemon that was stopped or failed to start is not restarted here
+	worker.mutex.Lock()
+	current := worker.daemon == daemon
+	restart := current && ts.shouldRestartDaemon(worker)
+	worker.mutex.Unlock()
+	if !current {
+		return
 	}
 
 	// Attempt restart if within limits
-	if ts.shouldRestartDaemon() {
-		ts.log.Info("Attempting to restart Whisper daemon")
+	if restart {
+		ts.log.Infof("Attempting to restart Whisper daemon %d", worker.id)
 		time.Sleep(time.Second * 5) // Brief delay before restart
-		if err := ts.ensureDaemon(); err != nil {
-			ts.log.Errorf("Failed to restart daemon: %v", err)
+		if _, err := ts.ensureDaemon(worker); err != nil {
+			ts.log.Errorf("Failed to restart daemon %d: %v", worker.id, err)
 		}
 	}
 }
 
-func (ts *service) logDaemonErrors() {
-	if ts.daemon == nil || ts.daemon.stderr == nil {
+func (ts *service) logDaemonErrors(worker *daemonWorker, daemon *WhisperDaemon) {
+	if daemon.stderr == nil {
 		return
 	}
 
-	scanner := bufio.NewScanner(ts.daemon.stderr)
+	scanner := bufio.NewScanner(daemon.stderr)
 	for scanner.Scan() {
 		line := scanner.Text()
 		if line != "" {
-			ts.log.Debugf("Daemon stderr: %s", line)
+			ts.log.Debugf("Daemon %d stderr: %s", worker.id, line)
 		}
 	}
 }
 
-func (ts *service) shouldRestartDaemon() bool {
+// shouldRestartDaemon counts a restart of the worker's daemon and reports
+// whether it is within the limit; the caller holds worker.mutex
+func (ts *service) shouldRestartDaemon(worker *daemonWorker) bool {
 	now := time.Now()
-	if now.Sub(ts.daemon.lastRestart) > time.Minute*5 {
-		ts.daemon.restartCount = 0 // Reset counter after 5 minutes
+	if now.Sub(worker.lastRestart) > time.Minute*5 {
+		worker.restartCount = 0 // Reset counter after 5 minutes
 	}
 
-	ts.daemon.restartCount++
-	ts.daemon.lastRestart = now
+	worker.restartCount++
+	worker.lastRestart = now
 
-	return ts.daemon.restartCount <= ts.cfg.Transcription.Daemon.RestartMaxAttempts
+	return worker.restartCount <= ts.cfg.Transcription.Daemon.RestartMaxAttempts
 }
 
-func (ts *service) stopDaemon() {
-	ts.mutex.Lock()
-	defer ts.mutex.Unlock()
+// stopDaemons stops the daemons of all workers
+func (ts *service) stopDaemons() {
+	var wg sync.WaitGroup
+	for _, worker := range ts.workers {
+		wg.Add(1)
+		go func(worker *daemonWorker) {
+			defer wg.Done()
+			worker.mutex.Lock()
+			defer worker.mutex.Unlock()
+			ts.stopDaemonLocked(worker)
+		}(worker)
+	}
+	wg.Wait()
+}
 
-	if ts.daemon == nil {
+// stopDaemonLocked stops the worker's daemon; the caller holds worker.mutex
+func (ts *service) stopDaemonLocked(worker *daemonWorker) {
+	daemon := worker.daemon
+	if daemon == nil {
 		return
 	}
 
-	ts.log.Info("Stopping Whisper daemon")
+	ts.log.Infof("Stopping Whisper daemon %d", worker.id)
 
 	// Send shutdown command
-	if ts.daemon.running {
+	if daemon.running {
 		shutdownRequest := TranscriptionRequest{
 			ID:     uuid.New().String(),
 			Action: "shutdown",
 		}
 		if requestJSON, err := json.Marshal(shutdownRequest); err == nil {
-			if _, writeErr := ts.daemon.stdin.Write(append(requestJSON, '\n')); writeErr != nil {
+			if _, writeErr := daemon.stdin.Write(append(requestJSON, '\n')); writeErr != nil {
 				ts.log.Errorf("Failed to write shutdown request: %v", writeErr)
 			}
 		}
 	}
 
 	// Close pipes
-	if ts.daemon.stdin != nil {
-		ts.daemon.stdin.Close()
+	if daemon.stdin != nil {
+		daemon.stdin.Close()
 	}
-	if ts.daemon.stdout != nil {
-		ts.daemon.stdout.Close()
+	if daemon.stdout != nil {
+		daemon.stdout.Close()
 	}
-	if ts.daemon.stderr != nil {
-		ts.daemon.stderr.Close()
+	if daemon.stderr != nil {
+		daemon.stderr.Close()
 	}
 
-	// Wait for process to exit (with timeout)
-	done := make(chan error, 1)
-	go func() {
-		done <- ts.daemon.cmd.Wait()
-	}()
-
+	// Wait for process to exit (with timeout); monitorDaemon reaps it
 	select {
-	case <-done:
-		ts.log.Info("Daemon stopped gracefully")
+	case <-daemon.exited:
+		ts.log.Infof("Daemon %d stopped gracefully", worker.id)
 	case <-time.After(10 * time.Second):
-		ts.log.Warn("Daemon shutdown timeout, killing process")
-		if killErr := ts.daemon.cmd.Process.Kill(); killErr != nil {
+		ts.log.Warnf("Daemon %d shutdown timeout, killing process", worker.id)
+		if killErr := daemon.cmd.Process.Kill(); killErr != nil {
 			ts.log.Errorf("Failed to kill daemon process: %v", killErr)
 		}
 	}
 
-	ts.daemon = nil
+	worker.daemon = nil
 }
 
 func (ts *service) convertToTranscriptionResult(response TranscriptionResponse) *TranscriptionResult {
@@ -469,30 +641,41 @@ func (ts *service) convertToTranscriptionResult(response TranscriptionResponse)
 }
 
 func (ts *service) Shutdown() {
-	ts.stopDaemon()
+	ts.stopDaemons()
 }
 
+// HealthCheck reports the pool healthy while at least one daemon is running.
+// It does not talk to the daemons; ReadinessCheck does.
 func (ts *service) HealthCheck() error {
-	if ts.daemon == nil {
-		return fmt.Errorf("transcription daemon not initialized")
+	running := 0
+	for _, worker := range ts.workers {
+		if worker.runningDaemon() != nil {
+			running++
+		}
 	}
 
-	ts.daemon.mutex.RLock()
-	running := ts.daemon.running
-	ts.daemon.mutex.RUnlock()
-
-	if !running {
-		return fmt.Errorf("transcription daemon not running")
+	if running == 0 {
+		return fmt.Errorf("no transcription daemon running (0 of %d)", len(ts.workers))
+	}
+	if running < len(ts.workers) {
+		ts.log.Debugf("%d of %d transcription daemons running", running, len(ts.workers))
 	}
 
 	return nil
 }
 
+// StartDaemon starts the daemons of all idle workers of the pool
 func (ts *service) StartDaemon() error {
-	return ts.ensureDaemon()
+	var errs []error
+	for _, worker := range ts.workers {
+		if _, err := ts.ensureDaemon(worker); err != nil {
+			errs = append(errs, fmt.Errorf("daemon %d: %w", worker.id, err))
+		}
+	}
+	return stderrors.Join(errs...)
 }
 
 func (ts *service) StopDaemon() error {
-	ts.stopDaemon()
+	ts.stopDaemons()
 	return nil
 }
@@ -0,0 +1,141 @@
+package transcription
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+// newTestTranscriber returns a transcription service whose poolSize daemons
+// answer through respond, limited to maxConcurrent transcriptions
+func newTestTranscriber(t *testing.T, poolSize, maxConcurrent int, respond func(TranscriptionRequest) *TranscriptionResponse) *service {
+	t.Helper()
+	cfg := &app.Config{}
+	cfg.Transcription.Enabled = true
+	cfg.Transcription.Daemon.Enabled = true
+	cfg.Transcription.Daemon.PoolSize = poolSize
+	cfg.Transcription.Processing.Timeout = 5 * time.Second
+	cfg.Transcription.MaxConcurrent = maxConcurrent
+	ts := NewService(cfg, logger.NewWithWriter("error", io.Discard, "text")).(*service)
+	for _, worker := range ts.workers {
+		attachFakeDaemon(t, worker, respond)
+	}
+	return ts
+}
+
+func TestTranscribeAudioLimitsConcurrentTranscriptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxConcurrent int
+		want          int
+	}{
+		{"one at a time", 1, 1},
+		{"unlimited", 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			active, peak := 0, 0
+			entered := make(chan struct{}, 3)
+			release := make(chan struct{})
+			ts := newTestTranscriber(t, 3, tt.maxConcurrent, func(TranscriptionRequest) *TranscriptionResponse {
+				mu.Lock()
+				active++
+				peak = max(peak, active)
+				mu.Unlock()
+				entered <- struct{}{}
+
+				<-release
+				mu.Lock()
+				active--
+				mu.Unlock()
+				return &TranscriptionResponse{Success: true, Text: "hello"}
+			})
+
+			var wg sync.WaitGroup
+			errs := make(chan error, 3)
+			for i := 0; i < 3; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, err := ts.TranscribeAudio(context.Background(), fmt.Sprintf("https://example.com/%d.mp3", i), "")
+					errs <- err
+				}(i)
+			}
+
+			for i := 0; i < tt.want; i++ {
+				<-entered
+			}
+			// Give a transcription over the limit the chance to start anyway
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				if err != nil {
+					t.Errorf("TranscribeAudio() error = %v", err)
+				}
+			}
+			if peak != tt.want {
+				t.Errorf("%d transcriptions ran at once, want %d", peak, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscribeAudioStopsWaitingForSlotOnCancel(t *testing.T) {
+	ts := newTestTranscriber(t, 2, 1, func(TranscriptionRequest) *TranscriptionResponse {
+		return &TranscriptionResponse{Success: true}
+	})
+	release, err := ts.acquireSlot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = ts.TranscribeAudio(ctx, "https://example.com/narration.mp3", "")
+	var vpe *errors.VideoProcessingError
+	if !stderrors.As(err, &vpe) || vpe.Code != errors.ErrCodeTranscriptionFailed {
+		t.Fatalf("TranscribeAudio() error = %v, want a transcription failure", err)
+	}
+}
+
+func TestTranscribeAudioSendsLanguage(t *testing.T) {
+	var mu sync.Mutex
+	var languages []string
+	ts := newTestTranscriber(t, 1, 0, func(req TranscriptionRequest) *TranscriptionResponse {
+		mu.Lock()
+		languages = append(languages, req.Language)
+		mu.Unlock()
+		return &TranscriptionResponse{Success: true, Text: "hello"}
+	})
+	ts.cfg.Transcription.Python.Language = "en"
+	ts.cfg.Transcription.Cache = app.TranscriptionCacheConfig{Enabled: true, Dir: t.TempDir()}
+	ts.cache = newResultCache(ts.cfg, ts.log)
+
+	// Repeating a URL and language reuses the cached transcription; the
+	// same URL in another language is transcribed again
+	for _, language := range []string{"", "de", "de", "en", "auto"} {
+		if _, err := ts.TranscribeAudio(context.Background(), "https://example.com/intro.mp3", language); err != nil {
+			t.Fatalf("TranscribeAudio(%q) error = %v", language, err)
+		}
+	}
+
+	want := []string{"en", "de", "auto"}
+	if !reflect.DeepEqual(languages, want) {
+		t.Errorf("daemon transcribed in %q, want %q", languages, want)
+	}
+}
@@ -1,11 +1,15 @@
 package composition
 
 import (
+	"time"
+
+	"github.com/activadee/videocraft/internal/api/models"
 	"github.com/activadee/videocraft/internal/app"
 	"github.com/activadee/videocraft/internal/core/media/audio"
 	"github.com/activadee/videocraft/internal/core/media/image"
 	"github.com/activadee/videocraft/internal/core/media/subtitle"
 	"github.com/activadee/videocraft/internal/core/media/video"
+	"github.com/activadee/videocraft/internal/core/services/health"
 	"github.com/activadee/videocraft/internal/core/services/job/queue"
 	"github.com/activadee/videocraft/internal/core/services/transcription"
 	"github.com/activadee/videocraft/internal/core/video/engine"
@@ -23,10 +27,23 @@ type Services struct {
 	Subtitle      SubtitleService
 	Storage       StorageService
 	Job           JobService
+	Health        HealthService
+
+	stopCleanup chan struct{}
+	cleanupDone chan struct{}
 }
 
 // Shutdown gracefully shuts down all services
 func (s *Services) Shutdown() {
+	if s.stopCleanup != nil {
+		// Let a running cleanup finish before the services it uses stop
+		close(s.stopCleanup)
+		<-s.cleanupDone
+		s.stopCleanup = nil
+	}
+	if s.Job != nil {
+		_ = s.Job.Stop()
+	}
 	if s.Transcription != nil {
 		s.Transcription.Shutdown()
 	}
@@ -56,6 +73,9 @@ type StorageService = storageServices.Service
 // JobService handles job management and processing
 type JobService = queue.Service
 
+// HealthService checks downstream dependencies for readiness probes
+type HealthService = health.Service
+
 // Supporting types that are specific to this package
 
 type FFmpegCommand struct {
@@ -76,10 +96,24 @@ func NewServices(cfg *app.Config, log logger.Logger) *Services {
 	// Initialize services with dependencies
 	subtitleService := subtitle.NewService(cfg, log, transcriptionService, audioService)
 
+	// Persist jobs across restarts unless job.store_dir is empty
+	var jobStore queue.JobStore
+	if cfg.Job.StoreDir != "" {
+		store, err := queue.NewFileJobStore(cfg.Job.StoreDir)
+		if err != nil {
+			log.Errorf("Failed to open job store, jobs will not survive restarts: %v", err)
+		} else {
+			jobStore = store
+		}
+	}
+
 	// Initialize job service with all dependencies including media services
-	jobService := queue.NewService(cfg, log, ffmpegService, subtitleService, storageService, audioService, videoService, imageService)
+	jobService := queue.NewService(cfg, log, jobStore, ffmpegService, subtitleService, storageService, audioService, videoService, imageService)
+	_ = jobService.Start()
 
-	return &Services{
+	healthService := health.NewService(cfg, log, transcriptionService, storageService)
+
+	services := &Services{
 		FFmpeg:        ffmpegService,
 		Audio:         audioService,
 		Video:         videoService,
@@ -88,5 +122,67 @@ func NewServices(cfg *app.Config, log logger.Logger) *Services {
 		Subtitle:      subtitleService,
 		Storage:       storageService,
 		Job:           jobService,
+		Health:        healthService,
+	}
+
+	services.startCleanupScheduler(cfg, log)
+	return services
+}
+
+// startCleanupScheduler runs storage cleanup every cleanup interval until shutdown
+func (s *Services) startCleanupScheduler(cfg *app.Config, log logger.Logger) {
+	if cfg.Storage.CleanupInterval <= 0 {
+		return
+	}
+
+	s.stopCleanup = make(chan struct{})
+	s.cleanupDone = make(chan struct{})
+	go func(storage StorageService, jobs JobService, stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(cfg.Storage.CleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				// Failures are counted and escalated by the storage service
+				removed, err := storage.CleanupOldFiles(oldestUnfinishedJob(jobs))
+				if err != nil {
+					log.Debugf("Scheduled cleanup run failed: %v", err)
+				}
+				if removed > 0 {
+					log.Infof("Scheduled cleanup removed %d files", removed)
+				}
+
+				// Job records expire with the videos they produced
+				cutoff := time.Now().AddDate(0, 0, -cfg.Storage.RetentionDays)
+				if expired := jobs.ExpireJobs(cutoff); expired > 0 {
+					log.Infof("Scheduled cleanup expired %d finished jobs", expired)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}(s.Storage, s.Job, s.stopCleanup, s.cleanupDone)
+}
+
+// oldestUnfinishedJob returns when the oldest pending or processing job was
+// created, or the zero time if every job has finished. Cleanup keeps files
+// newer than that since they may be the job's inputs or partial outputs.
+func oldestUnfinishedJob(jobs JobService) time.Time {
+	list, err := jobs.ListJobs()
+	if err != nil {
+		return time.Time{}
+	}
+
+	var oldest time.Time
+	for _, job := range list {
+		if job.Status != models.JobStatusPending && job.Status != models.JobStatusProcessing {
+			continue
+		}
+		if oldest.IsZero() || job.CreatedAt.Before(oldest) {
+			oldest = job.CreatedAt
+		}
 	}
+	return oldest
 }
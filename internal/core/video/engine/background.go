@@ -0,0 +1,194 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+const (
+	// backgroundSequenceRef labels the concatenated clips of a multi-video background
+	backgroundSequenceRef = "background"
+
+	// maxBackgroundInputs bounds the inputs a looped background sequence may add
+	maxBackgroundInputs = 256
+
+	// Frame size a sequence is normalized to when its first clip was not analyzed
+	fallbackBackgroundWidth  = 1920
+	fallbackBackgroundHeight = 1080
+)
+
+// backgroundStream is the video stream overlays start from, with the filters
+// producing it (none for a single background video)
+type backgroundStream struct {
+	ref     string
+	filters []string
+}
+
+// collectBackgroundVideos returns the project's video elements in playback order
+func collectBackgroundVideos(project models.VideoProject) []models.Element {
+	var videos []models.Element
+	for _, element := range project.Elements {
+		if element.Type == elementTypeVideo {
+			videos = append(videos, element)
+		}
+	}
+	return videos
+}
+
+// backgroundCanvasSize returns the frame size images are overlaid on, or 0x0
+// when a single background video was not analyzed. Sequences are normalized
+// to the first clip's size.
+func backgroundCanvasSize(videos []models.Element) (int, int) {
+	first := videos[0]
+	if len(videos) > 1 && (first.SourceWidth <= 0 || first.SourceHeight <= 0) {
+		return fallbackBackgroundWidth, fallbackBackgroundHeight
+	}
+	return first.SourceWidth, first.SourceHeight
+}
+
+// BackgroundLoopCount returns how often a background video of videoDuration
+// seconds is repeated after its first play to cover totalDuration, or -1
+// (loop until -t cuts the output) when its duration is unknown
+func BackgroundLoopCount(videoDuration, totalDuration float64) int {
+	if videoDuration <= 0 {
+		return -1
+	}
+	return int(totalDuration/videoDuration) + 1
+}
+
+// crossfadeLoops reports whether a single background video loops with
+// crossfaded boundaries. Videos too short to hold two fades fall back to
+// plain repeats.
+func (s *service) crossfadeLoops(project models.VideoProject, videos []models.Element) bool {
+	if project.BackgroundLoop != models.BackgroundLoopCrossfade || len(videos) != 1 {
+		return false
+	}
+	if videos[0].Duration <= 2*s.cfg.FFmpeg.BackgroundCrossfadeSeconds {
+		s.log.Warnf("Background video %s (%.2fs) is too short to crossfade; repeating it instead", videos[0].Src, videos[0].Duration)
+		return false
+	}
+	return true
+}
+
+// addBackgroundInput adds the first background video as input 0. A single
+// video loops itself over the total duration; sequences and crossfaded
+// loops are repeated by addBackgroundSequence instead.
+func addBackgroundInput(builder *commandBuilder, videos []models.Element, totalDuration float64, crossfade bool) {
+	if len(videos) > 1 || crossfade {
+		builder.addInput("-i", videos[0].Src)
+		return
+	}
+	loopsNeeded := BackgroundLoopCount(videos[0].Duration, totalDuration)
+	builder.addInput("-stream_loop", fmt.Sprintf("%d", loopsNeeded), "-i", videos[0].Src)
+}
+
+// addBackgroundSequence plays multiple background videos back to back. The
+// clips after the first are added as inputs starting at firstInput, repeated
+// as a whole until the sequence covers the total duration (-t cuts the last
+// pass). Every clip is scaled and padded to the canvas size and resampled to
+// the first clip's frame rate, as the concat filter needs uniform segments.
+func (s *service) addBackgroundSequence(builder *commandBuilder, videos []models.Element, totalDuration float64, firstInput int, crossfade bool) (backgroundStream, error) {
+	if crossfade {
+		return s.addCrossfadeLoop(builder, videos[0], totalDuration, firstInput)
+	}
+	if len(videos) == 1 {
+		return backgroundStream{ref: videoInputRef}, nil
+	}
+
+	var sequenceDuration float64
+	for _, video := range videos {
+		sequenceDuration += video.Duration
+	}
+	passes := 1
+	if sequenceDuration > 0 {
+		passes = int(totalDuration/sequenceDuration) + 1
+	}
+	if passes*len(videos) > maxBackgroundInputs {
+		return backgroundStream{}, errors.InvalidInput(fmt.Sprintf(
+			"background sequence of %.2fs would need %d passes to cover %.2fs; use longer clips",
+			sequenceDuration, passes, totalDuration))
+	}
+
+	width, height := backgroundCanvasSize(videos)
+	if videos[0].SourceWidth <= 0 || videos[0].SourceHeight <= 0 {
+		s.log.Warnf("Background frame size unknown, normalizing clips to %dx%d", width, height)
+	}
+	frameRate := videos[0].FrameRate
+	if frameRate <= 0 {
+		frameRate = defaultFrameRate
+	}
+
+	var filters, segments []string
+	nextInput := firstInput
+	for pass := 0; pass < passes; pass++ {
+		for i, video := range videos {
+			input := 0
+			if pass > 0 || i > 0 {
+				builder.addInput("-i", video.Src)
+				input = nextInput
+				nextInput++
+			}
+			segment := fmt.Sprintf("[bg_seg_%d]", len(segments))
+			filters = append(filters, fmt.Sprintf(
+				"[%d:v]scale=%[2]d:%[3]d:force_original_aspect_ratio=decrease,pad=%[2]d:%[3]d:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=%.3[4]f%[5]s",
+				input, width, height, frameRate, segment))
+			segments = append(segments, segment)
+		}
+	}
+	filters = append(filters, fmt.Sprintf("%sconcat=n=%d:v=1:a=0[%s]",
+		strings.Join(segments, ""), len(segments), backgroundSequenceRef))
+
+	s.log.Debugf("Background sequence: %d clips, %.2fs per pass, %d passes", len(videos), sequenceDuration, passes)
+	return backgroundStream{ref: backgroundSequenceRef, filters: filters}, nil
+}
+
+// addCrossfadeLoop repeats a single background video by adding it again as
+// inputs starting at firstInput and blending consecutive plays with xfade, so
+// loop boundaries fade instead of cutting. Each play after the first adds the
+// video's duration minus the fade.
+func (s *service) addCrossfadeLoop(builder *commandBuilder, video models.Element, totalDuration float64, firstInput int) (backgroundStream, error) {
+	fade := s.cfg.FFmpeg.BackgroundCrossfadeSeconds
+	step := video.Duration - fade
+
+	plays := 1
+	if totalDuration > video.Duration {
+		plays = int((totalDuration-fade)/step) + 1
+	}
+	if plays == 1 {
+		return backgroundStream{ref: videoInputRef}, nil
+	}
+	if plays > maxBackgroundInputs {
+		return backgroundStream{}, errors.InvalidInput(fmt.Sprintf(
+			"background video of %.2fs would need %d crossfaded plays to cover %.2fs; use a longer clip",
+			video.Duration, plays, totalDuration))
+	}
+
+	frameRate := video.FrameRate
+	if frameRate <= 0 {
+		frameRate = defaultFrameRate
+	}
+
+	// xfade needs matching frame rates and time bases on both sides
+	filters := []string{fmt.Sprintf("[0:v]fps=%.3f,settb=AVTB[bg_play_0]", frameRate)}
+	for play := 1; play < plays; play++ {
+		builder.addInput("-i", video.Src)
+		filters = append(filters, fmt.Sprintf("[%d:v]fps=%.3f,settb=AVTB[bg_play_%d]", firstInput+play-1, frameRate, play))
+	}
+
+	previous := "bg_play_0"
+	for play := 1; play < plays; play++ {
+		output := fmt.Sprintf("bg_xfade_%d", play)
+		if play == plays-1 {
+			output = backgroundSequenceRef
+		}
+		filters = append(filters, fmt.Sprintf("[%s][bg_play_%d]xfade=transition=fade:duration=%.3f:offset=%.3f[%s]",
+			previous, play, fade, float64(play)*step, output))
+		previous = output
+	}
+
+	s.log.Debugf("Background loop: %d crossfaded plays of %.2fs", plays, video.Duration)
+	return backgroundStream{ref: backgroundSequenceRef, filters: filters}, nil
+}
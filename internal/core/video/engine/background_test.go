@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+)
+
+// newLoopProject returns a project whose 10s of narration outlast its 4s background video
+func newLoopProject(loop string) models.VideoProject {
+	project := newTestProject()
+	project.BackgroundLoop = loop
+	project.Elements[0].Duration = 4
+	project.Elements[0].FrameRate = 25
+	project.Scenes[0].Elements[0].Duration = 10
+	return project
+}
+
+func TestBuildCommandCrossfadesBackgroundLoops(t *testing.T) {
+	s := newTestService(&app.Config{FFmpeg: app.FFmpegConfig{BackgroundCrossfadeSeconds: 1}})
+
+	cmd, err := s.BuildCommand(&models.VideoConfigArray{newLoopProject(models.BackgroundLoopCrossfade)})
+	if err != nil {
+		t.Fatalf("BuildCommand() error = %v", err)
+	}
+
+	// Plays start every 3s (4s minus the fade), so four plays cover 10s
+	wantInputs := []string{
+		"https://example.com/bg.mp4",
+		"https://example.com/intro.mp3",
+		"https://example.com/bg.mp4",
+		"https://example.com/bg.mp4",
+		"https://example.com/bg.mp4",
+	}
+	if got := inputSrcs(cmd.Args); !reflect.DeepEqual(got, wantInputs) {
+		t.Errorf("inputs = %q, want %q", got, wantInputs)
+	}
+	if got := countArgs(cmd.Args, "-stream_loop")["-stream_loop"]; got != 0 {
+		t.Errorf("crossfaded loop also uses -stream_loop: %q", cmd.Args)
+	}
+
+	want := strings.Join([]string{
+		"[0:v]fps=25.000,settb=AVTB[bg_play_0]",
+		"[2:v]fps=25.000,settb=AVTB[bg_play_1]",
+		"[3:v]fps=25.000,settb=AVTB[bg_play_2]",
+		"[4:v]fps=25.000,settb=AVTB[bg_play_3]",
+		"[bg_play_0][bg_play_1]xfade=transition=fade:duration=1.000:offset=3.000[bg_xfade_1]",
+		"[bg_xfade_1][bg_play_2]xfade=transition=fade:duration=1.000:offset=6.000[bg_xfade_2]",
+		"[bg_xfade_2][bg_play_3]xfade=transition=fade:duration=1.000:offset=9.000[background]",
+	}, ";")
+	if graph := argValue(cmd.Args, "-filter_complex"); !strings.HasPrefix(graph, want+";") {
+		t.Errorf("filter graph =\n%s\nwant prefix\n%s", graph, want)
+	}
+	if got := argValue(cmd.Args, "-map"); got != "[background]" {
+		t.Errorf("-map = %s, want [background]", got)
+	}
+}
+
+func TestBuildCommandRepeatsBackgroundWithoutCrossfade(t *testing.T) {
+	tests := []struct {
+		name   string
+		loop   string
+		fade   float64
+		length float64
+	}{
+		{"repeat", models.BackgroundLoopRepeat, 1, 4},
+		{"too short to crossfade", models.BackgroundLoopCrossfade, 2, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(&app.Config{FFmpeg: app.FFmpegConfig{BackgroundCrossfadeSeconds: tt.fade}})
+			project := newLoopProject(tt.loop)
+			project.Elements[0].Duration = tt.length
+
+			cmd, err := s.BuildCommand(&models.VideoConfigArray{project})
+			if err != nil {
+				t.Fatalf("BuildCommand() error = %v", err)
+			}
+			if got := argValue(cmd.Args, "-stream_loop"); got != "3" {
+				t.Errorf("-stream_loop = %s, want 3", got)
+			}
+			if got := len(inputSrcs(cmd.Args)); got != 2 {
+				t.Errorf("got %d inputs, want the background and narration only", got)
+			}
+			if graph := argValue(cmd.Args, "-filter_complex"); strings.Contains(graph, "xfade") {
+				t.Errorf("repeated background is crossfaded: %s", graph)
+			}
+		})
+	}
+}
+
+func TestBackgroundLoopCount(t *testing.T) {
+	tests := []struct {
+		video, total float64
+		want         int
+	}{
+		{0, 10, -1},
+		{4, 10, 3},
+		{5, 10, 3},
+		{12, 10, 1},
+	}
+
+	for _, tt := range tests {
+		if got := BackgroundLoopCount(tt.video, tt.total); got != tt.want {
+			t.Errorf("BackgroundLoopCount(%v, %v) = %d, want %d", tt.video, tt.total, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+// metadataEscaper escapes characters with special meaning in FFmetadata files
+var metadataEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"=", "\\=",
+	";", "\\;",
+	"#", "\\#",
+	"\n", "\\\n",
+)
+
+// validateChapters ensures every marker starts inside the rendered video
+func validateChapters(chapters []models.ChapterMarker, totalDuration float64) error {
+	for _, chapter := range chapters {
+		if chapter.Time >= totalDuration {
+			return errors.InvalidInput(fmt.Sprintf("chapter %q at %.2fs is beyond the video duration %.2fs",
+				chapter.Title, chapter.Time, totalDuration))
+		}
+	}
+	return nil
+}
+
+// buildChapterMetadata renders chapter markers as an FFmetadata document. Each
+// chapter ends where the next one starts; the last one ends with the video.
+func buildChapterMetadata(chapters []models.ChapterMarker, totalDuration float64) string {
+	var builder strings.Builder
+	builder.WriteString(";FFMETADATA1\n")
+
+	for i, chapter := range chapters {
+		end := totalDuration
+		if i+1 < len(chapters) {
+			end = chapters[i+1].Time
+		}
+
+		builder.WriteString("\n[CHAPTER]\n")
+		builder.WriteString("TIMEBASE=1/1000\n")
+		builder.WriteString(fmt.Sprintf("START=%d\n", int64(chapter.Time*1000)))
+		builder.WriteString(fmt.Sprintf("END=%d\n", int64(end*1000)))
+		builder.WriteString(fmt.Sprintf("title=%s\n", metadataEscaper.Replace(chapter.Title)))
+	}
+
+	return builder.String()
+}
+
+// writeChapterMetadata writes the project's chapter markers to a temporary FFmetadata file
+func (s *service) writeChapterMetadata(chapters []models.ChapterMarker, totalDuration float64) (string, error) {
+	if err := validateChapters(chapters, totalDuration); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(s.cfg.Storage.TempDir, s.cfg.Storage.DirPerm()); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	filePath := filepath.Join(s.cfg.Storage.TempDir, fmt.Sprintf("chapters_%s.txt", uuid.New().String()[:8]))
+	if err := os.WriteFile(filePath, []byte(buildChapterMetadata(chapters, totalDuration)), s.cfg.Storage.FilePerm()); err != nil {
+		return "", fmt.Errorf("failed to write chapter metadata: %w", err)
+	}
+
+	s.log.Debugf("Chapter metadata written: %s (%d chapters)", filePath, len(chapters))
+	return filePath, nil
+}
+
+// addChapterInput adds the project's chapter metadata file as an input and
+// returns its path, or "" when the project has no chapter markers
+func (s *service) addChapterInput(builder *commandBuilder, project models.VideoProject, totalDuration float64) (string, error) {
+	if len(project.Chapters) == 0 {
+		return "", nil
+	}
+
+	metadataPath, err := s.writeChapterMetadata(project.Chapters, totalDuration)
+	if err != nil {
+		return "", err
+	}
+
+	builder.addInput("-i", metadataPath)
+	return metadataPath, nil
+}
+
+// addChapterMapping copies chapters and global metadata from the metadata input
+func (s *service) addChapterMapping(builder *commandBuilder, inputIndex int) {
+	builder.addArg("-map_metadata", fmt.Sprintf("%d", inputIndex))
+	builder.addArg("-map_chapters", fmt.Sprintf("%d", inputIndex))
+}
+
+// addMetadataStripping stops FFmpeg from copying metadata of the inputs into
+// the output when the project strips metadata. With chapter markers the global
+// metadata already comes from the chapter file, which carries only chapters.
+func (s *service) addMetadataStripping(builder *commandBuilder, project models.VideoProject, hasChapters bool) {
+	strip := s.cfg.FFmpeg.StripMetadata
+	if project.StripMetadata != nil {
+		strip = *project.StripMetadata
+	}
+	if !strip {
+		return
+	}
+
+	if !hasChapters {
+		builder.addArg("-map_metadata", "-1")
+		builder.addArg("-map_chapters", "-1")
+	}
+	builder.addArg("-map_metadata:s", "-1")
+}
+
+// cleanupTempFiles removes files that only lived for the duration of an FFmpeg run
+func (s *service) cleanupTempFiles(paths []string) {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			s.log.Warnf("Failed to remove temp file %s: %v", path, err)
+		}
+	}
+}
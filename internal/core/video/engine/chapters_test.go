@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+)
+
+func TestBuildChapterMetadata(t *testing.T) {
+	chapters := []models.ChapterMarker{
+		{Time: 0, Title: "Intro"},
+		{Time: 12.5, Title: "Q&A; part=1 #live"},
+		{Time: 60.25, Title: "Outro"},
+	}
+
+	want := `;FFMETADATA1
+
+[CHAPTER]
+TIMEBASE=1/1000
+START=0
+END=12500
+title=Intro
+
+[CHAPTER]
+TIMEBASE=1/1000
+START=12500
+END=60250
+title=Q&A\; part\=1 \#live
+
+[CHAPTER]
+TIMEBASE=1/1000
+START=60250
+END=90000
+title=Outro
+`
+	if got := buildChapterMetadata(chapters, 90); got != want {
+		t.Errorf("buildChapterMetadata() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteChapterMetadataRejectsMarkerBeyondDuration(t *testing.T) {
+	cfg := &app.Config{}
+	cfg.Storage.TempDir = t.TempDir()
+	s := newTestService(cfg)
+
+	_, err := s.writeChapterMetadata([]models.ChapterMarker{{Time: 0, Title: "Intro"}, {Time: 4, Title: "Late"}}, 4)
+	if err == nil {
+		t.Fatal("writeChapterMetadata() error = nil, want a marker beyond the video")
+	}
+	if entries, _ := os.ReadDir(cfg.Storage.TempDir); len(entries) != 0 {
+		t.Errorf("rejected chapters left %d files in the temp dir", len(entries))
+	}
+}
+
+func TestGenerateVideoRemovesChapterMetadata(t *testing.T) {
+	for _, exitCode := range []int{0, 1} {
+		t.Run(fmt.Sprintf("ffmpeg exits %d", exitCode), func(t *testing.T) {
+			// The fake FFmpeg keeps a copy of the chapter input it was given
+			seen := filepath.Join(t.TempDir(), "seen.txt")
+			binary := fakeFFmpeg(t, fmt.Sprintf(`for arg in "$@"; do case "$arg" in */chapters_*) cp "$arg" %q;; esac; done; exit %d`, seen, exitCode))
+
+			cfg := &app.Config{FFmpeg: app.FFmpegConfig{BinaryPath: binary, Timeout: time.Minute}}
+			cfg.Storage.TempDir = t.TempDir()
+			cfg.Storage.OutputDir = t.TempDir()
+			project := newTestProject()
+			project.Chapters = []models.ChapterMarker{{Time: 0, Title: "Intro"}, {Time: 2, Title: "Main"}}
+
+			_, err := newTestService(cfg).GenerateVideo(context.Background(), &models.VideoConfigArray{project}, nil)
+			if (err != nil) != (exitCode != 0) {
+				t.Fatalf("GenerateVideo() error = %v", err)
+			}
+
+			content, err := os.ReadFile(seen)
+			if err != nil {
+				t.Fatalf("FFmpeg was not given the chapter metadata: %v", err)
+			}
+			if want := buildChapterMetadata(project.Chapters, 4); string(content) != want {
+				t.Errorf("chapter metadata =\n%s\nwant\n%s", content, want)
+			}
+			if entries, _ := os.ReadDir(cfg.Storage.TempDir); len(entries) != 0 {
+				t.Errorf("render left %d files in the temp dir", len(entries))
+			}
+		})
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
+	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -24,20 +26,29 @@ const (
 	elementTypeAudio     = "audio"
 	elementTypeSubtitles = "subtitles"
 	videoInputRef        = "0:v"
+	defaultFrameRate     = 30.0
+
+	// FFmpeg log lines kept for failure reports
+	ffmpegLogTailLines = 50
 )
 
 // FFmpegCommand represents a constructed FFmpeg command
 type FFmpegCommand struct {
 	Args       []string
 	OutputPath string
+	TempFiles  []string // Removed once the command has run
+	Duration   float64  // Rendered duration in seconds, used for progress
 }
 
 // Service provides FFmpeg video processing capabilities
 type Service interface {
-	GenerateVideo(ctx context.Context, config *models.VideoConfigArray, progressChan chan<- int) (string, error)
-	GenerateVideoWithSubtitles(ctx context.Context, config *models.VideoConfigArray, subtitleFilePath string, progressChan chan<- int) (string, error)
+	GenerateVideo(ctx context.Context, config *models.VideoConfigArray, progressChan chan<- models.RenderProgress) (string, error)
+	GenerateVideoWithSubtitles(ctx context.Context, config *models.VideoConfigArray, subtitleFilePath string, progressChan chan<- models.RenderProgress) (string, error)
 	BuildCommand(config *models.VideoConfigArray) (*FFmpegCommand, error)
 	Execute(ctx context.Context, cmd *FFmpegCommand) error
+	VerifyDecode(ctx context.Context, videoPath string) ([]string, error)
+	MeasureLoudness(ctx context.Context, audio models.Element) (*models.LoudnessMeasurement, error)
+	ValidateMediaURL(rawURL string) error
 }
 
 type service struct {
@@ -45,6 +56,12 @@ type service struct {
 	log logger.Logger
 }
 
+// withContextLogger returns the service logging through the job logger in
+// ctx, if there is one
+func (s *service) withContextLogger(ctx context.Context) *service {
+	return &service{cfg: s.cfg, log: logger.FromContext(ctx, s.log)}
+}
+
 // NewService creates a new FFmpeg service
 func NewService(cfg *app.Config, log logger.Logger) Service {
 	return &service{
@@ -53,7 +70,8 @@ func NewService(cfg *app.Config, log logger.Logger) Service {
 	}
 }
 
-func (s *service) GenerateVideo(ctx context.Context, config *models.VideoConfigArray, progressChan chan<- int) (string, error) {
+func (s *service) GenerateVideo(ctx context.Context, config *models.VideoConfigArray, progressChan chan<- models.RenderProgress) (string, error) {
+	s = s.withContextLogger(ctx)
 	s.log.Info("Starting video generation")
 
 	// Build basic FFmpeg command for Phase 2 - placeholder
@@ -63,34 +81,18 @@ func (s *service) GenerateVideo(ctx context.Context, config *models.VideoConfigA
 	}
 
 	s.log.Debugf("Generated FFmpeg command: %s %s", s.cfg.FFmpeg.BinaryPath, strings.Join(cmd.Args, " "))
+	defer s.cleanupTempFiles(cmd.TempFiles)
 
-	// Execute command with timeout
-	ctx, cancel := context.WithTimeout(ctx, s.cfg.FFmpeg.Timeout)
-	defer cancel()
-
-	ffmpegCmd := exec.CommandContext(ctx, s.cfg.FFmpeg.BinaryPath, cmd.Args...)
-
-	// Setup progress tracking
-	if progressChan != nil {
-		stderr, err := ffmpegCmd.StderrPipe()
-		if err != nil {
-			return "", errors.FFmpegFailed(err)
-		}
-
-		// Parse progress in goroutine
-		go s.parseProgress(stderr, progressChan)
-	}
-
-	// Execute command
-	if err := ffmpegCmd.Run(); err != nil {
-		return "", errors.FFmpegFailed(err)
+	if err := s.runFFmpeg(ctx, cmd, progressChan); err != nil {
+		return "", err
 	}
 
 	s.log.Infof("Video generation completed: %s", cmd.OutputPath)
 	return cmd.OutputPath, nil
 }
 
-func (s *service) GenerateVideoWithSubtitles(ctx context.Context, config *models.VideoConfigArray, subtitleFilePath string, progressChan chan<- int) (string, error) {
+func (s *service) GenerateVideoWithSubtitles(ctx context.Context, config *models.VideoConfigArray, subtitleFilePath string, progressChan chan<- models.RenderProgress) (string, error) {
+	s = s.withContextLogger(ctx)
 	s.log.Info("Starting video generation with subtitles")
 	s.log.Debugf("Subtitle file: %s", subtitleFilePath)
 
@@ -98,10 +100,10 @@ func (s *service) GenerateVideoWithSubtitles(ctx context.Context, config *models
 	if len(*config) == 0 {
 		return "", fmt.Errorf("no video projects provided")
 	}
-	
+
 	project := (*config)[0]
 	audioElements := s.collectAudioElements(project)
-	totalDuration := s.calculateTotalDuration(audioElements)
+	totalDuration := s.calculateTotalDuration(project, audioElements)
 
 	// Build FFmpeg command with subtitles
 	cmd, err := s.buildCommandWithSubtitleFileAndDuration(config, subtitleFilePath, totalDuration)
@@ -110,27 +112,10 @@ func (s *service) GenerateVideoWithSubtitles(ctx context.Context, config *models
 	}
 
 	s.log.Debugf("Generated FFmpeg command with subtitles: %s %s", s.cfg.FFmpeg.BinaryPath, strings.Join(cmd.Args, " "))
+	defer s.cleanupTempFiles(cmd.TempFiles)
 
-	// Execute command with timeout
-	ctx, cancel := context.WithTimeout(ctx, s.cfg.FFmpeg.Timeout)
-	defer cancel()
-
-	ffmpegCmd := exec.CommandContext(ctx, s.cfg.FFmpeg.BinaryPath, cmd.Args...)
-
-	// Setup progress tracking
-	if progressChan != nil {
-		stderr, err := ffmpegCmd.StderrPipe()
-		if err != nil {
-			return "", errors.FFmpegFailed(err)
-		}
-
-		// Parse progress in goroutine
-		go s.parseProgress(stderr, progressChan)
-	}
-
-	// Execute command
-	if err := ffmpegCmd.Run(); err != nil {
-		return "", errors.FFmpegFailed(err)
+	if err := s.runFFmpeg(ctx, cmd, progressChan); err != nil {
+		return "", err
 	}
 
 	s.log.Infof("Video generation with subtitles completed: %s", cmd.OutputPath)
@@ -150,72 +135,106 @@ func (s *service) BuildCommand(config *models.VideoConfigArray) (*FFmpegCommand,
 	// For now, process the first project in the array
 	project := (*config)[0]
 
-	builder := newCommandBuilder()
-
-	// Find background video element
-	var backgroundVideo *models.Element
-	for _, element := range project.Elements {
-		if element.Type == elementTypeVideo {
-			backgroundVideo = &element
-			break
-		}
+	// Timeline projects bypass the scene model entirely
+	if project.Timeline != nil {
+		return s.buildTimelineCommand(project)
 	}
 
-	if backgroundVideo == nil {
+	builder := newCommandBuilder(s.remoteInputArgs())
+
+	// Background video elements, played in sequence when there are several
+	backgroundVideos := collectBackgroundVideos(project)
+	if len(backgroundVideos) == 0 {
 		return nil, fmt.Errorf("no background video element found")
 	}
 
+	hw, err := s.selectHardwareEncoder(project)
+	if err != nil {
+		return nil, err
+	}
+
 	// Collect all audio elements from scenes
 	audioElements := s.collectAudioElements(project)
 
 	// Collect all image elements from scenes
-	imageElements := s.collectImageElements(project)
+	canvasWidth, canvasHeight := backgroundCanvasSize(backgroundVideos)
+	imageElements, err := s.fitImagesToCanvas(s.collectImageElements(project), canvasWidth, canvasHeight)
+	if err != nil {
+		return nil, err
+	}
 
 	// Calculate total duration
-	totalDuration := s.calculateTotalDuration(audioElements)
+	totalDuration := s.calculateTotalDuration(project, audioElements)
 
 	// Add inputs
-	builder.addInput("-y") // Overwrite output
 	builder.addInput("-protocol_whitelist", "file,http,https,tcp,tls")
 
 	// Background video with loop
-	loopsNeeded := int(totalDuration/backgroundVideo.Duration) + 1
-	builder.addInput("-stream_loop", fmt.Sprintf("%d", loopsNeeded), "-i", backgroundVideo.Src)
+	crossfade := s.crossfadeLoops(project, backgroundVideos)
+	s.addHardwareInputFlags(builder, hw)
+	addBackgroundInput(builder, backgroundVideos, totalDuration, crossfade)
 
 	// Audio inputs
+	inputs := inputIndexes{audio: builder.nextInput()}
 	for _, audio := range audioElements {
-		builder.addInput("-i", audio.Src)
+		s.addAudioInput(builder, audio)
+	}
+
+	// Image inputs - repeated sources share one input
+	inputs.image = builder.nextInput()
+	imageSources, _ := dedupeImageSources(imageElements)
+	for _, src := range imageSources {
+		builder.addInput("-i", src)
 	}
 
-	// Image inputs
-	for _, image := range imageElements {
-		builder.addInput("-i", image.Src)
+	// Chapter metadata input follows the images
+	inputs.chapters = builder.nextInput()
+	chapterPath, err := s.addChapterInput(builder, project, totalDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	// Background music follows the chapter metadata, further background clips come last
+	music := s.addBackgroundMusicInput(builder, findBackgroundMusic(project), builder.nextInput())
+	background, err := s.addBackgroundSequence(builder, backgroundVideos, totalDuration, builder.nextInput(), crossfade)
+	if err != nil {
+		if chapterPath != "" {
+			s.cleanupTempFiles([]string{chapterPath})
+		}
+		return nil, err
 	}
 
 	// Build filter complex with proper scene timing
-	sceneTiming := s.generateFallbackTiming(audioElements) // Use fallback for Phase 2
-	filterComplex := s.buildFilterComplexWithSceneTiming(project, audioElements, imageElements, sceneTiming, totalDuration)
+	sceneTiming := s.generateFallbackTiming(project, audioElements) // Use fallback for Phase 2
+	filterComplex := s.buildFilterComplexWithSceneTiming(project, background, music, inputs, audioElements, imageElements, sceneTiming, totalDuration)
+	outputVideoStream := s.getOutputVideoStream(project, background, audioElements, imageElements, "")
+	filterComplex, outputVideoStream = appendHardwareUpload(filterComplex, outputVideoStream, project, hw)
 
 	if filterComplex != "" {
 		builder.addArg("-filter_complex", filterComplex)
 	}
 
 	// Map outputs
-	if len(imageElements) > 0 {
-		builder.addArg("-map", fmt.Sprintf("[overlay_%d]", len(imageElements)-1))
-	} else {
-		builder.addArg("-map", "0:v")
-	}
+	builder.addArg("-map", outputVideoStream)
 
-	if len(audioElements) > 0 {
+	if len(audioElements) > 0 || music != nil {
 		builder.addArg("-map", "[final_audio]")
 	}
 
+	var tempFiles []string
+	if chapterPath != "" {
+		s.addChapterMapping(builder, inputs.chapters)
+		tempFiles = append(tempFiles, chapterPath)
+	}
+	s.addMetadataStripping(builder, project, chapterPath != "")
+
 	// Set duration
-	builder.addArg("-t", fmt.Sprintf("%.2f", totalDuration))
+	duration := s.outputDuration(project, totalDuration)
+	builder.addArg("-t", fmt.Sprintf("%.2f", duration))
 
 	// Output settings based on project config
-	s.addOutputSettingsForProject(builder, project)
+	s.addOutputSettingsForProject(builder, project, hw)
+	s.addFrameRateSettings(builder, backgroundVideos[0])
 
 	// Generate output path
 	outputPath := s.generateOutputPathForProject(project)
@@ -224,86 +243,249 @@ func (s *service) BuildCommand(config *models.VideoConfigArray) (*FFmpegCommand,
 	return &FFmpegCommand{
 		Args:       builder.args,
 		OutputPath: outputPath,
+		TempFiles:  tempFiles,
+		Duration:   duration,
 	}, nil
 }
 
+// runFFmpeg executes FFmpeg with the configured timeout, reporting progress
+// when progressChan is set. Progress is read from the machine-readable
+// -progress output on stdout; on failure the tail of FFmpeg's stderr log is
+// attached to the returned error.
+func (s *service) runFFmpeg(ctx context.Context, cmd *FFmpegCommand, progressChan chan<- models.RenderProgress) error {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.FFmpeg.Timeout)
+	defer cancel()
+
+	args := append([]string{"-progress", "pipe:1", "-nostats"}, cmd.Args...)
+	ffmpegCmd := exec.CommandContext(ctx, s.cfg.FFmpeg.BinaryPath, args...)
+	stdout, err := ffmpegCmd.StdoutPipe()
+	if err != nil {
+		return errors.FFmpegFailed(err)
+	}
+	stderr, err := ffmpegCmd.StderrPipe()
+	if err != nil {
+		return errors.FFmpegFailed(err)
+	}
+	// Only an output this render creates is removed after a failure
+	_, statErr := os.Stat(cmd.OutputPath)
+	createsOutput := os.IsNotExist(statErr)
+
+	if err := ffmpegCmd.Start(); err != nil {
+		return errors.FFmpegFailed(err)
+	}
+
+	// Both pipes must be fully read before Wait closes them
+	tailChan := make(chan []string, 1)
+	go func() {
+		tailChan <- s.collectLogTail(stderr)
+	}()
+	s.parseProgress(stdout, cmd.Duration, progressChan)
+	tail := <-tailChan
+
+	if err := ffmpegCmd.Wait(); err != nil {
+		// A failed or killed render leaves a partial output file behind
+		if createsOutput {
+			s.cleanupTempFiles([]string{cmd.OutputPath})
+		}
+		if ctx.Err() == context.Canceled {
+			return errors.FFmpegFailed(fmt.Errorf("render cancelled: %w", ctx.Err()))
+		}
+		return errors.FFmpegFailedWithLog(err, strings.Join(tail, "\n"))
+	}
+	return nil
+}
+
 func (s *service) Execute(ctx context.Context, cmd *FFmpegCommand) error {
 	ffmpegCmd := exec.CommandContext(ctx, s.cfg.FFmpeg.BinaryPath, cmd.Args...)
 	return ffmpegCmd.Run()
 }
 
-func (s *service) parseProgress(stderr io.ReadCloser, progressChan chan<- int) {
-	defer close(progressChan)
-	defer stderr.Close()
-
+// collectLogTail reads FFmpeg's stderr until it closes and returns the last
+// ffmpegLogTailLines lines
+func (s *service) collectLogTail(stderr io.Reader) []string {
 	scanner := bufio.NewScanner(stderr)
-	var totalDuration float64
-
-	// Regular expressions for parsing FFmpeg output
-	durationRegex := regexp.MustCompile(`Duration: (\d{2}):(\d{2}):(\d{2})\.(\d{2})`)
-	timeRegex := regexp.MustCompile(`time=(\d{2}):(\d{2}):(\d{2})\.(\d{2})`)
+	var tail []string
 
 	for scanner.Scan() {
 		line := scanner.Text()
 		s.log.Debugf("FFmpeg output: %s", line)
 
-		// Parse total duration from the beginning
-		if totalDuration == 0 {
-			if matches := durationRegex.FindStringSubmatch(line); len(matches) == 5 {
-				hours, _ := strconv.Atoi(matches[1])
-				minutes, _ := strconv.Atoi(matches[2])
-				seconds, _ := strconv.Atoi(matches[3])
-				centiseconds, _ := strconv.Atoi(matches[4])
+		tail = append(tail, line)
+		if len(tail) > ffmpegLogTailLines {
+			tail = tail[1:]
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		s.log.Errorf("Error reading FFmpeg stderr: %v", err)
+		// Keep draining so FFmpeg never blocks on a full pipe
+		_, _ = io.Copy(io.Discard, stderr)
+	}
+	return tail
+}
+
+// parseProgress reads FFmpeg's -progress key=value output until it closes,
+// sending a report to progressChan (if set) at the end of every block. The
+// percentage and ETA are relative to totalDuration; a final 100% report is
+// sent when FFmpeg reports progress=end. ffmpeg.initial_progress is reported
+// up front and serves as the floor of later percentages, and heartbeats
+// repeat the last report while FFmpeg stays silent.
+func (s *service) parseProgress(progress io.Reader, totalDuration float64, progressChan chan<- models.RenderProgress) {
+	if progressChan != nil {
+		defer close(progressChan)
+	}
+
+	// Lines are read in the background so heartbeats fire while FFmpeg is silent
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(progress)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		if err := scanner.Err(); err != nil {
+			s.log.Errorf("Error reading FFmpeg progress: %v", err)
+			_, _ = io.Copy(io.Discard, progress)
+		}
+	}()
+
+	var report models.RenderProgress
+	initialPercent := s.cfg.FFmpeg.InitialProgress
+	lastSent := time.Now()
+	if progressChan != nil && initialPercent > 0 {
+		report.Percent = initialPercent
+		progressChan <- models.RenderProgress{Percent: initialPercent, Heartbeat: true}
+	}
+
+	var heartbeat <-chan time.Time
+	if interval := s.cfg.FFmpeg.ProgressHeartbeat; progressChan != nil && interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
 
-				totalDuration = float64(hours*3600+minutes*60+seconds) + float64(centiseconds)/100
-				s.log.Debugf("Total duration parsed: %.2f seconds", totalDuration)
+	for {
+		var line string
+		select {
+		case next, ok := <-lines:
+			if !ok {
+				return
 			}
+			line = next
+		case <-heartbeat:
+			if time.Since(lastSent) < s.cfg.FFmpeg.ProgressHeartbeat {
+				continue
+			}
+			beat := report
+			beat.Heartbeat = true
+			select {
+			case progressChan <- beat:
+				s.log.Debugf("Progress heartbeat: %d%%", beat.Percent)
+			default:
+			}
+			lastSent = time.Now()
+			continue
 		}
 
-		// Parse current time progress
-		if totalDuration > 0 {
-			if matches := timeRegex.FindStringSubmatch(line); len(matches) == 5 {
-				hours, _ := strconv.Atoi(matches[1])
-				minutes, _ := strconv.Atoi(matches[2])
-				seconds, _ := strconv.Atoi(matches[3])
-				centiseconds, _ := strconv.Atoi(matches[4])
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || progressChan == nil {
+			continue
+		}
 
-				currentTime := float64(hours*3600+minutes*60+seconds) + float64(centiseconds)/100
-				progress := int((currentTime / totalDuration) * 100)
+		switch key {
+		case "frame":
+			if frame, err := strconv.ParseInt(value, 10, 64); err == nil {
+				report.Frame = frame
+			}
+		case "out_time_ms":
+			// Despite its name, FFmpeg reports out_time_ms in microseconds
+			if microseconds, err := strconv.ParseInt(value, 10, 64); err == nil && microseconds >= 0 {
+				report.CurrentTime = float64(microseconds) / 1e6
+			}
+		case "speed":
+			// Reported as e.g. "1.25x", or "N/A" before the first frame
+			report.Speed = 0
+			if speed, err := strconv.ParseFloat(strings.TrimSuffix(value, "x"), 64); err == nil && speed > 0 {
+				report.Speed = speed
+			}
+		case "progress":
+			if value == "end" {
+				report.Percent = 100
+				report.ETASeconds = 0
+				// Readers drain the channel until it closes, so this never blocks forever
+				progressChan <- report
+				s.log.Debug("Progress update: 100% (end)")
+				lastSent = time.Now()
+				continue
+			}
 
-				// Cap progress at 100%
-				if progress > 100 {
-					progress = 100
+			report.Percent, report.ETASeconds = initialPercent, 0
+			if totalDuration > 0 {
+				report.Percent = max(min(int(report.CurrentTime/totalDuration*100), 100), initialPercent)
+				if report.Speed > 0 {
+					report.ETASeconds = max(totalDuration-report.CurrentTime, 0) / report.Speed
 				}
+			}
 
-				// Send progress update
-				select {
-				case progressChan <- progress:
-					s.log.Debugf("Progress update: %d%%", progress)
-				default:
-				}
+			select {
+			case progressChan <- report:
+				s.log.Debugf("Progress update: %d%% (frame %d, %.2fx, ETA %.0fs)", report.Percent, report.Frame, report.Speed, report.ETASeconds)
+			default:
 			}
+			lastSent = time.Now()
 		}
 	}
-
-	if err := scanner.Err(); err != nil {
-		s.log.Errorf("Error reading FFmpeg stderr: %v", err)
-	}
 }
 
 // Command builder helper
 type commandBuilder struct {
 	args []string
+
+	// Number of inputs added so far, i.e. the index of the next input
+	inputs int
+
+	// Options placed before every HTTP(S) input
+	remoteArgs []string
 }
 
-func newCommandBuilder() *commandBuilder {
+// newCommandBuilder starts a command with -y. Renders are written to a fresh
+// temp path, so the output collision policy is enforced when the video is
+// stored under its final ID, not by FFmpeg. remoteArgs are added to every
+// HTTP(S) input.
+func newCommandBuilder(remoteArgs []string) *commandBuilder {
 	return &commandBuilder{
-		args: []string{"-y"}, // Overwrite output
+		args:       []string{"-y"},
+		remoteArgs: remoteArgs,
 	}
 }
 
+// remoteInputArgs returns the options of HTTP(S) inputs, so renders fetch
+// media with the same user agent and proxy as downloads and analysis
+func (s *service) remoteInputArgs() []string {
+	return append([]string{"-user_agent", s.cfg.Storage.UserAgent}, s.cfg.Storage.HTTPProxyArgs()...)
+}
+
+// isRemoteInput reports whether FFmpeg fetches an input over HTTP(S)
+func isRemoteInput(src string) bool {
+	lower := strings.ToLower(src)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
 func (cb *commandBuilder) addInput(args ...string) {
-	cb.args = append(cb.args, args...)
+	for i, arg := range args {
+		if arg == "-i" {
+			if i+1 < len(args) && isRemoteInput(args[i+1]) {
+				cb.args = append(cb.args, cb.remoteArgs...)
+			}
+			cb.inputs++
+		}
+		cb.args = append(cb.args, arg)
+	}
+}
+
+// nextInput returns the FFmpeg input index the next "-i" will get
+func (cb *commandBuilder) nextInput() int {
+	return cb.inputs
 }
 
 func (cb *commandBuilder) addArg(args ...string) {
@@ -312,13 +494,53 @@ func (cb *commandBuilder) addArg(args ...string) {
 
 // Helper functions for new scene-based architecture
 
+// addFrameRateSettings forces constant frame rate output for variable frame
+// rate backgrounds, which otherwise drift against the audio and subtitles
+func (s *service) addFrameRateSettings(builder *commandBuilder, backgroundVideo models.Element) {
+	if !backgroundVideo.VariableFrameRate {
+		return
+	}
+
+	frameRate := backgroundVideo.FrameRate
+	if frameRate <= 0 {
+		frameRate = defaultFrameRate
+	}
+	builder.addArg("-vsync", "cfr", "-r", strconv.FormatFloat(frameRate, 'f', 3, 64))
+}
+
+// clampOverlayWindow constrains an overlay window to the element's duration
+// bounds. The window always starts at the scene start; a short scene is
+// extended past its end (into the next scene) and a long one is cut off.
+func clampOverlayWindow(startTime, endTime, minDuration, maxDuration float64) float64 {
+	duration := endTime - startTime
+	if minDuration > 0 && duration < minDuration {
+		duration = minDuration
+	}
+	if maxDuration > 0 && duration > maxDuration {
+		duration = maxDuration
+	}
+	return startTime + duration
+}
+
+// addAudioInput adds an audio element as input. Video-sourced audio drops the
+// video streams at input level so only the audio track reaches the filter graph.
+func (s *service) addAudioInput(builder *commandBuilder, audio models.Element) {
+	if audio.AudioFromVideo {
+		builder.addInput("-vn", "-i", audio.Src)
+		return
+	}
+	builder.addInput("-i", audio.Src)
+}
+
 func (s *service) collectAudioElements(project models.VideoProject) []models.Element {
 	var audioElements []models.Element
 
-	// Collect from scenes in order
+	// Collect from scenes in order. Muted elements stay in the list so scene
+	// timing is unchanged; they are mixed at zero volume.
 	for _, scene := range project.Scenes {
 		for _, element := range scene.Elements {
 			if element.Type == elementTypeAudio {
+				element.Mute = project.AudioMuted(&scene, element)
 				audioElements = append(audioElements, element)
 			}
 		}
@@ -342,15 +564,39 @@ func (s *service) collectImageElements(project models.VideoProject) []models.Ele
 	return imageElements
 }
 
-func (s *service) calculateTotalDuration(audioElements []models.Element) float64 {
+// dedupeImageSources returns the distinct image sources in first-use order and,
+// for each image element, the index of the source input it reads from
+func dedupeImageSources(imageElements []models.Element) ([]string, []int) {
+	var sources []string
+	inputFor := make([]int, len(imageElements))
+	seen := make(map[string]int)
+
+	for i, image := range imageElements {
+		idx, ok := seen[image.Src]
+		if !ok {
+			idx = len(sources)
+			seen[image.Src] = idx
+			sources = append(sources, image.Src)
+		}
+		inputFor[i] = idx
+	}
+
+	return sources, inputFor
+}
+
+func (s *service) calculateTotalDuration(project models.VideoProject, audioElements []models.Element) float64 {
 	var total float64
 	for _, audio := range audioElements {
 		if audio.Duration > 0 {
 			total += audio.Duration
 		}
 	}
-	// Add 2 second buffer like in Python implementation
-	return total + 2.0
+	// Crossfaded clips overlap, each blend shortening the narration
+	for _, overlap := range s.audioCrossfades(project, audioElements) {
+		total -= overlap
+	}
+	// The tail padding is appended by apad, see addAudioConcatenationFilters
+	return total + s.cfg.Audio.TailPadding
 }
 
 func (s *service) calculateFallbackDuration(project models.VideoProject) float64 {
@@ -367,45 +613,153 @@ func (s *service) calculateFallbackDuration(project models.VideoProject) float64
 	return 30.0
 }
 
-func (s *service) buildFilterComplexWithSceneTiming(project models.VideoProject, audioElements, imageElements []models.Element, sceneTiming []models.TimingSegment, totalDuration float64) string {
-	var filters []string
+func (s *service) buildFilterComplexWithSceneTiming(project models.VideoProject, background backgroundStream, music *musicTrack, inputs inputIndexes, audioElements, imageElements []models.Element, sceneTiming []models.TimingSegment, totalDuration float64) string {
+	filters := append([]string(nil), background.filters...)
 
-	// Audio concatenation
-	s.addAudioConcatenationFilters(&filters, audioElements)
+	// Audio concatenation, mixed with any background music
+	s.addAudioFilters(&filters, audioElements, inputs.audio, music, s.audioNormalization(project, audioElements), s.audioCrossfades(project, audioElements), audioChainOutput(project, audioElements))
 
 	// Image overlays with timing based on actual audio analysis
-	currentInput := s.addImageOverlayFilters(&filters, imageElements, audioElements, sceneTiming)
+	currentInput := s.addImageOverlayFilters(&filters, project, background.ref, imageElements, inputs.image, sceneTiming)
+
+	// Waveform overlay sits above images
+	if hasWaveform(project, audioElements) {
+		s.splitAudioForWaveform(&filters)
+		currentInput = s.addWaveformOverlayFilter(&filters, project.Waveform, currentInput)
+	}
 	_ = currentInput // Prevent unused variable warning
 
 	return strings.Join(filters, ";")
 }
 
-func (s *service) addOutputSettingsForProject(builder *commandBuilder, project models.VideoProject) {
-	// Codec settings
-	builder.addArg("-c:v", "libx264")
-	builder.addArg("-c:a", "aac")
+func (s *service) addOutputSettingsForProject(builder *commandBuilder, project models.VideoProject, hw *hwEncoder) {
+	// Codec settings follow the output container
+	videoCodec := project.OutputVideoCodec()
+	builder.addArg("-c:a", project.OutputAudioCodec())
+	if project.AudioProfile != "" {
+		builder.addArg("-profile:a", project.AudioProfile)
+	}
+	if project.AudioSampleFormat != "" {
+		builder.addArg("-sample_fmt", project.AudioSampleFormat)
+	}
 
-	// Quality based on project settings
-	if project.Quality == "high" {
-		builder.addArg("-crf", "18")
-	} else {
-		builder.addArg("-crf", "23")
+	// Keyframe interval (GOP size); FFmpeg picks its own when unset
+	if gopSize := keyframeIntervalFrames(project); gopSize > 0 {
+		builder.addArg("-g", strconv.Itoa(gopSize))
 	}
 
-	// Resolution
-	if project.Width > 0 && project.Height > 0 {
+	// Optional peak bitrate cap on top of constant quality
+	if project.MaxBitrateKbps > 0 {
+		builder.addArg("-maxrate", fmt.Sprintf("%dk", project.MaxBitrateKbps))
+		builder.addArg("-bufsize", fmt.Sprintf("%dk", project.VBVBufferSize()))
+	}
+
+	// Resolution; VAAPI output is scaled before its upload to the device
+	if project.Width > 0 && project.Height > 0 && (hw == nil || hw.accel != app.HWAccelVAAPI) {
 		builder.addArg("-s", fmt.Sprintf("%dx%d", project.Width, project.Height))
 	}
 
-	// Additional settings
-	builder.addArg("-preset", "medium")
-	builder.addArg("-movflags", "+faststart")
+	if hw != nil {
+		addHardwareEncoderSettings(builder, project, hw)
+		s.addContainerFlags(builder, project)
+		return
+	}
+
+	// Quality based on project settings; VP9 uses its own CRF scale and
+	// needs a zero target bitrate for constant quality mode
+	builder.addArg("-c:v", videoCodec)
+	builder.addArg("-crf", strconv.Itoa(outputCRF(project, videoCodec)))
+	if videoCodec == models.VideoCodecVP9 {
+		builder.addArg("-b:v", "0")
+	}
+
+	// Additional settings; -preset is an x264 option
+	if videoCodec == models.VideoCodecH264 {
+		preset := "medium"
+		if project.IsPreview() {
+			preset = s.cfg.FFmpeg.PreviewPreset
+		} else if project.Preset != "" {
+			preset = project.Preset
+		}
+		builder.addArg("-preset", preset)
+	} else if project.IsPreview() {
+		// libvpx's fastest real-time mode
+		builder.addArg("-deadline", "realtime", "-cpu-used", "8")
+	}
 	builder.addArg("-pix_fmt", "yuv420p")
+	s.addContainerFlags(builder, project)
+}
+
+// outputDuration returns the rendered duration: the full duration, or the
+// preview length capped by ffmpeg.preview_max_seconds
+func (s *service) outputDuration(project models.VideoProject, totalDuration float64) float64 {
+	if !project.IsPreview() {
+		return totalDuration
+	}
+	duration := math.Min(project.PreviewSeconds, s.cfg.FFmpeg.PreviewMaxSeconds)
+	if duration < project.PreviewSeconds {
+		s.log.Infof("Preview of %.2fs capped to %.2fs", project.PreviewSeconds, duration)
+	}
+	return math.Min(duration, totalDuration)
+}
+
+// outputCRF returns the project's CRF override, or the value derived from its quality
+func outputCRF(project models.VideoProject, videoCodec string) int {
+	if project.CRF != nil {
+		return *project.CRF
+	}
+	high := project.Quality == "high"
+	switch {
+	case videoCodec == models.VideoCodecVP9 && high:
+		return 24
+	case videoCodec == models.VideoCodecVP9:
+		return 31
+	case high:
+		return 18
+	default:
+		return 23
+	}
+}
+
+// addContainerFlags adds the muxer flags of the project's output container
+func (s *service) addContainerFlags(builder *commandBuilder, project models.VideoProject) {
+	if !project.SupportsFaststart() {
+		return
+	}
+
+	faststart := s.cfg.FFmpeg.Faststart
+	if project.Faststart != nil {
+		faststart = *project.Faststart
+	}
+	if faststart {
+		builder.addArg("-movflags", "+faststart")
+	}
+}
+
+// keyframeIntervalFrames converts the project's keyframe interval to frames,
+// using the output frame rate for intervals given in seconds. Returns 0 if unset.
+func keyframeIntervalFrames(project models.VideoProject) int {
+	if project.KeyframeInterval <= 0 {
+		return 0
+	}
+	if project.KeyframeIntervalUnit == models.KeyframeUnitFrames {
+		return int(project.KeyframeInterval)
+	}
+
+	// Output runs at the background video's rate, or the CFR default for VFR sources
+	frameRate := defaultFrameRate
+	for _, element := range project.Elements {
+		if element.Type == elementTypeVideo && element.FrameRate > 0 {
+			frameRate = element.FrameRate
+			break
+		}
+	}
+
+	return max(1, int(math.Round(project.KeyframeInterval*frameRate)))
 }
 
 func (s *service) generateOutputPathForProject(project models.VideoProject) string {
-	format := "mp4" // default format
-	filename := fmt.Sprintf("video_%s.%s", uuid.New().String()[:8], format)
+	filename := fmt.Sprintf("video_%s.%s", uuid.New().String()[:8], project.OutputFormat())
 	return filepath.Join(s.cfg.Storage.OutputDir, filename)
 }
 
@@ -426,72 +780,104 @@ func (s *service) buildCommandWithSubtitleFileAndDuration(config *models.VideoCo
 	// For now, process the first project in the array
 	project := (*config)[0]
 
-	builder := newCommandBuilder()
+	builder := newCommandBuilder(s.remoteInputArgs())
 
-	// Find background video element
-	var backgroundVideo *models.Element
-	for _, element := range project.Elements {
-		if element.Type == elementTypeVideo {
-			backgroundVideo = &element
-			break
-		}
+	// Background video elements, played in sequence when there are several
+	backgroundVideos := collectBackgroundVideos(project)
+	if len(backgroundVideos) == 0 {
+		return nil, fmt.Errorf("no background video element found")
 	}
 
-	if backgroundVideo == nil {
-		return nil, fmt.Errorf("no background video element found")
+	hw, err := s.selectHardwareEncoder(project)
+	if err != nil {
+		return nil, err
 	}
 
 	// Collect all audio elements from scenes
 	audioElements := s.collectAudioElements(project)
 
 	// Collect all image elements from scenes
-	imageElements := s.collectImageElements(project)
+	canvasWidth, canvasHeight := backgroundCanvasSize(backgroundVideos)
+	imageElements, err := s.fitImagesToCanvas(s.collectImageElements(project), canvasWidth, canvasHeight)
+	if err != nil {
+		return nil, err
+	}
 
 	// Analyze audio timing for scene-based overlays using AudioService
 	sceneTiming, err := s.analyzeSceneTiming(audioElements)
 	if err != nil {
 		s.log.Warnf("Failed to analyze scene timing: %v, using fallback", err)
-		sceneTiming = s.generateFallbackTiming(audioElements)
+		sceneTiming = s.generateFallbackTiming(project, audioElements)
 	}
 
 	// Add inputs
-	builder.addInput("-y") // Overwrite output
 	builder.addInput("-protocol_whitelist", "file,http,https,tcp,tls")
 
 	// Background video with loop
-	loopsNeeded := int(totalDuration/backgroundVideo.Duration) + 1
-	builder.addInput("-stream_loop", fmt.Sprintf("%d", loopsNeeded), "-i", backgroundVideo.Src)
+	crossfade := s.crossfadeLoops(project, backgroundVideos)
+	s.addHardwareInputFlags(builder, hw)
+	addBackgroundInput(builder, backgroundVideos, totalDuration, crossfade)
 
 	// Audio inputs
+	inputs := inputIndexes{audio: builder.nextInput()}
 	for _, audio := range audioElements {
-		builder.addInput("-i", audio.Src)
+		s.addAudioInput(builder, audio)
 	}
 
-	// Image inputs
-	for _, image := range imageElements {
-		builder.addInput("-i", image.Src)
+	// Image inputs - repeated sources share one input
+	inputs.image = builder.nextInput()
+	imageSources, _ := dedupeImageSources(imageElements)
+	for _, src := range imageSources {
+		builder.addInput("-i", src)
+	}
+
+	// Chapter metadata input follows the images
+	inputs.chapters = builder.nextInput()
+	chapterPath, err := s.addChapterInput(builder, project, totalDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	// Background music follows the chapter metadata, further background clips come last
+	music := s.addBackgroundMusicInput(builder, findBackgroundMusic(project), builder.nextInput())
+	background, err := s.addBackgroundSequence(builder, backgroundVideos, totalDuration, builder.nextInput(), crossfade)
+	if err != nil {
+		if chapterPath != "" {
+			s.cleanupTempFiles([]string{chapterPath})
+		}
+		return nil, err
 	}
 
 	// Build filter complex with subtitle support and scene timing
-	filterComplex := s.buildFilterComplexWithSubtitlesAndTiming(project, audioElements, imageElements, sceneTiming, totalDuration, subtitleFilePath)
+	filterComplex := s.buildFilterComplexWithSubtitlesAndTiming(project, background, music, inputs, audioElements, imageElements, sceneTiming, totalDuration, subtitleFilePath)
+	outputVideoStream := s.getOutputVideoStream(project, background, audioElements, imageElements, subtitleFilePath)
+	filterComplex, outputVideoStream = appendHardwareUpload(filterComplex, outputVideoStream, project, hw)
 
 	if filterComplex != "" {
 		builder.addArg("-filter_complex", filterComplex)
 	}
 
 	// Map outputs
-	outputVideoStream := s.getOutputVideoStream(imageElements, subtitleFilePath)
 	builder.addArg("-map", outputVideoStream)
 
-	if len(audioElements) > 0 {
+	if len(audioElements) > 0 || music != nil {
 		builder.addArg("-map", "[final_audio]")
 	}
 
+	var tempFiles []string
+	if chapterPath != "" {
+		s.addChapterMapping(builder, inputs.chapters)
+		tempFiles = append(tempFiles, chapterPath)
+	}
+	s.addMetadataStripping(builder, project, chapterPath != "")
+
 	// Set duration
-	builder.addArg("-t", fmt.Sprintf("%.2f", totalDuration))
+	duration := s.outputDuration(project, totalDuration)
+	builder.addArg("-t", fmt.Sprintf("%.2f", duration))
 
 	// Output settings based on project config
-	s.addOutputSettingsForProject(builder, project)
+	s.addOutputSettingsForProject(builder, project, hw)
+	s.addFrameRateSettings(builder, backgroundVideos[0])
 
 	// Generate output path
 	outputPath := s.generateOutputPathForProject(project)
@@ -500,16 +886,24 @@ func (s *service) buildCommandWithSubtitleFileAndDuration(config *models.VideoCo
 	return &FFmpegCommand{
 		Args:       builder.args,
 		OutputPath: outputPath,
+		TempFiles:  tempFiles,
+		Duration:   duration,
 	}, nil
 }
 
 func (s *service) addSubtitleFilter(filters *[]string, currentVideo string, subtitleFilePath string) string {
 	s.log.Infof("Adding subtitle overlay: %s", subtitleFilePath)
 
+	// Fonts in subtitles.fonts_dir are found alongside the system fonts
+	assArgs := fmt.Sprintf("'%s'", subtitleFilePath)
+	if fontsDir := s.cfg.Subtitles.FontsDir; fontsDir != "" {
+		assArgs += fmt.Sprintf(":fontsdir='%s'", fontsDir)
+	}
+
 	if currentVideo == videoInputRef {
-		*filters = append(*filters, fmt.Sprintf("[0:v]ass='%s'[subtitled_video]", subtitleFilePath))
+		*filters = append(*filters, fmt.Sprintf("[0:v]ass=%s[subtitled_video]", assArgs))
 	} else {
-		*filters = append(*filters, fmt.Sprintf("[%s]ass='%s'[subtitled_video]", currentVideo, subtitleFilePath))
+		*filters = append(*filters, fmt.Sprintf("[%s]ass=%s[subtitled_video]", currentVideo, assArgs))
 	}
 
 	return "subtitled_video"
@@ -521,15 +915,20 @@ func (s *service) analyzeSceneTiming(audioElements []models.Element) ([]models.T
 	return nil, fmt.Errorf("audio timing analysis not yet implemented")
 }
 
-func (s *service) generateFallbackTiming(audioElements []models.Element) []models.TimingSegment {
+func (s *service) generateFallbackTiming(project models.VideoProject, audioElements []models.Element) []models.TimingSegment {
 	segments := make([]models.TimingSegment, len(audioElements))
 	currentTime := 0.0
+	crossfades := s.audioCrossfades(project, audioElements)
 
 	for i, audio := range audioElements {
 		duration := audio.Duration
 		if duration <= 0 {
 			duration = 5.0 // default fallback
 		}
+		// A crossfaded clip starts while the previous one fades out
+		if i > 0 && i-1 < len(crossfades) {
+			currentTime -= crossfades[i-1]
+		}
 
 		segments[i] = models.TimingSegment{
 			StartTime: currentTime,
@@ -542,14 +941,20 @@ func (s *service) generateFallbackTiming(audioElements []models.Element) []model
 	return segments
 }
 
-func (s *service) buildFilterComplexWithSubtitlesAndTiming(project models.VideoProject, audioElements, imageElements []models.Element, sceneTiming []models.TimingSegment, totalDuration float64, subtitleFilePath string) string {
-	var filters []string
+func (s *service) buildFilterComplexWithSubtitlesAndTiming(project models.VideoProject, background backgroundStream, music *musicTrack, inputs inputIndexes, audioElements, imageElements []models.Element, sceneTiming []models.TimingSegment, totalDuration float64, subtitleFilePath string) string {
+	filters := append([]string(nil), background.filters...)
 
-	// Audio concatenation
-	s.addAudioConcatenationFilters(&filters, audioElements)
+	// Audio concatenation, mixed with any background music
+	s.addAudioFilters(&filters, audioElements, inputs.audio, music, s.audioNormalization(project, audioElements), s.audioCrossfades(project, audioElements), audioChainOutput(project, audioElements))
 
 	// Image overlays with timing based on actual audio analysis
-	currentInput := s.addImageOverlayFilters(&filters, imageElements, audioElements, sceneTiming)
+	currentInput := s.addImageOverlayFilters(&filters, project, background.ref, imageElements, inputs.image, sceneTiming)
+
+	// Waveform overlay sits above images and below subtitles
+	if hasWaveform(project, audioElements) {
+		s.splitAudioForWaveform(&filters)
+		currentInput = s.addWaveformOverlayFilter(&filters, project.Waveform, currentInput)
+	}
 
 	// Add subtitle filter if subtitle file is provided
 	if subtitleFilePath != "" {
@@ -561,24 +966,114 @@ func (s *service) buildFilterComplexWithSubtitlesAndTiming(project models.VideoP
 	return strings.Join(filters, ";")
 }
 
-func (s *service) addAudioConcatenationFilters(filters *[]string, audioElements []models.Element) {
-	if len(audioElements) > 1 {
-		audioInputs := make([]string, len(audioElements))
-		for i := range audioElements {
-			audioInputs[i] = fmt.Sprintf("[%d:a]", i+1) // +1 because 0 is background video
+// addAudioConcatenationFilters concatenates the narration, whose inputs start
+// at firstInput, into the output label. normalization holds each element's
+// loudnorm chain from audioNormalization; nil or empty entries leave an
+// input's loudness as is. With crossfades from audioCrossfades, consecutive
+// inputs are blended over their overlap instead of cut.
+func (s *service) addAudioConcatenationFilters(filters *[]string, audioElements []models.Element, firstInput int, output string, normalization []string, crossfades []float64) {
+	// Each input is normalized and then passes through its element's volume;
+	// labels keep the concat order aligned with the audio inputs, and no
+	// inputs are added, so the image inputs after them keep their indexes
+	audioInputs := make([]string, len(audioElements))
+	for i, audio := range audioElements {
+		audioInputs[i] = fmt.Sprintf("[%d:a]", firstInput+i)
+		if i < len(normalization) && normalization[i] != "" {
+			*filters = append(*filters, fmt.Sprintf("%s%s[norm_audio_%d]", audioInputs[i], normalization[i], i))
+			audioInputs[i] = fmt.Sprintf("[norm_audio_%d]", i)
+		}
+		if volume := audioVolume(audio); volume != 1 {
+			*filters = append(*filters, fmt.Sprintf("%svolume=%s[vol_audio_%d]",
+				audioInputs[i], strconv.FormatFloat(volume, 'f', -1, 64), i))
+			audioInputs[i] = fmt.Sprintf("[vol_audio_%d]", i)
 		}
+	}
+
+	// The narration is padded by the same tail calculateTotalDuration adds,
+	// so -t ends the video where the padded audio ends
+	apad := "apad=pad_dur=" + strconv.FormatFloat(s.cfg.Audio.TailPadding, 'f', -1, 64)
+	if len(audioElements) > 1 && len(crossfades) > 0 {
+		s.addAudioCrossfadeFilters(filters, audioInputs, crossfades, "[concatenated_audio]")
+		*filters = append(*filters, fmt.Sprintf("[concatenated_audio]%s[%s]", apad, output))
+	} else if len(audioElements) > 1 {
 		audioConcat := fmt.Sprintf("%sconcat=n=%d:v=0:a=1[concatenated_audio]",
 			strings.Join(audioInputs, ""),
 			len(audioElements))
 		*filters = append(*filters, audioConcat)
-		*filters = append(*filters, "[concatenated_audio]apad=pad_dur=2[final_audio]")
+		*filters = append(*filters, fmt.Sprintf("[concatenated_audio]%s[%s]", apad, output))
 	} else if len(audioElements) == 1 {
-		*filters = append(*filters, "[1:a]apad=pad_dur=2[final_audio]")
+		*filters = append(*filters, fmt.Sprintf("%s%s[%s]", audioInputs[0], apad, output))
 	}
 }
 
-func (s *service) addImageOverlayFilters(filters *[]string, imageElements, audioElements []models.Element, sceneTiming []models.TimingSegment) string {
-	currentInput := videoInputRef
+// addAudioCrossfadeFilters joins the inputs pairwise into output, blending
+// each pair over its overlap with acrossfade. Pairs without an overlap, such
+// as clips of unknown duration, are concatenated.
+func (s *service) addAudioCrossfadeFilters(filters *[]string, audioInputs []string, crossfades []float64, output string) {
+	current := audioInputs[0]
+	for i := 1; i < len(audioInputs); i++ {
+		label := fmt.Sprintf("[xfade_audio_%d]", i)
+		if i == len(audioInputs)-1 {
+			label = output
+		}
+
+		join := "concat=n=2:v=0:a=1"
+		if i-1 < len(crossfades) && crossfades[i-1] > 0 {
+			join = "acrossfade=d=" + strconv.FormatFloat(crossfades[i-1], 'f', -1, 64)
+		}
+		*filters = append(*filters, fmt.Sprintf("%s%s%s%s", current, audioInputs[i], join, label))
+		current = label
+	}
+}
+
+// audioCrossfades returns the overlap of each pair of consecutive narration
+// clips, or nil when the project cuts between them
+func (s *service) audioCrossfades(project models.VideoProject, audioElements []models.Element) []float64 {
+	durations := make([]float64, len(audioElements))
+	for i, audio := range audioElements {
+		durations[i] = audio.Duration
+	}
+	return project.AudioCrossfades(durations, s.cfg.Audio.CrossfadeSeconds)
+}
+
+// audioVolume returns the element's volume multiplier. An unset volume
+// cannot be told apart from 0 in the JSON model, so both play at full volume;
+// muted elements are silenced instead.
+func audioVolume(audio models.Element) float64 {
+	if audio.Mute {
+		return 0
+	}
+	if audio.Volume <= 0 {
+		return 1
+	}
+	return audio.Volume
+}
+
+func (s *service) addImageOverlayFilters(filters *[]string, project models.VideoProject, videoInput string, imageElements []models.Element, firstInput int, sceneTiming []models.TimingSegment) string {
+	currentInput := videoInput
+
+	// Each distinct source is one input, split across every overlay that uses it;
+	// overlays are then scaled individually since they may differ in size
+	imageSources, inputFor := dedupeImageSources(imageElements)
+	splitLabels := make([][]string, len(imageSources))
+	for i, sourceIdx := range inputFor {
+		splitLabels[sourceIdx] = append(splitLabels[sourceIdx], fmt.Sprintf("[src_img_%d]", i))
+	}
+	for sourceIdx, labels := range splitLabels {
+		if len(labels) < 2 {
+			continue
+		}
+		*filters = append(*filters, fmt.Sprintf("[%d:v]split=%d%s",
+			firstInput+sourceIdx, len(labels), strings.Join(labels, "")))
+		s.log.Debugf("Image source %s reused by %d overlays", imageSources[sourceIdx], len(labels))
+	}
+	for i, image := range imageElements {
+		input := fmt.Sprintf("[%d:v]", firstInput+inputFor[i])
+		if len(splitLabels[inputFor[i]]) > 1 {
+			input = fmt.Sprintf("[src_img_%d]", i)
+		}
+		*filters = append(*filters, fmt.Sprintf("%s%s[scaled_img_%d]", input, imageScaleFilter(image), i))
+	}
 
 	for i, image := range imageElements {
 		// Use scene timing from audio analysis
@@ -591,19 +1086,28 @@ func (s *service) addImageOverlayFilters(filters *[]string, imageElements, audio
 			startTime = float64(i) * 5.0
 			endTime = startTime + 5.0
 		}
+		endTime = clampOverlayWindow(startTime, endTime, image.MinDuration, image.MaxDuration)
 
 		s.log.Debugf("Image %d overlay timing: %.2fs - %.2fs (duration: %.2fs)",
 			i, startTime, endTime, endTime-startTime)
 
-		// Scale image - use correct input index for images with :v selector
-		imageInputIndex := len(audioElements) + 1 + i
-		scaleFilter := fmt.Sprintf("[%d:v]scale=500:500[scaled_img_%d]",
-			imageInputIndex, i)
-		*filters = append(*filters, scaleFilter)
+		// Conditional images are enabled across every audio-bearing scene instead of their own slot
+		enableExpr := fmt.Sprintf("between(t\\,%f\\,%f)", startTime, endTime)
+		if image.ShowWhen == models.ShowWhenAudio {
+			enableExpr = s.buildAudioEnableExpression(project, sceneTiming)
+			s.log.Debugf("Image %d shown only during audio scenes: %s", i, enableExpr)
+		}
+
+		// Border and rounded corners are applied per overlay, after any split
+		imageLabel := fmt.Sprintf("scaled_img_%d", i)
+		if style := imageStyleFilter(image); style != "" {
+			*filters = append(*filters, fmt.Sprintf("[%s]%s[styled_img_%d]", imageLabel, style, i))
+			imageLabel = fmt.Sprintf("styled_img_%d", i)
+		}
 
 		// Overlay with timing based on actual audio duration
-		overlayFilter := fmt.Sprintf("[%s][scaled_img_%d]overlay=%d:%d:enable='between(t\\,%f\\,%f)'[overlay_%d]",
-			currentInput, i, image.X, image.Y, startTime, endTime, i)
+		overlayFilter := fmt.Sprintf("[%s][%s]overlay=%d:%d:enable='%s'[overlay_%d]",
+			currentInput, imageLabel, image.X, image.Y, enableExpr, i)
 		*filters = append(*filters, overlayFilter)
 
 		currentInput = fmt.Sprintf("overlay_%d", i)
@@ -612,11 +1116,152 @@ func (s *service) addImageOverlayFilters(filters *[]string, imageElements, audio
 	return currentInput
 }
 
-func (s *service) getOutputVideoStream(imageElements []models.Element, subtitleFilePath string) string {
+// inputIndexes are the FFmpeg input indexes of the first narration audio,
+// first image and chapter metadata inputs, taken from the command builder as
+// the inputs are added so the filters follow the actual input order
+type inputIndexes struct {
+	audio    int
+	image    int
+	chapters int
+}
+
+// imageScaleFilter returns the filter sizing an image overlay. Images keep
+// their native size unless the element sets a width and/or height.
+func imageScaleFilter(image models.Element) string {
+	w, h := image.Width, image.Height
+	switch {
+	case w == 0 && h == 0:
+		return "scale=iw:ih"
+	case w == 0:
+		return fmt.Sprintf("scale=-2:%d", h)
+	case h == 0:
+		return fmt.Sprintf("scale=%d:-2", w)
+	}
+
+	switch image.ScaleMode {
+	case models.ScaleModeStretch:
+		return fmt.Sprintf("scale=%d:%d", w, h)
+	case models.ScaleModeFill:
+		return fmt.Sprintf("scale=%[1]d:%[2]d:force_original_aspect_ratio=increase,crop=%[1]d:%[2]d", w, h)
+	default:
+		// Fit letterboxes into the box with transparent padding
+		return fmt.Sprintf("scale=%[1]d:%[2]d:force_original_aspect_ratio=decrease,format=rgba,"+
+			"pad=%[1]d:%[2]d:(ow-iw)/2:(oh-ih)/2:color=0x00000000", w, h)
+	}
+}
+
+// fitImagesToCanvas applies ffmpeg.oversized_image_policy to image overlays
+// whose explicit width or height exceeds the background video frame. Fitting
+// shrinks the requested box proportionally; unknown frame sizes are skipped.
+func (s *service) fitImagesToCanvas(images []models.Element, canvasWidth, canvasHeight int) ([]models.Element, error) {
+	policy := s.cfg.FFmpeg.OversizedImagePolicy
+	if policy == app.OversizedImageClip || canvasWidth <= 0 || canvasHeight <= 0 {
+		return images, nil
+	}
+
+	fitted := make([]models.Element, len(images))
+	for i, image := range images {
+		fitted[i] = image
+		if image.Width <= canvasWidth && image.Height <= canvasHeight {
+			continue
+		}
+
+		if policy == app.OversizedImageReject {
+			return nil, errors.InvalidInput(fmt.Sprintf("image %d sized %dx%d exceeds the %dx%d video frame",
+				i, image.Width, image.Height, canvasWidth, canvasHeight))
+		}
+
+		factor := 1.0
+		if image.Width > canvasWidth {
+			factor = float64(canvasWidth) / float64(image.Width)
+		}
+		if image.Height > canvasHeight {
+			factor = math.Min(factor, float64(canvasHeight)/float64(image.Height))
+		}
+		fitted[i].Width = scaleDimension(image.Width, factor)
+		fitted[i].Height = scaleDimension(image.Height, factor)
+		s.log.Infof("Image %s resized from %dx%d to %dx%d to fit the %dx%d video frame",
+			image.Src, image.Width, image.Height, fitted[i].Width, fitted[i].Height, canvasWidth, canvasHeight)
+	}
+	return fitted, nil
+}
+
+// scaleDimension scales a requested overlay dimension, keeping unset (0) ones unset
+func scaleDimension(size int, factor float64) int {
+	if size == 0 {
+		return 0
+	}
+	return max(1, int(float64(size)*factor))
+}
+
+// imageStyleFilter returns the filter chain adding the element's border and
+// rounded corners to a scaled image, or "" when the image is unstyled.
+// The border pads the image; rounding then masks the alpha channel outside
+// quarter circles of the radius in each corner of the bordered image.
+func imageStyleFilter(image models.Element) string {
+	var chain []string
+
+	if image.BorderWidth > 0 {
+		color := image.BorderColor
+		if color == "" {
+			color = "#FFFFFF"
+		}
+		chain = append(chain, fmt.Sprintf("pad=w=iw+%[1]d:h=ih+%[1]d:x=%[2]d:y=%[2]d:color=0x%[3]s",
+			2*image.BorderWidth, image.BorderWidth, strings.TrimPrefix(color, "#")))
+	}
+
+	if image.CornerRadius > 0 {
+		// dx/dy are the distances past the inner rectangle inset by the radius;
+		// pixels in a corner square beyond the radius become transparent. The
+		// radius is clamped to half the image since its size is only known to FFmpeg.
+		r := fmt.Sprintf("min(%d,min(W,H)/2)", image.CornerRadius)
+		dx := fmt.Sprintf("max(abs(X-W/2)-(W/2-%s),0)", r)
+		dy := fmt.Sprintf("max(abs(Y-H/2)-(H/2-%s),0)", r)
+		chain = append(chain, "format=rgba",
+			fmt.Sprintf("geq=r='r(X,Y)':g='g(X,Y)':b='b(X,Y)':a='if(gt(hypot(%s,%s),%s),0,alpha(X,Y))'", dx, dy, r))
+	}
+
+	return strings.Join(chain, ",")
+}
+
+// buildAudioEnableExpression builds an overlay enable expression that is true only
+// while a scene's audio plays. The project's scenes are walked in order, matching
+// their audio elements to sceneTiming, which holds one segment per audio element;
+// silent scenes contribute no window. Muted and soloed-out elements keep their
+// segment but are silent, so they contribute none either.
+func (s *service) buildAudioEnableExpression(project models.VideoProject, sceneTiming []models.TimingSegment) string {
+	var windows []string
+	segmentIdx := 0
+	for _, scene := range project.Scenes {
+		for _, element := range scene.Elements {
+			if element.Type != elementTypeAudio || segmentIdx >= len(sceneTiming) {
+				continue
+			}
+			segment := sceneTiming[segmentIdx]
+			segmentIdx++
+			if project.AudioMuted(&scene, element) || segment.EndTime <= segment.StartTime {
+				continue
+			}
+			windows = append(windows, fmt.Sprintf("between(t\\,%f\\,%f)", segment.StartTime, segment.EndTime))
+		}
+	}
+
+	if len(windows) == 0 {
+		return "0" // No audio anywhere - never show the overlay
+	}
+
+	return strings.Join(windows, "+")
+}
+
+func (s *service) getOutputVideoStream(project models.VideoProject, background backgroundStream, audioElements, imageElements []models.Element, subtitleFilePath string) string {
 	if subtitleFilePath != "" {
 		return "[subtitled_video]"
+	} else if project.Waveform != nil && len(audioElements) > 0 {
+		return fmt.Sprintf("[%s]", waveformOverlayRef)
 	} else if len(imageElements) > 0 {
 		return fmt.Sprintf("[overlay_%d]", len(imageElements)-1)
+	} else if background.ref != videoInputRef {
+		return fmt.Sprintf("[%s]", background.ref)
 	} else {
 		return videoInputRef
 	}
@@ -0,0 +1,860 @@
+package engine
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+func TestBuildAudioEnableExpressionSkipsSilentScenes(t *testing.T) {
+	s := newTestService(&app.Config{})
+	project := models.VideoProject{
+		Scenes: []models.Scene{
+			{ID: "intro", Elements: []models.Element{{Type: "audio", Src: "intro.mp3", Duration: 4}}},
+			{ID: "title", Elements: []models.Element{{Type: "image", Src: "title.png"}}},
+			{ID: "body", Elements: []models.Element{
+				{Type: "audio", Src: "body-1.mp3", Duration: 3},
+				{Type: "audio", Src: "body-2.mp3", Duration: 2},
+			}},
+			{ID: "outro"},
+		},
+	}
+	sceneTiming := s.generateFallbackTiming(project, s.collectAudioElements(project))
+
+	got := s.buildAudioEnableExpression(project, sceneTiming)
+	want := `between(t\,0.000000\,4.000000)+between(t\,4.000000\,7.000000)+between(t\,7.000000\,9.000000)`
+	if got != want {
+		t.Errorf("buildAudioEnableExpression() = %s, want %s", got, want)
+	}
+}
+
+func TestBuildAudioEnableExpressionWithoutAudio(t *testing.T) {
+	s := newTestService(&app.Config{})
+	project := models.VideoProject{
+		Scenes: []models.Scene{{ID: "title", Elements: []models.Element{{Type: "image", Src: "title.png"}}}},
+	}
+
+	if got := s.buildAudioEnableExpression(project, nil); got != "0" {
+		t.Errorf("buildAudioEnableExpression() = %s, want 0", got)
+	}
+}
+
+func TestBuildAudioEnableExpressionSkipsMutedAudio(t *testing.T) {
+	s := newTestService(&app.Config{})
+	project := models.VideoProject{
+		Scenes: []models.Scene{
+			{ID: "one", Elements: []models.Element{{Type: "audio", Src: "one.mp3", Duration: 2}}},
+			{ID: "two", Mute: true, Elements: []models.Element{{Type: "audio", Src: "two.mp3", Duration: 3}}},
+			{ID: "three", Elements: []models.Element{{Type: "audio", Src: "three.mp3", Duration: 1, Mute: true}}},
+			{ID: "four", Elements: []models.Element{{Type: "audio", Src: "four.mp3", Duration: 2}}},
+		},
+	}
+	sceneTiming := s.generateFallbackTiming(project, s.collectAudioElements(project))
+
+	got := s.buildAudioEnableExpression(project, sceneTiming)
+	want := `between(t\,0.000000\,2.000000)+between(t\,6.000000\,8.000000)`
+	if got != want {
+		t.Errorf("buildAudioEnableExpression() = %s, want %s", got, want)
+	}
+
+	// Once a scene is soloed, every other scene is silent
+	project.Scenes[3].Solo = true
+	got = s.buildAudioEnableExpression(project, sceneTiming)
+	want = `between(t\,6.000000\,8.000000)`
+	if got != want {
+		t.Errorf("with solo: buildAudioEnableExpression() = %s, want %s", got, want)
+	}
+}
+
+func TestCommandBuilderRemoteInputArgs(t *testing.T) {
+	builder := newCommandBuilder([]string{"-user_agent", "VideoCraft/test"})
+	builder.addInput("-i", "/tmp/local.mp3")
+	builder.addInput("-stream_loop", "2", "-i", "HTTPS://cdn.example.com/bg.mp4")
+	builder.addInput("-vn", "-i", "http://cdn.example.com/voice.mp3")
+
+	want := []string{
+		"-y",
+		"-i", "/tmp/local.mp3",
+		"-stream_loop", "2", "-user_agent", "VideoCraft/test", "-i", "HTTPS://cdn.example.com/bg.mp4",
+		"-vn", "-user_agent", "VideoCraft/test", "-i", "http://cdn.example.com/voice.mp3",
+	}
+	if !reflect.DeepEqual(builder.args, want) {
+		t.Errorf("args = %q, want %q", builder.args, want)
+	}
+	if builder.nextInput() != 3 {
+		t.Errorf("nextInput() = %d, want 3", builder.nextInput())
+	}
+}
+
+func TestBuildCommandSendsUserAgentWithRemoteInputs(t *testing.T) {
+	cfg := &app.Config{}
+	cfg.Storage.UserAgent = "VideoCraft/1.0 (+https://example.com/bot)"
+	cmd, err := newTestService(cfg).BuildCommand(&models.VideoConfigArray{newTestProject()})
+	if err != nil {
+		t.Fatalf("BuildCommand() error = %v", err)
+	}
+
+	inputs := 0
+	for i, arg := range cmd.Args {
+		if arg != "-i" || !isRemoteInput(cmd.Args[i+1]) {
+			continue
+		}
+		inputs++
+		if i < 2 || cmd.Args[i-2] != "-user_agent" || cmd.Args[i-1] != cfg.Storage.UserAgent {
+			t.Errorf("input %s is not preceded by -user_agent %q: %q", cmd.Args[i+1], cfg.Storage.UserAgent, cmd.Args)
+		}
+	}
+	if inputs != 2 {
+		t.Errorf("found %d remote inputs, want 2", inputs)
+	}
+}
+
+// newTestProject returns a scene project with a background video and one
+// narration clip that BuildCommand accepts
+func newTestProject() models.VideoProject {
+	return models.VideoProject{
+		Width:    1280,
+		Height:   720,
+		Elements: []models.Element{{Type: "video", Src: "https://example.com/bg.mp4", Duration: 30}},
+		Scenes: []models.Scene{
+			{ID: "intro", Elements: []models.Element{{Type: "audio", Src: "https://example.com/intro.mp3", Duration: 4}}},
+		},
+	}
+}
+
+// countArgs counts the occurrences of each flag in args
+func countArgs(args []string, flags ...string) map[string]int {
+	counts := make(map[string]int, len(flags))
+	for _, arg := range args {
+		for _, flag := range flags {
+			if arg == flag {
+				counts[flag]++
+			}
+		}
+	}
+	return counts
+}
+
+func TestCommandsOverwriteTheirTempOutput(t *testing.T) {
+	timeline := models.VideoProject{Timeline: &models.Timeline{Tracks: []models.TimelineTrack{
+		{Type: models.TrackTypeVideo, Clips: []models.TimelineClip{{Src: "https://example.com/bg.mp4", Duration: 10}}},
+	}}}
+
+	for _, policy := range []string{app.CollisionPolicyOverwrite, app.CollisionPolicyReject, app.CollisionPolicyVersion} {
+		s := newTestService(&app.Config{Storage: app.StorageConfig{OutputCollisionPolicy: policy}})
+		builds := map[string]func() (*FFmpegCommand, error){
+			"scenes": func() (*FFmpegCommand, error) {
+				return s.BuildCommand(&models.VideoConfigArray{newTestProject()})
+			},
+			"subtitles": func() (*FFmpegCommand, error) {
+				return s.buildCommandWithSubtitleFileAndDuration(&models.VideoConfigArray{newTestProject()}, "/tmp/subtitles.ass", 4)
+			},
+			"timeline": func() (*FFmpegCommand, error) {
+				return s.BuildCommand(&models.VideoConfigArray{timeline})
+			},
+		}
+		for name, build := range builds {
+			t.Run(policy+"/"+name, func(t *testing.T) {
+				cmd, err := build()
+				if err != nil {
+					t.Fatalf("build error = %v", err)
+				}
+				counts := countArgs(cmd.Args, "-y", "-n")
+				if counts["-y"] != 1 || counts["-n"] != 0 {
+					t.Errorf("args have %d -y and %d -n, want exactly one -y: %q", counts["-y"], counts["-n"], cmd.Args)
+				}
+			})
+		}
+	}
+}
+
+// fakeFFmpeg writes an executable script standing in for FFmpeg
+func fakeFFmpeg(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunFFmpegCancelKillsRenderAndRemovesItsOutput(t *testing.T) {
+	// The fake writes its output, the last argument, then hangs like a long render
+	binary := fakeFFmpeg(t, `for arg; do out="$arg"; done; echo partial > "$out"; exec sleep 30`)
+	s := newTestService(&app.Config{FFmpeg: app.FFmpegConfig{BinaryPath: binary, Timeout: time.Minute}})
+	output := filepath.Join(t.TempDir(), "video_test.mp4")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.runFFmpeg(ctx, &FFmpegCommand{Args: []string{"-y", output}, OutputPath: output}, nil)
+	}()
+
+	// Cancel once the render has written its partial output
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(output); err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "render cancelled") {
+			t.Errorf("runFFmpeg() error = %v, want a cancelled render", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runFFmpeg() did not return after cancellation; FFmpeg was not killed")
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("partial output of the cancelled render was kept: %v", err)
+	}
+}
+
+func TestRunFFmpegFailureKeepsFileItDidNotCreate(t *testing.T) {
+	binary := fakeFFmpeg(t, `echo "File exists" >&2; exit 1`)
+	s := newTestService(&app.Config{FFmpeg: app.FFmpegConfig{BinaryPath: binary, Timeout: time.Minute}})
+	output := filepath.Join(t.TempDir(), "video_test.mp4")
+	if err := os.WriteFile(output, []byte("another job's render"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.runFFmpeg(context.Background(), &FFmpegCommand{Args: []string{"-y", output}, OutputPath: output}, nil); err == nil {
+		t.Fatal("runFFmpeg() error = nil, want the failed render")
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("file that existed before the render was removed: %v", err)
+	}
+}
+
+func TestImageScaleFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		image models.Element
+		want  string
+	}{
+		{"native size", models.Element{}, "scale=iw:ih"},
+		{"height only", models.Element{Height: 200}, "scale=-2:200"},
+		{"width only", models.Element{Width: 300}, "scale=300:-2"},
+		{"stretch", models.Element{Width: 300, Height: 200, ScaleMode: models.ScaleModeStretch}, "scale=300:200"},
+		{"fill", models.Element{Width: 300, Height: 200, ScaleMode: models.ScaleModeFill},
+			"scale=300:200:force_original_aspect_ratio=increase,crop=300:200"},
+		{"fit by default", models.Element{Width: 300, Height: 200},
+			"scale=300:200:force_original_aspect_ratio=decrease,format=rgba,pad=300:200:(ow-iw)/2:(oh-ih)/2:color=0x00000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := imageScaleFilter(tt.image); got != tt.want {
+				t.Errorf("imageScaleFilter() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFitImagesToCanvas(t *testing.T) {
+	images := []models.Element{
+		{Type: "image", Src: "small.png", Width: 640, Height: 360},
+		{Type: "image", Src: "wide.png", Width: 2560, Height: 720},
+		{Type: "image", Src: "tall.png", Height: 1440},
+	}
+
+	tests := []struct {
+		name          string
+		policy        string
+		width, height int
+		want          [][2]int
+	}{
+		{"fit shrinks oversized boxes", app.OversizedImageFit, 1280, 720, [][2]int{{640, 360}, {1280, 360}, {0, 720}}},
+		{"clip keeps requested sizes", app.OversizedImageClip, 1280, 720, [][2]int{{640, 360}, {2560, 720}, {0, 1440}}},
+		{"unknown frame size is skipped", app.OversizedImageReject, 0, 0, [][2]int{{640, 360}, {2560, 720}, {0, 1440}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(&app.Config{FFmpeg: app.FFmpegConfig{OversizedImagePolicy: tt.policy}})
+
+			fitted, err := s.fitImagesToCanvas(images, tt.width, tt.height)
+			if err != nil {
+				t.Fatalf("fitImagesToCanvas() error = %v", err)
+			}
+			for i, image := range fitted {
+				if got := [2]int{image.Width, image.Height}; got != tt.want[i] {
+					t.Errorf("%s sized %v, want %v", image.Src, got, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if images[1].Width != 2560 {
+		t.Errorf("fitImagesToCanvas modified its input: %+v", images[1])
+	}
+}
+
+func TestFitImagesToCanvasRejectsOversizedImage(t *testing.T) {
+	s := newTestService(&app.Config{FFmpeg: app.FFmpegConfig{OversizedImagePolicy: app.OversizedImageReject}})
+	images := []models.Element{
+		{Type: "image", Src: "small.png", Width: 1280, Height: 720},
+		{Type: "image", Src: "tall.png", Height: 721},
+	}
+
+	_, err := s.fitImagesToCanvas(images, 1280, 720)
+
+	var vpe *errors.VideoProcessingError
+	if !stderrors.As(err, &vpe) || vpe.Code != errors.ErrCodeInvalidInput {
+		t.Fatalf("fitImagesToCanvas() error = %v, want invalid input", err)
+	}
+	if !strings.Contains(vpe.Message, "image 1 sized 0x721") {
+		t.Errorf("error should name the oversized image: %s", vpe.Message)
+	}
+}
+
+// flagValues returns the value following every occurrence of flag in args
+func flagValues(args []string, flag string) []string {
+	var values []string
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == flag {
+			values = append(values, args[i+1])
+		}
+	}
+	return values
+}
+
+func TestBuildCommandStripsMetadata(t *testing.T) {
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name     string
+		config   bool
+		project  *bool
+		chapters []models.ChapterMarker
+		want     map[string][]string
+	}{
+		{
+			name:   "enabled in config",
+			config: true,
+			want:   map[string][]string{"-map_metadata": {"-1"}, "-map_chapters": {"-1"}, "-map_metadata:s": {"-1"}},
+		},
+		{
+			name:    "enabled by the project",
+			project: &enabled,
+			want:    map[string][]string{"-map_metadata": {"-1"}, "-map_chapters": {"-1"}, "-map_metadata:s": {"-1"}},
+		},
+		{
+			name:    "disabled by the project",
+			config:  true,
+			project: &disabled,
+			want:    map[string][]string{},
+		},
+		{
+			name: "disabled",
+			want: map[string][]string{},
+		},
+		{
+			name:     "chapters keep their global metadata",
+			config:   true,
+			chapters: []models.ChapterMarker{{Time: 0, Title: "Intro"}},
+			want:     map[string][]string{"-map_metadata": {"2"}, "-map_chapters": {"2"}, "-map_metadata:s": {"-1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &app.Config{}
+			cfg.FFmpeg.StripMetadata = tt.config
+			cfg.Storage.TempDir = t.TempDir()
+			project := newTestProject()
+			project.StripMetadata = tt.project
+			project.Chapters = tt.chapters
+
+			cmd, err := newTestService(cfg).BuildCommand(&models.VideoConfigArray{project})
+			if err != nil {
+				t.Fatalf("BuildCommand() error = %v", err)
+			}
+
+			for _, flag := range []string{"-map_metadata", "-map_chapters", "-map_metadata:s"} {
+				if got := flagValues(cmd.Args, flag); !reflect.DeepEqual(got, tt.want[flag]) {
+					t.Errorf("%s = %q, want %q", flag, got, tt.want[flag])
+				}
+			}
+		})
+	}
+}
+
+func TestBuildCommandSetsKeyframeInterval(t *testing.T) {
+	tests := []struct {
+		name      string
+		interval  float64
+		unit      string
+		frameRate float64
+		want      []string
+	}{
+		{"unset", 0, "", 0, nil},
+		{"seconds at the default rate", 2, "", 0, []string{"60"}},
+		{"seconds at the source rate", 0.5, models.KeyframeUnitSeconds, 25, []string{"13"}},
+		{"frames", 48, models.KeyframeUnitFrames, 25, []string{"48"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := newTestProject()
+			project.KeyframeInterval = tt.interval
+			project.KeyframeIntervalUnit = tt.unit
+			project.Elements[0].FrameRate = tt.frameRate
+
+			cmd, err := newTestService(&app.Config{}).BuildCommand(&models.VideoConfigArray{project})
+			if err != nil {
+				t.Fatalf("BuildCommand() error = %v", err)
+			}
+			if got := flagValues(cmd.Args, "-g"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("-g = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCommandRendersPreview(t *testing.T) {
+	tests := []struct {
+		name           string
+		previewSeconds float64
+		maxSeconds     float64
+		format         string
+		wantDuration   string
+		wantPreset     []string
+		wantDeadline   []string
+	}{
+		{"full render", 0, 30, "", "4.00", []string{"medium"}, nil},
+		{"preview", 2.5, 30, "", "2.50", []string{"ultrafast"}, nil},
+		{"preview capped by config", 3, 1.5, "", "1.50", []string{"ultrafast"}, nil},
+		{"preview longer than the video", 10, 30, "", "4.00", []string{"ultrafast"}, nil},
+		{"webm preview", 2, 30, models.ContainerWebM, "2.00", nil, []string{"realtime"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &app.Config{}
+			cfg.FFmpeg.PreviewMaxSeconds = tt.maxSeconds
+			cfg.FFmpeg.PreviewPreset = "ultrafast"
+			project := newTestProject()
+			project.PreviewSeconds = tt.previewSeconds
+			project.Format = tt.format
+
+			cmd, err := newTestService(cfg).BuildCommand(&models.VideoConfigArray{project})
+			if err != nil {
+				t.Fatalf("BuildCommand() error = %v", err)
+			}
+
+			if got := argValue(cmd.Args, "-t"); got != tt.wantDuration {
+				t.Errorf("-t = %s, want %s", got, tt.wantDuration)
+			}
+			if got := fmt.Sprintf("%.2f", cmd.Duration); got != tt.wantDuration {
+				t.Errorf("Duration = %s, want %s", got, tt.wantDuration)
+			}
+			if got := flagValues(cmd.Args, "-preset"); !reflect.DeepEqual(got, tt.wantPreset) {
+				t.Errorf("-preset = %q, want %q", got, tt.wantPreset)
+			}
+			if got := flagValues(cmd.Args, "-deadline"); !reflect.DeepEqual(got, tt.wantDeadline) {
+				t.Errorf("-deadline = %q, want %q", got, tt.wantDeadline)
+			}
+			// Previews keep the output resolution so layout can be checked
+			if got := argValue(cmd.Args, "-s"); got != "1280x720" {
+				t.Errorf("-s = %s, want 1280x720", got)
+			}
+		})
+	}
+}
+
+func TestBuildCommandRendersVariableFrameRateAtConstantRate(t *testing.T) {
+	tests := []struct {
+		name      string
+		vfr       bool
+		frameRate float64
+		want      map[string][]string
+	}{
+		{"constant frame rate source", false, 25, map[string][]string{}},
+		{"variable frame rate source", true, 29.97, map[string][]string{"-vsync": {"cfr"}, "-r": {"29.970"}}},
+		{"variable frame rate without a detected rate", true, 0, map[string][]string{"-vsync": {"cfr"}, "-r": {"30.000"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(&app.Config{})
+			project := newTestProject()
+			project.Elements[0].VariableFrameRate = tt.vfr
+			project.Elements[0].FrameRate = tt.frameRate
+
+			scenes, err := s.BuildCommand(&models.VideoConfigArray{project})
+			if err != nil {
+				t.Fatalf("BuildCommand() error = %v", err)
+			}
+			subtitles, err := s.buildCommandWithSubtitleFileAndDuration(&models.VideoConfigArray{project}, "/tmp/subtitles.ass", 4)
+			if err != nil {
+				t.Fatalf("buildCommandWithSubtitleFileAndDuration() error = %v", err)
+			}
+
+			for name, cmd := range map[string]*FFmpegCommand{"scenes": scenes, "subtitles": subtitles} {
+				for _, flag := range []string{"-vsync", "-r"} {
+					if got := flagValues(cmd.Args, flag); !reflect.DeepEqual(got, tt.want[flag]) {
+						t.Errorf("%s: %s = %q, want %q", name, flag, got, tt.want[flag])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBuildCommandSharesRepeatedImageInputs(t *testing.T) {
+	project := newTestProject()
+	project.Scenes = []models.Scene{
+		{ID: "one", Elements: []models.Element{
+			{Type: "audio", Src: "https://example.com/one.mp3", Duration: 2},
+			{Type: "image", Src: "https://example.com/logo.png"},
+		}},
+		{ID: "two", Elements: []models.Element{
+			{Type: "audio", Src: "https://example.com/two.mp3", Duration: 2},
+			{Type: "image", Src: "https://example.com/chart.png", Width: 640},
+		}},
+		{ID: "three", Elements: []models.Element{
+			{Type: "audio", Src: "https://example.com/three.mp3", Duration: 2},
+			{Type: "image", Src: "https://example.com/logo.png", Height: 100},
+		}},
+	}
+
+	cmd, err := newTestService(&app.Config{}).BuildCommand(&models.VideoConfigArray{project})
+	if err != nil {
+		t.Fatalf("BuildCommand() error = %v", err)
+	}
+
+	wantInputs := []string{
+		"https://example.com/bg.mp4",
+		"https://example.com/one.mp3",
+		"https://example.com/two.mp3",
+		"https://example.com/three.mp3",
+		"https://example.com/logo.png",
+		"https://example.com/chart.png",
+	}
+	if got := inputSrcs(cmd.Args); !reflect.DeepEqual(got, wantInputs) {
+		t.Errorf("inputs = %q, want %q", got, wantInputs)
+	}
+
+	filters := strings.Split(argValue(cmd.Args, "-filter_complex"), ";")
+	for _, want := range []string{
+		"[4:v]split=2[src_img_0][src_img_2]",
+		"[src_img_0]scale=iw:ih[scaled_img_0]",
+		"[5:v]scale=640:-2[scaled_img_1]",
+		"[src_img_2]scale=-2:100[scaled_img_2]",
+	} {
+		found := false
+		for _, filter := range filters {
+			found = found || filter == want
+		}
+		if !found {
+			t.Errorf("filter %s missing from:\n%s", want, strings.Join(filters, "\n"))
+		}
+	}
+}
+
+func TestClampOverlayWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end float64
+		min, max   float64
+		want       float64
+	}{
+		{"no clamp", 2, 7, 0, 0, 7},
+		{"within bounds", 2, 7, 3, 8, 7},
+		{"exactly the minimum", 2, 5, 3, 0, 5},
+		{"short scene extended", 2, 4, 3, 0, 5},
+		{"exactly the maximum", 2, 10, 0, 8, 10},
+		{"long scene cut off", 2, 12, 0, 8, 10},
+		{"fixed duration", 2, 12, 4, 4, 6},
+		{"zero-length scene", 2, 2, 1.5, 0, 3.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clampOverlayWindow(tt.start, tt.end, tt.min, tt.max); got != tt.want {
+				t.Errorf("clampOverlayWindow(%v, %v, %v, %v) = %v, want %v", tt.start, tt.end, tt.min, tt.max, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCommandTakesNarrationFromVideoAudio(t *testing.T) {
+	project := newTestProject()
+	project.Scenes = []models.Scene{
+		{ID: "clip", Elements: []models.Element{{Type: "audio", Src: "https://example.com/interview.mp4", Duration: 6, AudioFromVideo: true}}},
+		{ID: "voice", Elements: []models.Element{{Type: "audio", Src: "https://example.com/voice.mp3", Duration: 3}}},
+	}
+
+	cmd, err := newTestService(&app.Config{}).BuildCommand(&models.VideoConfigArray{project})
+	if err != nil {
+		t.Fatalf("BuildCommand() error = %v", err)
+	}
+
+	// Input options are the args since the previous input
+	optionsStart := 0
+	for i, arg := range cmd.Args {
+		if arg != "-i" {
+			continue
+		}
+		dropsVideo := false
+		for _, option := range cmd.Args[optionsStart:i] {
+			dropsVideo = dropsVideo || option == "-vn"
+		}
+		optionsStart = i + 2
+		if wantDrop := cmd.Args[i+1] == "https://example.com/interview.mp4"; dropsVideo != wantDrop {
+			t.Errorf("input %s preceded by -vn = %v, want %v: %q", cmd.Args[i+1], dropsVideo, wantDrop, cmd.Args)
+		}
+	}
+	if graph := argValue(cmd.Args, "-filter_complex"); !strings.Contains(graph, "[1:a][2:a]concat=n=2:v=0:a=1[concatenated_audio]") {
+		t.Errorf("video audio is not concatenated with the narration: %s", graph)
+	}
+}
+
+func TestBuildCommandSetsOutputAudioEncoding(t *testing.T) {
+	tests := []struct {
+		name                string
+		codec, format, prof string
+		want                map[string][]string
+		wantExt             string
+	}{
+		{"default", "", "", "", map[string][]string{"-c:a": {"aac"}}, ".mp4"},
+		{"aac profile", models.AudioCodecAAC, "fltp", "aac_low",
+			map[string][]string{"-c:a": {"aac"}, "-sample_fmt": {"fltp"}, "-profile:a": {"aac_low"}}, ".mp4"},
+		{"alac", models.AudioCodecALAC, "s16p", "", map[string][]string{"-c:a": {"alac"}, "-sample_fmt": {"s16p"}}, ".mp4"},
+		{"pcm needs mov", models.AudioCodecPCMS24LE, "s32", "",
+			map[string][]string{"-c:a": {"pcm_s24le"}, "-sample_fmt": {"s32"}}, ".mov"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := newTestProject()
+			project.AudioCodec = tt.codec
+			project.AudioSampleFormat = tt.format
+			project.AudioProfile = tt.prof
+
+			cmd, err := newTestService(&app.Config{}).BuildCommand(&models.VideoConfigArray{project})
+			if err != nil {
+				t.Fatalf("BuildCommand() error = %v", err)
+			}
+
+			for _, flag := range []string{"-c:a", "-sample_fmt", "-profile:a"} {
+				if got := flagValues(cmd.Args, flag); !reflect.DeepEqual(got, tt.want[flag]) {
+					t.Errorf("%s = %q, want %q", flag, got, tt.want[flag])
+				}
+			}
+			if ext := filepath.Ext(cmd.OutputPath); ext != tt.wantExt {
+				t.Errorf("output %s, want a %s file", cmd.OutputPath, tt.wantExt)
+			}
+		})
+	}
+}
+
+func TestBuildCommandSetsContainerFlags(t *testing.T) {
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name    string
+		config  bool
+		project *bool
+		format  string
+		audio   string
+		want    []string
+	}{
+		{"mp4 follows the config", true, nil, "", "", []string{"+faststart"}},
+		{"disabled in config", false, nil, "", "", nil},
+		{"enabled by the project", false, &enabled, "", "", []string{"+faststart"}},
+		{"disabled by the project", true, &disabled, "", "", nil},
+		{"mov", true, nil, "", models.AudioCodecPCMS16LE, []string{"+faststart"}},
+		{"webm has no index to move", true, nil, models.ContainerWebM, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &app.Config{}
+			cfg.FFmpeg.Faststart = tt.config
+			project := newTestProject()
+			project.Faststart = tt.project
+			project.Format = tt.format
+			project.AudioCodec = tt.audio
+
+			cmd, err := newTestService(cfg).BuildCommand(&models.VideoConfigArray{project})
+			if err != nil {
+				t.Fatalf("BuildCommand() error = %v", err)
+			}
+			if got := flagValues(cmd.Args, "-movflags"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("-movflags = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCommandCapsBitrate(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxBitrate  int
+		bufferSize  int
+		wantMaxrate []string
+		wantBufsize []string
+	}{
+		{"uncapped", 0, 0, nil, nil},
+		{"default buffer", 4000, 0, []string{"4000k"}, []string{"8000k"}},
+		{"explicit buffer", 4000, 6000, []string{"4000k"}, []string{"6000k"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := newTestProject()
+			project.MaxBitrateKbps = tt.maxBitrate
+			project.BufferSizeKbits = tt.bufferSize
+
+			cmd, err := newTestService(&app.Config{}).BuildCommand(&models.VideoConfigArray{project})
+			if err != nil {
+				t.Fatalf("BuildCommand() error = %v", err)
+			}
+			if got := flagValues(cmd.Args, "-maxrate"); !reflect.DeepEqual(got, tt.wantMaxrate) {
+				t.Errorf("-maxrate = %q, want %q", got, tt.wantMaxrate)
+			}
+			if got := flagValues(cmd.Args, "-bufsize"); !reflect.DeepEqual(got, tt.wantBufsize) {
+				t.Errorf("-bufsize = %q, want %q", got, tt.wantBufsize)
+			}
+			// The cap sits on top of constant quality
+			if got := argValue(cmd.Args, "-crf"); got == "" {
+				t.Errorf("capped render lost its -crf: %q", cmd.Args)
+			}
+		})
+	}
+}
+
+func TestBuildCommandStylesImageOverlays(t *testing.T) {
+	rounded := "format=rgba,geq=r='r(X,Y)':g='g(X,Y)':b='b(X,Y)':a='if(gt(hypot(" +
+		"max(abs(X-W/2)-(W/2-min(12,min(W,H)/2)),0),max(abs(Y-H/2)-(H/2-min(12,min(W,H)/2)),0)),min(12,min(W,H)/2)),0,alpha(X,Y))'"
+
+	tests := []struct {
+		name  string
+		image models.Element
+		want  string
+	}{
+		{"unstyled", models.Element{}, ""},
+		{"border with default color", models.Element{BorderWidth: 4}, "pad=w=iw+8:h=ih+8:x=4:y=4:color=0xFFFFFF"},
+		{"border", models.Element{BorderWidth: 3, BorderColor: "#FF8800"}, "pad=w=iw+6:h=ih+6:x=3:y=3:color=0xFF8800"},
+		{"rounded corners", models.Element{CornerRadius: 12}, rounded},
+		{"border and rounded corners", models.Element{BorderWidth: 4, CornerRadius: 12},
+			"pad=w=iw+8:h=ih+8:x=4:y=4:color=0xFFFFFF," + rounded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image := tt.image
+			image.Type, image.Src, image.X, image.Y = "image", "https://example.com/logo.png", 10, 20
+			project := newTestProject()
+			project.Scenes[0].Elements = append(project.Scenes[0].Elements, image)
+
+			cmd, err := newTestService(&app.Config{}).BuildCommand(&models.VideoConfigArray{project})
+			if err != nil {
+				t.Fatalf("BuildCommand() error = %v", err)
+			}
+
+			graph := argValue(cmd.Args, "-filter_complex")
+			overlayInput := "[scaled_img_0]"
+			if tt.want != "" {
+				if want := "[scaled_img_0]" + tt.want + "[styled_img_0]"; !strings.Contains(graph, want) {
+					t.Errorf("filter graph is missing %s:\n%s", want, graph)
+				}
+				overlayInput = "[styled_img_0]"
+			} else if strings.Contains(graph, "styled_img") {
+				t.Errorf("unstyled image was styled: %s", graph)
+			}
+			if !strings.Contains(graph, "[0:v]"+overlayInput+"overlay=10:20:") {
+				t.Errorf("overlay does not read %s: %s", overlayInput, graph)
+			}
+		})
+	}
+}
+
+func TestBuildCommandReferencesInputsByTheirIndex(t *testing.T) {
+	project := newTestProject()
+	project.Elements = []models.Element{
+		{Type: "video", Src: "https://example.com/bg1.mp4", Duration: 3},
+		{Type: "video", Src: "https://example.com/bg2.mp4", Duration: 3},
+		{Type: "audio", Src: "https://example.com/music.mp3", Role: models.RoleBackgroundMusic},
+	}
+	project.Scenes = []models.Scene{
+		{ID: "one", Elements: []models.Element{
+			{Type: "audio", Src: "https://example.com/one.mp3", Duration: 2},
+			{Type: "image", Src: "https://example.com/logo.png"},
+		}},
+		{ID: "two", Elements: []models.Element{
+			{Type: "audio", Src: "https://example.com/two.mp4", Duration: 2, AudioFromVideo: true},
+		}},
+	}
+	project.Chapters = []models.ChapterMarker{{Time: 0, Title: "Intro"}, {Time: 2, Title: "Main"}}
+
+	cfg := &app.Config{}
+	cfg.Storage.TempDir = t.TempDir()
+	s := newTestService(cfg)
+	builds := map[string]func() (*FFmpegCommand, error){
+		"scenes": func() (*FFmpegCommand, error) {
+			return s.BuildCommand(&models.VideoConfigArray{project})
+		},
+		"subtitles": func() (*FFmpegCommand, error) {
+			return s.buildCommandWithSubtitleFileAndDuration(&models.VideoConfigArray{project}, "/tmp/subtitles.ass", 4)
+		},
+	}
+	// Each stream the filter graph reads, by the source it must come from
+	wantStreams := map[string]string{
+		"[0:v]": "https://example.com/bg1.mp4",
+		"[1:a]": "https://example.com/one.mp3",
+		"[2:a]": "https://example.com/two.mp4",
+		"[3:v]": "https://example.com/logo.png",
+		"[5:a]": "https://example.com/music.mp3",
+		"[6:v]": "https://example.com/bg2.mp4",
+	}
+	streamRef := regexp.MustCompile(`\[(\d+):[av]\]`)
+
+	for name, build := range builds {
+		t.Run(name, func(t *testing.T) {
+			cmd, err := build()
+			if err != nil {
+				t.Fatalf("build error = %v", err)
+			}
+			srcs := inputSrcs(cmd.Args)
+			filterComplex := argValue(cmd.Args, "-filter_complex")
+
+			for ref, want := range wantStreams {
+				if !strings.Contains(filterComplex, ref) {
+					t.Errorf("filter graph does not read %s:\n%s", ref, filterComplex)
+				}
+				index, _ := strconv.Atoi(streamRef.FindStringSubmatch(ref)[1])
+				if index >= len(srcs) || srcs[index] != want {
+					t.Errorf("%s reads input %d of %q, want %s", ref, index, srcs, want)
+				}
+			}
+			for _, match := range streamRef.FindAllString(filterComplex, -1) {
+				if _, ok := wantStreams[match]; !ok {
+					t.Errorf("filter graph reads unexpected stream %s:\n%s", match, filterComplex)
+				}
+			}
+
+			chapters := argValue(cmd.Args, "-map_metadata")
+			if index, err := strconv.Atoi(chapters); err != nil || index >= len(srcs) || !strings.Contains(srcs[index], "chapters_") {
+				t.Errorf("-map_metadata %s does not name the chapter input of %q", chapters, srcs)
+			}
+		})
+	}
+}
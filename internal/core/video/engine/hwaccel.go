@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+const (
+	// hwUploadRef labels the output video after it was uploaded to the VAAPI device
+	hwUploadRef = "hw_video"
+
+	// nvidiaControlDevice exists when the NVIDIA driver is loaded
+	nvidiaControlDevice = "/dev/nvidiactl"
+)
+
+// hardwareEncoders maps each accelerator to its encoder per software codec
+var hardwareEncoders = map[string]map[string]string{
+	app.HWAccelNVENC: {models.VideoCodecH264: "h264_nvenc"},
+	app.HWAccelVAAPI: {models.VideoCodecH264: "h264_vaapi", models.VideoCodecVP9: "vp9_vaapi"},
+	app.HWAccelQSV:   {models.VideoCodecH264: "h264_qsv", models.VideoCodecVP9: "vp9_qsv"},
+}
+
+// hwEncoder is the hardware encoder of a render; nil encodes in software
+type hwEncoder struct {
+	accel   string
+	encoder string
+}
+
+// selectHardwareEncoder returns the encoder for ffmpeg.hwaccel, or nil for
+// software encoding. A codec the accelerator cannot produce is an error; an
+// unavailable device falls back to software when ffmpeg.hwaccel_fallback is set.
+func (s *service) selectHardwareEncoder(project models.VideoProject) (*hwEncoder, error) {
+	accel := s.cfg.FFmpeg.HWAccel
+	if accel == "" || accel == app.HWAccelNone {
+		return nil, nil
+	}
+
+	codec := project.OutputVideoCodec()
+	encoder, ok := hardwareEncoders[accel][codec]
+	if !ok {
+		return nil, errors.InvalidInput(fmt.Sprintf(
+			"hardware acceleration %s cannot encode %s output; choose another format or disable ffmpeg.hwaccel", accel, codec))
+	}
+
+	if _, err := os.Stat(s.hardwareDevice(accel)); err != nil {
+		if !s.cfg.FFmpeg.HWAccelFallback {
+			return nil, fmt.Errorf("hardware acceleration %s unavailable: %w", accel, err)
+		}
+		s.log.Warnf("Hardware acceleration %s unavailable, encoding in software: %v", accel, err)
+		return nil, nil
+	}
+
+	return &hwEncoder{accel: accel, encoder: encoder}, nil
+}
+
+// hardwareDevice returns the device node an accelerator needs
+func (s *service) hardwareDevice(accel string) string {
+	if accel == app.HWAccelNVENC {
+		return nvidiaControlDevice
+	}
+	return s.cfg.FFmpeg.HWAccelDevice
+}
+
+// addHardwareInputFlags enables hardware decoding of the next input (the
+// background video). Decoded frames return to system memory for the
+// software filters.
+func (s *service) addHardwareInputFlags(builder *commandBuilder, hw *hwEncoder) {
+	if hw == nil {
+		return
+	}
+	switch hw.accel {
+	case app.HWAccelNVENC:
+		builder.addInput("-hwaccel", "cuda")
+	case app.HWAccelVAAPI:
+		builder.addInput("-hwaccel", "vaapi", "-vaapi_device", s.cfg.FFmpeg.HWAccelDevice)
+	case app.HWAccelQSV:
+		builder.addInput("-hwaccel", "qsv", "-qsv_device", s.cfg.FFmpeg.HWAccelDevice)
+	}
+}
+
+// appendHardwareUpload appends the filter moving the output video stream onto
+// the VAAPI device to filterComplex and returns the stream to map instead.
+// VAAPI encoders only take device frames, so scaling to the project size
+// happens before the upload. Other encoders are returned unchanged.
+func appendHardwareUpload(filterComplex, videoStream string, project models.VideoProject, hw *hwEncoder) (string, string) {
+	if hw == nil || hw.accel != app.HWAccelVAAPI {
+		return filterComplex, videoStream
+	}
+	if videoStream == videoInputRef {
+		videoStream = fmt.Sprintf("[%s]", videoInputRef)
+	}
+	scale := ""
+	if project.Width > 0 && project.Height > 0 {
+		scale = fmt.Sprintf("scale=%d:%d,", project.Width, project.Height)
+	}
+
+	upload := fmt.Sprintf("%s%sformat=nv12,hwupload[%s]", videoStream, scale, hwUploadRef)
+	if filterComplex != "" {
+		upload = filterComplex + ";" + upload
+	}
+	return upload, fmt.Sprintf("[%s]", hwUploadRef)
+}
+
+// addHardwareEncoderSettings sets the encoder, its constant-quality mode at
+// the project's CRF and its pixel format. x264 presets do not apply; previews
+// use the encoder's fastest preset.
+func addHardwareEncoderSettings(builder *commandBuilder, project models.VideoProject, hw *hwEncoder) {
+	quality := strconv.Itoa(outputCRF(project, project.OutputVideoCodec()))
+	builder.addArg("-c:v", hw.encoder)
+
+	switch hw.accel {
+	case app.HWAccelNVENC:
+		builder.addArg("-rc", "vbr", "-cq", quality, "-b:v", "0")
+		if project.IsPreview() {
+			builder.addArg("-preset", "p1")
+		}
+		builder.addArg("-pix_fmt", "yuv420p")
+	case app.HWAccelVAAPI:
+		// Frames are already nv12 on the device (see appendHardwareUpload)
+		builder.addArg("-qp", quality)
+	case app.HWAccelQSV:
+		builder.addArg("-global_quality", quality)
+		if project.IsPreview() {
+			builder.addArg("-preset", "veryfast")
+		}
+		builder.addArg("-pix_fmt", "nv12")
+	}
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+// maxDecodeErrors caps how many decode errors of one file are reported
+const maxDecodeErrors = 10
+
+// VerifyDecode decodes the whole file with "ffmpeg -v error -i <path> -f null -"
+// and returns the errors FFmpeg reported; none means it decoded cleanly. The
+// error is only set when the check itself could not run.
+func (s *service) VerifyDecode(ctx context.Context, videoPath string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.FFmpeg.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.cfg.FFmpeg.BinaryPath, "-nostdin", "-v", "error", "-i", videoPath, "-f", "null", "-")
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, errors.FFmpegFailed(err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, errors.FFmpegFailed(err)
+	}
+
+	lines, total := collectDecodeErrors(stderr)
+	runErr := cmd.Wait()
+	if ctx.Err() != nil {
+		return nil, errors.FFmpegFailed(fmt.Errorf("decode check interrupted: %w", ctx.Err()))
+	}
+
+	s.log.Debugf("Decode check of %s reported %d errors", videoPath, total)
+	return interpretDecodeCheck(lines, total, runErr)
+}
+
+// collectDecodeErrors reads FFmpeg's error log until it closes and returns
+// its first maxDecodeErrors non-empty lines along with the number of lines
+func collectDecodeErrors(stderr io.Reader) ([]string, int) {
+	scanner := bufio.NewScanner(stderr)
+	var lines []string
+	total := 0
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		total++
+		if len(lines) < maxDecodeErrors {
+			lines = append(lines, line)
+		}
+	}
+
+	// Keep draining so FFmpeg never blocks on a full pipe
+	_, _ = io.Copy(io.Discard, stderr)
+	return lines, total
+}
+
+// interpretDecodeCheck turns the outcome of a decode pass into the decode
+// errors to report. With "-v error" every logged line is an error; a decoder
+// that exits non-zero without logging still fails the check.
+func interpretDecodeCheck(lines []string, total int, runErr error) ([]string, error) {
+	if runErr != nil {
+		if _, exited := runErr.(*exec.ExitError); !exited {
+			return nil, errors.FFmpegFailed(runErr)
+		}
+		if total == 0 {
+			return []string{fmt.Sprintf("decoding failed: %v", runErr)}, nil
+		}
+	}
+
+	if total > len(lines) {
+		lines = append(lines, fmt.Sprintf("... and %d more", total-len(lines)))
+	}
+	return lines, nil
+}
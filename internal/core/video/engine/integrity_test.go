@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/app"
+)
+
+func TestVerifyDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    []string
+		wantErr bool
+	}{
+		{name: "clean", script: "exit 0"},
+		{
+			name:   "decode errors",
+			script: `printf '[h264 @ 0x1] corrupt macroblock\n\nError while decoding stream #0:0\n' >&2; exit 0`,
+			want:   []string{"[h264 @ 0x1] corrupt macroblock", "Error while decoding stream #0:0"},
+		},
+		{
+			name:   "errors beyond the cap",
+			script: `i=1; while [ $i -le 12 ]; do echo "error $i" >&2; i=$((i+1)); done; exit 1`,
+			want: []string{
+				"error 1", "error 2", "error 3", "error 4", "error 5",
+				"error 6", "error 7", "error 8", "error 9", "error 10",
+				"... and 2 more",
+			},
+		},
+		{name: "silent failure", script: "exit 1", want: []string{"decoding failed: exit status 1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &app.Config{FFmpeg: app.FFmpegConfig{BinaryPath: fakeFFmpeg(t, tt.script), Timeout: time.Minute}}
+			got, err := newTestService(cfg).VerifyDecode(context.Background(), "/tmp/render.mp4")
+			if err != nil {
+				t.Fatalf("VerifyDecode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("VerifyDecode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyDecodeReportsCheckThatCannotRun(t *testing.T) {
+	cfg := &app.Config{FFmpeg: app.FFmpegConfig{BinaryPath: filepath.Join(t.TempDir(), "missing"), Timeout: time.Minute}}
+	if got, err := newTestService(cfg).VerifyDecode(context.Background(), "/tmp/render.mp4"); err == nil {
+		t.Errorf("VerifyDecode() = %q, want an error", got)
+	}
+
+	cfg.FFmpeg.BinaryPath = fakeFFmpeg(t, "exec sleep 5")
+	cfg.FFmpeg.Timeout = 50 * time.Millisecond
+	if got, err := newTestService(cfg).VerifyDecode(context.Background(), "/tmp/render.mp4"); err == nil {
+		t.Errorf("VerifyDecode() of a hung decoder = %q, want an error", got)
+	}
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/activadee/videocraft/internal/api/models"
+)
+
+// loudnormTarget is the EBU R128 target of normalized audio: -16 LUFS
+// integrated loudness, -1.5 dBTP true peak and a loudness range of 11 LU
+const loudnormTarget = "I=-16:TP=-1.5:LRA=11"
+
+// loudnormSampleRate resamples loudnorm's 192 kHz output back to a common
+// rate, so normalized inputs don't upsample the whole mix
+const loudnormSampleRate = 48000
+
+// loudnormSummary is the JSON summary a measuring loudnorm pass prints
+type loudnormSummary struct {
+	InputI       string `json:"input_i"`
+	InputTP      string `json:"input_tp"`
+	InputLRA     string `json:"input_lra"`
+	InputThresh  string `json:"input_thresh"`
+	TargetOffset string `json:"target_offset"`
+}
+
+// audioNormalization returns the loudnorm filter chain of each narration
+// element, in input order, or nil when the project is not normalized. Muted
+// elements are silenced anyway and get no filter. Elements measured by the
+// job are normalized linearly from their measurement, all others in a
+// single dynamic pass.
+func (s *service) audioNormalization(project models.VideoProject, audioElements []models.Element) []string {
+	if !project.NormalizeAudioOr(s.cfg.FFmpeg.NormalizeAudio) || len(audioElements) == 0 {
+		return nil
+	}
+
+	normalization := make([]string, len(audioElements))
+	for i, audio := range audioElements {
+		if audio.Mute {
+			continue
+		}
+		normalization[i] = loudnormFilter(audio.Loudness)
+	}
+	return normalization
+}
+
+// loudnormFilter returns the loudnorm filter chain for the target. With a
+// measurement the second pass normalizes linearly from the measured values;
+// without one loudnorm adapts dynamically in a single pass.
+func loudnormFilter(measurement *models.LoudnessMeasurement) string {
+	filter := "loudnorm=" + loudnormTarget
+	if measurement != nil {
+		filter += fmt.Sprintf(":measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:linear=true",
+			formatLoudness(measurement.Integrated), formatLoudness(measurement.TruePeak),
+			formatLoudness(measurement.Range), formatLoudness(measurement.Threshold),
+			formatLoudness(measurement.TargetOffset))
+	}
+	return fmt.Sprintf("%s,aresample=%d", filter, loudnormSampleRate)
+}
+
+func formatLoudness(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// MeasureLoudness runs the first loudnorm pass over an audio element and
+// parses the measurement FFmpeg prints at the end of its log. It is run by
+// the job before rendering, so building commands never spawns FFmpeg.
+func (s *service) MeasureLoudness(ctx context.Context, audio models.Element) (*models.LoudnessMeasurement, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.FFmpeg.Timeout)
+	defer cancel()
+
+	args := []string{"-nostdin", "-hide_banner", "-protocol_whitelist", "file,http,https,tcp,tls"}
+	if isRemoteInput(audio.Src) {
+		args = append(args, s.remoteInputArgs()...)
+	}
+	if audio.AudioFromVideo {
+		args = append(args, "-vn")
+	}
+	args = append(args, "-i", audio.Src,
+		"-af", "loudnorm="+loudnormTarget+":print_format=json",
+		"-f", "null", "-")
+
+	output, err := exec.CommandContext(ctx, s.cfg.FFmpeg.BinaryPath, args...).CombinedOutput()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("measurement pass interrupted: %w", ctx.Err())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("measurement pass failed: %w", err)
+	}
+	return parseLoudnormMeasurement(string(output))
+}
+
+// parseLoudnormMeasurement extracts the JSON summary from a measuring pass's
+// log. Silent inputs measure as -inf, which a second pass cannot use.
+func parseLoudnormMeasurement(output string) (*models.LoudnessMeasurement, error) {
+	start := strings.LastIndex(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no loudnorm measurement in FFmpeg output")
+	}
+
+	var summary loudnormSummary
+	if err := json.Unmarshal([]byte(output[start:end+1]), &summary); err != nil {
+		return nil, fmt.Errorf("failed to parse loudnorm measurement: %w", err)
+	}
+
+	var measurement models.LoudnessMeasurement
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *float64
+	}{
+		{"input_i", summary.InputI, &measurement.Integrated},
+		{"input_tp", summary.InputTP, &measurement.TruePeak},
+		{"input_lra", summary.InputLRA, &measurement.Range},
+		{"input_thresh", summary.InputThresh, &measurement.Threshold},
+		{"target_offset", summary.TargetOffset, &measurement.TargetOffset},
+	} {
+		parsed, err := strconv.ParseFloat(field.value, 64)
+		if err != nil || math.IsInf(parsed, 0) || math.IsNaN(parsed) {
+			return nil, fmt.Errorf("unusable loudnorm measurement %s=%q", field.name, field.value)
+		}
+		*field.dest = parsed
+	}
+	return &measurement, nil
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/activadee/videocraft/internal/api/models"
+)
+
+const (
+	finalAudioRef     = "final_audio"
+	narrationAudioRef = "narration_audio"
+	musicAudioRef     = "music_audio"
+	narrationMixRef   = "narration_mix"
+	duckKeyRef        = "duck_key"
+	duckedMusicRef    = "ducked_music"
+)
+
+// Ducking reacts quickly to speech and recovers gently between sentences
+const (
+	duckAttackMs  = 20
+	duckReleaseMs = 400
+)
+
+// musicTrack is a background music element and its FFmpeg input index
+type musicTrack struct {
+	element models.Element
+	input   int
+}
+
+// findBackgroundMusic returns the project's background music element, or nil
+func findBackgroundMusic(project models.VideoProject) *models.Element {
+	for _, element := range project.Elements {
+		if element.Type == elementTypeAudio && element.Role == models.RoleBackgroundMusic {
+			element.Mute = project.AudioMuted(nil, element)
+			return &element
+		}
+	}
+	return nil
+}
+
+// addBackgroundMusicInput adds the music as an endlessly looping input at
+// index input; the mix and -t decide where it ends. Returns nil without music.
+func (s *service) addBackgroundMusicInput(builder *commandBuilder, music *models.Element, input int) *musicTrack {
+	if music == nil {
+		return nil
+	}
+	builder.addInput("-stream_loop", "-1")
+	s.addAudioInput(builder, *music)
+	return &musicTrack{element: *music, input: input}
+}
+
+// addAudioFilters builds the audio labelled output: the concatenated narration,
+// mixed with the background music when there is one. The mix lasts as long as the padded
+// narration and keeps both at their own volume instead of amix's averaging.
+// The narration inputs start at firstInput and are normalized and crossfaded
+// as given by normalization and crossfades; the music keeps its own loudness.
+func (s *service) addAudioFilters(filters *[]string, audioElements []models.Element, firstInput int, music *musicTrack, normalization []string, crossfades []float64, output string) {
+	if music == nil {
+		s.addAudioConcatenationFilters(filters, audioElements, firstInput, output, normalization, crossfades)
+		return
+	}
+
+	volume := strconv.FormatFloat(audioVolume(music.element), 'f', -1, 64)
+	if len(audioElements) == 0 {
+		*filters = append(*filters, fmt.Sprintf("[%d:a]volume=%s[%s]", music.input, volume, output))
+		return
+	}
+
+	s.addAudioConcatenationFilters(filters, audioElements, firstInput, narrationAudioRef, normalization, crossfades)
+	*filters = append(*filters, fmt.Sprintf("[%d:a]volume=%s[%s]", music.input, volume, musicAudioRef))
+
+	narration, musicRef := narrationAudioRef, musicAudioRef
+	if music.element.Duck {
+		narration, musicRef = addDuckingFilters(filters, music.element)
+	}
+	*filters = append(*filters,
+		fmt.Sprintf("[%s][%s]amix=inputs=2:duration=first:dropout_transition=0:normalize=0[%s]",
+			narration, musicRef, output))
+}
+
+// addDuckingFilters compresses the music with the narration as sidechain key,
+// so it drops whenever someone speaks. The narration is split since it is
+// both the key and part of the mix; returns the narration and music labels
+// to mix.
+func addDuckingFilters(filters *[]string, music models.Element) (string, string) {
+	threshold, ratio := music.DuckSettings()
+	*filters = append(*filters,
+		fmt.Sprintf("[%s]asplit=2[%s][%s]", narrationAudioRef, narrationMixRef, duckKeyRef),
+		fmt.Sprintf("[%s][%s]sidechaincompress=threshold=%s:ratio=%s:attack=%d:release=%d[%s]",
+			musicAudioRef, duckKeyRef,
+			strconv.FormatFloat(threshold, 'f', -1, 64), strconv.FormatFloat(ratio, 'f', -1, 64),
+			duckAttackMs, duckReleaseMs, duckedMusicRef))
+	return narrationMixRef, duckedMusicRef
+}
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+func newTestService(cfg *app.Config) *service {
+	return &service{cfg: cfg, log: logger.NewWithWriter("error", io.Discard, "text")}
+}
+
+func TestAddAudioFiltersDucksMusicUnderNarration(t *testing.T) {
+	s := newTestService(&app.Config{Audio: app.AudioConfig{TailPadding: 2}})
+	narration := []models.Element{{Type: "audio", Src: "a.mp3"}, {Type: "audio", Src: "b.mp3"}}
+	music := &musicTrack{
+		element: models.Element{Type: "audio", Role: models.RoleBackgroundMusic, Volume: 0.3, Duck: true, DuckRatio: 4},
+		input:   5,
+	}
+
+	var filters []string
+	s.addAudioFilters(&filters, narration, 1, music, nil, nil, finalAudioRef)
+
+	want := []string{
+		"[1:a][2:a]concat=n=2:v=0:a=1[concatenated_audio]",
+		"[concatenated_audio]apad=pad_dur=2[narration_audio]",
+		"[5:a]volume=0.3[music_audio]",
+		"[narration_audio]asplit=2[narration_mix][duck_key]",
+		"[music_audio][duck_key]sidechaincompress=threshold=0.05:ratio=4:attack=20:release=400[ducked_music]",
+		"[narration_mix][ducked_music]amix=inputs=2:duration=first:dropout_transition=0:normalize=0[final_audio]",
+	}
+	if got := strings.Join(filters, ";"); got != strings.Join(want, ";") {
+		t.Errorf("filters =\n%s\nwant\n%s", strings.Join(filters, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestAddAudioFiltersWithoutDuckingMixesMusicDirectly(t *testing.T) {
+	s := newTestService(&app.Config{Audio: app.AudioConfig{TailPadding: 2}})
+	narration := []models.Element{{Type: "audio", Src: "a.mp3"}}
+	music := &musicTrack{element: models.Element{Type: "audio", Role: models.RoleBackgroundMusic}, input: 3}
+
+	var filters []string
+	s.addAudioFilters(&filters, narration, 1, music, nil, nil, finalAudioRef)
+
+	graph := strings.Join(filters, ";")
+	if strings.Contains(graph, "sidechaincompress") {
+		t.Errorf("music without duck must not be compressed: %s", graph)
+	}
+	if !strings.HasSuffix(graph, "[narration_audio][music_audio]amix=inputs=2:duration=first:dropout_transition=0:normalize=0[final_audio]") {
+		t.Errorf("unexpected mix: %s", graph)
+	}
+}
@@ -185,6 +185,27 @@ func (s *service) ValidateURLAllowlist(rawURL string) error {
 	return errors.New("domain not in allowlist")
 }
 
+// ValidateMediaURL applies the URL and domain allowlist validation of media
+// srcs to a remote URL
+func (s *service) ValidateMediaURL(rawURL string) error {
+	// Basic URL validation
+	if err := s.ValidateURL(rawURL); err != nil {
+		return err
+	}
+
+	// Domain allowlist validation
+	return s.ValidateURLAllowlist(rawURL)
+}
+
+// validateMediaSrc applies URL and domain allowlist validation to a media src.
+// Files uploaded with the request are local paths and skip both.
+func (s *service) validateMediaSrc(src string) error {
+	if s.cfg.Storage.IsUploadedFile(src) {
+		return nil
+	}
+	return s.ValidateMediaURL(src)
+}
+
 // validateAllURLsInConfig validates all URLs in a video configuration
 // This is the main entry point for security validation during command building
 func (s *service) validateAllURLsInConfig(config *models.VideoConfigArray) error {
@@ -193,22 +214,48 @@ func (s *service) validateAllURLsInConfig(config *models.VideoConfigArray) error
 	for projectIdx, project := range *config {
 		for sceneIdx, scene := range project.Scenes {
 			for elementIdx, element := range scene.Elements {
-				if element.Src != "" {
-					urlCount++
-
-					// Create context for better error reporting
-					elementContext := fmt.Sprintf("project[%d].scene[%d].element[%d](%s)",
-						projectIdx, sceneIdx, elementIdx, element.Type)
-
-					// Basic URL validation
-					if err := s.ValidateURL(element.Src); err != nil {
-						return fmt.Errorf("security validation failed for %s: %w", elementContext, err)
-					}
-
-					// Domain allowlist validation
-					if err := s.ValidateURLAllowlist(element.Src); err != nil {
-						return fmt.Errorf("security validation failed for %s: %w", elementContext, err)
-					}
+				// Create context for better error reporting
+				elementContext := fmt.Sprintf("project[%d].scene[%d].element[%d](%s)",
+					projectIdx, sceneIdx, elementIdx, element.Type)
+
+				validated, err := s.validateElementSrcs(element, elementContext)
+				urlCount += validated
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Project-level elements (background video, background music) are
+		// FFmpeg inputs as well
+		for elementIdx, element := range project.Elements {
+			elementContext := fmt.Sprintf("project[%d].element[%d](%s)",
+				projectIdx, elementIdx, element.Type)
+
+			validated, err := s.validateElementSrcs(element, elementContext)
+			urlCount += validated
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Timeline clips carry their own URLs
+	for projectIdx, project := range *config {
+		if project.Timeline == nil {
+			continue
+		}
+		for trackIdx, track := range project.Timeline.Tracks {
+			for clipIdx, clip := range track.Clips {
+				if clip.Src == "" {
+					continue
+				}
+				urlCount++
+
+				clipContext := fmt.Sprintf("project[%d].timeline.track[%d].clip[%d](%s)",
+					projectIdx, trackIdx, clipIdx, track.Type)
+				if err := s.validateMediaSrc(clip.Src); err != nil {
+					return fmt.Errorf("security validation failed for %s: %w", clipContext, err)
 				}
 			}
 		}
@@ -223,6 +270,22 @@ func (s *service) validateAllURLsInConfig(config *models.VideoConfigArray) error
 	return nil
 }
 
+// validateElementSrcs validates an element's src and its fallback srcs, any
+// of which may become an FFmpeg input, and returns how many were checked
+func (s *service) validateElementSrcs(element models.Element, elementContext string) (int, error) {
+	validated := 0
+	for _, src := range append([]string{element.Src}, element.FallbackSrcs...) {
+		if src == "" {
+			continue
+		}
+		validated++
+		if err := s.validateMediaSrc(src); err != nil {
+			return validated, fmt.Errorf("security validation failed for %s: %w", elementContext, err)
+		}
+	}
+	return validated, nil
+}
+
 // logSecurityViolation logs security violations with structured data
 func (s *service) logSecurityViolation(message string, fields map[string]interface{}) {
 	s.log.WithFields(fields).Errorf("SECURITY_VIOLATION: %s", message)
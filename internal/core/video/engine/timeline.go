@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/activadee/videocraft/internal/api/models"
+)
+
+// defaultTimelineFontSize is used for text clips without a font size
+const defaultTimelineFontSize = 48
+
+// drawtextEscaper escapes text for drawtext's text option inside a quoted filter argument
+var drawtextEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"%", "\\%",
+	":", "\\:",
+	"'", "’", // A straight quote would end the quoted argument
+)
+
+// timelineInput is a clip that is fed to FFmpeg as an input
+type timelineInput struct {
+	trackType string
+	clip      models.TimelineClip
+	index     int
+}
+
+// buildTimelineCommand compiles an EDL-style timeline into a single FFmpeg
+// command: the base video is looped, audio clips are delayed to their offsets
+// and mixed, and video, image and text clips are overlaid in their windows.
+func (s *service) buildTimelineCommand(project models.VideoProject) (*FFmpegCommand, error) {
+	timeline := project.Timeline
+	base, ok := timeline.BaseVideo()
+	if !ok {
+		return nil, fmt.Errorf("timeline has no base video")
+	}
+	totalDuration := timeline.Duration()
+
+	hw, err := s.selectHardwareEncoder(project)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := newCommandBuilder(s.remoteInputArgs())
+	builder.addInput("-protocol_whitelist", "file,http,https,tcp,tls")
+	s.addHardwareInputFlags(builder, hw)
+	builder.addInput("-stream_loop", "-1", "-i", base.Src)
+
+	// Every clip with media becomes an input after the base video
+	var inputs []timelineInput
+	var textClips []models.TimelineClip
+	skippedBase := false
+	for _, track := range timeline.Tracks {
+		for _, clip := range track.Clips {
+			switch {
+			case track.Type == models.TrackTypeText:
+				textClips = append(textClips, clip)
+			case track.Type == models.TrackTypeVideo && !skippedBase:
+				skippedBase = true // First video clip is input 0
+			default:
+				inputs = append(inputs, timelineInput{trackType: track.Type, clip: clip, index: len(inputs) + 1})
+				if track.Type == models.TrackTypeImage {
+					builder.addInput("-loop", "1", "-i", clip.Src)
+				} else {
+					builder.addInput("-i", clip.Src)
+				}
+			}
+		}
+	}
+
+	var filters []string
+	hasAudio := s.addTimelineAudioFilters(&filters, inputs)
+	currentVideo := s.addTimelineOverlayFilters(&filters, inputs, textClips)
+
+	outputVideoStream := videoInputRef
+	if currentVideo != videoInputRef {
+		outputVideoStream = fmt.Sprintf("[%s]", currentVideo)
+	}
+	filterComplex, outputVideoStream := appendHardwareUpload(strings.Join(filters, ";"), outputVideoStream, project, hw)
+
+	if filterComplex != "" {
+		builder.addArg("-filter_complex", filterComplex)
+	}
+	builder.addArg("-map", outputVideoStream)
+	if hasAudio {
+		builder.addArg("-map", "[final_audio]")
+	}
+	s.addMetadataStripping(builder, project, false)
+
+	duration := s.outputDuration(project, totalDuration)
+	builder.addArg("-t", fmt.Sprintf("%.2f", duration))
+	s.addOutputSettingsForProject(builder, project, hw)
+
+	outputPath := s.generateOutputPathForProject(project)
+	builder.addArg(outputPath)
+
+	s.log.Debugf("Compiled timeline: %d tracks, %d inputs, %.2fs", len(timeline.Tracks), len(inputs)+1, totalDuration)
+
+	return &FFmpegCommand{
+		Args:       builder.args,
+		OutputPath: outputPath,
+		Duration:   duration,
+	}, nil
+}
+
+// addTimelineAudioFilters trims each audio clip, delays it to its offset and
+// mixes all clips into [final_audio]. Returns false if there is no audio.
+func (s *service) addTimelineAudioFilters(filters *[]string, inputs []timelineInput) bool {
+	var labels []string
+	for _, input := range inputs {
+		if input.trackType != models.TrackTypeAudio {
+			continue
+		}
+
+		label := fmt.Sprintf("timeline_audio_%d", len(labels))
+		delayMs := int64(input.clip.Offset * 1000)
+		*filters = append(*filters, fmt.Sprintf("[%d:a]atrim=0:%f,asetpts=PTS-STARTPTS,adelay=%d:all=1[%s]",
+			input.index, input.clip.Duration, delayMs, label))
+		labels = append(labels, "["+label+"]")
+	}
+
+	switch len(labels) {
+	case 0:
+		return false
+	case 1:
+		*filters = append(*filters, fmt.Sprintf("%sapad[final_audio]", labels[0]))
+	default:
+		*filters = append(*filters, fmt.Sprintf("%samix=inputs=%d:duration=longest:normalize=0,apad[final_audio]",
+			strings.Join(labels, ""), len(labels)))
+	}
+	return true
+}
+
+// addTimelineOverlayFilters layers video, image and text clips over the base
+// video, each enabled only within its window. Returns the final video label.
+func (s *service) addTimelineOverlayFilters(filters *[]string, inputs []timelineInput, textClips []models.TimelineClip) string {
+	currentVideo := videoInputRef
+	overlayCount := 0
+
+	for _, input := range inputs {
+		if input.trackType != models.TrackTypeVideo && input.trackType != models.TrackTypeImage {
+			continue
+		}
+
+		clip := input.clip
+		clipLabel := fmt.Sprintf("timeline_clip_%d", overlayCount)
+		// Shift the clip's timestamps so it starts playing at its offset
+		*filters = append(*filters, fmt.Sprintf("[%d:v]trim=0:%f,setpts=PTS-STARTPTS+%f/TB[%s]",
+			input.index, clip.Duration, clip.Offset, clipLabel))
+
+		outputLabel := fmt.Sprintf("timeline_overlay_%d", overlayCount)
+		*filters = append(*filters, fmt.Sprintf("[%s][%s]overlay=%d:%d:eof_action=pass:enable='between(t\\,%f\\,%f)'[%s]",
+			currentVideo, clipLabel, clip.X, clip.Y, clip.Offset, clip.End(), outputLabel))
+
+		currentVideo = outputLabel
+		overlayCount++
+	}
+
+	for i, clip := range textClips {
+		fontSize := clip.FontSize
+		if fontSize == 0 {
+			fontSize = defaultTimelineFontSize
+		}
+
+		outputLabel := fmt.Sprintf("timeline_text_%d", i)
+		*filters = append(*filters, fmt.Sprintf("[%s]drawtext=text='%s':x=%d:y=%d:fontsize=%d:fontcolor=white:enable='between(t\\,%f\\,%f)'[%s]",
+			currentVideo, drawtextEscaper.Replace(clip.Text), clip.X, clip.Y, fontSize, clip.Offset, clip.End(), outputLabel))
+		currentVideo = outputLabel
+	}
+
+	return currentVideo
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+)
+
+// argValue returns the value following flag in args
+func argValue(args []string, flag string) string {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// inputSrcs returns the values of every -i in args
+func inputSrcs(args []string) []string {
+	var srcs []string
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == "-i" {
+			srcs = append(srcs, args[i+1])
+		}
+	}
+	return srcs
+}
+
+func TestBuildTimelineCommand(t *testing.T) {
+	project := models.VideoProject{Timeline: &models.Timeline{Tracks: []models.TimelineTrack{
+		{Type: models.TrackTypeText, Clips: []models.TimelineClip{{Text: "50% off: today's deal", Offset: 1, Duration: 2, X: 10, Y: 20}}},
+		{Type: models.TrackTypeAudio, Clips: []models.TimelineClip{
+			{Src: "https://example.com/a.mp3", Offset: 0.5, Duration: 3},
+			// Gap from 3.5s to 6s
+			{Src: "https://example.com/b.mp3", Offset: 6, Duration: 2},
+		}},
+		{Type: models.TrackTypeVideo, Clips: []models.TimelineClip{
+			{Src: "https://example.com/bg.mp4", Duration: 8},
+			{Src: "https://example.com/cut.mp4", Offset: 4, Duration: 3, X: 100, Y: 50},
+		}},
+		// The image overlaps the video cut on another track
+		{Type: models.TrackTypeImage, Clips: []models.TimelineClip{{Src: "https://example.com/logo.png", Offset: 5, Duration: 5}}},
+	}}}
+
+	cmd, err := newTestService(&app.Config{}).BuildCommand(&models.VideoConfigArray{project})
+	if err != nil {
+		t.Fatalf("BuildCommand() error = %v", err)
+	}
+
+	// The base video is input 0; other clips follow in track order
+	wantInputs := []string{
+		"https://example.com/bg.mp4",
+		"https://example.com/a.mp3",
+		"https://example.com/b.mp3",
+		"https://example.com/cut.mp4",
+		"https://example.com/logo.png",
+	}
+	if got := inputSrcs(cmd.Args); !reflect.DeepEqual(got, wantInputs) {
+		t.Errorf("inputs = %q, want %q", got, wantInputs)
+	}
+
+	wantFilters := []string{
+		`[1:a]atrim=0:3.000000,asetpts=PTS-STARTPTS,adelay=500:all=1[timeline_audio_0]`,
+		`[2:a]atrim=0:2.000000,asetpts=PTS-STARTPTS,adelay=6000:all=1[timeline_audio_1]`,
+		`[timeline_audio_0][timeline_audio_1]amix=inputs=2:duration=longest:normalize=0,apad[final_audio]`,
+		`[3:v]trim=0:3.000000,setpts=PTS-STARTPTS+4.000000/TB[timeline_clip_0]`,
+		`[0:v][timeline_clip_0]overlay=100:50:eof_action=pass:enable='between(t\,4.000000\,7.000000)'[timeline_overlay_0]`,
+		`[4:v]trim=0:5.000000,setpts=PTS-STARTPTS+5.000000/TB[timeline_clip_1]`,
+		`[timeline_overlay_0][timeline_clip_1]overlay=0:0:eof_action=pass:enable='between(t\,5.000000\,10.000000)'[timeline_overlay_1]`,
+		`[timeline_overlay_1]drawtext=text='50\% off\: today’s deal':x=10:y=20:fontsize=48:fontcolor=white:enable='between(t\,1.000000\,3.000000)'[timeline_text_0]`,
+	}
+	if got := strings.Split(argValue(cmd.Args, "-filter_complex"), ";"); !reflect.DeepEqual(got, wantFilters) {
+		t.Errorf("filters =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantFilters, "\n"))
+	}
+
+	// The output runs to the end of the last clip, past the base video
+	if cmd.Duration != 10 || argValue(cmd.Args, "-t") != "10.00" {
+		t.Errorf("duration = %v (-t %s), want 10", cmd.Duration, argValue(cmd.Args, "-t"))
+	}
+	if got := countArgs(cmd.Args, "-map")["-map"]; got != 2 {
+		t.Errorf("%d -map args, want video and audio", got)
+	}
+}
+
+func TestBuildTimelineCommandWithoutAudio(t *testing.T) {
+	project := models.VideoProject{Timeline: &models.Timeline{Tracks: []models.TimelineTrack{
+		{Type: models.TrackTypeVideo, Clips: []models.TimelineClip{{Src: "https://example.com/bg.mp4", Duration: 6}}},
+	}}}
+
+	cmd, err := newTestService(&app.Config{}).BuildCommand(&models.VideoConfigArray{project})
+	if err != nil {
+		t.Fatalf("BuildCommand() error = %v", err)
+	}
+	for _, arg := range cmd.Args {
+		if arg == "-filter_complex" || arg == "[final_audio]" {
+			t.Errorf("timeline without audio or overlays has %s: %q", arg, cmd.Args)
+		}
+	}
+	if argValue(cmd.Args, "-map") != videoInputRef {
+		t.Errorf("-map = %q, want %q", argValue(cmd.Args, "-map"), videoInputRef)
+	}
+}
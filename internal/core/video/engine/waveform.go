@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/activadee/videocraft/internal/api/models"
+)
+
+const (
+	waveformSourceRef  = "waveform_source"
+	waveformAudioRef   = "waveform_audio"
+	waveformOverlayRef = "waveform_overlay"
+
+	// Default waveform overlay size and distance from the frame edge
+	defaultWaveformWidth  = 640
+	defaultWaveformHeight = 120
+	waveformMargin        = 20
+)
+
+// audioChainOutput returns the label the audio chain ends in: [final_audio],
+// or the waveform source that splitAudioForWaveform forks when a waveform is drawn
+func audioChainOutput(project models.VideoProject, audioElements []models.Element) string {
+	if hasWaveform(project, audioElements) {
+		return waveformSourceRef
+	}
+	return finalAudioRef
+}
+
+// hasWaveform reports whether the project draws a waveform of its narration
+func hasWaveform(project models.VideoProject, audioElements []models.Element) bool {
+	return project.Waveform != nil && len(audioElements) > 0
+}
+
+// splitAudioForWaveform forks the waveform source so the waveform node can
+// consume a copy while [final_audio] stays available for output mapping
+func (s *service) splitAudioForWaveform(filters *[]string) {
+	*filters = append(*filters, fmt.Sprintf("[%s]asplit=2[%s][%s]", waveformSourceRef, finalAudioRef, waveformAudioRef))
+}
+
+// addWaveformOverlayFilter renders the audio with showwaves and overlays it on
+// the current video at the configured anchor. Returns the new video label.
+func (s *service) addWaveformOverlayFilter(filters *[]string, waveform *models.WaveformOverlay, currentInput string) string {
+	width, height := waveform.Width, waveform.Height
+	if width == 0 {
+		width = defaultWaveformWidth
+	}
+	if height == 0 {
+		height = defaultWaveformHeight
+	}
+
+	color := "0xFFFFFF"
+	if waveform.Color != "" {
+		color = "0x" + strings.TrimPrefix(waveform.Color, "#")
+	}
+
+	*filters = append(*filters, fmt.Sprintf("[%s]showwaves=s=%dx%d:mode=cline:colors=%s:rate=25[waveform]",
+		waveformAudioRef, width, height, color))
+
+	x, y := waveformPosition(waveform.Position)
+	*filters = append(*filters, fmt.Sprintf("[%s][waveform]overlay=%s:%s[%s]",
+		currentInput, x, y, waveformOverlayRef))
+
+	s.log.Debugf("Waveform overlay: %dx%d at %s", width, height, waveform.Position)
+	return waveformOverlayRef
+}
+
+// waveformPosition maps a subtitle-style anchor ("center-bottom", ...) to overlay coordinates
+func waveformPosition(position string) (string, string) {
+	horizontal, vertical, _ := strings.Cut(position, "-")
+
+	x := "(W-w)/2"
+	switch horizontal {
+	case "left":
+		x = fmt.Sprintf("%d", waveformMargin)
+	case "right":
+		x = fmt.Sprintf("W-w-%d", waveformMargin)
+	}
+
+	y := fmt.Sprintf("H-h-%d", waveformMargin)
+	switch vertical {
+	case "top":
+		y = fmt.Sprintf("%d", waveformMargin)
+	case "center":
+		y = "(H-h)/2"
+	}
+
+	return x, y
+}
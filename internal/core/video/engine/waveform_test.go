@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+)
+
+func TestWaveformSplitsAudioChainOutput(t *testing.T) {
+	narration := []models.Element{{Type: "audio", Src: "a.mp3"}, {Type: "audio", Src: "b.mp3"}}
+	background := backgroundStream{ref: "0:v"}
+	inputs := inputIndexes{audio: 1, image: 3}
+
+	tests := []struct {
+		name  string
+		music *musicTrack
+		want  string
+	}{
+		{
+			name: "narration only",
+			want: "[concatenated_audio]apad=pad_dur=2[waveform_source]",
+		},
+		{
+			name: "with background music",
+			music: &musicTrack{
+				element: models.Element{Type: "audio", Role: models.RoleBackgroundMusic, Volume: 0.3},
+				input:   3,
+			},
+			want: "[narration_audio][music_audio]amix=inputs=2:duration=first:dropout_transition=0:normalize=0[waveform_source]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(&app.Config{Audio: app.AudioConfig{TailPadding: 2}})
+			project := models.VideoProject{Waveform: &models.WaveformOverlay{Position: "left-top", Color: "#FF0000"}}
+
+			filters := strings.Split(s.buildFilterComplexWithSceneTiming(project, background, tt.music, inputs, narration, nil, nil, 10), ";")
+
+			want := []string{
+				tt.want,
+				"[waveform_source]asplit=2[final_audio][waveform_audio]",
+				"[waveform_audio]showwaves=s=640x120:mode=cline:colors=0xFF0000:rate=25[waveform]",
+				"[0:v][waveform]overlay=20:20[waveform_overlay]",
+			}
+			if got := filters[len(filters)-len(want):]; strings.Join(got, ";") != strings.Join(want, ";") {
+				t.Errorf("filters =\n%s\nwant suffix\n%s", strings.Join(filters, "\n"), strings.Join(want, "\n"))
+			}
+
+			finals := 0
+			for _, filter := range filters {
+				if strings.Contains(filter, "[final_audio]") {
+					finals++
+				}
+			}
+			if finals != 1 {
+				t.Errorf("[final_audio] appears in %d filters, want 1: %v", finals, filters)
+			}
+		})
+	}
+}
+
+func TestAudioChainEndsInFinalAudioWithoutWaveform(t *testing.T) {
+	s := newTestService(&app.Config{Audio: app.AudioConfig{TailPadding: 2}})
+	narration := []models.Element{{Type: "audio", Src: "a.mp3"}}
+
+	graph := s.buildFilterComplexWithSceneTiming(models.VideoProject{}, backgroundStream{ref: "0:v"}, nil, inputIndexes{audio: 1, image: 2}, narration, nil, nil, 10)
+
+	if !strings.HasSuffix(graph, "[1:a]apad=pad_dur=2[final_audio]") {
+		t.Errorf("audio chain should end in [final_audio]: %s", graph)
+	}
+	for _, unwanted := range []string{"asplit", "showwaves", waveformSourceRef} {
+		if strings.Contains(graph, unwanted) {
+			t.Errorf("graph without waveform contains %q: %s", unwanted, graph)
+		}
+	}
+}
+
+func TestWaveformPosition(t *testing.T) {
+	tests := []struct {
+		position string
+		x, y     string
+	}{
+		{"", "(W-w)/2", "H-h-20"},
+		{"center-bottom", "(W-w)/2", "H-h-20"},
+		{"left-top", "20", "20"},
+		{"right-center", "W-w-20", "(H-h)/2"},
+		{"right-bottom", "W-w-20", "H-h-20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.position, func(t *testing.T) {
+			x, y := waveformPosition(tt.position)
+			if x != tt.x || y != tt.y {
+				t.Errorf("waveformPosition(%q) = %s, %s, want %s, %s", tt.position, x, y, tt.x, tt.y)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package errors
 
-import "fmt"
+import (
+	stderrors "errors"
+	"fmt"
+	"time"
+)
 
 // Custom error types for the application
 
@@ -33,6 +37,9 @@ const (
 	ErrCodeDownloadFailed      = "DOWNLOAD_FAILED"
 	ErrCodeTimeout             = "TIMEOUT"
 	ErrCodeInternalError       = "INTERNAL_ERROR"
+	ErrCodeConflict            = "CONFLICT"
+
+	ErrCodeTranscriptionUnavailable = "TRANSCRIPTION_UNAVAILABLE"
 )
 
 // Error constructors
@@ -52,12 +59,31 @@ func FFmpegFailed(err error) *VideoProcessingError {
 		map[string]interface{}{"original_error": err.Error()})
 }
 
+// FFmpegFailedWithLog is FFmpegFailed with the tail of FFmpeg's log in the details
+func FFmpegFailedWithLog(err error, log string) *VideoProcessingError {
+	e := FFmpegFailed(err)
+	e.Details["ffmpeg_log"] = log
+	return e
+}
+
 func TranscriptionFailed(err error) *VideoProcessingError {
 	return NewVideoProcessingError(ErrCodeTranscriptionFailed,
 		fmt.Sprintf("Audio transcription failed: %v", err),
 		map[string]interface{}{"original_error": err.Error()})
 }
 
+// TranscriptionUnavailable reports that transcription is failing fast after
+// repeated daemon startup failures; retryAfter is when it will be tried again
+func TranscriptionUnavailable(retryAfter time.Duration, cause error) *VideoProcessingError {
+	details := map[string]interface{}{"retry_after": retryAfter.String()}
+	if cause != nil {
+		details["original_error"] = cause.Error()
+	}
+	return NewVideoProcessingError(ErrCodeTranscriptionUnavailable,
+		fmt.Sprintf("Transcription unavailable: the Whisper daemon failed to start repeatedly, retrying in %s", retryAfter.Round(time.Second)),
+		details)
+}
+
 func JobNotFound(jobID string) *VideoProcessingError {
 	return NewVideoProcessingError(ErrCodeJobNotFound,
 		fmt.Sprintf("Job not found: %s", jobID),
@@ -88,6 +114,10 @@ func Timeout(operation string, timeout string) *VideoProcessingError {
 		})
 }
 
+func Conflict(message string) *VideoProcessingError {
+	return NewVideoProcessingError(ErrCodeConflict, message, nil)
+}
+
 func InternalError(err error) *VideoProcessingError {
 	return NewVideoProcessingError(ErrCodeInternalError,
 		fmt.Sprintf("Internal server error: %v", err),
@@ -113,6 +143,9 @@ var clientErrorMessages = map[string]string{
 	ErrCodeInvalidInput:        "Invalid request format",
 	ErrCodeJobNotFound:         "The requested job could not be found. It may have been completed or removed.",
 	ErrCodeInternalError:       "An internal error occurred. Please try again later or contact support.",
+	ErrCodeConflict:            "The requested resource already exists.",
+
+	ErrCodeTranscriptionUnavailable: "Audio transcription is temporarily unavailable. Please try again later.",
 }
 
 // SanitizeForClient returns a user-friendly error message safe for client consumption
@@ -141,6 +174,29 @@ func GetErrorCode(err *VideoProcessingError) string {
 	return err.Code
 }
 
+// retryableCodes are error codes of failures that may succeed when retried
+var retryableCodes = map[string]bool{
+	ErrCodeFFmpegFailed:        true,
+	ErrCodeDownloadFailed:      true,
+	ErrCodeTimeout:             true,
+	ErrCodeStorageFailed:       true,
+	ErrCodeTranscriptionFailed: true,
+	ErrCodeInternalError:       true,
+
+	ErrCodeTranscriptionUnavailable: true,
+}
+
+// IsRetryable reports whether err, or an error it wraps, is a
+// VideoProcessingError with a transient error code. Invalid input and
+// errors without a code are not retryable.
+func IsRetryable(err error) bool {
+	var vpe *VideoProcessingError
+	if !stderrors.As(err, &vpe) {
+		return false
+	}
+	return retryableCodes[vpe.Code]
+}
+
 // GetLogContext returns structured context for logging
 func GetLogContext(err error) map[string]interface{} {
 	logContext := make(map[string]interface{})
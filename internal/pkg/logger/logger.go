@@ -24,6 +24,10 @@ type Logger interface {
 	WithField(key string, value interface{}) Logger
 	WithFields(fields map[string]interface{}) Logger
 	WithError(err error) Logger
+
+	// WithLevel returns a logger writing to the same output at another level,
+	// e.g. to debug a single job while others keep the configured level
+	WithLevel(level string) Logger
 }
 
 type logger struct {
@@ -32,75 +36,40 @@ type logger struct {
 
 func New(level string) Logger {
 	// Parse log level
-	var logLevel slog.Level
-	switch level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
-	}
+	logLevel := parseLevel(level)
 
-	// Create handler options
+	// Create handler options; the level is enforced by the logger so that
+	// WithLevel can lower it
 	opts := &slog.HandlerOptions{
-		Level:     logLevel,
+		Level:     slog.LevelDebug,
 		AddSource: true, // Add source file and line number
 	}
 
 	// Create text handler for readable output
 	handler := slog.NewTextHandler(os.Stdout, opts)
 
-	return &logger{slog: slog.New(handler)}
+	return newLogger(handler, logLevel)
 }
 
 // NewJSON creates a logger with JSON output format
 func NewJSON(level string) Logger {
-	var logLevel slog.Level
-	switch level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
-	}
+	logLevel := parseLevel(level)
 
 	opts := &slog.HandlerOptions{
-		Level:     logLevel,
+		Level:     slog.LevelDebug,
 		AddSource: true,
 	}
 
 	handler := slog.NewJSONHandler(os.Stdout, opts)
-	return &logger{slog: slog.New(handler)}
+	return newLogger(handler, logLevel)
 }
 
 // NewWithWriter creates a logger with custom writer
 func NewWithWriter(level string, writer io.Writer, format string) Logger {
-	var logLevel slog.Level
-	switch level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
-	}
+	logLevel := parseLevel(level)
 
 	opts := &slog.HandlerOptions{
-		Level:     logLevel,
+		Level:     slog.LevelDebug,
 		AddSource: true,
 	}
 
@@ -111,7 +80,50 @@ func NewWithWriter(level string, writer io.Writer, format string) Logger {
 		handler = slog.NewTextHandler(writer, opts)
 	}
 
-	return &logger{slog: slog.New(handler)}
+	return newLogger(handler, logLevel)
+}
+
+// newLogger wraps handler, which must accept all levels, in a logger
+// discarding records below level
+func newLogger(handler slog.Handler, level slog.Level) Logger {
+	return &logger{slog: slog.New(&levelHandler{level: level, handler: handler})}
+}
+
+// parseLevel converts a configured level name, defaulting to info
+func parseLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// levelHandler filters records below its level before passing them to the
+// wrapped handler, so loggers sharing one output can log at different levels
+type levelHandler struct {
+	level   slog.Level
+	handler slog.Handler
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level && h.handler.Enabled(ctx, level)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler.Handle(ctx, record)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{level: h.level, handler: h.handler.WithAttrs(attrs)}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{level: h.level, handler: h.handler.WithGroup(name)}
 }
 
 func (l *logger) Debug(args ...interface{}) {
@@ -173,6 +185,15 @@ func (l *logger) WithError(err error) Logger {
 	return l.WithField("error", err.Error())
 }
 
+// WithLevel returns a logger with the same fields logging at level
+func (l *logger) WithLevel(level string) Logger {
+	handler := l.slog.Handler()
+	if lh, ok := handler.(*levelHandler); ok {
+		handler = lh.handler
+	}
+	return newLogger(handler, parseLevel(level))
+}
+
 // formatArgs converts variadic args to a single string message
 func formatArgs(args ...interface{}) string {
 	if len(args) == 0 {
@@ -200,6 +221,7 @@ func (nl *noopLogger) Fatalf(format string, args ...interface{})       {}
 func (nl *noopLogger) WithField(key string, value interface{}) Logger  { return nl }
 func (nl *noopLogger) WithFields(fields map[string]interface{}) Logger { return nl }
 func (nl *noopLogger) WithError(err error) Logger                      { return nl }
+func (nl *noopLogger) WithLevel(level string) Logger                   { return nl }
 
 // NewNoop creates a no-op logger for testing
 func NewNoop() Logger {
@@ -214,6 +236,23 @@ func NewFromConfig(level, format string) Logger {
 	return New(level)
 }
 
+// contextKey carries a Logger in a context.Context
+type contextKey struct{}
+
+// NewContext returns a context carrying log, e.g. a job's logger for the
+// services processing that job
+func NewContext(ctx context.Context, log Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, log)
+}
+
+// FromContext returns the logger carried by ctx, or fallback
+func FromContext(ctx context.Context, fallback Logger) Logger {
+	if log, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return log
+	}
+	return fallback
+}
+
 // Context-aware logging methods for advanced use cases
 
 // DebugContext logs a debug message with context
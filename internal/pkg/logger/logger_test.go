@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWithLevelSharesOutputAndFields(t *testing.T) {
+	var out bytes.Buffer
+	base := NewWithWriter("error", &out, "text")
+	job := base.WithField("job_id", "job-1")
+	debug := job.WithLevel("debug")
+
+	base.Debug("service debug")
+	job.Info("job info")
+	debug.Debug("job debug")
+	job.Debug("job debug after WithLevel")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("logged %d lines, want only the debug logger's:\n%s", len(lines), out.String())
+	}
+	for _, want := range []string{"level=DEBUG", `msg="job debug"`, "job_id=job-1"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("line %q does not contain %q", lines[0], want)
+		}
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	fallback := NewWithWriter("info", io.Discard, "text")
+	jobLog := fallback.WithField("job_id", "job-1")
+
+	if got := FromContext(context.Background(), fallback); got != fallback {
+		t.Error("FromContext() without a logger did not return the fallback")
+	}
+	if got := FromContext(NewContext(context.Background(), jobLog), fallback); got != jobLog {
+		t.Error("FromContext() did not return the logger in the context")
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	domainErrors "github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+// DownloadPathPrefix is the API route serving signed local download links
+const DownloadPathPrefix = "/api/v1/downloads/"
+
+// downloadSignature authenticates a video ID and expiry with secret
+func downloadSignature(secret, videoID string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%d", videoID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyDownloadSignature reports whether the expires and signature query
+// values of a signed download link are authentic for videoID and unexpired
+func VerifyDownloadSignature(secret, videoID, expires, signature string) bool {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+	expected := downloadSignature(secret, videoID, expiresAt)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// checkDownloadTTL rejects lifetimes outside (0, storage.download_url_max_ttl]
+func (s *storageService) checkDownloadTTL(ttl time.Duration) error {
+	if ttl <= 0 || ttl > s.cfg.Storage.DownloadURLMaxTTL {
+		return domainErrors.InvalidInput(fmt.Sprintf("download URL lifetime must be between 1s and %s", s.cfg.Storage.DownloadURLMaxTTL))
+	}
+	return nil
+}
+
+// GetDownloadURL returns an API path that serves the video without an API
+// key until ttl elapses. The link is signed with storage.download_url_secret
+// and verified by the download handler.
+func (s *storageService) GetDownloadURL(videoID string, ttl time.Duration) (string, error) {
+	if err := s.checkDownloadTTL(ttl); err != nil {
+		return "", err
+	}
+
+	// Resolving the video applies the output directory bounds checks
+	if _, err := s.GetVideo(videoID); err != nil {
+		return "", err
+	}
+
+	expires := time.Now().Add(ttl).Unix()
+	query := url.Values{
+		"expires":   {strconv.FormatInt(expires, 10)},
+		"signature": {downloadSignature(s.cfg.Storage.DownloadURLSecret, videoID, expires)},
+	}
+	return DownloadPathPrefix + url.PathEscape(videoID) + "?" + query.Encode(), nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetDownloadURLIsVerifiable(t *testing.T) {
+	s := newTestStorage(t, "")
+	s.cfg.Storage.DownloadURLSecret = "download-secret"
+	s.cfg.Storage.DownloadURLMaxTTL = time.Hour
+	if _, err := s.StoreVideoWithID(writeRender(t, s, "video"), "launch", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := s.GetDownloadURL("launch", 10*time.Minute)
+	if err != nil {
+		t.Fatalf("GetDownloadURL() error = %v", err)
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Path != DownloadPathPrefix+"launch" {
+		t.Errorf("path = %q, want %q", parsed.Path, DownloadPathPrefix+"launch")
+	}
+	expires, signature := parsed.Query().Get("expires"), parsed.Query().Get("signature")
+
+	tampered := []byte(signature)
+	tampered[0] ^= 1
+	later, _ := strconv.ParseInt(expires, 10, 64)
+
+	tests := []struct {
+		name      string
+		secret    string
+		videoID   string
+		expires   string
+		signature string
+		want      bool
+	}{
+		{"valid", "download-secret", "launch", expires, signature, true},
+		{"tampered signature", "download-secret", "launch", expires, string(tampered), false},
+		{"other video", "download-secret", "other", expires, signature, false},
+		{"extended expiry", "download-secret", "launch", strconv.FormatInt(later+3600, 10), signature, false},
+		{"other secret", "rotated-secret", "launch", expires, signature, false},
+		{"malformed expiry", "download-secret", "launch", "soon", signature, false},
+		{"missing signature", "download-secret", "launch", expires, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyDownloadSignature(tt.secret, tt.videoID, tt.expires, tt.signature); got != tt.want {
+				t.Errorf("VerifyDownloadSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyDownloadSignatureRejectsExpiredLink(t *testing.T) {
+	expires := time.Now().Add(-time.Second).Unix()
+	signature := downloadSignature("download-secret", "launch", expires)
+	if VerifyDownloadSignature("download-secret", "launch", strconv.FormatInt(expires, 10), signature) {
+		t.Error("VerifyDownloadSignature() accepted an expired link")
+	}
+}
+
+func TestGetDownloadURLRejects(t *testing.T) {
+	s := newTestStorage(t, "")
+	s.cfg.Storage.DownloadURLSecret = "download-secret"
+	s.cfg.Storage.DownloadURLMaxTTL = time.Hour
+	if _, err := s.StoreVideoWithID(writeRender(t, s, "video"), "launch", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		videoID string
+		ttl     time.Duration
+		wantErr string
+	}{
+		{"zero lifetime", "launch", 0, "lifetime"},
+		{"lifetime above maximum", "launch", 2 * time.Hour, "lifetime"},
+		{"missing video", "missing", time.Minute, ""},
+		{"path traversal", "../launch", time.Minute, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.GetDownloadURL(tt.videoID, tt.ttl)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GetDownloadURL() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package services
+
+import "sync"
+
+// idLocks is a keyed mutex serializing operations on the same ID. Entries
+// are reference counted and removed once no caller holds or awaits them.
+type idLocks struct {
+	mu    sync.Mutex
+	locks map[string]*idLock
+}
+
+type idLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock blocks until the caller holds the lock for id and returns its unlock function
+func (l *idLocks) lock(id string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*idLock)
+	}
+	entry, ok := l.locks[id]
+	if !ok {
+		entry = &idLock{}
+		l.locks[id] = entry
+	}
+	entry.refs++
+	l.mu.Unlock()
+
+	entry.mu.Lock()
+	return func() {
+		entry.mu.Unlock()
+
+		l.mu.Lock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(l.locks, id)
+		}
+		l.mu.Unlock()
+	}
+}
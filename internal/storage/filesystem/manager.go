@@ -3,11 +3,14 @@ package services
 import (
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,24 +23,53 @@ import (
 
 // Service provides file storage capabilities
 type Service interface {
-	StoreVideo(videoPath string) (string, error)
+	StoreVideo(videoPath, subdir string) (string, error)
+	StoreVideoWithID(videoPath, desiredID, subdir string) (string, error)
+	StoreSubtitle(subtitlePath, videoID string) (string, error)
+	StoreCaption(captionPath, videoID, format string) (string, error)
+	StoreManifest(data []byte, videoID string) (string, error)
+	VideoExists(videoID string) bool
 	GetVideo(videoID string) (string, error)
+	GetVideoMetadata(videoID string) (VideoMetadata, error)
+	GetDownloadURL(videoID string, ttl time.Duration) (string, error)
 	DeleteVideo(videoID string) error
 	ListVideos() ([]models.VideoInfo, error)
-	CleanupOldFiles() error
+	CleanupOldFiles(activeSince time.Time) (int, error)
+	CleanupHealth() error
 }
 
 type storageService struct {
 	cfg *app.Config
 	log logger.Logger
+
+	// Serializes stores to the same output ID so concurrent jobs resolve
+	// collisions one after another instead of writing the same file
+	outputLocks idLocks
+
+	// Consecutive cleanup runs that failed to delete at least one file
+	cleanupMu       sync.Mutex
+	cleanupFailures int
+	lastCleanupErr  error
+
+	// remove deletes an expired file during cleanup
+	remove func(name string) error
 }
 
-// NewService creates a new storage service
+func newStorageService(cfg *app.Config, log logger.Logger) *storageService {
+	return &storageService{cfg: cfg, log: log, remove: os.Remove}
+}
+
+// NewService creates the storage service selected by storage.backend
 func NewService(cfg *app.Config, log logger.Logger) Service {
-	return &storageService{
-		cfg: cfg,
-		log: log,
+	if cfg.Storage.Backend == app.StorageBackendS3 {
+		service, err := newS3Service(cfg, log)
+		if err == nil {
+			return service
+		}
+		log.Errorf("Failed to initialize S3 storage, falling back to filesystem: %v", err)
 	}
+
+	return newStorageService(cfg, log)
 }
 
 // Security patterns for path validation
@@ -52,17 +84,237 @@ var (
 	validVideoIDRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
 )
 
-func (s *storageService) StoreVideo(videoPath string) (string, error) {
+// Subtitle sidecars share the output directory with videos
+const subtitleIDSuffix = "-subtitles"
+
+var subtitleExtensions = map[string]bool{".ass": true, ".srt": true, ".vtt": true, ".json": true}
+
+// Render manifests are stored as "<videoID>-manifest.json"
+const (
+	manifestIDSuffix  = "-manifest"
+	manifestExtension = ".json"
+)
+
+// StoreVideo stores a video under a generated ID in the given output
+// subdirectory ("" for the output directory itself)
+func (s *storageService) StoreVideo(videoPath, subdir string) (string, error) {
 	s.log.Debugf("Storing video: %s", videoPath)
 
+	dir, err := s.ensureOutputSubdir(subdir)
+	if err != nil {
+		return "", err
+	}
+
 	// Generate unique video ID
-	videoID := uuid.New().String()
+	return s.storeVideoAs(videoPath, uuid.New().String(), dir)
+}
+
+// StoreVideoWithID stores a video under a client-supplied ID, resolving collisions
+// with an existing video according to the configured output collision policy.
+// IDs are unique across output subdirectories.
+func (s *storageService) StoreVideoWithID(videoPath, desiredID, subdir string) (string, error) {
+	s.log.Debugf("Storing video %s with desired ID: %s", videoPath, desiredID)
+
+	videoID, err := s.sanitizeVideoID(desiredID)
+	if err != nil {
+		return "", domainErrors.InvalidInput(fmt.Sprintf("invalid output ID: %v", err))
+	}
+
+	dir, err := s.ensureOutputSubdir(subdir)
+	if err != nil {
+		return "", err
+	}
+
+	// The collision check and the store must not interleave with another
+	// store to this ID; the later job then meets the earlier one's video
+	// and the collision policy decides its outcome
+	unlock := s.outputLocks.lock(videoID)
+	defer unlock()
+
+	existing, err := s.findVideoFiles(videoID)
+	if err != nil {
+		return "", err
+	}
+
+	if len(existing) > 0 {
+		switch s.cfg.Storage.OutputCollisionPolicy {
+		case app.CollisionPolicyOverwrite:
+			s.log.Infof("Overwriting existing video: %s", videoID)
+			for _, path := range existing {
+				if err := os.Remove(path); err != nil {
+					return "", domainErrors.StorageFailed(err)
+				}
+			}
+		case app.CollisionPolicyVersion:
+			versionedID, err := s.nextVersionedID(videoID)
+			if err != nil {
+				return "", err
+			}
+			s.log.Infof("Video %s exists, storing as version: %s", videoID, versionedID)
+			videoID = versionedID
+		default:
+			return "", domainErrors.Conflict(fmt.Sprintf("video already exists: %s", videoID))
+		}
+	}
+
+	return s.storeVideoAs(videoPath, videoID, dir)
+}
+
+// VideoExists reports whether a video with the given ID is already stored
+func (s *storageService) VideoExists(videoID string) bool {
+	sanitizedID, err := s.sanitizeVideoID(videoID)
+	if err != nil {
+		return false
+	}
+	matches, err := s.findVideoFiles(sanitizedID)
+	return err == nil && len(matches) > 0
+}
+
+// findVideoFiles returns stored files for a sanitized video ID regardless of
+// extension or output subdirectory
+func (s *storageService) findVideoFiles(videoID string) ([]string, error) {
+	var matches []string
+	for _, dir := range s.outputDirs() {
+		found, err := filepath.Glob(filepath.Join(dir, videoID+".*"))
+		if err != nil {
+			return nil, domainErrors.StorageFailed(err)
+		}
+		matches = append(matches, found...)
+	}
+	return matches, nil
+}
+
+// videoDir returns the directory a stored video lives in so its sidecars
+// are kept next to it, or the output directory if it is not stored
+func (s *storageService) videoDir(videoID string) string {
+	matches, err := s.findVideoFiles(videoID)
+	if err != nil || len(matches) == 0 {
+		return s.cfg.Storage.OutputDir
+	}
+	return filepath.Dir(matches[0])
+}
+
+// outputSubdirOf returns the output subdirectory of a directory below the
+// output directory ("" for the output directory itself). ok is false when a
+// path segment is not a valid video ID or the directory lies deeper than
+// models.MaxOutputSubdirDepth, so such directories are never searched.
+func (s *storageService) outputSubdirOf(dir string) (subdir string, ok bool) {
+	rel, err := filepath.Rel(s.cfg.Storage.OutputDir, dir)
+	if err != nil {
+		return "", false
+	}
+	if rel == "." {
+		return "", true
+	}
+
+	segments := strings.Split(rel, string(filepath.Separator))
+	if len(segments) > models.MaxOutputSubdirDepth {
+		return "", false
+	}
+	for _, segment := range segments {
+		if !validVideoIDRegex.MatchString(segment) {
+			return "", false
+		}
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// outputDirs returns the output directory followed by its output
+// subdirectories. Symbolic links are not followed.
+func (s *storageService) outputDirs() []string {
+	root := s.cfg.Storage.OutputDir
+	if _, err := os.Stat(root); err != nil {
+		return nil
+	}
+
+	var dirs []string
+	walkErr := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			s.log.Warnf("Failed to read output directory %s: %v", path, err)
+			return fs.SkipDir
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if _, ok := s.outputSubdirOf(path); !ok {
+			return fs.SkipDir
+		}
+		dirs = append(dirs, path)
+		return nil
+	})
+	if walkErr != nil {
+		s.log.Warnf("Failed to walk output directory: %v", walkErr)
+	}
+	return dirs
+}
 
-	// Ensure output directory exists
-	if err := os.MkdirAll(s.cfg.Storage.OutputDir, 0755); err != nil {
+// ensureOutputSubdir validates an output subdirectory with the video ID
+// rules applied to every segment, creates it and returns its path
+func (s *storageService) ensureOutputSubdir(subdir string) (string, error) {
+	root := s.cfg.Storage.OutputDir
+	if err := os.MkdirAll(root, s.cfg.Storage.DirPerm()); err != nil {
 		return "", domainErrors.StorageFailed(err)
 	}
+	if subdir == "" {
+		return root, nil
+	}
+
+	if err := s.validateVideoID(subdir); err != nil {
+		s.logSecurityViolation("Invalid output subdirectory provided", map[string]interface{}{
+			"subdir": subdir,
+			"error":  err.Error(),
+		})
+		return "", domainErrors.InvalidInput(fmt.Sprintf("invalid output subdirectory: %v", err))
+	}
+
+	dir := filepath.Join(root, filepath.FromSlash(subdir))
+	if got, ok := s.outputSubdirOf(dir); !ok || got != subdir {
+		return "", domainErrors.InvalidInput(fmt.Sprintf("invalid output subdirectory: %s", subdir))
+	}
+	// Existing segments must be real directories; a symbolic link could
+	// lead outside the output directory
+	current := root
+	for _, segment := range strings.Split(subdir, "/") {
+		current = filepath.Join(current, segment)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return "", domainErrors.StorageFailed(err)
+		}
+		if !info.IsDir() {
+			s.logSecurityViolation("Output subdirectory is not a directory", map[string]interface{}{
+				"subdir": subdir,
+				"path":   current,
+			})
+			return "", domainErrors.InvalidInput(fmt.Sprintf("invalid output subdirectory: %s", subdir))
+		}
+	}
+
+	if err := os.MkdirAll(dir, s.cfg.Storage.DirPerm()); err != nil {
+		return "", domainErrors.StorageFailed(err)
+	}
+	return dir, nil
+}
+
+// nextVersionedID appends the first free "-vN" suffix to a video ID
+func (s *storageService) nextVersionedID(videoID string) (string, error) {
+	for version := 2; version < 10000; version++ {
+		candidate := fmt.Sprintf("%s-v%d", videoID, version)
+		matches, err := s.findVideoFiles(candidate)
+		if err != nil {
+			return "", err
+		}
+		if len(matches) == 0 {
+			return candidate, nil
+		}
+	}
+	return "", domainErrors.Conflict(fmt.Sprintf("no free version left for video: %s", videoID))
+}
 
+// storeVideoAs moves a rendered video into dir under the given ID
+func (s *storageService) storeVideoAs(videoPath, videoID, dir string) (string, error) {
 	// Get file extension
 	ext := filepath.Ext(videoPath)
 	if ext == "" {
@@ -70,12 +322,16 @@ func (s *storageService) StoreVideo(videoPath string) (string, error) {
 	}
 
 	// Create destination path
-	destPath := filepath.Join(s.cfg.Storage.OutputDir, fmt.Sprintf("%s%s", videoID, ext))
+	destPath := filepath.Join(dir, fmt.Sprintf("%s%s", videoID, ext))
 
-	// Copy file to destination
-	if err := s.copyFile(videoPath, destPath); err != nil {
+	// Copy file to destination, checksumming it on the way
+	metadata, err := s.copyFile(videoPath, destPath)
+	if err != nil {
 		return "", domainErrors.StorageFailed(err)
 	}
+	if err := s.writeMetadata(dir, videoID, metadata); err != nil {
+		s.log.Warnf("Failed to write metadata for video %s: %v", videoID, err)
+	}
 
 	// Remove original temp file
 	if err := os.Remove(videoPath); err != nil {
@@ -86,6 +342,55 @@ func (s *storageService) StoreVideo(videoPath string) (string, error) {
 	return videoID, nil
 }
 
+// StoreSubtitle copies a subtitle file next to its video as "<videoID>-subtitles"
+// and returns that ID. The source file is left for the caller to clean up.
+func (s *storageService) StoreSubtitle(subtitlePath, videoID string) (string, error) {
+	return s.storeSubtitleAs(subtitlePath, videoID, videoID+subtitleIDSuffix)
+}
+
+// StoreCaption copies an exported caption file next to its video as
+// "<videoID>-<format>-subtitles" and returns that ID
+func (s *storageService) StoreCaption(captionPath, videoID, format string) (string, error) {
+	return s.storeSubtitleAs(captionPath, videoID, videoID+"-"+format+subtitleIDSuffix)
+}
+
+// storeSubtitleAs copies a subtitle file into the directory of videoID as subtitleID
+func (s *storageService) storeSubtitleAs(subtitlePath, videoID, subtitleID string) (string, error) {
+	if err := s.validateVideoID(subtitleID); err != nil {
+		return "", domainErrors.InvalidInput(fmt.Sprintf("invalid subtitle ID: %v", err))
+	}
+
+	ext := filepath.Ext(subtitlePath)
+	if !subtitleExtensions[ext] {
+		return "", domainErrors.InvalidInput(fmt.Sprintf("unsupported subtitle file type: %s", ext))
+	}
+
+	destPath := filepath.Join(s.videoDir(videoID), subtitleID+ext)
+	if _, err := s.copyFile(subtitlePath, destPath); err != nil {
+		return "", domainErrors.StorageFailed(err)
+	}
+
+	s.log.Infof("Subtitle sidecar stored with ID: %s", subtitleID)
+	return subtitleID, nil
+}
+
+// StoreManifest writes a render manifest next to its video as
+// "<videoID>-manifest" and returns that ID
+func (s *storageService) StoreManifest(data []byte, videoID string) (string, error) {
+	manifestID := videoID + manifestIDSuffix
+	if err := s.validateVideoID(manifestID); err != nil {
+		return "", domainErrors.InvalidInput(fmt.Sprintf("invalid manifest ID: %v", err))
+	}
+
+	destPath := filepath.Join(s.videoDir(videoID), manifestID+manifestExtension)
+	if err := os.WriteFile(destPath, data, s.cfg.Storage.FilePerm()); err != nil {
+		return "", domainErrors.StorageFailed(err)
+	}
+
+	s.log.Infof("Render manifest stored with ID: %s", manifestID)
+	return manifestID, nil
+}
+
 func (s *storageService) GetVideo(videoID string) (string, error) {
 	s.log.Debugf("Getting video: %s", videoID)
 
@@ -104,21 +409,10 @@ func (s *storageService) GetVideo(videoID string) (string, error) {
 		return "", err
 	}
 
-	// Build safe pattern within output directory
-	pattern := filepath.Join(s.cfg.Storage.OutputDir, sanitizedID+".*")
-
-	// Additional security check: ensure pattern is within output directory
-	if validateErr := s.validatePathWithinBounds(pattern, s.cfg.Storage.OutputDir); validateErr != nil {
-		s.logSecurityViolation("Path outside allowed directory", map[string]interface{}{
-			"pattern":    pattern,
-			"output_dir": s.cfg.Storage.OutputDir,
-		})
-		return "", errors.New("path traversal detected")
-	}
-
-	matches, err := filepath.Glob(pattern)
+	// Search the output directory and its output subdirectories
+	matches, err := s.findVideoFiles(sanitizedID)
 	if err != nil {
-		return "", domainErrors.StorageFailed(err)
+		return "", err
 	}
 
 	if len(matches) == 0 {
@@ -177,6 +471,10 @@ func (s *storageService) DeleteVideo(videoID string) error {
 	if err := os.Remove(videoPath); err != nil {
 		return domainErrors.StorageFailed(err)
 	}
+	metadataID := strings.TrimSuffix(filepath.Base(videoPath), filepath.Ext(videoPath))
+	if err := os.Remove(s.metadataPath(filepath.Dir(videoPath), metadataID)); err != nil && !os.IsNotExist(err) {
+		s.log.Warnf("Failed to remove metadata of video %s: %v", videoID, err)
+	}
 
 	s.log.Infof("Video deleted: %s", videoID)
 	return nil
@@ -185,11 +483,29 @@ func (s *storageService) DeleteVideo(videoID string) error {
 func (s *storageService) ListVideos() ([]models.VideoInfo, error) {
 	s.log.Debug("Listing videos")
 
-	pattern := filepath.Join(s.cfg.Storage.OutputDir, "*")
-	matches, err := filepath.Glob(pattern)
+	var videos []models.VideoInfo
+	for _, dir := range s.outputDirs() {
+		found, err := s.listVideosIn(dir)
+		if err != nil {
+			return nil, err
+		}
+		videos = append(videos, found...)
+	}
+	if videos == nil {
+		videos = []models.VideoInfo{}
+	}
+
+	s.log.Debugf("Found %d videos", len(videos))
+	return videos, nil
+}
+
+// listVideosIn lists the videos stored directly in one output directory
+func (s *storageService) listVideosIn(dir string) ([]models.VideoInfo, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*"))
 	if err != nil {
 		return nil, domainErrors.StorageFailed(err)
 	}
+	subdir, _ := s.outputSubdirOf(dir)
 
 	videos := make([]models.VideoInfo, 0, len(matches))
 
@@ -204,6 +520,11 @@ func (s *storageService) ListVideos() ([]models.VideoInfo, error) {
 		ext := filepath.Ext(filename)
 		videoID := strings.TrimSuffix(filename, ext)
 
+		// Subtitle sidecars and manifests are artifacts of a video, not videos themselves
+		if subtitleExtensions[ext] || ext == manifestExtension {
+			continue
+		}
+
 		// Get file info
 		fileInfo, err := os.Stat(match)
 		if err != nil {
@@ -212,54 +533,131 @@ func (s *storageService) ListVideos() ([]models.VideoInfo, error) {
 		}
 
 		video := models.VideoInfo{
-			ID:        videoID,
-			Filename:  filename,
-			Size:      fileInfo.Size(),
-			CreatedAt: fileInfo.ModTime().Format(time.RFC3339),
+			ID:          videoID,
+			Filename:    filename,
+			Size:        fileInfo.Size(),
+			CreatedAt:   fileInfo.ModTime().Format(time.RFC3339),
+			ContentType: contentTypeForExtension(ext),
+			Subdir:      subdir,
+		}
+		if metadata, err := s.readMetadata(dir, videoID); err == nil {
+			video.SHA256 = metadata.SHA256
+			video.ContentType = metadata.ContentType
 		}
 
 		videos = append(videos, video)
 	}
 
-	s.log.Debugf("Found %d videos", len(videos))
 	return videos, nil
 }
 
-func (s *storageService) CleanupOldFiles() error {
+// CleanupOldFiles removes files past retention from the output and temp
+// directories and returns how many it removed. activeSince is when the
+// oldest unfinished job was created (zero if there is none); files modified
+// since then may belong to it and are kept. Every failure of a run is
+// collected into the returned error, and repeated failing runs escalate to
+// an error-level alert.
+func (s *storageService) CleanupOldFiles(activeSince time.Time) (int, error) {
 	s.log.Debug("Starting cleanup of old files")
 
-	cutoffTime := time.Now().AddDate(0, 0, -s.cfg.Storage.RetentionDays)
+	cutoffTime := s.cleanupCutoff(activeSince)
 
-	// Cleanup output directory
-	if err := s.cleanupDirectory(s.cfg.Storage.OutputDir, cutoffTime); err != nil {
-		return err
+	// Cleanup output and temp directories, continuing past failures
+	var failures []error
+	removed := 0
+	for _, dir := range append(s.outputDirs(), s.cfg.Storage.TempDir) {
+		count, dirFailures := s.cleanupDirectory(dir, cutoffTime)
+		removed += count
+		failures = append(failures, dirFailures...)
+	}
+
+	return removed, s.finishCleanup(failures)
+}
+
+// cleanupCutoff returns the modification time before which files are removed:
+// the retention limit, moved back to activeSince when a job is still unfinished
+func (s *storageService) cleanupCutoff(activeSince time.Time) time.Time {
+	cutoffTime := time.Now().AddDate(0, 0, -s.cfg.Storage.RetentionDays)
+	if !activeSince.IsZero() && activeSince.Before(cutoffTime) {
+		return activeSince
 	}
+	return cutoffTime
+}
 
-	// Cleanup temp directory
-	if err := s.cleanupDirectory(s.cfg.Storage.TempDir, cutoffTime); err != nil {
+// finishCleanup records the outcome of a cleanup run
+func (s *storageService) finishCleanup(failures []error) error {
+	if len(failures) > 0 {
+		err := domainErrors.StorageFailed(fmt.Errorf("cleanup failed for %d files: %w", len(failures), errors.Join(failures...)))
+		s.recordCleanupFailure(err)
 		return err
 	}
 
+	s.cleanupMu.Lock()
+	s.cleanupFailures = 0
+	s.lastCleanupErr = nil
+	s.cleanupMu.Unlock()
+
 	s.log.Info("File cleanup completed")
 	return nil
 }
 
-func (s *storageService) cleanupDirectory(dir string, cutoffTime time.Time) error {
+// recordCleanupFailure counts a failed run and alerts once the threshold is reached
+func (s *storageService) recordCleanupFailure(err error) {
+	s.cleanupMu.Lock()
+	s.cleanupFailures++
+	s.lastCleanupErr = err
+	failures := s.cleanupFailures
+	s.cleanupMu.Unlock()
+
+	if failures >= s.cfg.Storage.CleanupFailureThreshold {
+		s.log.WithFields(map[string]interface{}{
+			"consecutive_failures": failures,
+			"output_dir":           s.cfg.Storage.OutputDir,
+			"temp_dir":             s.cfg.Storage.TempDir,
+		}).Errorf("File cleanup keeps failing, disk usage is not being reclaimed: %v", err)
+		return
+	}
+
+	s.log.Warnf("File cleanup failed (%d consecutive): %v", failures, err)
+}
+
+// CleanupHealth reports an error once cleanup has failed for the configured
+// number of consecutive runs
+func (s *storageService) CleanupHealth() error {
+	s.cleanupMu.Lock()
+	defer s.cleanupMu.Unlock()
+
+	if s.cleanupFailures >= s.cfg.Storage.CleanupFailureThreshold {
+		return fmt.Errorf("cleanup failed %d consecutive runs: %w", s.cleanupFailures, s.lastCleanupErr)
+	}
+	return nil
+}
+
+// cleanupDirectory deletes expired files in dir and returns how many it
+// deleted along with every failure
+func (s *storageService) cleanupDirectory(dir string, cutoffTime time.Time) (int, []error) {
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return nil // Directory doesn't exist, nothing to clean
+		return 0, nil // Directory doesn't exist, nothing to clean
 	}
 
 	pattern := filepath.Join(dir, "*")
 	matches, err := filepath.Glob(pattern)
 	if err != nil {
-		return domainErrors.StorageFailed(err)
+		return 0, []error{err}
 	}
 
+	var failures []error
+
 	deletedCount := 0
 
 	for _, match := range matches {
 		fileInfo, err := os.Stat(match)
 		if err != nil {
+			// A file removed since the glob needs no cleanup
+			if !os.IsNotExist(err) {
+				s.log.Warnf("Failed to inspect old file %s: %v", match, err)
+				failures = append(failures, err)
+			}
 			continue
 		}
 
@@ -270,11 +668,15 @@ func (s *storageService) cleanupDirectory(dir string, cutoffTime time.Time) erro
 
 		// Delete files older than cutoff time
 		if fileInfo.ModTime().Before(cutoffTime) {
-			if err := os.Remove(match); err != nil {
-				s.log.Warnf("Failed to delete old file %s: %v", match, err)
-			} else {
+			switch err := s.remove(match); {
+			case err == nil:
 				deletedCount++
 				s.log.Debugf("Deleted old file: %s", match)
+			case os.IsNotExist(err):
+				// Removed by someone else in the meantime
+			default:
+				s.log.Warnf("Failed to delete old file %s: %v", match, err)
+				failures = append(failures, err)
 			}
 		}
 	}
@@ -283,7 +685,7 @@ func (s *storageService) cleanupDirectory(dir string, cutoffTime time.Time) erro
 		s.log.Infof("Deleted %d old files from %s", deletedCount, dir)
 	}
 
-	return nil
+	return deletedCount, failures
 }
 
 // validateVideoID checks if video ID is safe and valid
@@ -391,30 +793,29 @@ func (s *storageService) logSecurityViolation(message string, fields map[string]
 	s.log.WithFields(fields).Errorf("SECURITY_VIOLATION: %s", message)
 }
 
-func (s *storageService) copyFile(src, dst string) error {
+func (s *storageService) copyFile(src, dst string) (VideoMetadata, error) {
 	sourceFile, err := os.Open(src)
 	if err != nil {
-		return err
+		return VideoMetadata{}, err
 	}
 	defer sourceFile.Close()
 
-	destFile, err := os.Create(dst)
+	perm := s.cfg.Storage.FilePerm()
+	destFile, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm)
 	if err != nil {
-		return err
+		return VideoMetadata{}, err
 	}
 	defer destFile.Close()
 
-	// Copy file contents
-	_, err = destFile.ReadFrom(sourceFile)
-	if err != nil {
-		return err
+	// Copy file contents, hashing and sniffing them in the same pass
+	recorder := newMetadataRecorder()
+	if _, err := io.Copy(io.MultiWriter(destFile, recorder), sourceFile); err != nil {
+		return VideoMetadata{}, err
 	}
 
-	// Copy file permissions
-	sourceInfo, err := os.Stat(src)
-	if err != nil {
-		return err
+	// Apply the configured permissions regardless of umask or an existing file
+	if err := os.Chmod(dst, perm); err != nil {
+		return VideoMetadata{}, err
 	}
-
-	return os.Chmod(dst, sourceInfo.Mode())
+	return recorder.metadata(filepath.Ext(dst)), nil
 }
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"bytes"
+	stderrors "errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/activadee/videocraft/internal/app"
+	domainErrors "github.com/activadee/videocraft/internal/pkg/errors"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+// newTestStorage returns a filesystem storage service in temp directories
+func newTestStorage(t *testing.T, collisionPolicy string) *storageService {
+	t.Helper()
+	cfg := &app.Config{}
+	cfg.Storage.OutputDir = t.TempDir()
+	cfg.Storage.TempDir = t.TempDir()
+	cfg.Storage.OutputCollisionPolicy = collisionPolicy
+	return NewService(cfg, logger.NewWithWriter("error", io.Discard, "text")).(*storageService)
+}
+
+// writeRender writes a rendered video to the temp directory, as FFmpeg would
+func writeRender(t *testing.T, s *storageService, content string) string {
+	t.Helper()
+	f, err := os.CreateTemp(s.cfg.Storage.TempDir, "render-*.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+// storedContent returns the content of a stored video
+func storedContent(t *testing.T, s *storageService, videoID string) string {
+	t.Helper()
+	path, err := s.GetVideo(videoID)
+	if err != nil {
+		t.Fatalf("GetVideo(%s) error = %v", videoID, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestStoreVideoWithIDCollisionPolicies(t *testing.T) {
+	tests := []struct {
+		policy      string
+		wantID      string
+		wantErr     bool
+		wantContent map[string]string
+	}{
+		{
+			policy:      app.CollisionPolicyReject,
+			wantErr:     true,
+			wantContent: map[string]string{"launch": "first"},
+		},
+		{
+			policy:      app.CollisionPolicyVersion,
+			wantID:      "launch-v2",
+			wantContent: map[string]string{"launch": "first", "launch-v2": "second"},
+		},
+		{
+			policy:      app.CollisionPolicyOverwrite,
+			wantID:      "launch",
+			wantContent: map[string]string{"launch": "second"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			s := newTestStorage(t, tt.policy)
+			if id, err := s.StoreVideoWithID(writeRender(t, s, "first"), "launch", ""); err != nil || id != "launch" {
+				t.Fatalf("first StoreVideoWithID() = %q, %v", id, err)
+			}
+
+			render := writeRender(t, s, "second")
+			id, err := s.StoreVideoWithID(render, "launch", "")
+			if tt.wantErr {
+				var vpe *domainErrors.VideoProcessingError
+				if !stderrors.As(err, &vpe) || vpe.Code != domainErrors.ErrCodeConflict {
+					t.Fatalf("StoreVideoWithID() error = %v, want conflict", err)
+				}
+				// A rejected render stays in place for the caller to clean up
+				if _, err := os.Stat(render); err != nil {
+					t.Errorf("rejected render removed: %v", err)
+				}
+			} else if err != nil || id != tt.wantID {
+				t.Fatalf("StoreVideoWithID() = %q, %v, want %q", id, err, tt.wantID)
+			}
+
+			for videoID, want := range tt.wantContent {
+				if got := storedContent(t, s, videoID); got != want {
+					t.Errorf("video %s = %q, want %q", videoID, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreVideoWithIDVersionsSkipTakenVersions(t *testing.T) {
+	s := newTestStorage(t, app.CollisionPolicyVersion)
+	var ids []string
+	for _, content := range []string{"first", "second", "third"} {
+		id, err := s.StoreVideoWithID(writeRender(t, s, content), "launch", "")
+		if err != nil {
+			t.Fatalf("StoreVideoWithID() error = %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	want := []string{"launch", "launch-v2", "launch-v3"}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("store %d got ID %q, want %q", i, ids[i], want[i])
+		}
+	}
+}
+
+func TestStoreVideoWithIDCollidesAcrossSubdirs(t *testing.T) {
+	s := newTestStorage(t, app.CollisionPolicyReject)
+	if _, err := s.StoreVideoWithID(writeRender(t, s, "first"), "launch", "campaign"); err != nil {
+		t.Fatalf("StoreVideoWithID() error = %v", err)
+	}
+	if _, err := s.StoreVideoWithID(writeRender(t, s, "second"), "launch", ""); err == nil {
+		t.Fatal("StoreVideoWithID() in another subdir error = nil, want conflict")
+	}
+	if path, err := s.GetVideo("launch"); err != nil || filepath.Base(filepath.Dir(path)) != "campaign" {
+		t.Errorf("GetVideo() = %q, %v, want the video in campaign", path, err)
+	}
+}
+
+func TestCleanupOldFilesReportsFailedRemovals(t *testing.T) {
+	var logs bytes.Buffer
+	s := newTestStorage(t, "")
+	s.log = logger.NewWithWriter("warn", &logs, "text")
+	s.cfg.Storage.RetentionDays = 1
+	s.cfg.Storage.CleanupFailureThreshold = 2
+
+	expired := time.Now().Add(-48 * time.Hour)
+	for _, name := range []string{"locked.mp4", "gone.mp4", "old.mp4", "fresh.mp4"} {
+		path := filepath.Join(s.cfg.Storage.TempDir, name)
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if name != "fresh.mp4" {
+			if err := os.Chtimes(path, expired, expired); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	locked := true
+	s.remove = func(name string) error {
+		switch filepath.Base(name) {
+		case "locked.mp4":
+			if locked {
+				return &os.PathError{Op: "remove", Path: name, Err: syscall.EACCES}
+			}
+		case "gone.mp4":
+			os.Remove(name)
+			return &os.PathError{Op: "remove", Path: name, Err: syscall.ENOENT}
+		}
+		return os.Remove(name)
+	}
+
+	for run, wantHealthy := range []bool{true, false} {
+		removed, err := s.CleanupOldFiles(time.Time{})
+		var vpe *domainErrors.VideoProcessingError
+		if !stderrors.As(err, &vpe) || vpe.Code != domainErrors.ErrCodeStorageFailed || !strings.Contains(err.Error(), "locked.mp4") {
+			t.Fatalf("run %d: CleanupOldFiles() error = %v, want a storage failure naming locked.mp4", run+1, err)
+		}
+		if want := 1 - run; removed != want {
+			t.Errorf("run %d: removed %d files, want %d", run+1, removed, want)
+		}
+		if healthErr := s.CleanupHealth(); (healthErr == nil) != wantHealthy {
+			t.Errorf("run %d: CleanupHealth() = %v, want healthy %v", run+1, healthErr, wantHealthy)
+		}
+	}
+	if !strings.Contains(logs.String(), "Failed to delete old file "+filepath.Join(s.cfg.Storage.TempDir, "locked.mp4")) {
+		t.Errorf("failed removal was not logged:\n%s", logs.String())
+	}
+
+	locked = false
+	if removed, err := s.CleanupOldFiles(time.Time{}); err != nil || removed != 1 {
+		t.Fatalf("CleanupOldFiles() = %d, %v, want the locked file removed", removed, err)
+	}
+	if err := s.CleanupHealth(); err != nil {
+		t.Errorf("CleanupHealth() after a clean run = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(s.cfg.Storage.TempDir, "fresh.mp4")); err != nil {
+		t.Errorf("file within retention was removed: %v", err)
+	}
+}
+
+func TestStoreSubtitleStoresSidecarNextToVideo(t *testing.T) {
+	s := newTestStorage(t, app.CollisionPolicyReject)
+	if _, err := s.StoreVideoWithID(writeRender(t, s, "video"), "launch", ""); err != nil {
+		t.Fatalf("StoreVideoWithID() error = %v", err)
+	}
+	subtitles := filepath.Join(s.cfg.Storage.TempDir, "subtitles_1234.ass")
+	if err := os.WriteFile(subtitles, []byte("[Script Info]"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := s.StoreSubtitle(subtitles, "launch")
+	if err != nil || id != "launch-subtitles" {
+		t.Fatalf("StoreSubtitle() = %q, %v, want launch-subtitles", id, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(s.cfg.Storage.OutputDir, "launch-subtitles.ass")); err != nil || string(data) != "[Script Info]" {
+		t.Errorf("stored sidecar = %q, %v", data, err)
+	}
+	// The generated file is left for the subtitle service to clean up
+	if _, err := os.Stat(subtitles); err != nil {
+		t.Errorf("source subtitle file removed: %v", err)
+	}
+
+	videos, err := s.ListVideos()
+	if err != nil {
+		t.Fatalf("ListVideos() error = %v", err)
+	}
+	if len(videos) != 1 || videos[0].ID != "launch" {
+		t.Errorf("ListVideos() = %+v, want only the video", videos)
+	}
+
+	unsupported := filepath.Join(s.cfg.Storage.TempDir, "subtitles_1234.txt")
+	if err := os.WriteFile(unsupported, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	var vpe *domainErrors.VideoProcessingError
+	if _, err := s.StoreSubtitle(unsupported, "launch"); !stderrors.As(err, &vpe) || vpe.Code != domainErrors.ErrCodeInvalidInput {
+		t.Errorf("StoreSubtitle(.txt) error = %v, want invalid input", err)
+	}
+}
+
+func TestStoreVideoAppliesConfiguredPermissions(t *testing.T) {
+	s := newTestStorage(t, app.CollisionPolicyReject)
+	s.cfg.Storage.FileMode = "0640"
+	s.cfg.Storage.DirMode = "0750"
+
+	videoID, err := s.StoreVideo(writeRender(t, s, "render"), "client")
+	if err != nil {
+		t.Fatalf("StoreVideo() error = %v", err)
+	}
+	path, err := s.GetVideo(videoID)
+	if err != nil {
+		t.Fatalf("GetVideo() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0640 {
+		t.Errorf("video mode = %o, want 640", got)
+	}
+	info, err = os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0750 {
+		t.Errorf("output subdirectory mode = %o, want 750", got)
+	}
+}
+
+func TestStoreVideoInOutputSubdir(t *testing.T) {
+	s := newTestStorage(t, app.CollisionPolicyReject)
+
+	videoID, err := s.StoreVideo(writeRender(t, s, "render"), "clients/acme")
+	if err != nil {
+		t.Fatalf("StoreVideo() error = %v", err)
+	}
+	path, err := s.GetVideo(videoID)
+	if err != nil {
+		t.Fatalf("GetVideo() error = %v", err)
+	}
+	if want := filepath.Join(s.cfg.Storage.OutputDir, "clients", "acme", videoID+".mp4"); path != want {
+		t.Errorf("GetVideo() = %q, want %q", path, want)
+	}
+
+	videos, err := s.ListVideos()
+	if err != nil {
+		t.Fatalf("ListVideos() error = %v", err)
+	}
+	if len(videos) != 1 || videos[0].ID != videoID {
+		t.Errorf("ListVideos() = %+v, want only %s", videos, videoID)
+	}
+
+	if err := s.DeleteVideo(videoID); err != nil {
+		t.Fatalf("DeleteVideo() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("video still exists after DeleteVideo(): %v", err)
+	}
+}
+
+func TestStoreVideoRejectsInvalidOutputSubdir(t *testing.T) {
+	s := newTestStorage(t, app.CollisionPolicyReject)
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(s.cfg.Storage.OutputDir, "linked")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(s.cfg.Storage.OutputDir, "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, subdir := range []string{"../escape", "/absolute", "a//b", "a/b/c/d", "linked", "linked/nested", "file"} {
+		t.Run(subdir, func(t *testing.T) {
+			_, err := s.StoreVideo(writeRender(t, s, "render"), subdir)
+			var vpe *domainErrors.VideoProcessingError
+			if !stderrors.As(err, &vpe) || vpe.Code != domainErrors.ErrCodeInvalidInput {
+				t.Fatalf("StoreVideo(%q) error = %v, want invalid input", subdir, err)
+			}
+		})
+	}
+
+	entries, err := os.ReadDir(outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("stored %d entries through a symbolic link", len(entries))
+	}
+}
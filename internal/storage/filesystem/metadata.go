@@ -0,0 +1,129 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	domainErrors "github.com/activadee/videocraft/internal/pkg/errors"
+)
+
+// metadataIDSuffix names the sidecar holding a stored video's checksum and
+// content type: "<videoID>-metadata.json"
+const metadataIDSuffix = "-metadata"
+
+// sniffLength is how many leading bytes http.DetectContentType considers
+const sniffLength = 512
+
+// objectContentTypes maps stored file extensions to their content type
+var objectContentTypes = map[string]string{
+	".mp4":  "video/mp4",
+	".mov":  "video/quicktime",
+	".webm": "video/webm",
+	".mkv":  "video/x-matroska",
+	".ass":  "text/plain; charset=utf-8",
+	".srt":  "text/plain; charset=utf-8",
+	".vtt":  "text/vtt; charset=utf-8",
+	".json": "application/json",
+}
+
+// VideoMetadata is recorded when a video is stored so downloads can be
+// verified; it is kept in a JSON sidecar so it survives restarts
+type VideoMetadata struct {
+	SHA256      string `json:"sha256,omitempty"`
+	ContentType string `json:"content_type"`
+}
+
+// metadataRecorder computes a video's metadata from the bytes written to it,
+// so it can share the single pass that copies or uploads the file
+type metadataRecorder struct {
+	hash hash.Hash
+	head []byte
+}
+
+func newMetadataRecorder() *metadataRecorder {
+	return &metadataRecorder{hash: sha256.New()}
+}
+
+func (r *metadataRecorder) Write(p []byte) (int, error) {
+	if missing := sniffLength - len(r.head); missing > 0 {
+		r.head = append(r.head, p[:min(missing, len(p))]...)
+	}
+	return r.hash.Write(p)
+}
+
+// metadata returns the checksum and detected content type of everything written
+func (r *metadataRecorder) metadata(ext string) VideoMetadata {
+	return VideoMetadata{
+		SHA256:      hex.EncodeToString(r.hash.Sum(nil)),
+		ContentType: detectContentType(r.head, ext),
+	}
+}
+
+// detectContentType sniffs the leading bytes of a file, falling back to its
+// extension for containers the sniffer does not recognize (e.g. QuickTime)
+func detectContentType(head []byte, ext string) string {
+	if contentType := http.DetectContentType(head); contentType != "application/octet-stream" {
+		return contentType
+	}
+	return contentTypeForExtension(ext)
+}
+
+// contentTypeForExtension returns the content type of a stored file by extension
+func contentTypeForExtension(ext string) string {
+	if contentType, ok := objectContentTypes[ext]; ok {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// metadataPath returns the sidecar path of a video stored in dir
+func (s *storageService) metadataPath(dir, videoID string) string {
+	return filepath.Join(dir, videoID+metadataIDSuffix+manifestExtension)
+}
+
+func (s *storageService) writeMetadata(dir, videoID string, metadata VideoMetadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.metadataPath(dir, videoID), data, s.cfg.Storage.FilePerm())
+}
+
+// readMetadata loads a video's sidecar; os.ErrNotExist means it has none
+func (s *storageService) readMetadata(dir, videoID string) (VideoMetadata, error) {
+	var metadata VideoMetadata
+	data, err := os.ReadFile(s.metadataPath(dir, videoID))
+	if err != nil {
+		return metadata, err
+	}
+	err = json.Unmarshal(data, &metadata)
+	return metadata, err
+}
+
+// GetVideoMetadata returns the checksum and content type recorded when the
+// video was stored. Videos stored without a sidecar report only a content
+// type derived from their extension.
+func (s *storageService) GetVideoMetadata(videoID string) (VideoMetadata, error) {
+	videoPath, err := s.GetVideo(videoID)
+	if err != nil {
+		return VideoMetadata{}, err
+	}
+	sanitizedID, err := s.sanitizeVideoID(videoID)
+	if err != nil {
+		return VideoMetadata{}, err
+	}
+
+	metadata, err := s.readMetadata(filepath.Dir(videoPath), sanitizedID)
+	if os.IsNotExist(err) {
+		return VideoMetadata{ContentType: contentTypeForExtension(filepath.Ext(videoPath))}, nil
+	}
+	if err != nil {
+		return VideoMetadata{}, domainErrors.StorageFailed(err)
+	}
+	return metadata, nil
+}
@@ -0,0 +1,545 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/activadee/videocraft/internal/api/models"
+	"github.com/activadee/videocraft/internal/app"
+	domainErrors "github.com/activadee/videocraft/internal/pkg/errors"
+	"github.com/activadee/videocraft/internal/pkg/logger"
+)
+
+// s3RequestTimeout bounds object operations other than transfers
+const s3RequestTimeout = 30 * time.Second
+
+// s3Service stores videos, subtitles and manifests as objects named
+// "<prefix><id><ext>" in an S3-compatible bucket. Video ID validation and
+// cleanup health tracking are shared with the filesystem backend.
+type s3Service struct {
+	*storageService
+	client *s3Client
+
+	// Serializes downloads of the same object into the cache
+	cacheLocks idLocks
+}
+
+func newS3Service(cfg *app.Config, log logger.Logger) (Service, error) {
+	client, err := newS3Client(cfg.Storage.S3)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Service{
+		storageService: newStorageService(cfg, log),
+		client:         client,
+	}, nil
+}
+
+// StoreVideo uploads a video under a generated ID. Objects are stored flat
+// below the prefix, so output subdirectories are not supported.
+func (s *s3Service) StoreVideo(videoPath, subdir string) (string, error) {
+	s.log.Debugf("Storing video: %s", videoPath)
+	s.warnOutputSubdir(subdir)
+	return s.storeVideoAs(videoPath, uuid.New().String())
+}
+
+// StoreVideoWithID stores a video under a client-supplied ID, resolving
+// collisions with existing objects like the filesystem backend
+func (s *s3Service) StoreVideoWithID(videoPath, desiredID, subdir string) (string, error) {
+	s.log.Debugf("Storing video %s with desired ID: %s", videoPath, desiredID)
+	s.warnOutputSubdir(subdir)
+
+	videoID, err := s.sanitizeVideoID(desiredID)
+	if err != nil {
+		return "", domainErrors.InvalidInput(fmt.Sprintf("invalid output ID: %v", err))
+	}
+
+	unlock := s.outputLocks.lock(videoID)
+	defer unlock()
+
+	existing, err := s.findObjects(videoID)
+	if err != nil {
+		return "", err
+	}
+
+	if len(existing) > 0 {
+		switch s.cfg.Storage.OutputCollisionPolicy {
+		case app.CollisionPolicyOverwrite:
+			s.log.Infof("Overwriting existing video: %s", videoID)
+			for _, object := range existing {
+				if err := s.deleteObject(object.Key); err != nil {
+					return "", domainErrors.StorageFailed(err)
+				}
+			}
+		case app.CollisionPolicyVersion:
+			versionedID, err := s.nextVersionedObjectID(videoID)
+			if err != nil {
+				return "", err
+			}
+			s.log.Infof("Video %s exists, storing as version: %s", videoID, versionedID)
+			videoID = versionedID
+		default:
+			return "", domainErrors.Conflict(fmt.Sprintf("video already exists: %s", videoID))
+		}
+	}
+
+	return s.storeVideoAs(videoPath, videoID)
+}
+
+func (s *s3Service) VideoExists(videoID string) bool {
+	sanitizedID, err := s.sanitizeVideoID(videoID)
+	if err != nil {
+		return false
+	}
+	objects, err := s.findObjects(sanitizedID)
+	return err == nil && len(objects) > 0
+}
+
+// warnOutputSubdir notes that a requested output subdirectory is ignored
+func (s *s3Service) warnOutputSubdir(subdir string) {
+	if subdir != "" {
+		s.log.Warnf("Output subdirectory %q is not supported by the s3 storage backend, storing below the bucket prefix", subdir)
+	}
+}
+
+// nextVersionedObjectID appends the first free "-vN" suffix to a video ID
+func (s *s3Service) nextVersionedObjectID(videoID string) (string, error) {
+	for version := 2; version < 10000; version++ {
+		candidate := fmt.Sprintf("%s-v%d", videoID, version)
+		objects, err := s.findObjects(candidate)
+		if err != nil {
+			return "", err
+		}
+		if len(objects) == 0 {
+			return candidate, nil
+		}
+	}
+	return "", domainErrors.Conflict(fmt.Sprintf("no free version left for video: %s", videoID))
+}
+
+// storeVideoAs uploads a rendered video under the given ID and removes the local render
+func (s *s3Service) storeVideoAs(videoPath, videoID string) (string, error) {
+	ext := filepath.Ext(videoPath)
+	if ext == "" {
+		ext = ".mp4"
+	}
+
+	metadata, err := s.uploadFile(videoPath, videoID+ext)
+	if err != nil {
+		return "", domainErrors.StorageFailed(err)
+	}
+	if err := s.putMetadata(videoID, metadata); err != nil {
+		s.log.Warnf("Failed to write metadata for video %s: %v", videoID, err)
+	}
+
+	if err := os.Remove(videoPath); err != nil {
+		s.log.Warnf("Failed to remove temp file %s: %v", videoPath, err)
+	}
+
+	s.log.Infof("Video stored with ID: %s", videoID)
+	return videoID, nil
+}
+
+// StoreSubtitle uploads a subtitle file as "<videoID>-subtitles". The source
+// file is left for the caller to clean up.
+func (s *s3Service) StoreSubtitle(subtitlePath, videoID string) (string, error) {
+	return s.storeSubtitleAs(subtitlePath, videoID+subtitleIDSuffix)
+}
+
+// StoreCaption uploads an exported caption file as "<videoID>-<format>-subtitles"
+func (s *s3Service) StoreCaption(captionPath, videoID, format string) (string, error) {
+	return s.storeSubtitleAs(captionPath, videoID+"-"+format+subtitleIDSuffix)
+}
+
+// storeSubtitleAs uploads a subtitle file under subtitleID
+func (s *s3Service) storeSubtitleAs(subtitlePath, subtitleID string) (string, error) {
+	if err := s.validateVideoID(subtitleID); err != nil {
+		return "", domainErrors.InvalidInput(fmt.Sprintf("invalid subtitle ID: %v", err))
+	}
+
+	ext := filepath.Ext(subtitlePath)
+	if !subtitleExtensions[ext] {
+		return "", domainErrors.InvalidInput(fmt.Sprintf("unsupported subtitle file type: %s", ext))
+	}
+
+	if _, err := s.uploadFile(subtitlePath, subtitleID+ext); err != nil {
+		return "", domainErrors.StorageFailed(err)
+	}
+
+	s.log.Infof("Subtitle sidecar stored with ID: %s", subtitleID)
+	return subtitleID, nil
+}
+
+// StoreManifest uploads a render manifest as "<videoID>-manifest"
+func (s *s3Service) StoreManifest(data []byte, videoID string) (string, error) {
+	manifestID := videoID + manifestIDSuffix
+	if err := s.validateVideoID(manifestID); err != nil {
+		return "", domainErrors.InvalidInput(fmt.Sprintf("invalid manifest ID: %v", err))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+
+	key := s.objectKey(manifestID + manifestExtension)
+	if err := s.client.putObject(ctx, key, bytes.NewReader(data), int64(len(data)), contentTypeForExtension(manifestExtension)); err != nil {
+		return "", domainErrors.StorageFailed(err)
+	}
+
+	s.log.Infof("Render manifest stored with ID: %s", manifestID)
+	return manifestID, nil
+}
+
+// GetVideo downloads the object stored under videoID into the cache
+// directory and returns the local path, reusing an up-to-date cached copy
+func (s *s3Service) GetVideo(videoID string) (string, error) {
+	s.log.Debugf("Getting video: %s", videoID)
+
+	if err := s.validateVideoID(videoID); err != nil {
+		s.logSecurityViolation("Invalid video ID provided", map[string]interface{}{
+			"video_id": videoID,
+			"error":    err.Error(),
+		})
+		return "", err
+	}
+	sanitizedID, err := s.sanitizeVideoID(videoID)
+	if err != nil {
+		return "", err
+	}
+
+	objects, err := s.findObjects(sanitizedID)
+	if err != nil {
+		return "", err
+	}
+	if len(objects) == 0 {
+		return "", domainErrors.FileNotFound(videoID)
+	}
+	object := objects[0]
+
+	unlock := s.cacheLocks.lock(sanitizedID)
+	defer unlock()
+
+	cachePath := filepath.Join(s.cfg.Storage.S3.CacheDir, path.Base(object.Key))
+	if info, err := os.Stat(cachePath); err == nil && info.Size() == object.Size && !info.ModTime().Before(object.LastModified) {
+		return cachePath, nil
+	}
+
+	if err := s.downloadObject(object.Key, cachePath); err != nil {
+		if errors.Is(err, errObjectNotFound) {
+			return "", domainErrors.FileNotFound(videoID)
+		}
+		return "", domainErrors.StorageFailed(err)
+	}
+	return cachePath, nil
+}
+
+// GetDownloadURL presigns a GET for the video's object, letting clients
+// fetch it from the bucket directly until ttl elapses
+func (s *s3Service) GetDownloadURL(videoID string, ttl time.Duration) (string, error) {
+	if err := s.checkDownloadTTL(ttl); err != nil {
+		return "", err
+	}
+	if err := s.validateVideoID(videoID); err != nil {
+		return "", err
+	}
+	sanitizedID, err := s.sanitizeVideoID(videoID)
+	if err != nil {
+		return "", err
+	}
+
+	objects, err := s.findObjects(sanitizedID)
+	if err != nil {
+		return "", err
+	}
+	if len(objects) == 0 {
+		return "", domainErrors.FileNotFound(videoID)
+	}
+
+	key := objects[0].Key
+	query := url.Values{
+		"response-content-disposition": {fmt.Sprintf(`attachment; filename="video_%s"`, path.Base(key))},
+	}
+	downloadURL, err := s.client.presignGet(key, ttl, query, time.Now())
+	if err != nil {
+		return "", domainErrors.StorageFailed(err)
+	}
+	return downloadURL, nil
+}
+
+func (s *s3Service) DeleteVideo(videoID string) error {
+	s.log.Debugf("Deleting video: %s", videoID)
+
+	if err := s.validateVideoID(videoID); err != nil {
+		return err
+	}
+	sanitizedID, err := s.sanitizeVideoID(videoID)
+	if err != nil {
+		return err
+	}
+
+	objects, err := s.findObjects(sanitizedID)
+	if err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return domainErrors.FileNotFound(videoID)
+	}
+
+	for _, object := range objects {
+		if err := s.deleteObject(object.Key); err != nil {
+			return domainErrors.StorageFailed(err)
+		}
+		if err := os.Remove(filepath.Join(s.cfg.Storage.S3.CacheDir, path.Base(object.Key))); err != nil && !os.IsNotExist(err) {
+			s.log.Warnf("Failed to remove cached copy of %s: %v", object.Key, err)
+		}
+	}
+	if err := s.deleteObject(s.metadataKey(sanitizedID)); err != nil {
+		s.log.Warnf("Failed to remove metadata of video %s: %v", videoID, err)
+	}
+
+	s.log.Infof("Video deleted: %s", videoID)
+	return nil
+}
+
+func (s *s3Service) ListVideos() ([]models.VideoInfo, error) {
+	s.log.Debug("Listing videos")
+
+	objects, err := s.listObjects("")
+	if err != nil {
+		return nil, domainErrors.StorageFailed(err)
+	}
+
+	videos := make([]models.VideoInfo, 0, len(objects))
+	for _, object := range objects {
+		filename, ok := s.storedFilename(object.Key)
+		if !ok {
+			continue
+		}
+
+		// Subtitle sidecars and manifests are artifacts of a video, not videos themselves
+		ext := path.Ext(filename)
+		if subtitleExtensions[ext] || ext == manifestExtension {
+			continue
+		}
+
+		// Checksums live in per-video metadata objects and are not fetched
+		// here to keep listing to one request per page
+		videos = append(videos, models.VideoInfo{
+			ID:          strings.TrimSuffix(filename, ext),
+			Filename:    filename,
+			Size:        object.Size,
+			CreatedAt:   object.LastModified.Format(time.RFC3339),
+			ContentType: contentTypeForExtension(ext),
+		})
+	}
+
+	s.log.Debugf("Found %d videos", len(videos))
+	return videos, nil
+}
+
+// CleanupOldFiles deletes objects past retention along with expired cached
+// copies, renders and temp files, keeping what unfinished jobs may still use.
+// Only objects this backend stored are deleted, since the prefix may be shared.
+// Failures are collected and escalate like those of the filesystem backend.
+func (s *s3Service) CleanupOldFiles(activeSince time.Time) (int, error) {
+	s.log.Debug("Starting cleanup of old files")
+
+	cutoffTime := s.cleanupCutoff(activeSince)
+
+	var failures []error
+	objects, err := s.listObjects("")
+	if err != nil {
+		failures = append(failures, err)
+	}
+	deletedCount := 0
+	for _, object := range objects {
+		// The prefix may be shared, so only objects this backend wrote expire
+		if _, ok := s.storedFilename(object.Key); !ok || !object.LastModified.Before(cutoffTime) {
+			continue
+		}
+		if err := s.deleteObject(object.Key); err != nil {
+			s.log.Debugf("Failed to delete old object %s: %v", object.Key, err)
+			failures = append(failures, err)
+			continue
+		}
+		deletedCount++
+	}
+	if deletedCount > 0 {
+		s.log.Infof("Deleted %d old objects from bucket %s", deletedCount, s.cfg.Storage.S3.Bucket)
+	}
+
+	removed := deletedCount
+	for _, dir := range []string{s.cfg.Storage.S3.CacheDir, s.cfg.Storage.OutputDir, s.cfg.Storage.TempDir} {
+		count, dirFailures := s.cleanupDirectory(dir, cutoffTime)
+		removed += count
+		failures = append(failures, dirFailures...)
+	}
+
+	return removed, s.finishCleanup(failures)
+}
+
+// storedFilename returns the file name of an object this backend stored,
+// "<prefix><id><ext>" with a storage ID and a stored file type. Objects in
+// sub-prefixes or with other names were written by someone else.
+func (s *s3Service) storedFilename(key string) (string, bool) {
+	if !strings.HasPrefix(key, s.cfg.Storage.S3.Prefix) {
+		return "", false
+	}
+	filename := strings.TrimPrefix(key, s.cfg.Storage.S3.Prefix)
+	if strings.Contains(filename, "/") {
+		return "", false
+	}
+	ext := path.Ext(filename)
+	if _, ok := objectContentTypes[ext]; !ok {
+		return "", false
+	}
+	return filename, validVideoIDRegex.MatchString(strings.TrimSuffix(filename, ext))
+}
+
+// objectKey returns the bucket key of a stored file name
+func (s *s3Service) objectKey(filename string) string {
+	return s.cfg.Storage.S3.Prefix + filename
+}
+
+// findObjects returns the objects stored for a sanitized ID regardless of extension
+func (s *s3Service) findObjects(id string) ([]s3Object, error) {
+	objects, err := s.listObjects(id + ".")
+	if err != nil {
+		return nil, domainErrors.StorageFailed(err)
+	}
+	return objects, nil
+}
+
+// listObjects lists the objects below the configured prefix starting with namePrefix
+func (s *s3Service) listObjects(namePrefix string) ([]s3Object, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+	return s.client.listObjects(ctx, s.objectKey(namePrefix))
+}
+
+func (s *s3Service) deleteObject(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+
+	if err := s.client.deleteObject(ctx, key); err != nil && !errors.Is(err, errObjectNotFound) {
+		return err
+	}
+	return nil
+}
+
+// uploadFile streams a local file to the object for filename, checksumming
+// it on the way
+func (s *s3Service) uploadFile(localPath, filename string) (VideoMetadata, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return VideoMetadata{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return VideoMetadata{}, err
+	}
+
+	ext := filepath.Ext(filename)
+	recorder := newMetadataRecorder()
+	body := io.TeeReader(file, recorder)
+	if err := s.client.putObject(context.Background(), s.objectKey(filename), body, info.Size(), contentTypeForExtension(ext)); err != nil {
+		return VideoMetadata{}, err
+	}
+	return recorder.metadata(ext), nil
+}
+
+// metadataKey returns the key of a video's metadata object
+func (s *s3Service) metadataKey(videoID string) string {
+	return s.objectKey(videoID + metadataIDSuffix + manifestExtension)
+}
+
+func (s *s3Service) putMetadata(videoID string, metadata VideoMetadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+	return s.client.putObject(ctx, s.metadataKey(videoID), bytes.NewReader(data), int64(len(data)), contentTypeForExtension(manifestExtension))
+}
+
+// GetVideoMetadata returns the checksum and content type recorded when the
+// video was uploaded, or only an extension-derived content type without one
+func (s *s3Service) GetVideoMetadata(videoID string) (VideoMetadata, error) {
+	if err := s.validateVideoID(videoID); err != nil {
+		return VideoMetadata{}, err
+	}
+	sanitizedID, err := s.sanitizeVideoID(videoID)
+	if err != nil {
+		return VideoMetadata{}, err
+	}
+
+	objects, err := s.findObjects(sanitizedID)
+	if err != nil {
+		return VideoMetadata{}, err
+	}
+	if len(objects) == 0 {
+		return VideoMetadata{}, domainErrors.FileNotFound(videoID)
+	}
+	fallback := VideoMetadata{ContentType: contentTypeForExtension(path.Ext(objects[0].Key))}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+	body, err := s.client.getObject(ctx, s.metadataKey(sanitizedID))
+	if errors.Is(err, errObjectNotFound) {
+		return fallback, nil
+	}
+	if err != nil {
+		return VideoMetadata{}, domainErrors.StorageFailed(err)
+	}
+	defer body.Close()
+
+	var metadata VideoMetadata
+	if err := json.NewDecoder(io.LimitReader(body, 64*1024)).Decode(&metadata); err != nil {
+		return VideoMetadata{}, domainErrors.StorageFailed(err)
+	}
+	return metadata, nil
+}
+
+// downloadObject streams an object to localPath through a temporary file
+func (s *s3Service) downloadObject(key, localPath string) error {
+	if err := os.MkdirAll(filepath.Dir(localPath), s.cfg.Storage.DirPerm()); err != nil {
+		return err
+	}
+
+	body, err := s.client.getObject(context.Background(), key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	tmpPath := localPath + ".part"
+	file, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, s.cfg.Storage.FilePerm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, localPath)
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/activadee/videocraft/internal/app"
+)
+
+// unsignedPayload skips hashing request bodies, so uploads stream from disk
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// errObjectNotFound is returned for keys missing from the bucket
+var errObjectNotFound = errors.New("object not found")
+
+// s3Client is a minimal S3 REST client signing requests with AWS Signature
+// Version 4. It covers the object operations of the s3 storage backend.
+type s3Client struct {
+	cfg  app.S3Config
+	base *url.URL
+	http *http.Client
+}
+
+// s3Object is an object listed from the bucket
+type s3Object struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+// listBucketResult is the ListObjectsV2 response
+type listBucketResult struct {
+	Contents              []s3Object `xml:"Contents"`
+	IsTruncated           bool       `xml:"IsTruncated"`
+	NextContinuationToken string     `xml:"NextContinuationToken"`
+}
+
+func newS3Client(cfg app.S3Config) (*s3Client, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	base, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	return &s3Client{cfg: cfg, base: base, http: &http.Client{}}, nil
+}
+
+// putObject uploads size bytes from body under key
+func (c *s3Client) putObject(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	req, err := c.newRequest(ctx, http.MethodPut, key, nil, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// getObject returns the content of key; the caller closes it
+func (c *s3Client) getObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// deleteObject removes key; deleting a missing key succeeds
+func (c *s3Client) deleteObject(ctx context.Context, key string) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// listObjects returns every object whose key starts with prefix
+func (c *s3Client) listObjects(ctx context.Context, prefix string) ([]s3Object, error) {
+	var objects []s3Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := c.newRequest(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode S3 object listing: %w", err)
+		}
+
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// newRequest builds a signed request for key ("" addresses the bucket)
+func (c *s3Client) newRequest(ctx context.Context, method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	target := *c.base
+	path := "/" + key
+	if c.cfg.PathStyle {
+		path = "/" + c.cfg.Bucket + path
+	} else {
+		target.Host = c.cfg.Bucket + "." + target.Host
+	}
+	target.Path = c.base.Path + path
+	target.RawPath = awsEscape(c.base.Path, false) + awsEscape(path, false)
+	target.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 request: %w", err)
+	}
+	c.sign(req, time.Now())
+	return req, nil
+}
+
+// do sends a request and turns error responses into errors
+func (c *s3Client) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 %s failed: %w", req.Method, err)
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errObjectNotFound
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, fmt.Errorf("S3 %s %s returned status %d: %s", req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(message)))
+}
+
+// sign adds AWS Signature Version 4 headers to req
+func (c *s3Client) sign(req *http.Request, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + unsignedPayload + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	scope := c.scope(date)
+	signature := c.signature(date, amzDate, scope, canonicalRequest)
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// presignGet returns a GET URL for key that is valid for ttl without
+// credentials, using query-string authentication
+func (c *s3Client) presignGet(key string, ttl time.Duration, query url.Values, now time.Time) (string, error) {
+	req, err := c.newRequest(context.Background(), http.MethodGet, key, nil, nil)
+	if err != nil {
+		return "", err
+	}
+
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	scope := c.scope(date)
+
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", c.cfg.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(ttl/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+	rawQuery := canonicalQuery(query)
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		req.URL.EscapedPath(),
+		rawQuery,
+		"host:" + req.URL.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	req.URL.RawQuery = rawQuery + "&X-Amz-Signature=" + c.signature(date, amzDate, scope, canonicalRequest)
+	return req.URL.String(), nil
+}
+
+// scope is the SigV4 credential scope for a signing date
+func (c *s3Client) scope(date string) string {
+	return date + "/" + c.cfg.Region + "/s3/aws4_request"
+}
+
+// signature signs a canonical request with the key derived for date
+func (c *s3Client) signature(date, amzDate, scope, canonicalRequest string) string {
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+c.cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, c.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query parameters sorted by key as SigV4 requires
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, awsEscape(key, true)+"="+awsEscape(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape percent-encodes everything but unreserved characters, and
+// slashes unless encodeSlash is set
+func awsEscape(s string, encodeSlash bool) string {
+	var builder strings.Builder
+	for _, b := range []byte(s) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~':
+			builder.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			builder.WriteByte(b)
+		default:
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
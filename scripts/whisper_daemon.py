@@ -265,12 +265,20 @@ class WhisperDaemon:
                         f"{cleanup_error}"
                     )
 
-            # Extract word timestamps for progressive subtitles
+            # Extract word timestamps for progressive subtitles, with Whisper's
+            # per-word probability as the confidence used for caption filtering
             word_timestamps_list = []
             if "segments" in result:
                 for segment in result["segments"]:
-                    if "words" in segment:
-                        word_timestamps_list.extend(segment["words"])
+                    for word in segment.get("words", []):
+                        entry = {
+                            "word": word["word"],
+                            "start": word["start"],
+                            "end": word["end"],
+                        }
+                        if word.get("probability") is not None:
+                            entry["probability"] = float(word["probability"])
+                        word_timestamps_list.append(entry)
 
             response = {
                 "success": True,